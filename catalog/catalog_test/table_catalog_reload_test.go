@@ -8,14 +8,18 @@ import (
 	"github.com/ryogrid/SamehadaDB/catalog"
 	"github.com/ryogrid/SamehadaDB/common"
 	"github.com/ryogrid/SamehadaDB/samehada"
+	"github.com/ryogrid/SamehadaDB/storage/access"
 	"github.com/ryogrid/SamehadaDB/storage/buffer"
 	"github.com/ryogrid/SamehadaDB/storage/index/index_constants"
 	"github.com/ryogrid/SamehadaDB/storage/table/column"
 	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
 	testingpkg "github.com/ryogrid/SamehadaDB/testing"
 	"github.com/ryogrid/SamehadaDB/types"
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 // test reloading serialized catalog info in db file at lauching system
@@ -50,7 +54,7 @@ func TestTableCatalogReload(t *testing.T) {
 	//catalog := GetCatalog(bpm)
 	catalog_recov := catalog.RecoveryCatalogFromCatalogPage(samehada_instance_new.GetBufferPoolManager(), samehada_instance_new.GetLogManager(), samehada_instance_new.GetLockManager(), txn_new)
 
-	columnToCheck := catalog_recov.GetTableByOID(1).Schema().GetColumn(1)
+	columnToCheck := catalog_recov.GetTableByName("test_1").Schema().GetColumn(1)
 
 	testingpkg.Assert(t, columnToCheck.GetColumnName() == "b", "")
 	testingpkg.Assert(t, columnToCheck.GetType() == 4, "")
@@ -60,3 +64,217 @@ func TestTableCatalogReload(t *testing.T) {
 	//samehada_instance.Shutdown(true)
 	common.TempSuppressOnMemStorageMutex.Unlock()
 }
+
+// writes db file whose catalog tables have layout of passed format version.
+// version 1 has no catalog_version table and its columns catalog has 9 columns.
+// columns catalog of version 2 has 23 columns (no default_seq)
+func writeOldFormatDB(dbName string, version int32) {
+	samehada_instance := samehada.NewSamehadaInstance(dbName, common.BufferPoolMaxFrameNumForTest)
+	samehada_instance.GetLogManager().DeactivateLogging()
+	bpm := samehada_instance.GetBufferPoolManager()
+	log_manager := samehada_instance.GetLogManager()
+	lock_manager := samehada_instance.GetLockManager()
+	txn := samehada_instance.GetTransactionManager().Begin(nil)
+
+	columnCount := 9
+	if version == 2 {
+		columnCount = 23
+	}
+	oldColumnsCatalogSchema := schema.NewSchema(catalog.ColumnsCatalogSchema().GetColumns()[:columnCount])
+	tableCatalogHeap := access.NewTableHeap(bpm, log_manager, lock_manager, txn)
+	columnsCatalogHeap := access.NewTableHeap(bpm, log_manager, lock_manager, txn)
+	userTableHeap := access.NewTableHeap(bpm, log_manager, lock_manager, txn)
+
+	insertTable := func(oid uint32, name string, heap *access.TableHeap, schema_ *schema.Schema) {
+		row := []types.Value{types.NewInteger(int32(oid)), types.NewVarchar(name), types.NewInteger(int32(heap.GetFirstPageId()))}
+		tableCatalogHeap.InsertTuple(tuple.NewTupleFromSchema(row, catalog.TableCatalogSchema()), txn, oid)
+		for _, col := range schema_.GetColumns() {
+			hasIndex := int32(0)
+			if col.HasIndex() {
+				hasIndex = 1
+			}
+			row := []types.Value{
+				types.NewInteger(int32(oid)),
+				types.NewInteger(int32(col.GetType())),
+				types.NewVarchar(col.GetColumnName()),
+				types.NewInteger(int32(col.FixedLength())),
+				types.NewInteger(int32(col.VariableLength())),
+				types.NewInteger(int32(col.GetOffset())),
+				types.NewInteger(hasIndex),
+				types.NewInteger(int32(col.IndexKind())),
+				types.NewInteger(int32(col.IndexHeaderPageId()))}
+			if columnCount == 23 {
+				// no constraint, DEFAULT, FOREIGN KEY, expression, AUTO_INCREMENT and DECIMAL attribute
+				row = append(row, types.NewInteger(0), types.NewInteger(0), types.NewInteger(0), types.NewInteger(0), types.NewNullOfType(types.Varchar),
+					types.NewInteger(-1), types.NewNullOfType(types.Varchar), types.NewInteger(0), types.NewInteger(0),
+					types.NewNullOfType(types.Varchar), types.NewNullOfType(types.Varchar), types.NewNullOfType(types.Varchar),
+					types.NewInteger(0), types.NewInteger(0))
+			}
+			columnsCatalogHeap.InsertTuple(tuple.NewTupleFromSchema(row, oldColumnsCatalogSchema), txn, 0)
+		}
+	}
+
+	columnA := column.NewColumn("a", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	columnB := column.NewColumn("b", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	userSchema := schema.NewSchema([]*column.Column{columnA, columnB})
+	insertTable(0, "columns_catalog", columnsCatalogHeap, oldColumnsCatalogSchema)
+	insertTable(1, "test_1", userTableHeap, userSchema)
+	userTableHeap.InsertTuple(tuple.NewTupleFromSchema([]types.Value{types.NewInteger(10), types.NewVarchar("foo")}, userSchema), txn, 1)
+	if version > 1 {
+		versionCatalogHeap := access.NewTableHeap(bpm, log_manager, lock_manager, txn)
+		insertTable(2, catalog.CatalogVersionCatalogName, versionCatalogHeap, catalog.CatalogVersionCatalogSchema())
+		versionCatalogHeap.InsertTuple(tuple.NewTupleFromSchema([]types.Value{types.NewInteger(version)}, catalog.CatalogVersionCatalogSchema()), txn, 2)
+	}
+
+	samehada_instance.GetTransactionManager().Commit(txn)
+	samehada_instance.Shutdown(false)
+}
+
+// test migration of db file of old catalog format and rejection of unknown format
+func TestCatalogFormatVersion(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	os.Remove(t.Name() + ".db")
+	os.Remove(t.Name() + ".log")
+	writeOldFormatDB(t.Name(), 1)
+
+	// columns catalog is migrated at launch
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, results := db.ExecuteSQL("SELECT a, b FROM test_1;")
+	testingpkg.SimpleAssert(t, err == nil && len(results) == 1 && results[0][0].(int32) == 10 && results[0][1].(string) == "foo")
+	err, _ = db.ExecuteSQL("INSERT INTO test_1(a, b) VALUES (20, 'bar');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE test_2(c INT PRIMARY KEY, d DECIMAL(5, 2) DEFAULT 1.5);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO test_2(c) VALUES (1);")
+	testingpkg.SimpleAssert(t, err == nil)
+	db.Shutdown()
+
+	// migration is not done again
+	db = samehada.NewSamehadaDB(t.Name(), 200)
+	err, results = db.ExecuteSQL("SELECT a FROM test_1 ORDER BY a;")
+	testingpkg.SimpleAssert(t, err == nil && len(results) == 2 && results[0][0].(int32) == 10 && results[1][0].(int32) == 20)
+	err, results = db.ExecuteSQL("SELECT d FROM test_2;")
+	testingpkg.SimpleAssert(t, err == nil && len(results) == 1 && fmt.Sprint(results[0][0]) == "1.50")
+	err, _ = db.ExecuteSQL("INSERT INTO test_2(c) VALUES (1);")
+	testingpkg.SimpleAssert(t, err != nil)
+	db.Shutdown()
+
+	// columns catalog of version 2 is migrated too
+	os.Remove(t.Name() + ".db")
+	os.Remove(t.Name() + ".log")
+	writeOldFormatDB(t.Name(), 2)
+	db = samehada.NewSamehadaDB(t.Name(), 200)
+	err, results = db.ExecuteSQL("SELECT a, b FROM test_1;")
+	testingpkg.SimpleAssert(t, err == nil && len(results) == 1 && results[0][0].(int32) == 10 && results[0][1].(string) == "foo")
+	err, _ = db.ExecuteSQL("CREATE SEQUENCE sq;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE test_2(c INT PRIMARY KEY DEFAULT NEXTVAL('sq'), d INT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	db.Shutdown()
+
+	db = samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ = db.ExecuteSQL("INSERT INTO test_2(d) VALUES (1);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results = db.ExecuteSQL("SELECT c FROM test_2;")
+	testingpkg.SimpleAssert(t, err == nil && len(results) == 1 && results[0][0].(int32) == 1)
+	db.Shutdown()
+
+	// db file written by newer version is rejected
+	os.Remove(t.Name() + ".db")
+	os.Remove(t.Name() + ".log")
+	writeOldFormatDB(t.Name(), catalog.CatalogFormatVersion+1)
+	func() {
+		defer func() {
+			r := recover()
+			testingpkg.SimpleAssert(t, r != nil && strings.Contains(fmt.Sprint(r), fmt.Sprintf("catalog format version %d of the db file is not supported", catalog.CatalogFormatVersion+1)))
+		}()
+		samehada.NewSamehadaDB(t.Name(), 200)
+	}()
+
+	common.TempSuppressOnMemStorage = false
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+// test that sequence created by aborted transaction doesn't remain on memory
+func TestCreateSequenceRollback(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	os.Remove(t.Name() + ".db")
+	os.Remove(t.Name() + ".log")
+	samehada_instance := samehada.NewSamehadaInstance(t.Name(), common.BufferPoolMaxFrameNumForTest)
+	txnMgr := samehada_instance.GetTransactionManager()
+
+	txn := txnMgr.Begin(nil)
+	catalog_ := catalog.BootstrapCatalog(samehada_instance.GetBufferPoolManager(), samehada_instance.GetLogManager(), samehada_instance.GetLockManager(), txn)
+	catalog_.SetTransactionManager(txnMgr)
+	txnMgr.Commit(txn)
+
+	txn = txnMgr.Begin(nil)
+	testingpkg.SimpleAssert(t, catalog_.CreateSequence("sq", 1, 1, txn) == nil)
+	testingpkg.SimpleAssert(t, catalog_.SequenceExists("sq"))
+	txnMgr.Abort(catalog_, txn)
+	testingpkg.SimpleAssert(t, !catalog_.SequenceExists("sq"))
+	_, err := catalog_.NextVal("sq")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// same name can be used again
+	txn = txnMgr.Begin(nil)
+	testingpkg.SimpleAssert(t, catalog_.CreateSequence("sq", 1, 1, txn) == nil)
+	txnMgr.Commit(txn)
+	testingpkg.SimpleAssert(t, catalog_.SequenceExists("sq"))
+	val, err := catalog_.NextVal("sq")
+	testingpkg.SimpleAssert(t, err == nil && val == 1)
+
+	samehada_instance.Shutdown(false)
+	common.TempSuppressOnMemStorage = false
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+// test that lock for UNIQUE check is shared with TableMetadata which replaces old one on schema change
+func TestUniqueCheckLockAfterSchemaChange(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	os.Remove(t.Name() + ".db")
+	os.Remove(t.Name() + ".log")
+	samehada_instance := samehada.NewSamehadaInstance(t.Name(), common.BufferPoolMaxFrameNumForTest)
+	txnMgr := samehada_instance.GetTransactionManager()
+
+	txn := txnMgr.Begin(nil)
+	catalog_ := catalog.BootstrapCatalog(samehada_instance.GetBufferPoolManager(), samehada_instance.GetLogManager(), samehada_instance.GetLockManager(), txn)
+	columnA := column.NewColumn("a", types.Integer, true, index_constants.INDEX_KIND_SKIP_LIST, types.PageID(-1), nil)
+	columnA.SetIsUnique(true)
+	oldMetadata := catalog_.CreateTable("test_1", schema.NewSchema([]*column.Column{columnA}), txn)
+	txnMgr.Commit(txn)
+
+	txn = txnMgr.Begin(nil)
+	columnA2 := column.NewColumn("a", types.Integer, true, index_constants.INDEX_KIND_SKIP_LIST, types.PageID(-1), nil)
+	columnA2.SetIsUnique(true)
+	columnB := column.NewColumn("b", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	newMetadata, err := catalog_.AlterTableSchema(oldMetadata, schema.NewSchema([]*column.Column{columnA2, columnB}), []int{0, -1}, []types.Value{types.Value{}, types.NewNullOfType(types.Integer)}, txn)
+	testingpkg.SimpleAssert(t, err == nil && newMetadata != oldMetadata)
+	txnMgr.Commit(txn)
+
+	// transaction which got old metadata before the change still excludes one which uses new metadata
+	oldMetadata.LockUniqueCheck()
+	locked := make(chan bool)
+	go func() {
+		newMetadata.LockUniqueCheck()
+		locked <- true
+		newMetadata.UnlockUniqueCheck()
+	}()
+	select {
+	case <-locked:
+		testingpkg.SimpleAssert(t, false)
+	case <-time.After(100 * time.Millisecond):
+	}
+	oldMetadata.UnlockUniqueCheck()
+	testingpkg.SimpleAssert(t, <-locked)
+
+	samehada_instance.Shutdown(false)
+	common.TempSuppressOnMemStorage = false
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
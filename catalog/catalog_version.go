@@ -0,0 +1,144 @@
+package catalog
+
+import (
+	"fmt"
+	"github.com/ryogrid/SamehadaDB/recovery"
+	"github.com/ryogrid/SamehadaDB/storage/access"
+	"github.com/ryogrid/SamehadaDB/storage/buffer"
+	"github.com/ryogrid/SamehadaDB/storage/index/index_constants"
+	"github.com/ryogrid/SamehadaDB/storage/page"
+	"github.com/ryogrid/SamehadaDB/storage/table/column"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+const CatalogVersionCatalogName = "catalog_version"
+
+// CatalogFormatVersion is format version of catalog tables which this code writes.
+// it must be incremented when layout of catalog tables changes, and migration from
+// the previous version should be added to migrateCatalog.
+//
+//	1: columns catalog has 9 columns (table_oid ... index_header_page_id). catalog_version table doesn't exist
+//	2: columns catalog has columns of constraints, DEFAULT value, expressions, AUTO_INCREMENT and DECIMAL.
+//	   next_value and increment of sequences catalog are BIGINT
+//	3: columns catalog has default_seq column
+const CatalogFormatVersion = 3
+
+// readCatalogFormatVersion reads format version of catalog tables in db file.
+// table catalog is read directly because layout of other catalog tables depends on the version
+func readCatalogFormatVersion(bpm *buffer.BufferPoolManager, log_manager *recovery.LogManager, lock_manager *access.LockManager, txn *access.Transaction) int32 {
+	tableCatalogHeapIt := access.InitTableHeap(bpm, TableCatalogPageId, log_manager, lock_manager).Iterator(txn)
+	for tuple_ := tableCatalogHeapIt.Current(); !tableCatalogHeapIt.End(); tuple_ = tableCatalogHeapIt.Next() {
+		if tuple_.GetValue(TableCatalogSchema(), TableCatalogSchema().GetColIndex("name")).ToVarchar() != CatalogVersionCatalogName {
+			continue
+		}
+		firstPage := tuple_.GetValue(TableCatalogSchema(), TableCatalogSchema().GetColIndex("first_page")).ToInteger()
+		versionCatalogHeapIt := access.InitTableHeap(bpm, types.PageID(firstPage), log_manager, lock_manager).Iterator(txn)
+		if versionCatalogHeapIt.End() {
+			panic("catalog_version table has no entry.")
+		}
+		return versionCatalogHeapIt.Current().GetValue(CatalogVersionCatalogSchema(), CatalogVersionCatalogSchema().GetColIndex("version")).ToInteger()
+	}
+	// catalog_version table was added on version 2
+	return 1
+}
+
+// migrateCatalog converts catalog tables of older format version to current one.
+// it is called at launch before catalog tables are read, and db file of unknown version is rejected
+func migrateCatalog(version int32, bpm *buffer.BufferPoolManager, log_manager *recovery.LogManager, lock_manager *access.LockManager, txn *access.Transaction) {
+	if version < 1 || version > CatalogFormatVersion {
+		panic(fmt.Sprintf("catalog format version %d of the db file is not supported. supported versions are 1 to %d.", version, CatalogFormatVersion))
+	}
+	if version < 2 {
+		// sequences catalog doesn't exist on version 1, so only columns catalog is converted
+		migrateColumnsCatalogFromV1(bpm, log_manager, lock_manager, txn)
+	} else if version < 3 {
+		migrateColumnsCatalogFromV2(bpm, log_manager, lock_manager, txn)
+	}
+}
+
+// migrateColumnsCatalogFromV1 rewrites all entries of columns catalog with current layout.
+// added columns get values of a column which has no constraint and no expression
+func migrateColumnsCatalogFromV1(bpm *buffer.BufferPoolManager, log_manager *recovery.LogManager, lock_manager *access.LockManager, txn *access.Transaction) {
+	oldSchema := columnsCatalogSchemaV1()
+	rewriteColumnsCatalog(bpm, log_manager, lock_manager, txn, func(tuple_ *tuple.Tuple) []types.Value {
+		tableOid := tuple_.GetValue(oldSchema, oldSchema.GetColIndex("table_oid")).ToInteger()
+		columnType := tuple_.GetValue(oldSchema, oldSchema.GetColIndex("type")).ToInteger()
+		columnName := tuple_.GetValue(oldSchema, oldSchema.GetColIndex("name")).ToVarchar()
+		indexHeaderPageId := tuple_.GetValue(oldSchema, oldSchema.GetColIndex("index_header_page_id")).ToInteger()
+
+		column_ := column.NewColumn(columnName, types.TypeID(columnType), false, index_constants.INDEX_KIND_INVAID, types.PageID(indexHeaderPageId), nil)
+		column_.SetFixedLength(uint32(tuple_.GetValue(oldSchema, oldSchema.GetColIndex("fixed_length")).ToInteger()))
+		column_.SetVariableLength(uint32(tuple_.GetValue(oldSchema, oldSchema.GetColIndex("variable_length")).ToInteger()))
+		column_.SetOffset(uint32(tuple_.GetValue(oldSchema, oldSchema.GetColIndex("offset")).ToInteger()))
+		column_.SetHasIndex(Int32toBool(tuple_.GetValue(oldSchema, oldSchema.GetColIndex("has_index")).ToInteger()))
+		column_.SetIndexKind(index_constants.IndexKind(tuple_.GetValue(oldSchema, oldSchema.GetColIndex("index_kind")).ToInteger()))
+		return columnsCatalogRow(uint32(tableOid), column_)
+	})
+}
+
+// migrateColumnsCatalogFromV2 rewrites all entries of columns catalog with current layout.
+// default_seq of all columns is NULL because DEFAULT NEXTVAL can't be specified on version 2
+func migrateColumnsCatalogFromV2(bpm *buffer.BufferPoolManager, log_manager *recovery.LogManager, lock_manager *access.LockManager, txn *access.Transaction) {
+	oldSchema := columnsCatalogSchemaV2()
+	rewriteColumnsCatalog(bpm, log_manager, lock_manager, txn, func(tuple_ *tuple.Tuple) []types.Value {
+		row := make([]types.Value, 0)
+		for colIdx := range oldSchema.GetColumns() {
+			row = append(row, tuple_.GetValue(oldSchema, uint32(colIdx)))
+		}
+		return append(row, types.NewNullOfType(types.Varchar))
+	})
+}
+
+// rewriteColumnsCatalog replaces each entry of columns catalog with values returned by convert.
+// logging is deactivated at launch, so old entries are removed with ApplyDelete directly
+// (iterator can't go over tuples marked as deleted)
+func rewriteColumnsCatalog(bpm *buffer.BufferPoolManager, log_manager *recovery.LogManager, lock_manager *access.LockManager, txn *access.Transaction, convert func(*tuple.Tuple) []types.Value) {
+	columnsCatalogHeap := access.InitTableHeap(bpm, ColumnsCatalogPageId, log_manager, lock_manager)
+
+	rids := make([]page.RID, 0)
+	rows := make([][]types.Value, 0)
+	it := columnsCatalogHeap.Iterator(txn)
+	for tuple_ := it.Current(); !it.End(); tuple_ = it.Next() {
+		rids = append(rids, *tuple_.GetRID())
+		rows = append(rows, convert(tuple_))
+	}
+
+	for ii := range rids {
+		if err := columnsCatalogHeap.ApplyDelete(&rids[ii], txn); err != nil {
+			panic("migration of columns catalog failed: " + err.Error())
+		}
+	}
+	for _, row := range rows {
+		if _, err := columnsCatalogHeap.InsertTuple(tuple.NewTupleFromSchema(row, ColumnsCatalogSchema()), txn, ColumnsCatalogOID); err != nil {
+			panic("migration of columns catalog failed: " + err.Error())
+		}
+	}
+	bpm.FlushPage(ColumnsCatalogPageId)
+}
+
+// createCatalogVersionCatalog creates catalog_version table which has CatalogFormatVersion
+func (c *Catalog) createCatalogVersionCatalog(txn *access.Transaction) {
+	versionCatalog := c.CreateTable(CatalogVersionCatalogName, CatalogVersionCatalogSchema(), txn)
+	row := []types.Value{types.NewInteger(CatalogFormatVersion)}
+	if _, err := versionCatalog.Table().InsertTuple(tuple.NewTupleFromSchema(row, CatalogVersionCatalogSchema()), txn, versionCatalog.OID()); err != nil {
+		panic("creation of catalog_version table failed: " + err.Error())
+	}
+}
+
+// updateCatalogVersion rewrites the entry of catalog_version table to CatalogFormatVersion after migration
+func (c *Catalog) updateCatalogVersion(txn *access.Transaction) {
+	versionCatalog := c.GetTableByName(CatalogVersionCatalogName)
+	it := versionCatalog.Table().Iterator(txn)
+	if it.End() {
+		panic("catalog_version table has no entry.")
+	}
+	rid := *it.Current().GetRID()
+	if err := versionCatalog.Table().ApplyDelete(&rid, txn); err != nil {
+		panic("update of catalog_version table failed: " + err.Error())
+	}
+	row := []types.Value{types.NewInteger(CatalogFormatVersion)}
+	if _, err := versionCatalog.Table().InsertTuple(tuple.NewTupleFromSchema(row, CatalogVersionCatalogSchema()), txn, versionCatalog.OID()); err != nil {
+		panic("update of catalog_version table failed: " + err.Error())
+	}
+}
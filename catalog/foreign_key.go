@@ -0,0 +1,71 @@
+package catalog
+
+import (
+	"errors"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/samehada/samehada_util"
+	"github.com/ryogrid/SamehadaDB/storage/access"
+	"github.com/ryogrid/SamehadaDB/storage/table/column"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"math"
+)
+
+// ForeignKeyReference represents a column of child table which references a column of parent table
+type ForeignKeyReference struct {
+	ChildTable   *TableMetadata
+	ChildColIdx  uint32
+	ParentColIdx uint32
+	ForeignKey   *column.ForeignKey
+}
+
+// GetReferencingForeignKeys returns FOREIGN KEY constraints which reference columns of parent
+func (c *Catalog) GetReferencingForeignKeys(parent *TableMetadata) []*ForeignKeyReference {
+	ret := make([]*ForeignKeyReference, 0)
+	for _, child := range c.GetAllTables() {
+		for colIdx, col := range child.Schema().GetColumns() {
+			fk := col.ForeignKey()
+			if fk == nil || fk.RefTableOID != parent.OID() {
+				continue
+			}
+			ret = append(ret, &ForeignKeyReference{child, uint32(colIdx), parent.Schema().GetColIndex(fk.RefColumnName), fk})
+		}
+	}
+	return ret
+}
+
+// CheckForeignKeyConstraints checks that values of tuple_ on FOREIGN KEY columns exist on referenced tables.
+// only columns included in colIdxs are checked (nil means all columns).
+// found parent tuples are locked in shared mode until end of txn, so they can't be deleted
+// by other transactions before commit of txn.
+func (c *Catalog) CheckForeignKeyConstraints(tableMetadata *TableMetadata, tuple_ *tuple.Tuple, colIdxs []int, txn *access.Transaction) error {
+	for colIdx, col := range tableMetadata.Schema().GetColumns() {
+		fk := col.ForeignKey()
+		if fk == nil {
+			continue
+		}
+		if colIdxs != nil && !samehada_util.IsContainList[int](colIdxs, colIdx) {
+			continue
+		}
+		val := tuple_.GetValue(tableMetadata.Schema(), uint32(colIdx))
+		if val.IsNull() {
+			// NULL does not reference any tuple
+			continue
+		}
+		parent := c.GetTableByOID(fk.RefTableOID)
+		if parent == nil {
+			return errors.New("table referenced by " + tableMetadata.name + "." + col.GetColumnName() + " does not exist.")
+		}
+		parentColIdx := parent.Schema().GetColIndex(fk.RefColumnName)
+		if parentColIdx == math.MaxUint32 {
+			return errors.New("column referenced by " + tableMetadata.name + "." + col.GetColumnName() + " does not exist.")
+		}
+		found, err := parent.FindTuplesByValue(int(parentColIdx), val, txn)
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return samehada_errors.NewConstraintViolationError(samehada_errors.CONSTRAINT_FOREIGN_KEY, tableMetadata.name, col.GetColumnName())
+		}
+	}
+	return nil
+}
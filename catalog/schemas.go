@@ -27,6 +27,27 @@ func ColumnsCatalogSchema() *schema.Schema {
 	hasIndexColumn := column.NewColumn("has_index", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
 	indexKind := column.NewColumn("index_kind", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
 	indexHeaderPageId := column.NewColumn("index_header_page_id", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	isPrimaryKey := column.NewColumn("is_primary_key", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	isUnique := column.NewColumn("is_unique", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	isNotNull := column.NewColumn("is_not_null", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	hasDefault := column.NewColumn("has_default", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// string representation of DEFAULT value. NULL when the DEFAULT value is NULL
+	defaultValue := column.NewColumn("default_value", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// OID of referenced table. -1 when the column has no FOREIGN KEY constraint
+	fkTableOID := column.NewColumn("fk_table_oid", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	fkColumn := column.NewColumn("fk_column", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	fkOnDelete := column.NewColumn("fk_on_delete", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	fkOnUpdate := column.NewColumn("fk_on_update", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// SQL text of generation expression and CHECK constraint. NULL when not defined
+	generatedExpr := column.NewColumn("generated_expr", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	checkExpr := column.NewColumn("check_expr", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// name of sequence used by AUTO_INCREMENT column. NULL when the column is not AUTO_INCREMENT
+	autoIncrementSeq := column.NewColumn("auto_increment_seq", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// precision and scale of DECIMAL column. 0 on other types
+	decimalPrecision := column.NewColumn("decimal_precision", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	decimalScale := column.NewColumn("decimal_scale", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// name of sequence whose NEXTVAL is DEFAULT value. NULL when DEFAULT is not NEXTVAL
+	defaultSeq := column.NewColumn("default_seq", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
 
 	return schema.NewSchema([]*column.Column{
 		tableOIDColumn,
@@ -37,5 +58,63 @@ func ColumnsCatalogSchema() *schema.Schema {
 		offsetColumn,
 		hasIndexColumn,
 		indexKind,
-		indexHeaderPageId})
+		indexHeaderPageId,
+		isPrimaryKey,
+		isUnique,
+		isNotNull,
+		hasDefault,
+		defaultValue,
+		fkTableOID,
+		fkColumn,
+		fkOnDelete,
+		fkOnUpdate,
+		generatedExpr,
+		checkExpr,
+		autoIncrementSeq,
+		decimalPrecision,
+		decimalScale,
+		defaultSeq})
+}
+
+// SequencesCatalogSchema is schema of the table which persists counters of sequences.
+// the table is created when first sequence is created
+func SequencesCatalogSchema() *schema.Schema {
+	nameColumn := column.NewColumn("name", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// value returned by next NEXTVAL
+	nextValueColumn := column.NewColumn("next_value", types.BigInt, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	incrementColumn := column.NewColumn("increment", types.BigInt, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// whether NEXTVAL has been called. CURRVAL fails when it is 0.
+	// 2 means that the last value is returned and next_value is the value
+	isCalledColumn := column.NewColumn("is_called", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	return schema.NewSchema([]*column.Column{nameColumn, nextValueColumn, incrementColumn, isCalledColumn})
+}
+
+// ViewsCatalogSchema is schema of the table which persists definitions of views.
+// the table is created when first view is created
+func ViewsCatalogSchema() *schema.Schema {
+	nameColumn := column.NewColumn("name", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// SQL text of SELECT statement which defines the view
+	selectSQLColumn := column.NewColumn("select_sql", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	// result of materialized view is stored to the table which has same name with the view
+	isMaterializedColumn := column.NewColumn("is_materialized", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	return schema.NewSchema([]*column.Column{nameColumn, selectSQLColumn, isMaterializedColumn})
+}
+
+// CatalogVersionCatalogSchema is schema of the table which persists format version of catalog tables.
+// the table has only one row
+func CatalogVersionCatalogSchema() *schema.Schema {
+	versionColumn := column.NewColumn("version", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	return schema.NewSchema([]*column.Column{versionColumn})
+}
+
+// columnsCatalogSchemaV1 is schema of columns catalog on catalog format version 1.
+// columns added later are appended to ColumnsCatalogSchema, so first 9 columns of it are same as version 1.
+// it is used only for migration
+func columnsCatalogSchemaV1() *schema.Schema {
+	return schema.NewSchema(ColumnsCatalogSchema().GetColumns()[:9])
+}
+
+// columnsCatalogSchemaV2 is schema of columns catalog on catalog format version 2. it is used only for migration
+func columnsCatalogSchemaV2() *schema.Schema {
+	return schema.NewSchema(ColumnsCatalogSchema().GetColumns()[:23])
 }
@@ -0,0 +1,208 @@
+package catalog
+
+import (
+	"errors"
+	"github.com/ryogrid/SamehadaDB/storage/access"
+	"github.com/ryogrid/SamehadaDB/storage/page"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+	"math"
+)
+
+const SequencesCatalogName = "sequences_catalog"
+
+// values of is_called column of sequences catalog
+const (
+	seqNotCalled int32 = iota
+	seqCalled
+	// the last value in the range of BIGINT is returned. nextValue is the value and NEXTVAL fails
+	seqExhausted
+)
+
+// Sequence is on memory counter of a sequence. it is persisted to sequences catalog
+type Sequence struct {
+	name      string
+	nextValue int64
+	increment int64
+	state     int32
+	rid       page.RID // location of the entry on sequences catalog
+}
+
+func (s *Sequence) GetName() string {
+	return s.name
+}
+
+// SetTransactionManager sets TransactionManager which is used for persisting counters of sequences.
+// NEXTVAL fails when it is not set
+func (c *Catalog) SetTransactionManager(txnMgr *access.TransactionManager) {
+	c.txnMgr = txnMgr
+}
+
+func (c *Catalog) GetSequence(name string) *Sequence {
+	c.sequenceMutex.Lock()
+	defer c.sequenceMutex.Unlock()
+	return c.sequences[name]
+}
+
+// SequenceExists returns true when sequence named name exists
+func (c *Catalog) SequenceExists(name string) bool {
+	return c.GetSequence(name) != nil
+}
+
+// CreateSequence creates a sequence whose first value is start.
+// the entry of sequences catalog is inserted with txn. so creation is rollbacked with txn
+func (c *Catalog) CreateSequence(name string, start int64, increment int64, txn *access.Transaction) error {
+	if increment == 0 {
+		return errors.New("INCREMENT of sequence " + name + " can't be 0.")
+	}
+	c.sequenceMutex.Lock()
+	defer c.sequenceMutex.Unlock()
+	if _, ok := c.sequences[name]; ok {
+		return errors.New("sequence " + name + " already exists.")
+	}
+
+	seqCatalog := c.GetTableByName(SequencesCatalogName)
+	if seqCatalog == nil {
+		seqCatalog = c.CreateTable(SequencesCatalogName, SequencesCatalogSchema(), txn)
+	}
+	seq := &Sequence{name, start, increment, seqNotCalled, page.RID{}}
+	rid, err := seqCatalog.Table().InsertTuple(seq.toTuple(), txn, seqCatalog.OID())
+	if err != nil {
+		return err
+	}
+	seq.rid = *rid
+	c.sequences[name] = seq
+	txn.AddCallbackIntoWriteSet(nil, func() {
+		c.sequenceMutex.Lock()
+		defer c.sequenceMutex.Unlock()
+		delete(c.sequences, name)
+	})
+	return nil
+}
+
+// NextVal advances the sequence and returns the value.
+// the advance is persisted and committed with a transaction which is independent of the caller's one.
+// so the value is not reused even if the caller's transaction is aborted or the system crashes,
+// and row lock of sequences catalog is not held until the caller's transaction finishes.
+// error is returned when all values in the range of BIGINT are returned
+func (c *Catalog) NextVal(name string) (int64, error) {
+	c.sequenceMutex.Lock()
+	defer c.sequenceMutex.Unlock()
+	seq, ok := c.sequences[name]
+	if !ok {
+		return 0, errors.New("sequence " + name + " does not exist.")
+	}
+	if seq.state == seqExhausted {
+		return 0, errors.New("sequence " + name + " is exhausted.")
+	}
+	ret := seq.nextValue
+	next, ok := addWithoutOverflow(ret, seq.increment)
+	if !ok {
+		// ret is the last value
+		if err := c.persistSequence(seq, ret, seqExhausted); err != nil {
+			return 0, err
+		}
+		return ret, nil
+	}
+	if err := c.persistSequence(seq, next, seqCalled); err != nil {
+		return 0, err
+	}
+	return ret, nil
+}
+
+// CurrVal returns the value which is returned by last NEXTVAL of the sequence
+func (c *Catalog) CurrVal(name string) (int64, error) {
+	c.sequenceMutex.Lock()
+	defer c.sequenceMutex.Unlock()
+	seq, ok := c.sequences[name]
+	if !ok {
+		return 0, errors.New("sequence " + name + " does not exist.")
+	}
+	switch seq.state {
+	case seqNotCalled:
+		return 0, errors.New("NEXTVAL of sequence " + name + " is not called yet.")
+	case seqExhausted:
+		return seq.nextValue, nil
+	}
+	return seq.nextValue - seq.increment, nil
+}
+
+// AdvanceSequenceTo advances the sequence so that NEXTVAL doesn't return val and values before it.
+// this is used when a value is specified explicitly to AUTO_INCREMENT column
+func (c *Catalog) AdvanceSequenceTo(name string, val int64) error {
+	c.sequenceMutex.Lock()
+	defer c.sequenceMutex.Unlock()
+	seq, ok := c.sequences[name]
+	if !ok {
+		return errors.New("sequence " + name + " does not exist.")
+	}
+	if seq.state == seqExhausted || (seq.increment > 0 && val < seq.nextValue) || (seq.increment < 0 && val > seq.nextValue) {
+		return nil
+	}
+	next, ok := addWithoutOverflow(val, seq.increment)
+	if !ok {
+		// NEXTVAL fails after this. CURRVAL returns val
+		return c.persistSequence(seq, val, seqExhausted)
+	}
+	return c.persistSequence(seq, next, seq.state)
+}
+
+// addWithoutOverflow returns a + b. ok is false when the result overflows int64
+func addWithoutOverflow(a int64, b int64) (ret int64, ok bool) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, false
+	}
+	return a + b, true
+}
+
+// caller must hold sequenceMutex
+func (c *Catalog) persistSequence(seq *Sequence, nextValue int64, state int32) error {
+	if c.txnMgr == nil {
+		return errors.New("TransactionManager is not set to catalog.")
+	}
+	seqCatalog := c.GetTableByName(SequencesCatalogName)
+	newSeq := &Sequence{seq.name, nextValue, seq.increment, state, seq.rid}
+
+	// global transaction latch is already held by the caller's transaction
+	txn := c.txnMgr.BeginSystemTxn()
+	isUpdated, newRID := seqCatalog.Table().UpdateTuple(newSeq.toTuple(), nil, nil, seqCatalog.OID(), seq.rid, txn)
+	if !isUpdated {
+		c.txnMgr.AbortSystemTxn(c, txn)
+		return errors.New("update of sequence " + seq.name + " failed.")
+	}
+	c.txnMgr.CommitSystemTxn(txn)
+
+	if newRID != nil {
+		seq.rid = *newRID
+	}
+	seq.nextValue = nextValue
+	seq.state = state
+	return nil
+}
+
+func (s *Sequence) toTuple() *tuple.Tuple {
+	row := make([]types.Value, 0)
+	row = append(row, types.NewVarchar(s.name))
+	row = append(row, types.NewBigInt(s.nextValue))
+	row = append(row, types.NewBigInt(s.increment))
+	row = append(row, types.NewInteger(s.state))
+	return tuple.NewTupleFromSchema(row, SequencesCatalogSchema())
+}
+
+func (c *Catalog) recoverySequences(txn *access.Transaction) {
+	seqCatalog := c.GetTableByName(SequencesCatalogName)
+	if seqCatalog == nil {
+		return
+	}
+	schema_ := SequencesCatalogSchema()
+	it := seqCatalog.Table().Iterator(txn)
+	for tuple_ := it.Current(); !it.End(); tuple_ = it.Next() {
+		seq := new(Sequence)
+		seq.name = tuple_.GetValue(schema_, schema_.GetColIndex("name")).ToVarchar()
+		seq.nextValue = tuple_.GetValue(schema_, schema_.GetColIndex("next_value")).ToBigInt()
+		seq.increment = tuple_.GetValue(schema_, schema_.GetColIndex("increment")).ToBigInt()
+		seq.state = tuple_.GetValue(schema_, schema_.GetColIndex("is_called")).ToInteger()
+		seq.rid = *tuple_.GetRID()
+		c.sequences[seq.name] = seq
+	}
+}
@@ -4,8 +4,15 @@
 package catalog
 
 import (
+	"errors"
+	"github.com/ryogrid/SamehadaDB/execution/expression"
+	"github.com/ryogrid/SamehadaDB/parser"
 	"github.com/ryogrid/SamehadaDB/storage/index"
 	"github.com/ryogrid/SamehadaDB/storage/index/index_constants"
+	"github.com/ryogrid/SamehadaDB/storage/page"
+	"sort"
+	"strconv"
+	"sync"
 	"sync/atomic"
 
 	"github.com/ryogrid/SamehadaDB/recovery"
@@ -38,6 +45,15 @@ type Catalog struct {
 	tableHeap    *access.TableHeap
 	Log_manager  *recovery.LogManager
 	Lock_manager *access.LockManager
+	// name of sequence => counter of the sequence
+	sequences map[string]*Sequence
+	// protects sequences and serializes updates of sequences catalog
+	sequenceMutex *sync.Mutex
+	// used for persisting counters of sequences with transactions which are independent of callers
+	txnMgr *access.TransactionManager
+	// name of view => definition of the view
+	views     map[string]*View
+	viewMutex *sync.Mutex
 }
 
 func Int32toBool(val int32) bool {
@@ -51,17 +67,23 @@ func Int32toBool(val int32) bool {
 // BootstrapCatalog bootstrap the systems' catalogs on the first database initialization
 func BootstrapCatalog(bpm *buffer.BufferPoolManager, log_manager *recovery.LogManager, lock_manager *access.LockManager, txn *access.Transaction) *Catalog {
 	tableCatalogHeap := access.NewTableHeap(bpm, log_manager, lock_manager, txn)
-	tableCatalog := &Catalog{bpm, make(map[uint32]*TableMetadata), make(map[string]*TableMetadata), 0, tableCatalogHeap, log_manager, lock_manager}
+	tableCatalog := &Catalog{bpm, make(map[uint32]*TableMetadata), make(map[string]*TableMetadata), 0, tableCatalogHeap, log_manager, lock_manager, make(map[string]*Sequence), new(sync.Mutex), nil, make(map[string]*View), new(sync.Mutex)}
 	tableCatalog.CreateTable("columns_catalog", ColumnsCatalogSchema(), txn)
+	tableCatalog.createCatalogVersionCatalog(txn)
 	return tableCatalog
 }
 
 // RecoveryCatalogFromCatalogPage get all information about tables and columns from disk and put it on memory
 func RecoveryCatalogFromCatalogPage(bpm *buffer.BufferPoolManager, log_manager *recovery.LogManager, lock_manager *access.LockManager, txn *access.Transaction) *Catalog {
+	version := readCatalogFormatVersion(bpm, log_manager, lock_manager, txn)
+	migrateCatalog(version, bpm, log_manager, lock_manager, txn)
+
 	tableCatalogHeapIt := access.InitTableHeap(bpm, TableCatalogPageId, log_manager, lock_manager).Iterator(txn)
 
 	tableIds := make(map[uint32]*TableMetadata)
 	tableNames := make(map[string]*TableMetadata)
+	// OID is referenced from other tables (ex: FOREIGN KEY), so it must not be reused after relaunch
+	var nextTableId uint32 = 1
 
 	for tuple := tableCatalogHeapIt.Current(); !tableCatalogHeapIt.End(); tuple = tableCatalogHeapIt.Next() {
 		oid := tuple.GetValue(TableCatalogSchema(), TableCatalogSchema().GetColIndex("oid")).ToInteger()
@@ -83,6 +105,21 @@ func RecoveryCatalogFromCatalogPage(bpm *buffer.BufferPoolManager, log_manager *
 			hasIndex := Int32toBool(tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("has_index")).ToInteger())
 			indexKind := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("index_kind")).ToInteger()
 			indexHeaderPageId := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("index_header_page_id")).ToInteger()
+			isPrimaryKey := Int32toBool(tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("is_primary_key")).ToInteger())
+			isUnique := Int32toBool(tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("is_unique")).ToInteger())
+			isNotNull := Int32toBool(tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("is_not_null")).ToInteger())
+			hasDefault := Int32toBool(tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("has_default")).ToInteger())
+			defaultValStr := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("default_value"))
+			fkTableOID := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("fk_table_oid")).ToInteger()
+			fkColumn := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("fk_column")).ToVarchar()
+			fkOnDelete := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("fk_on_delete")).ToInteger()
+			fkOnUpdate := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("fk_on_update")).ToInteger()
+			generatedExpr := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("generated_expr"))
+			checkExpr := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("check_expr"))
+			autoIncrementSeq := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("auto_increment_seq"))
+			decimalPrecision := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("decimal_precision")).ToInteger()
+			decimalScale := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("decimal_scale")).ToInteger()
+			defaultSeq := tuple.GetValue(ColumnsCatalogSchema(), ColumnsCatalogSchema().GetColIndex("default_seq"))
 
 			column_ := column.NewColumn(columnName, types.TypeID(columnType), false, index_constants.INDEX_KIND_INVAID, types.PageID(indexHeaderPageId), nil)
 			column_.SetFixedLength(uint32(fixedLength))
@@ -91,21 +128,62 @@ func RecoveryCatalogFromCatalogPage(bpm *buffer.BufferPoolManager, log_manager *
 			column_.SetHasIndex(hasIndex)
 			column_.SetIndexKind(index_constants.IndexKind(indexKind))
 			column_.SetIndexHeaderPageId(types.PageID(indexHeaderPageId))
+			column_.SetIsPrimaryKey(isPrimaryKey)
+			column_.SetIsUnique(isUnique)
+			column_.SetIsNotNull(isNotNull)
+			column_.SetDecimalPrecisionAndScale(decimalPrecision, decimalScale)
+			if hasDefault {
+				column_.SetDefaultValue(stringValueToValue(defaultValStr, types.TypeID(columnType)))
+			}
+			if fkTableOID >= 0 {
+				column_.SetForeignKey(column.NewForeignKey(uint32(fkTableOID), fkColumn, column.ReferentialAction(fkOnDelete), column.ReferentialAction(fkOnUpdate)))
+			}
+			if !generatedExpr.IsNull() {
+				column_.SetGeneratedExprStr(generatedExpr.ToVarchar())
+			}
+			if !checkExpr.IsNull() {
+				column_.SetCheckExprStr(checkExpr.ToVarchar())
+			}
+			if !autoIncrementSeq.IsNull() {
+				column_.SetAutoIncrementSeqName(autoIncrementSeq.ToVarchar())
+			}
+			if !defaultSeq.IsNull() {
+				column_.SetDefaultSeqName(defaultSeq.ToVarchar())
+			}
 
 			columns = append(columns, column_)
 		}
+		// rows of columns_catalog may not be stored in column order
+		// (ex: ALTER TABLE reuses freed slots), so restore the order with offset
+		sort.SliceStable(columns, func(i, j int) bool { return columns[i].GetOffset() < columns[j].GetOffset() })
+		schema_ := schema.NewSchema(columns)
+		if err := CompileColumnExpressions(schema_); err != nil {
+			panic("compile of stored expression failed: " + err.Error())
+		}
 
 		tableMetadata := NewTableMetadata(
-			schema.NewSchema(columns),
+			schema_,
 			name,
 			access.InitTableHeap(bpm, types.PageID(firstPage), log_manager, lock_manager),
 			uint32(oid))
 
 		tableIds[uint32(oid)] = tableMetadata
 		tableNames[name] = tableMetadata
+		if uint32(oid)+1 > nextTableId {
+			nextTableId = uint32(oid) + 1
+		}
 	}
 
-	return &Catalog{bpm, tableIds, tableNames, 1, access.InitTableHeap(bpm, 0, log_manager, lock_manager), log_manager, lock_manager}
+	ret := &Catalog{bpm, tableIds, tableNames, nextTableId, access.InitTableHeap(bpm, 0, log_manager, lock_manager), log_manager, lock_manager, make(map[string]*Sequence), new(sync.Mutex), nil, make(map[string]*View), new(sync.Mutex)}
+	if version < 2 {
+		// catalog_version table was added on version 2
+		ret.createCatalogVersionCatalog(txn)
+	} else if version < CatalogFormatVersion {
+		ret.updateCatalogVersion(txn)
+	}
+	ret.recoverySequences(txn)
+	ret.recoveryViews(txn)
+	return ret
 
 }
 
@@ -146,6 +224,55 @@ func (c *Catalog) CreateTable(name string, schema *schema.Schema, txn *access.Tr
 	return tableMetadata
 }
 
+// FillUnspecifiedValues fills values of columns which are not specified on INSERT.
+// they are filled with DEFAULT value or NULL. DEFAULT NEXTVAL advances the sequence for each row. AUTO_INCREMENT column gets generated value
+// when it is not specified or NULL is specified. when a value is specified, the sequence
+// is advanced so as not to generate the value later
+func (c *Catalog) FillUnspecifiedValues(tableMetadata *TableMetadata, row []types.Value, isSpecified []bool) error {
+	for colIdx, col := range tableMetadata.Schema().GetColumns() {
+		if col.IsAutoIncrement() {
+			if isSpecified[colIdx] && !row[colIdx].IsNull() {
+				if err := c.AdvanceSequenceTo(col.AutoIncrementSeqName(), row[colIdx].ToInt64()); err != nil {
+					return err
+				}
+				continue
+			}
+			seqVal, err := c.NextVal(col.AutoIncrementSeqName())
+			if err != nil {
+				return err
+			}
+			// generated value may not fit to the column when its type is smaller than BIGINT
+			val, err := expression.ConvertValue(types.NewBigInt(seqVal), col.GetType())
+			if err != nil {
+				return errors.New("AUTO_INCREMENT value of column " + col.GetColumnName() + " is exhausted: " + err.Error())
+			}
+			row[colIdx] = val
+			continue
+		}
+		if isSpecified[colIdx] {
+			continue
+		}
+		if col.HasDefaultSeq() {
+			seqVal, err := c.NextVal(col.DefaultSeqName())
+			if err != nil {
+				return err
+			}
+			val, err := expression.ConvertValue(types.NewBigInt(seqVal), col.GetType())
+			if err != nil {
+				return errors.New("DEFAULT value of column " + col.GetColumnName() + " is out of range: " + err.Error())
+			}
+			row[colIdx] = val
+			continue
+		}
+		if col.DefaultValue() != nil {
+			row[colIdx] = *col.DefaultValue()
+		} else {
+			row[colIdx] = types.NewNullOfType(col.GetType())
+		}
+	}
+	return nil
+}
+
 func boolToInt32(val bool) int32 {
 	if val {
 		return 1
@@ -154,6 +281,90 @@ func boolToInt32(val bool) int32 {
 	}
 }
 
+// DEFAULT value is stored to columns catalog as string
+func valueToStringValue(val *types.Value) types.Value {
+	if val == nil || val.IsNull() {
+		return types.NewNullOfType(types.Varchar)
+	}
+	return types.NewVarchar(val.ToString())
+}
+
+// empty expression is stored as NULL
+func exprStrToStringValue(exprStr string) types.Value {
+	if exprStr == "" {
+		return types.NewNullOfType(types.Varchar)
+	}
+	return types.NewVarchar(exprStr)
+}
+
+// CompileColumnExpressions compiles SQL text of generation expressions and CHECK constraints
+// of columns and sets results to the columns. ColumnValue in compiled expressions refers columns of schema_.
+// generated column can't refer other generated column
+func CompileColumnExpressions(schema_ *schema.Schema) error {
+	for _, col := range schema_.GetColumns() {
+		if col.IsGenerated() {
+			expr, exprType, err := parser.ExprStrToExpression(col.GeneratedExprStr(), schema_)
+			if err != nil {
+				return err
+			}
+			if exprType != col.GetType() {
+				return errors.New("generated column " + col.GetColumnName() + " is " + col.GetType().String() + " but expression is " + exprType.String() + ".")
+			}
+			for _, colIdx := range expression.GetColIndexesOfExpression(expr) {
+				if schema_.GetColumn(colIdx).IsGenerated() {
+					return errors.New("generated column " + col.GetColumnName() + " can't refer generated column.")
+				}
+			}
+			col.SetExpr(expr)
+		}
+		if col.CheckExprStr() != "" {
+			expr, exprType, err := parser.ExprStrToExpression(col.CheckExprStr(), schema_)
+			if err != nil {
+				return err
+			}
+			if exprType != types.Boolean {
+				return errors.New("CHECK constraint of column " + col.GetColumnName() + " is not boolean expression.")
+			}
+			col.SetCheckExpr(expr)
+		}
+	}
+	return nil
+}
+
+func stringValueToValue(strVal types.Value, valueType types.TypeID) *types.Value {
+	if strVal.IsNull() {
+		ret := types.NewNullOfType(valueType)
+		return &ret
+	}
+	var ret types.Value
+	str := strVal.ToVarchar()
+	switch valueType {
+	case types.Tinyint, types.Smallint, types.Integer, types.BigInt:
+		ival, _ := strconv.ParseInt(str, 10, 64)
+		ret = types.NewIntegerOfType(valueType, ival)
+	case types.Float:
+		fval, _ := strconv.ParseFloat(str, 32)
+		ret = types.NewFloat(float32(fval))
+	case types.Decimal:
+		ret, _ = types.NewDecimalFromString(str)
+	case types.Timestamp:
+		ret, _ = types.ParseTimestamp(str)
+	case types.Date:
+		ret, _ = types.ParseDate(str)
+	case types.Boolean:
+		ret = types.NewBoolean(str == "true")
+	case types.Text:
+		ret = types.NewText(str)
+	case types.Blob:
+		ret = types.NewBlob([]byte(str))
+	case types.JSON:
+		ret = types.NewJSON(str)
+	default:
+		ret = types.NewVarchar(str)
+	}
+	return &ret
+}
+
 func (c *Catalog) insertTable(tableMetadata *TableMetadata, txn *access.Transaction) {
 	row := make([]types.Value, 0)
 
@@ -164,26 +375,245 @@ func (c *Catalog) insertTable(tableMetadata *TableMetadata, txn *access.Transact
 
 	// insert entry to TableCatalogPage (PageId = 0)
 	c.tableHeap.InsertTuple(first_tuple, txn, tableMetadata.OID())
+	c.insertColumns(tableMetadata, txn)
+	// flush a page having table definitions
+	c.bpm.FlushPage(TableCatalogPageId)
+	// flush a page having columns definitions on table
+	c.bpm.FlushPage(ColumnsCatalogPageId)
+}
+
+func (c *Catalog) insertColumns(tableMetadata *TableMetadata, txn *access.Transaction) error {
 	for _, column_ := range tableMetadata.schema.GetColumns() {
-		row := make([]types.Value, 0)
-		row = append(row, types.NewInteger(int32(tableMetadata.oid)))
-		row = append(row, types.NewInteger(int32(column_.GetType())))
-		row = append(row, types.NewVarchar(column_.GetColumnName()))
-		row = append(row, types.NewInteger(int32(column_.FixedLength())))
-		row = append(row, types.NewInteger(int32(column_.VariableLength())))
-		row = append(row, types.NewInteger(int32(column_.GetOffset())))
-		row = append(row, types.NewInteger(boolToInt32(column_.HasIndex())))
-		row = append(row, types.NewInteger(int32(column_.IndexKind())))
-		row = append(row, types.NewInteger(int32(column_.IndexHeaderPageId())))
-		new_tuple := tuple.NewTupleFromSchema(row, ColumnsCatalogSchema())
+		new_tuple := tuple.NewTupleFromSchema(columnsCatalogRow(tableMetadata.oid, column_), ColumnsCatalogSchema())
 
 		// insert entry to ColumnsCatalogPage (PageId = 1)
-		c.tableIds[ColumnsCatalogOID].Table().InsertTuple(new_tuple, txn, ColumnsCatalogOID)
+		if _, err := c.tableIds[ColumnsCatalogOID].Table().InsertTuple(new_tuple, txn, ColumnsCatalogOID); err != nil {
+			return err
+		}
 	}
-	// flush a page having table definitions
+	return nil
+}
+
+// columnsCatalogRow makes values of columns catalog entry of a column
+func columnsCatalogRow(oid uint32, column_ *column.Column) []types.Value {
+	row := make([]types.Value, 0)
+	row = append(row, types.NewInteger(int32(oid)))
+	row = append(row, types.NewInteger(int32(column_.GetType())))
+	row = append(row, types.NewVarchar(column_.GetColumnName()))
+	row = append(row, types.NewInteger(int32(column_.FixedLength())))
+	row = append(row, types.NewInteger(int32(column_.VariableLength())))
+	row = append(row, types.NewInteger(int32(column_.GetOffset())))
+	row = append(row, types.NewInteger(boolToInt32(column_.HasIndex())))
+	row = append(row, types.NewInteger(int32(column_.IndexKind())))
+	row = append(row, types.NewInteger(int32(column_.IndexHeaderPageId())))
+	row = append(row, types.NewInteger(boolToInt32(column_.IsPrimaryKey())))
+	row = append(row, types.NewInteger(boolToInt32(column_.IsUnique())))
+	row = append(row, types.NewInteger(boolToInt32(column_.IsNotNull())))
+	row = append(row, types.NewInteger(boolToInt32(column_.DefaultValue() != nil)))
+	row = append(row, valueToStringValue(column_.DefaultValue()))
+	if fk := column_.ForeignKey(); fk != nil {
+		row = append(row, types.NewInteger(int32(fk.RefTableOID)))
+		row = append(row, types.NewVarchar(fk.RefColumnName))
+		row = append(row, types.NewInteger(int32(fk.OnDelete)))
+		row = append(row, types.NewInteger(int32(fk.OnUpdate)))
+	} else {
+		row = append(row, types.NewInteger(-1))
+		row = append(row, types.NewNullOfType(types.Varchar))
+		row = append(row, types.NewInteger(0))
+		row = append(row, types.NewInteger(0))
+	}
+	row = append(row, exprStrToStringValue(column_.GeneratedExprStr()))
+	row = append(row, exprStrToStringValue(column_.CheckExprStr()))
+	row = append(row, exprStrToStringValue(column_.AutoIncrementSeqName()))
+	row = append(row, types.NewInteger(column_.DecimalPrecision()))
+	row = append(row, types.NewInteger(column_.DecimalScale()))
+	row = append(row, exprStrToStringValue(column_.DefaultSeqName()))
+	return row
+}
+
+// rewriteTuples rewrites all tuples of tableHeap with newSchema page by page, so whole table is not held on memory.
+// tuple which doesn't fit in its page is moved and may be placed on a page which is not processed yet.
+// RIDs of moved tuples are kept to avoid rewriting them twice
+func rewriteTuples(tableHeap *access.TableHeap, oid uint32, oldSchema *schema.Schema, newSchema *schema.Schema, srcColIdxs []int, fillVals []types.Value, txn *access.Transaction) error {
+	movedRIDs := make(map[page.RID]bool)
+	for pageId := tableHeap.GetFirstPageId(); pageId.IsValid(); {
+		tuples, nextPageId, err := tableHeap.GetTuplesOnPage(pageId, txn)
+		if err != nil {
+			return err
+		}
+		for _, oldTuple := range tuples {
+			if movedRIDs[*oldTuple.GetRID()] {
+				continue
+			}
+			vals := make([]types.Value, 0)
+			for idx, srcIdx := range srcColIdxs {
+				if srcIdx < 0 {
+					vals = append(vals, fillVals[idx])
+				} else {
+					vals = append(vals, oldTuple.GetValue(oldSchema, uint32(srcIdx)))
+				}
+			}
+			newTuple := computeGeneratedColumns(newSchema, tuple.NewTupleFromSchema(vals, newSchema))
+			isUpdated, newRID := tableHeap.UpdateTuple(newTuple, nil, nil, oid, *oldTuple.GetRID(), txn)
+			if !isUpdated {
+				return errors.New("rewriting of tuple on schema change failed.")
+			}
+			if newRID != nil {
+				movedRIDs[*newRID] = true
+			}
+		}
+		pageId = nextPageId
+	}
+	return nil
+}
+
+// collect RIDs of catalog entries which have passed oid.
+// RIDs are collected before modification because iterator can't go over tuples marked as deleted
+func collectCatalogEntryRIDs(heap *access.TableHeap, schema_ *schema.Schema, colName string, oid uint32, txn *access.Transaction) []page.RID {
+	ret := make([]page.RID, 0)
+	it := heap.Iterator(txn)
+	for tuple_ := it.Current(); !it.End(); tuple_ = it.Next() {
+		if uint32(tuple_.GetValue(schema_, schema_.GetColIndex(colName)).ToInteger()) == oid {
+			ret = append(ret, *tuple_.GetRID())
+		}
+	}
+	return ret
+}
+
+// RenameTable changes name of the table and updates the table catalog entry
+func (c *Catalog) RenameTable(tableMetadata *TableMetadata, newName string, txn *access.Transaction) error {
+	if _, exist := c.tableNames[newName]; exist {
+		return errors.New("table " + newName + " already exists.")
+	}
+
+	for _, rid := range collectCatalogEntryRIDs(c.tableHeap, TableCatalogSchema(), "oid", tableMetadata.OID(), txn) {
+		if !c.tableHeap.MarkDelete(&rid, tableMetadata.OID(), txn) {
+			return errors.New("deletion of table catalog entry failed.")
+		}
+	}
+
+	row := make([]types.Value, 0)
+	row = append(row, types.NewInteger(int32(tableMetadata.oid)))
+	row = append(row, types.NewVarchar(newName))
+	row = append(row, types.NewInteger(int32(tableMetadata.table.GetFirstPageId())))
+	if _, err := c.tableHeap.InsertTuple(tuple.NewTupleFromSchema(row, TableCatalogSchema()), txn, tableMetadata.OID()); err != nil {
+		return err
+	}
+
+	oldName := tableMetadata.name
+	delete(c.tableNames, oldName)
+	tableMetadata.name = newName
+	c.tableNames[newName] = tableMetadata
+	txn.AddCallbackIntoWriteSet(nil, func() {
+		delete(c.tableNames, newName)
+		tableMetadata.name = oldName
+		c.tableNames[oldName] = tableMetadata
+	})
+
 	c.bpm.FlushPage(TableCatalogPageId)
-	// flush a page having columns definitions on table
+	return nil
+}
+
+// AlterTableSchema replaces schema of the table with newSchema.
+// srcColIdxs[i] is index of the column on current schema which corresponds to i-th column of newSchema.
+// when srcColIdxs[i] is -1, i-th column is new one and fillVals[i] is set to existing tuples.
+// existing tuples are rewritten page by page only when tuple layout is changed. all indexes of the table
+// are rebuilt and pages of old indexes are freed at commit.
+func (c *Catalog) AlterTableSchema(tableMetadata *TableMetadata, newSchema *schema.Schema, srcColIdxs []int, fillVals []types.Value, txn *access.Transaction) (*TableMetadata, error) {
+	oldSchema := tableMetadata.Schema()
+	tableHeap := tableMetadata.Table()
+	oid := tableMetadata.OID()
+
+	// ColumnValue objects of stored expressions must refer columns of new schema
+	if err := CompileColumnExpressions(newSchema); err != nil {
+		return nil, err
+	}
+
+	isLayoutChanged := int(oldSchema.GetColumnCount()) != len(srcColIdxs)
+	for idx, srcIdx := range srcColIdxs {
+		if srcIdx != idx {
+			isLayoutChanged = true
+		}
+	}
+
+	// registered before rewriting, so this is called last at abort
+	txn.AddCallbackIntoWriteSet(nil, func() {
+		c.tableIds[oid] = tableMetadata
+		c.tableNames[tableMetadata.name] = tableMetadata
+	})
+
+	if isLayoutChanged {
+		if err := rewriteTuples(tableHeap, oid, oldSchema, newSchema, srcColIdxs, fillVals, txn); err != nil {
+			return nil, err
+		}
+	}
+
+	// indexes are recreated with new pages
+	for _, col := range newSchema.GetColumns() {
+		col.SetIndexHeaderPageId(types.InvalidPageID)
+	}
+	newTableMetadata := NewTableMetadata(newSchema, tableMetadata.name, tableHeap, oid)
+	// tuples are read page by page again. tuples moved by rewriting are read once
+	// because their old locations are marked as deleted
+	for pageId := tableHeap.GetFirstPageId(); pageId.IsValid(); {
+		tuples, nextPageId, err := tableHeap.GetTuplesOnPage(pageId, txn)
+		if err != nil {
+			return nil, err
+		}
+		for _, tuple_ := range tuples {
+			// filled value of added column may break NOT NULL and CHECK constraint
+			if err := newTableMetadata.CheckNotNullConstraints(tuple_, nil); err != nil {
+				return nil, err
+			}
+			if err := newTableMetadata.CheckCheckConstraints(tuple_); err != nil {
+				return nil, err
+			}
+			for colIdx, index_ := range newTableMetadata.Indexes() {
+				if index_ == nil {
+					continue
+				}
+				// existing tuples may break PRIMARY KEY or UNIQUE constraint of added column
+				if err := newTableMetadata.CheckUniqueConstraints(tuple_, []int{colIdx}, tuple_.GetRID(), txn); err != nil {
+					return nil, err
+				}
+				index_.InsertEntry(tuple_, *tuple_.GetRID(), txn)
+			}
+		}
+		pageId = nextPageId
+	}
+
+	// replace entries on columns catalog
+	columnsCatalogHeap := c.tableIds[ColumnsCatalogOID].Table()
+	for _, rid := range collectCatalogEntryRIDs(columnsCatalogHeap, ColumnsCatalogSchema(), "table_oid", oid, txn) {
+		if !columnsCatalogHeap.MarkDelete(&rid, ColumnsCatalogOID, txn) {
+			return nil, errors.New("deletion of columns catalog entry failed.")
+		}
+	}
+	if err := c.insertColumns(newTableMetadata, txn); err != nil {
+		return nil, err
+	}
+
+	c.tableIds[oid] = newTableMetadata
+	c.tableNames[newTableMetadata.name] = newTableMetadata
+	txn.AddCallbackIntoWriteSet(func() {
+		freeIndexPages(tableMetadata.Indexes())
+	}, func() {
+		freeIndexPages(newTableMetadata.Indexes())
+		// rewritten tuples are rolled back after this. old indexes don't have entries of rewritten tuples
+		// and new indexes are discarded, so index data must not be rolled back at that time
+		c.tableIds[oid] = &TableMetadata{schema: oldSchema, name: tableMetadata.name, table: tableHeap, indexes: make([]index.Index, len(tableMetadata.Indexes())), oid: oid}
+	})
+
 	c.bpm.FlushPage(ColumnsCatalogPageId)
+	return newTableMetadata, nil
+}
+
+func freeIndexPages(indexes []index.Index) {
+	for _, index_ := range indexes {
+		if index_ != nil {
+			index_.FreeAllPages()
+		}
+	}
 }
 
 // for Redo/Undo
@@ -4,11 +4,18 @@
 package catalog
 
 import (
+	"errors"
 	"github.com/ryogrid/SamehadaDB/common"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/execution/expression"
+	"github.com/ryogrid/SamehadaDB/samehada/samehada_util"
 	"github.com/ryogrid/SamehadaDB/storage/access"
 	"github.com/ryogrid/SamehadaDB/storage/index"
 	"github.com/ryogrid/SamehadaDB/storage/index/index_constants"
+	"github.com/ryogrid/SamehadaDB/storage/page"
 	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
 )
 
 type TableMetadata struct {
@@ -49,6 +56,10 @@ func NewTableMetadata(schema *schema.Schema, name string, table *access.TableHea
 				slIdx := index.NewSkipListIndex(im, table.GetBufferPoolManager(), uint32(idx))
 				indexes = append(indexes, slIdx)
 				//column_.SetIndexHeaderPageId(slIdx.GetHeaderPageId())
+			case index_constants.INDEX_KIND_NON_UNIQUE_SKIP_LIST:
+				im := index.NewIndexMetadata(column_.GetColumnName()+"_index", name, schema, []uint32{uint32(idx)})
+				slIdx := index.NewNonUniqueSkipListIndex(im, table.GetBufferPoolManager(), uint32(idx))
+				indexes = append(indexes, slIdx)
 			default:
 				panic("illegal index kind!")
 			}
@@ -66,6 +77,10 @@ func (t *TableMetadata) Schema() *schema.Schema {
 	return t.schema
 }
 
+func (t *TableMetadata) GetTableName() string {
+	return t.name
+}
+
 func (t *TableMetadata) OID() uint32 {
 	return t.oid
 }
@@ -93,3 +108,239 @@ func (t *TableMetadata) GetColumnNum() uint32 {
 func (t *TableMetadata) Indexes() []index.Index {
 	return t.indexes
 }
+
+// CheckNotNullConstraints checks that tuple_ has no NULL value on NOT NULL columns.
+// only columns included in colIdxs are checked (nil means all columns).
+func (t *TableMetadata) CheckNotNullConstraints(tuple_ *tuple.Tuple, colIdxs []int) error {
+	for colIdx, col := range t.schema.GetColumns() {
+		if !col.IsNotNull() {
+			continue
+		}
+		if colIdxs != nil && !samehada_util.IsContainList[int](colIdxs, colIdx) {
+			continue
+		}
+		if tuple_.GetValue(t.schema, uint32(colIdx)).IsNull() {
+			return samehada_errors.NewConstraintViolationError(samehada_errors.CONSTRAINT_NOT_NULL, t.name, col.GetColumnName())
+		}
+	}
+	return nil
+}
+
+// CheckCheckConstraints evaluates CHECK constraints with values of tuple_.
+// same as standard SQL, a constraint is violated only when it is evaluated to false.
+func (t *TableMetadata) CheckCheckConstraints(tuple_ *tuple.Tuple) error {
+	for colIdx, col := range t.schema.GetColumns() {
+		if col.GetCheckExpr() == nil {
+			continue
+		}
+		checkExpr := col.GetCheckExpr().(expression.Expression)
+		// CHECK constraint is satisfied when the result is NULL (unknown)
+		if result := checkExpr.Evaluate(tuple_, t.schema); !result.IsNull() && !result.ToBoolean() {
+			return samehada_errors.NewConstraintViolationError(samehada_errors.CONSTRAINT_CHECK, t.name, t.schema.GetColumn(uint32(colIdx)).GetColumnName())
+		}
+	}
+	return nil
+}
+
+func (t *TableMetadata) HasGeneratedColumn() bool {
+	for _, col := range t.schema.GetColumns() {
+		if col.IsGenerated() {
+			return true
+		}
+	}
+	return false
+}
+
+// ComputeGeneratedColumns returns tuple whose values of generated columns are
+// computed from other values of tuple_. values of generated columns in tuple_ are ignored.
+func (t *TableMetadata) ComputeGeneratedColumns(tuple_ *tuple.Tuple) *tuple.Tuple {
+	if !t.HasGeneratedColumn() {
+		return tuple_
+	}
+	return computeGeneratedColumns(t.schema, tuple_)
+}
+
+func computeGeneratedColumns(schema_ *schema.Schema, tuple_ *tuple.Tuple) *tuple.Tuple {
+	values := make([]types.Value, 0)
+	for colIdx, col := range schema_.GetColumns() {
+		if col.IsGenerated() {
+			values = append(values, col.GetExpr().(expression.Expression).Evaluate(tuple_, schema_))
+		} else {
+			values = append(values, tuple_.GetValue(schema_, uint32(colIdx)))
+		}
+	}
+	return tuple.NewTupleFromSchema(values, schema_)
+}
+
+func (t *TableMetadata) HasUniqueConstraint() bool {
+	for _, col := range t.schema.GetColumns() {
+		if col.IsUnique() {
+			return true
+		}
+	}
+	return false
+}
+
+// LockUniqueCheck should be called before CheckUniqueConstraints and
+// UnlockUniqueCheck should be called after index entries of checked tuple are inserted.
+// without it, two transactions can store same key concurrently. the lock is held by TableHeap,
+// so it is shared with TableMetadata which replaces this one on ALTER TABLE and CREATE INDEX
+func (t *TableMetadata) LockUniqueCheck() {
+	t.table.LockUniqueCheck()
+}
+
+func (t *TableMetadata) UnlockUniqueCheck() {
+	t.table.UnlockUniqueCheck()
+}
+
+// CheckUniqueConstraints checks that values of tuple_ on PRIMARY KEY and UNIQUE columns
+// are not stored on other tuples. only columns included in colIdxs are checked (nil means all columns).
+// tuple located at ignoreRID is not treated as duplication (nil is also ok). it is used for update.
+// when duplication is found, *samehada_errors.ConstraintViolationError is returned.
+func (t *TableMetadata) CheckUniqueConstraints(tuple_ *tuple.Tuple, colIdxs []int, ignoreRID *page.RID, txn *access.Transaction) error {
+	conflicted, colIdx, err := t.findUniqueConflict(tuple_, colIdxs, ignoreRID, txn)
+	if err != nil {
+		return err
+	}
+	if conflicted != nil {
+		col := t.schema.GetColumn(uint32(colIdx))
+		kind := samehada_errors.CONSTRAINT_UNIQUE
+		if col.IsPrimaryKey() {
+			kind = samehada_errors.CONSTRAINT_PRIMARY_KEY
+		}
+		return samehada_errors.NewConstraintViolationError(kind, t.name, col.GetColumnName())
+	}
+	return nil
+}
+
+// FindConflictingTuple returns a stored tuple which has same value with tuple_ on
+// one of PRIMARY KEY and UNIQUE columns. nil is returned when there is no such tuple.
+// returned tuple is locked in shared mode.
+// LockUniqueCheck should be called before this method as with CheckUniqueConstraints
+func (t *TableMetadata) FindConflictingTuple(tuple_ *tuple.Tuple, txn *access.Transaction) (*tuple.Tuple, error) {
+	conflicted, _, err := t.findUniqueConflict(tuple_, nil, nil, txn)
+	return conflicted, err
+}
+
+// returns conflicting tuple and index of the column which has same value
+func (t *TableMetadata) findUniqueConflict(tuple_ *tuple.Tuple, colIdxs []int, ignoreRID *page.RID, txn *access.Transaction) (*tuple.Tuple, int, error) {
+	for colIdx, col := range t.schema.GetColumns() {
+		if !col.IsUnique() || t.indexes[colIdx] == nil {
+			continue
+		}
+		if colIdxs != nil && !samehada_util.IsContainList[int](colIdxs, colIdx) {
+			continue
+		}
+		val := tuple_.GetValue(t.schema, uint32(colIdx))
+		if val.IsNull() {
+			// NULL does not equal to any value
+			continue
+		}
+		// index may return RIDs of tuples which have different value but same hash
+		rids := t.indexes[colIdx].ScanKey(tuple_, txn)
+		if cause := txn.GetAbortCause(); cause != nil {
+			// read of index page failed
+			return nil, -1, cause
+		}
+		for _, rid_ := range rids {
+			// returned tuple keeps the pointer, so RID is copied for each iteration
+			rid := rid_
+			if ignoreRID != nil && rid == *ignoreRID {
+				continue
+			}
+			storedTuple := t.table.GetTuple(&rid, txn)
+			if storedTuple == nil {
+				if cause := txn.GetAbortCause(); cause != nil {
+					// read of table page failed
+					return nil, -1, cause
+				}
+				if txn.GetState() == access.ABORTED {
+					// the tuple is locked by other transaction
+					return nil, -1, errors.New("transaction was aborted on unique constraint check.")
+				}
+				continue
+			}
+			if storedTuple.GetValue(t.schema, uint32(colIdx)).CompareEquals(val) {
+				return storedTuple, colIdx, nil
+			}
+		}
+	}
+	return nil, -1, nil
+}
+
+// CheckUniqueConstraintsInBatch checks that tuples which will be inserted at once
+// don't have same value on PRIMARY KEY and UNIQUE columns each other.
+// duplication with stored tuples should be checked with CheckUniqueConstraints
+func (t *TableMetadata) CheckUniqueConstraintsInBatch(tuples []*tuple.Tuple) error {
+	for colIdx, col := range t.schema.GetColumns() {
+		if !col.IsUnique() {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, tuple_ := range tuples {
+			if tuple_.GetValue(t.schema, uint32(colIdx)).IsNull() {
+				// NULL does not equal to any value
+				continue
+			}
+			key := string(tuple_.GetValueInBytes(t.schema, uint32(colIdx)))
+			if seen[key] {
+				kind := samehada_errors.CONSTRAINT_UNIQUE
+				if col.IsPrimaryKey() {
+					kind = samehada_errors.CONSTRAINT_PRIMARY_KEY
+				}
+				return samehada_errors.NewConstraintViolationError(kind, t.name, col.GetColumnName())
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+// FindTuplesByValue returns tuples whose value on colIdx-th column equals to val.
+// the column must have index. returned tuples are locked in shared mode.
+// when the lock can't be acquired, txn is aborted and error is returned.
+func (t *TableMetadata) FindTuplesByValue(colIdx int, val types.Value, txn *access.Transaction) ([]*tuple.Tuple, error) {
+	index_ := t.indexes[colIdx]
+	if index_ == nil {
+		return nil, errors.New("column " + t.schema.GetColumn(uint32(colIdx)).GetColumnName() + " of " + t.name + " has no index.")
+	}
+
+	// key tuple has val on the column only
+	keyVals := make([]types.Value, 0)
+	for idx, col := range t.schema.GetColumns() {
+		if idx == colIdx {
+			keyVals = append(keyVals, val)
+		} else {
+			keyVals = append(keyVals, types.NewNullOfType(col.GetType()))
+		}
+	}
+	keyTuple := tuple.NewTupleFromSchema(keyVals, t.schema)
+
+	ret := make([]*tuple.Tuple, 0)
+	// index may return RIDs of tuples which have different value but same hash
+	rids := index_.ScanKey(keyTuple, txn)
+	if cause := txn.GetAbortCause(); cause != nil {
+		// read of index page failed
+		return nil, cause
+	}
+	for _, rid_ := range rids {
+		// returned tuple keeps the pointer, so RID is copied for each iteration
+		rid := rid_
+		storedTuple := t.table.GetTuple(&rid, txn)
+		if storedTuple == nil {
+			if cause := txn.GetAbortCause(); cause != nil {
+				// read of table page failed
+				return nil, cause
+			}
+			if txn.GetState() == access.ABORTED {
+				// the tuple is locked by other transaction
+				return nil, errors.New("transaction was aborted on lookup of " + t.name + ".")
+			}
+			continue
+		}
+		if storedTuple.GetValue(t.schema, uint32(colIdx)).CompareEquals(val) {
+			ret = append(ret, storedTuple)
+		}
+	}
+	return ret, nil
+}
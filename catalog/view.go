@@ -0,0 +1,77 @@
+package catalog
+
+import (
+	"errors"
+	"github.com/ryogrid/SamehadaDB/storage/access"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+const ViewsCatalogName = "views_catalog"
+
+// View is definition of a view. query of the view is inlined at planning.
+// result of materialized view is stored to the table which has same name with the view
+type View struct {
+	name           string
+	selectSQL      string
+	isMaterialized bool
+}
+
+func (v *View) GetName() string {
+	return v.name
+}
+
+func (v *View) GetSelectSQL() string {
+	return v.selectSQL
+}
+
+func (v *View) IsMaterialized() bool {
+	return v.isMaterialized
+}
+
+func (c *Catalog) GetView(name string) *View {
+	c.viewMutex.Lock()
+	defer c.viewMutex.Unlock()
+	return c.views[name]
+}
+
+// CreateView persists definition of a view with txn.
+// table of materialized view should be created by caller
+func (c *Catalog) CreateView(name string, selectSQL string, isMaterialized bool, txn *access.Transaction) error {
+	c.viewMutex.Lock()
+	defer c.viewMutex.Unlock()
+	if _, ok := c.views[name]; ok {
+		return errors.New("view " + name + " already exists.")
+	}
+
+	viewsCatalog := c.GetTableByName(ViewsCatalogName)
+	if viewsCatalog == nil {
+		viewsCatalog = c.CreateTable(ViewsCatalogName, ViewsCatalogSchema(), txn)
+	}
+	view := &View{name, selectSQL, isMaterialized}
+	row := make([]types.Value, 0)
+	row = append(row, types.NewVarchar(view.name))
+	row = append(row, types.NewVarchar(view.selectSQL))
+	row = append(row, types.NewInteger(boolToInt32(view.isMaterialized)))
+	if _, err := viewsCatalog.Table().InsertTuple(tuple.NewTupleFromSchema(row, ViewsCatalogSchema()), txn, viewsCatalog.OID()); err != nil {
+		return err
+	}
+	c.views[name] = view
+	return nil
+}
+
+func (c *Catalog) recoveryViews(txn *access.Transaction) {
+	viewsCatalog := c.GetTableByName(ViewsCatalogName)
+	if viewsCatalog == nil {
+		return
+	}
+	schema_ := ViewsCatalogSchema()
+	it := viewsCatalog.Table().Iterator(txn)
+	for tuple_ := it.Current(); !it.End(); tuple_ = it.Next() {
+		view := new(View)
+		view.name = tuple_.GetValue(schema_, schema_.GetColIndex("name")).ToVarchar()
+		view.selectSQL = tuple_.GetValue(schema_, schema_.GetColIndex("select_sql")).ToVarchar()
+		view.isMaterialized = Int32toBool(tuple_.GetValue(schema_, schema_.GetColIndex("is_materialized")).ToInteger())
+		c.views[view.name] = view
+	}
+}
@@ -38,6 +38,10 @@ const (
 	LogBufferSize = ((LogBufferSizeBase + 1) * PageSize)
 	// size of hash bucket
 	BucketSizeOfHashIndex = 10
+	// INSERT of this number of tuples or more uses bulk insertion. tuples are inserted in batches of this size
+	BulkInsertBatchSize = 1024
+	// recursive CTE which is not finished with this number of iterations is treated as error
+	MaxCTERecursionDepth = 1000
 	// probability used for determin node level on SkipList
 	SkipListProb    = 0.5  //0.25
 	LogLevelSetting = INFO //| RDB_OP_FUNC_CALL | DEBUGGING //DEBUG_INFO_DETAIL //DEBUG_INFO //DEBUGGING
@@ -30,7 +30,7 @@ func TestLinearProbeHashTable(t *testing.T) {
 
 	for i := 0; i < 5; i++ {
 		ht.Insert(IntToBytes(i), uint32(i))
-		res := ht.GetValue(IntToBytes(i))
+		res, _ := ht.GetValue(IntToBytes(i))
 		if len(res) == 0 {
 			t.Errorf("result should not be nil")
 		} else {
@@ -39,7 +39,7 @@ func TestLinearProbeHashTable(t *testing.T) {
 	}
 
 	for i := 0; i < 5; i++ {
-		res := ht.GetValue(IntToBytes(i))
+		res, _ := ht.GetValue(IntToBytes(i))
 		if len(res) == 0 {
 			t.Errorf("result should not be nil")
 		} else {
@@ -55,7 +55,7 @@ func TestLinearProbeHashTable(t *testing.T) {
 			testingpkg.Ok(t, ht.Insert(IntToBytes(i), uint32(2*i)))
 		}
 		ht.Insert(IntToBytes(i), uint32(2*i))
-		res := ht.GetValue(IntToBytes(i))
+		res, _ := ht.GetValue(IntToBytes(i))
 		if i == 0 {
 			testingpkg.Equals(t, 1, len(res))
 			testingpkg.Equals(t, uint32(i), res[0])
@@ -71,13 +71,13 @@ func TestLinearProbeHashTable(t *testing.T) {
 	}
 
 	// look for a key that does not exist
-	res := ht.GetValue(IntToBytes(20))
+	res, _ := ht.GetValue(IntToBytes(20))
 	testingpkg.Equals(t, 0, len(res))
 
 	// delete some values
 	for i := 0; i < 5; i++ {
 		ht.Remove(IntToBytes(i), uint32(i))
-		res := ht.GetValue(IntToBytes(i))
+		res, _ := ht.GetValue(IntToBytes(i))
 
 		if i == 0 {
 			testingpkg.Equals(t, 0, len(res))
@@ -91,7 +91,7 @@ func TestLinearProbeHashTable(t *testing.T) {
 	for i := 1; i < 5; i++ {
 		ht.Remove(IntToBytes(i), uint32(i*2))
 		ht.Insert(IntToBytes(i), uint32(i*3))
-		res := ht.GetValue(IntToBytes(i))
+		res, _ := ht.GetValue(IntToBytes(i))
 
 		testingpkg.Equals(t, 1, len(res))
 		testingpkg.Equals(t, uint32(3*i), res[0])
@@ -11,12 +11,13 @@ import (
 /** @return the hash of the value */
 func HashValue(val *types.Value) uint32 {
 	switch val.ValueType() {
-	// case types.TINYINT:
-	// 	raw := static_cast<int64_t>(val.GetAs<int8_t>())
-	// 	return Hash<int64_t>(&raw)
-	// case types.SMALLINT:
-	// 	raw := static_cast<int64_t>(val.GetAs<int16_t>())
-	// 	return Hash<int64_t>(&raw)
+	case types.Tinyint, types.Smallint, types.BigInt, types.Timestamp, types.Date, types.Boolean:
+		raw := val.Serialize()
+		return GenHashMurMur(raw)
+	case types.Decimal:
+		// equal values which have different scale (ex: 1.5 and 1.50) should have same hash
+		raw := []byte(val.ToBigRat().String())
+		return GenHashMurMur(raw)
 	case types.Integer:
 		//raw := static_cast<int64_t>(val.GetAs<int32_t>())
 		raw := val.Serialize()
@@ -24,21 +25,9 @@ func HashValue(val *types.Value) uint32 {
 	case types.Float:
 		raw := val.Serialize()
 		return GenHashMurMur(raw)
-	// case types.BIGINT:
-	// 	raw := static_cast<int64_t>(val.GetAs<int64_t>())
-	// 	return Hash<int64_t>(&raw)
-	// case types.BOOLEAN:
-	// 	raw := val.GetAs<bool>()
-	// 	return Hash<bool>(&raw)
-	// case types.DECIMAL:
-	// 	raw := val.GetAs<double>()
-	// 	return Hash<double>(&raw)
-	case types.Varchar:
+	case types.Varchar, types.Text, types.Blob, types.JSON:
 		raw := val.Serialize()
 		return GenHashMurMur(raw)
-	// case types.TIMESTAMP:
-	// 	raw := val.GetAs<uint64_t>()
-	// 	return Hash<uint64_t>(&raw)
 	default:
 		fmt.Println(val.ValueType())
 		panic("not supported type!")
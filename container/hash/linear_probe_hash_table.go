@@ -7,6 +7,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"github.com/ryogrid/SamehadaDB/storage/page"
+	"strconv"
 	"unsafe"
 
 	"github.com/ryogrid/SamehadaDB/common"
@@ -15,6 +16,9 @@ import (
 	"github.com/spaolacci/murmur3"
 )
 
+// ErrDuplicatedValue is returned by Insert when same pair of key and value is already stored
+var ErrDuplicatedValue = errors.New("duplicated values on the same key are not allowed")
+
 /**
  * Implementation of linear probing hash table that is backed by a buffer pool
  * manager. Non-unique keys are supported. Supports insert and delete. The
@@ -50,25 +54,53 @@ func NewLinearProbeHashTable(bpm *buffer.BufferPoolManager, numBuckets int, head
 
 		return &LinearProbeHashTable{header.ID(), bpm, common.NewRWLatch()}
 	} else {
-		header := bpm.FetchPage(headerPageId)
-		bpm.UnpinPage(header.ID(), true)
+		// pages of the table are read when they are accessed
+		return &LinearProbeHashTable{headerPageId, bpm, common.NewRWLatch()}
+	}
+}
 
-		return &LinearProbeHashTable{header.ID(), bpm, common.NewRWLatch()}
+// fetchPage is same as FetchPageWithError of BufferPoolManager except that error is also
+// returned when no frame is available
+func fetchPage(bpm *buffer.BufferPoolManager, pageId types.PageID) (*page.Page, error) {
+	pg, err := bpm.FetchPageWithError(pageId)
+	if pg == nil && err == nil {
+		err = errors.New("page " + strconv.Itoa(int(pageId)) + " can't be fetched. buffer pool is full.")
 	}
+	return pg, err
 }
 
-func (ht *LinearProbeHashTable) GetValue(key []byte) []uint32 {
-	ht.table_latch.RLock()
-	defer ht.table_latch.RUnlock()
-	hPageData := ht.bpm.FetchPage(ht.headerPageId).Data()
-	headerPage := (*page.HashTableHeaderPage)(unsafe.Pointer(hPageData))
+// newIteratorOfKey fetches header page and returns iterator which starts from the slot of key.
+// when error is returned, no page is pinned
+func (ht *LinearProbeHashTable) newIteratorOfKey(key []byte) (iterator *hashTableIterator, hash uint32, err error) {
+	hPage, err := fetchPage(ht.bpm, ht.headerPageId)
+	if err != nil {
+		return nil, 0, err
+	}
+	headerPage := (*page.HashTableHeaderPage)(unsafe.Pointer(hPage.Data()))
 
-	hash := ht.hash(key)
+	hash = ht.hash(key)
 
 	originalBucketIndex := hash % headerPage.NumBlocks()
 	originalBucketOffset := hash % page.BlockArraySize
 
-	iterator := newHashTableIterator(ht.bpm, headerPage, originalBucketIndex, originalBucketOffset)
+	iterator, err = newHashTableIterator(ht.bpm, headerPage, originalBucketIndex, originalBucketOffset)
+	if err != nil {
+		ht.bpm.UnpinPage(ht.headerPageId, false)
+		return nil, 0, err
+	}
+	return iterator, hash, nil
+}
+
+// GetValue returns values of entries which have key.
+// err is *samehada_errors.IOError which is returned when read of a page fails
+func (ht *LinearProbeHashTable) GetValue(key []byte) ([]uint32, error) {
+	ht.table_latch.RLock()
+	defer ht.table_latch.RUnlock()
+	iterator, hash, err := ht.newIteratorOfKey(key)
+	if err != nil {
+		return nil, err
+	}
+	originalBucketIndex, originalBucketOffset := iterator.bucket, iterator.offset
 
 	result := []uint32{}
 	blockPage, offset := iterator.blockPage, iterator.offset
@@ -78,7 +110,10 @@ func (ht *LinearProbeHashTable) GetValue(key []byte) []uint32 {
 			result = append(result, blockPage.ValueAt(offset))
 		}
 
-		iterator.next()
+		if err = iterator.next(); err != nil {
+			ht.bpm.UnpinPage(ht.headerPageId, false)
+			return nil, err
+		}
 		blockPage, bucket, offset = iterator.blockPage, iterator.bucket, iterator.offset
 		if bucket == originalBucketIndex && offset == originalBucketOffset {
 			break
@@ -88,27 +123,23 @@ func (ht *LinearProbeHashTable) GetValue(key []byte) []uint32 {
 	ht.bpm.UnpinPage(iterator.blockId, true)
 	ht.bpm.UnpinPage(ht.headerPageId, false)
 
-	return result
+	return result, nil
 }
 
 func (ht *LinearProbeHashTable) Insert(key []byte, value uint32) (err error) {
 	ht.table_latch.WLock()
 	defer ht.table_latch.WUnlock()
-	hPageData := ht.bpm.FetchPage(ht.headerPageId).Data()
-	headerPage := (*page.HashTableHeaderPage)(unsafe.Pointer(hPageData))
-
-	hash := ht.hash(key)
-
-	originalBucketIndex := hash % headerPage.NumBlocks()
-	originalBucketOffset := hash % page.BlockArraySize
-
-	iterator := newHashTableIterator(ht.bpm, headerPage, originalBucketIndex, originalBucketOffset)
+	iterator, hash, err := ht.newIteratorOfKey(key)
+	if err != nil {
+		return err
+	}
+	originalBucketIndex, originalBucketOffset := iterator.bucket, iterator.offset
 
 	blockPage, offset := iterator.blockPage, iterator.offset
 	var bucket uint32
 	for {
 		if blockPage.IsOccupied(offset) && blockPage.IsReadable(offset) && blockPage.ValueAt(offset) == value {
-			err = errors.New("duplicated values on the same key are not allowed")
+			err = ErrDuplicatedValue
 			break
 		}
 
@@ -124,7 +155,10 @@ func (ht *LinearProbeHashTable) Insert(key []byte, value uint32) (err error) {
 			err = nil
 			break
 		}
-		iterator.next()
+		if err = iterator.next(); err != nil {
+			ht.bpm.UnpinPage(ht.headerPageId, false)
+			return err
+		}
 
 		blockPage, bucket, offset = iterator.blockPage, iterator.bucket, iterator.offset
 		if bucket == originalBucketIndex && offset == originalBucketOffset {
@@ -138,18 +172,16 @@ func (ht *LinearProbeHashTable) Insert(key []byte, value uint32) (err error) {
 	return
 }
 
-func (ht *LinearProbeHashTable) Remove(key []byte, value uint32) {
+// Remove removes the entry which has key and value.
+// err is *samehada_errors.IOError which is returned when read of a page fails
+func (ht *LinearProbeHashTable) Remove(key []byte, value uint32) error {
 	ht.table_latch.WLock()
 	defer ht.table_latch.WUnlock()
-	hPageData := ht.bpm.FetchPage(ht.headerPageId).Data()
-	headerPage := (*page.HashTableHeaderPage)(unsafe.Pointer(hPageData))
-
-	hash := ht.hash(key)
-
-	originalBucketIndex := hash % headerPage.NumBlocks()
-	originalBucketOffset := hash % page.BlockArraySize
-
-	iterator := newHashTableIterator(ht.bpm, headerPage, originalBucketIndex, originalBucketOffset)
+	iterator, hash, err := ht.newIteratorOfKey(key)
+	if err != nil {
+		return err
+	}
+	originalBucketIndex, originalBucketOffset := iterator.bucket, iterator.offset
 
 	blockPage, offset := iterator.blockPage, iterator.offset
 	var bucket uint32
@@ -158,7 +190,10 @@ func (ht *LinearProbeHashTable) Remove(key []byte, value uint32) {
 			blockPage.Remove(offset)
 		}
 
-		iterator.next()
+		if err = iterator.next(); err != nil {
+			ht.bpm.UnpinPage(ht.headerPageId, false)
+			return err
+		}
 		blockPage, bucket, offset = iterator.blockPage, iterator.bucket, iterator.offset
 		if bucket == originalBucketIndex && offset == originalBucketOffset {
 			break
@@ -167,6 +202,7 @@ func (ht *LinearProbeHashTable) Remove(key []byte, value uint32) {
 
 	ht.bpm.UnpinPage(iterator.blockId, true)
 	ht.bpm.UnpinPage(ht.headerPageId, false)
+	return nil
 }
 
 //func (ht *LinearProbeHashTable) hash(key int) int {
@@ -184,6 +220,30 @@ func (ht *LinearProbeHashTable) hash(key []byte) uint32 {
 	return binary.LittleEndian.Uint32(hash)
 }
 
+// FreeAllPages frees header page and block pages. the table must not be used after calling this
+func (ht *LinearProbeHashTable) FreeAllPages() {
+	ht.table_latch.WLock()
+	defer ht.table_latch.WUnlock()
+
+	hPage := ht.bpm.FetchPage(ht.headerPageId)
+	if hPage == nil {
+		// block pages can't be known. they are left as they are
+		ht.bpm.DeletePage(ht.headerPageId)
+		return
+	}
+	headerPage := (*page.HashTableHeaderPage)(unsafe.Pointer(hPage.Data()))
+	blockPageIds := make([]types.PageID, 0)
+	for ii := uint32(0); ii < headerPage.NumBlocks(); ii++ {
+		blockPageIds = append(blockPageIds, headerPage.GetBlockPageId(ii))
+	}
+	ht.bpm.UnpinPage(ht.headerPageId, false)
+
+	for _, blockPageId := range blockPageIds {
+		ht.bpm.DeletePage(blockPageId)
+	}
+	ht.bpm.DeletePage(ht.headerPageId)
+}
+
 func (ht *LinearProbeHashTable) GetHeaderPageId() types.PageID {
 	return ht.headerPageId
 }
@@ -20,16 +20,21 @@ type hashTableIterator struct {
 	blockPage  *page.HashTableBlockPage
 }
 
-func newHashTableIterator(bpm *buffer.BufferPoolManager, header *page.HashTableHeaderPage, bucket uint32, offset uint32) *hashTableIterator {
+func newHashTableIterator(bpm *buffer.BufferPoolManager, header *page.HashTableHeaderPage, bucket uint32, offset uint32) (*hashTableIterator, error) {
 	blockPageId := header.GetBlockPageId(bucket)
 
-	bPageData := bpm.FetchPage(blockPageId).Data()
-	blockPage := (*page.HashTableBlockPage)(unsafe.Pointer(bPageData))
+	bPage, err := fetchPage(bpm, blockPageId)
+	if err != nil {
+		return nil, err
+	}
+	blockPage := (*page.HashTableBlockPage)(unsafe.Pointer(bPage.Data()))
 
-	return &hashTableIterator{bpm, header, bucket, offset, blockPageId, blockPage}
+	return &hashTableIterator{bpm, header, bucket, offset, blockPageId, blockPage}, nil
 }
 
-func (itr *hashTableIterator) next() {
+// next moves to next slot. when read of next block page fails, current block page is unpinned
+// and error is returned. the iterator must not be used after that
+func (itr *hashTableIterator) next() error {
 	itr.offset++
 	// reached end of the current block page, we need to go to the next one
 	if itr.offset >= page.BlockArraySize {
@@ -44,7 +49,11 @@ func (itr *hashTableIterator) next() {
 		itr.bpm.UnpinPage(itr.blockId, true)
 		itr.blockId = itr.headerPage.GetBlockPageId(itr.bucket)
 
-		bPageData := itr.bpm.FetchPage(itr.blockId).Data()
-		itr.blockPage = (*page.HashTableBlockPage)(unsafe.Pointer(bPageData))
+		bPage, err := fetchPage(itr.bpm, itr.blockId)
+		if err != nil {
+			return err
+		}
+		itr.blockPage = (*page.HashTableBlockPage)(unsafe.Pointer(bPage.Data()))
 	}
+	return nil
 }
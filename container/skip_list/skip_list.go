@@ -82,7 +82,8 @@ func latchOpWithOpType(node *skip_list_page.SkipListBlockPage, getOrUnlatch Latc
 // ATTENTION:
 // this method returns with keep having RLatch or WLatch of corners_[0] and one pin of corners_[0]
 // (when corners_[0] is startNode, having pin count is two, but caller does not have to consider the difference)
-func (sl *SkipList) FindNode(key *types.Value, opType SkipListOpType) (isSuccess bool, foundNode *skip_list_page.SkipListBlockPage, predOfCorners_ []skip_list_page.SkipListCornerInfo, corners_ []skip_list_page.SkipListCornerInfo) {
+// when err is not nil, read of a node failed. isSuccess is false and all latch and pin is released already
+func (sl *SkipList) FindNode(key *types.Value, opType SkipListOpType) (isSuccess bool, foundNode *skip_list_page.SkipListBlockPage, predOfCorners_ []skip_list_page.SkipListCornerInfo, corners_ []skip_list_page.SkipListCornerInfo, err error) {
 	if common.EnableDebug {
 		common.ShPrintf(common.DEBUG_INFO, "FindNode: start. key=%v opType=%d\n", key.ToIFValue(), opType)
 	}
@@ -108,8 +109,15 @@ func (sl *SkipList) FindNode(key *types.Value, opType SkipListOpType) (isSuccess
 			if pred == sl.startNode && pred.GetForwardEntry(ii) == sl.SentinelNodeID {
 				break
 			}
-			curr = skip_list_page.FetchAndCastToBlockPage(sl.bpm, pred.GetForwardEntry(int(ii)))
+			curr, err = skip_list_page.FetchAndCastToBlockPageWithError(sl.bpm, pred.GetForwardEntry(int(ii)))
 			if curr == nil {
+				if err != nil {
+					if pred.GetPageId() != sl.getStartNode().GetPageId() {
+						sl.bpm.UnpinPage(pred.GetPageId(), false)
+					}
+					latchOpWithOpType(pred, SKIP_LIST_UTIL_UNLATCH, opType)
+					return false, nil, nil, nil, err
+				}
 				common.ShPrintf(common.FATAL, "PageID to passed FetchAndCastToBlockPage is %d\n", pred.GetForwardEntry(int(ii)))
 				panic("SkipList::FindNode: FetchAndCastToBlockPage returned nil!")
 			}
@@ -146,11 +154,20 @@ func (sl *SkipList) FindNode(key *types.Value, opType SkipListOpType) (isSuccess
 			latchOpWithOpType(pred, SKIP_LIST_UTIL_UNLATCH, opType)
 
 			// go backward for gathering appropriate corner nodes info
-			pred = skip_list_page.FetchAndCastToBlockPage(sl.bpm, predOfPredId)
+			pred, err = skip_list_page.FetchAndCastToBlockPageWithError(sl.bpm, predOfPredId)
+			if err != nil {
+				return false, nil, nil, nil, err
+			}
 			latchOpWithOpType(pred, SKIP_LIST_UTIL_GET_LATCH, opType)
 
 			// check updating occurred or not
-			beforePred := skip_list_page.FetchAndCastToBlockPage(sl.bpm, beforePredId)
+			var beforePred *skip_list_page.SkipListBlockPage
+			beforePred, err = skip_list_page.FetchAndCastToBlockPageWithError(sl.bpm, beforePredId)
+			if err != nil {
+				sl.bpm.UnpinPage(pred.GetPageId(), false)
+				latchOpWithOpType(pred, SKIP_LIST_UTIL_UNLATCH, opType)
+				return false, nil, nil, nil, err
+			}
 			latchOpWithOpType(beforePred, SKIP_LIST_UTIL_GET_LATCH, opType)
 			afterLSN := beforePred.GetLSN()
 			// check update state of beforePred (pred which was pred before sliding)
@@ -167,7 +184,7 @@ func (sl *SkipList) FindNode(key *types.Value, opType SkipListOpType) (isSuccess
 					common.ShPrintf(common.DEBUG_INFO, "curr: ")
 					curr.PrintMutexDebugInfo()
 				}
-				return false, nil, nil, nil
+				return false, nil, nil, nil, nil
 			}
 			sl.bpm.UnpinPage(beforePred.GetPageId(), false)
 			latchOpWithOpType(beforePred, SKIP_LIST_UTIL_UNLATCH, opType)
@@ -195,7 +212,7 @@ func (sl *SkipList) FindNode(key *types.Value, opType SkipListOpType) (isSuccess
 						// additionaly got pin at Fetch
 						sl.bpm.UnpinPage(pred.GetPageId(), false)
 						pred.WUnlatch()
-						return false, nil, nil, nil
+						return false, nil, nil, nil, nil
 					}
 					// additionaly got pin at Fetch
 					sl.bpm.DecPinOfPage(pred)
@@ -220,25 +237,34 @@ func (sl *SkipList) FindNode(key *types.Value, opType SkipListOpType) (isSuccess
 		pred.PrintPinCount()
 	}
 
-	return true, pred, predOfCorners, corners
+	return true, pred, predOfCorners, corners, nil
 }
 
 // ATTENTION:
 // this method returns with keep having RLatch of corners_[0] and pinned corners_[0]
-func (sl *SkipList) FindNodeWithEntryIdxForItr(key *types.Value) (found_ bool, node_ *skip_list_page.SkipListBlockPage, idx_ int32) {
+// (when err is not nil, no latch and pin is kept)
+func (sl *SkipList) FindNodeWithEntryIdxForItr(key *types.Value) (found_ bool, node_ *skip_list_page.SkipListBlockPage, idx_ int32, err error) {
 	// get idx of target entry or one of nearest smaller entry
-	_, node, _, _ := sl.FindNode(key, SKIP_LIST_OP_GET)
+	_, node, _, _, err := sl.FindNode(key, SKIP_LIST_OP_GET)
+	if err != nil {
+		return false, nil, -1, err
+	}
 
 	// locking is not needed because already have lock with FindNode method call
 	found, _, idx := node.FindEntryByKey(key)
-	return found, node, idx
+	return found, node, idx, nil
 }
 
-func (sl *SkipList) GetValue(key *types.Value) uint32 {
+// GetValue returns value of the entry which has key. math.MaxUint32 is returned when the entry is not found.
+// err is *samehada_errors.IOError which is returned when read of a node fails
+func (sl *SkipList) GetValue(key *types.Value) (uint32, error) {
 	if common.EnableDebug {
 		common.ShPrintf(common.DEBUG_INFO, "SkipList::GetValue: start. key=%v\n", key.ToIFValue())
 	}
-	_, node, _, _ := sl.FindNode(key, SKIP_LIST_OP_GET)
+	_, node, _, _, err := sl.FindNode(key, SKIP_LIST_OP_GET)
+	if err != nil {
+		return math.MaxUint32, err
+	}
 	//node := skip_list_page.FetchAndCastToBlockPage(sl.bpm, corners[0].PageId)
 	// locking is not needed because already have lock with FindNode method call
 	found, entry, _ := node.FindEntryByKey(key)
@@ -250,9 +276,9 @@ func (sl *SkipList) GetValue(key *types.Value) uint32 {
 		common.ShPrintf(common.DEBUG_INFO, "SkipList::GetValue: finish. key=%v\n", key.ToIFValue())
 	}
 	if found {
-		return entry.Value
+		return entry.Value, nil
 	} else {
-		return math.MaxUint32
+		return math.MaxUint32, nil
 	}
 }
 
@@ -263,7 +289,10 @@ func (sl *SkipList) Insert(key *types.Value, value uint32) (err error) {
 	isNeedRetry := true
 
 	for isNeedRetry {
-		isSuccess, node, _, corners := sl.FindNode(key, SKIP_LIST_OP_INSERT)
+		isSuccess, node, _, corners, err := sl.FindNode(key, SKIP_LIST_OP_INSERT)
+		if err != nil {
+			return err
+		}
 		if !isSuccess {
 			// when isSuccess == false, all latch and pin is released already
 			common.ShPrintf(common.DEBUG_INFO, "SkipList::Insert: retry. key=%v\n", key.ToIFValue())
@@ -272,8 +301,11 @@ func (sl *SkipList) Insert(key *types.Value, value uint32) (err error) {
 		levelWhenNodeSplitOccur := sl.GetNodeLevel()
 
 		// locking is not needed because already have lock with FindNode method call
-		isNeedRetry = node.Insert(key, value, sl.bpm, corners, levelWhenNodeSplitOccur)
+		isNeedRetry, err = node.Insert(key, value, sl.bpm, corners, levelWhenNodeSplitOccur)
 		// lock and pin of node is already released on Insert method call
+		if err != nil {
+			return err
+		}
 	}
 
 	if common.EnableDebug {
@@ -282,7 +314,7 @@ func (sl *SkipList) Insert(key *types.Value, value uint32) (err error) {
 	return nil
 }
 
-func (sl *SkipList) Remove(key *types.Value, value uint32) (isDeleted_ bool) {
+func (sl *SkipList) Remove(key *types.Value, value uint32) (isDeleted_ bool, err error) {
 	if common.EnableDebug {
 		common.ShPrintf(common.DEBUG_INFO, "SkipList::Remove: start. key=%v\n", key.ToIFValue())
 	}
@@ -291,15 +323,21 @@ func (sl *SkipList) Remove(key *types.Value, value uint32) (isDeleted_ bool) {
 	isNeedRetry := true
 
 	for isNeedRetry {
-		isSuccess, node, predOfCorners, corners := sl.FindNode(key, SKIP_LIST_OP_REMOVE)
+		isSuccess, node, predOfCorners, corners, err := sl.FindNode(key, SKIP_LIST_OP_REMOVE)
+		if err != nil {
+			return false, err
+		}
 		if !isSuccess {
 			// having no latch and pin here
 			continue
 		}
 
 		// locking is not needed because already have lock with FindNode method call
-		isNodeShouldBeDeleted, isDeleted, isNeedRetry = node.Remove(sl.bpm, key, predOfCorners, corners)
+		isNodeShouldBeDeleted, isDeleted, isNeedRetry, err = node.Remove(sl.bpm, key, predOfCorners, corners)
 		// lock and pin which is got FindNode are released on Remove method
+		if err != nil {
+			return false, err
+		}
 
 		if isNodeShouldBeDeleted {
 			// TODO: (SDB) need implement DeletePage collectly and need WLach of corners[0]
@@ -311,7 +349,7 @@ func (sl *SkipList) Remove(key *types.Value, value uint32) (isDeleted_ bool) {
 	if common.EnableDebug {
 		common.ShPrintf(common.DEBUG_INFO, "SkipList::Remove: finish. key=%v\n", key.ToIFValue())
 	}
-	return isDeleted
+	return isDeleted, nil
 }
 
 //func (sl *SkipList) GetRangeScanIterator(rangeStartKey *types.Value, rangeEndKey *types.Value) *SkipListIterator {
@@ -355,6 +393,27 @@ func (sl *SkipList) GetNodeLevel() int32 {
 	return ret
 }
 
+// FreeAllPages frees all nodes and header page. the list must not be used after calling this
+func (sl *SkipList) FreeAllPages() {
+	// nodes between start node and sentinel node are linked by level 0 forward entries
+	for pageId := sl.startNode.GetForwardEntry(0); pageId.IsValid() && pageId != sl.SentinelNodeID; {
+		node := skip_list_page.FetchAndCastToBlockPage(sl.bpm, pageId)
+		if node == nil {
+			break
+		}
+		nextPageId := node.GetForwardEntry(0)
+		sl.bpm.UnpinPage(pageId, false)
+		sl.bpm.DeletePage(pageId)
+		pageId = nextPageId
+	}
+
+	// header page, start node and sentinel node are kept pinned since creation
+	for _, pageId := range []types.PageID{sl.startNode.GetPageId(), sl.SentinelNodeID, sl.headerPage.GetPageId()} {
+		sl.bpm.UnpinPage(pageId, false)
+		sl.bpm.DeletePage(pageId)
+	}
+}
+
 func (sl *SkipList) GetHeaderPageId() types.PageID {
 	return sl.headerPage.GetPageId()
 }
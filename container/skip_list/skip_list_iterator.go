@@ -18,6 +18,8 @@ type SkipListIterator struct {
 	keyType       types.TypeID
 	entryList     []*skip_list_page.SkipListPair
 	curEntryIdx   int32
+	// error which occured while collecting entries. it is returned by Next
+	err error
 }
 
 func NewSkipListIterator(sl *SkipList, rangeStartKey *types.Value, rangeEndKey *types.Value) *SkipListIterator {
@@ -33,16 +35,21 @@ func NewSkipListIterator(sl *SkipList, rangeStartKey *types.Value, rangeEndKey *
 	ret.keyType = headerPage.GetKeyType()
 	ret.entryList = make([]*skip_list_page.SkipListPair, 0)
 
-	ret.initRIDList(sl)
+	ret.err = ret.initRIDList(sl)
 
 	return ret
 }
 
-func (itr *SkipListIterator) initRIDList(sl *SkipList) {
+// initRIDList collects entries in the range. when read of a node fails, latch and pin of
+// nodes are released and *samehada_errors.IOError is returned
+func (itr *SkipListIterator) initRIDList(sl *SkipList) error {
 	curPageSlotIdx := int32(0)
 	// set appropriate start position
 	if itr.rangeStartKey != nil {
-		found, node, slotIdx := itr.sl.FindNodeWithEntryIdxForItr(itr.rangeStartKey)
+		found, node, slotIdx, err := itr.sl.FindNodeWithEntryIdxForItr(itr.rangeStartKey)
+		if err != nil {
+			return err
+		}
 		// locking is not needed because already have lock with FindNodeWithEntryIdxForItr method call
 		if found {
 			// considering increment of curPageSlotIdx after here
@@ -67,14 +74,18 @@ func (itr *SkipListIterator) initRIDList(sl *SkipList) {
 			nextNodeId := itr.curNode.GetForwardEntry(0)
 			itr.bpm.UnpinPage(prevNodeId, false)
 			itr.curNode.RUnlatch()
-			itr.curNode = skip_list_page.FetchAndCastToBlockPage(itr.bpm, nextNodeId)
+			var err error
+			itr.curNode, err = skip_list_page.FetchAndCastToBlockPageWithError(itr.bpm, nextNodeId)
+			if err != nil {
+				return err
+			}
 			itr.curNode.RLatch()
 			curPageSlotIdx = -1
 			if itr.curNode.GetSmallestKey(itr.keyType).IsInfMax() {
 				// reached tail node
 				itr.bpm.UnpinPage(itr.curNode.GetPageId(), false)
 				itr.curNode.RUnlatch()
-				break
+				return nil
 			}
 		}
 
@@ -85,7 +96,7 @@ func (itr *SkipListIterator) initRIDList(sl *SkipList) {
 		if itr.rangeEndKey != nil && itr.curNode.GetEntry(int(curPageSlotIdx), itr.keyType).Key.CompareGreaterThan(*itr.rangeEndKey) {
 			itr.bpm.UnpinPage(itr.curNode.GetPageId(), false)
 			itr.curNode.RUnlatch()
-			break
+			return nil
 		}
 
 		itr.entryList = append(itr.entryList, itr.curNode.GetEntry(int(curPageSlotIdx), itr.keyType))
@@ -93,6 +104,9 @@ func (itr *SkipListIterator) initRIDList(sl *SkipList) {
 }
 
 func (itr *SkipListIterator) Next() (done bool, err error, key *types.Value, rid *page.RID) {
+	if itr.err != nil {
+		return true, itr.err, nil, nil
+	}
 	if itr.curEntryIdx < int32(len(itr.entryList)) {
 		ret := itr.entryList[itr.curEntryIdx]
 		itr.curEntryIdx++
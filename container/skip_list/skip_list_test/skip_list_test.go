@@ -8,6 +8,7 @@ import (
 	testingpkg "github.com/ryogrid/SamehadaDB/testing"
 	"github.com/ryogrid/SamehadaDB/types"
 	"math"
+	"math/big"
 	"os"
 	"testing"
 )
@@ -382,7 +383,7 @@ func TestSkipListSimple(t *testing.T) {
 	// Get entries
 	for i := 0; i < 250; i++ {
 		//fmt.Printf("get entry i=%d key=%d\n", i, i*11)
-		res := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
+		res, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
 		if res == math.MaxUint32 {
 			t.Errorf("result should not be nil")
 		} else {
@@ -393,7 +394,7 @@ func TestSkipListSimple(t *testing.T) {
 	// delete some values
 	for i := 0; i < 100; i++ {
 		// check existance before delete
-		res := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
+		res, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
 		if res == math.MaxUint32 {
 			panic("result should not be nil")
 		} else {
@@ -403,7 +404,7 @@ func TestSkipListSimple(t *testing.T) {
 		// check no existance after delete
 		sl.Remove(samehada_util.GetPonterOfValue(types.NewInteger(int32(i*11))), uint32(i*11))
 
-		res = sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
+		res, _ = sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
 		testingpkg.SimpleAssert(t, math.MaxUint32 == res)
 		//fmt.Println("contents listing after delete")
 		//confirmSkipListContent(t, sl, -1)
@@ -412,6 +413,96 @@ func TestSkipListSimple(t *testing.T) {
 	shi.Shutdown(false)
 }
 
+func TestSkipListDecimal(t *testing.T) {
+	t.Parallel()
+	if !common.EnableOnMemStorage {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	shi := samehada.NewSamehadaInstance(t.Name(), common.BufferPoolMaxFrameNumForTest)
+	sl := skip_list.NewSkipList(shi.GetBufferPoolManager(), types.Decimal)
+
+	// keys are -1.25, -1.14, ... and have different scale
+	insVals := make([]int64, 0)
+	for i := -125; i < 125; i++ {
+		insVals = append(insVals, int64(i*11))
+	}
+	rand.Shuffle(len(insVals), func(i, j int) { insVals[i], insVals[j] = insVals[j], insVals[i] })
+	for ii, insVal := range insVals {
+		key := types.NewDecimal(big.NewInt(insVal), 2)
+		if ii%2 == 0 {
+			key = key.RescaleDecimal(4)
+		}
+		sl.Insert(&key, uint32(ii))
+	}
+
+	for ii, insVal := range insVals {
+		res, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewDecimal(big.NewInt(insVal*10), 3)))
+		testingpkg.SimpleAssert(t, uint32(ii) == res)
+	}
+
+	// keys are iterated in order of numeric value
+	cnt := 0
+	var lastKey *types.Value = nil
+	startValP := samehada_util.GetPonterOfValue(types.NewDecimal(big.NewInt(-5), 1))
+	itr := sl.Iterator(startValP, nil)
+	for done, _, key, _ := itr.Next(); !done; done, _, key, _ = itr.Next() {
+		testingpkg.SimpleAssert(t, key.CompareGreaterThanOrEqual(*startValP))
+		testingpkg.SimpleAssert(t, lastKey == nil || lastKey.CompareLessThan(*key))
+		lastKey = key
+		cnt++
+	}
+	// -0.44, -0.33, ... 1.36
+	testingpkg.SimpleAssert(t, cnt == 129)
+
+	shi.Shutdown(false)
+}
+
+func TestSkipListTimestamp(t *testing.T) {
+	t.Parallel()
+	if !common.EnableOnMemStorage {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	shi := samehada.NewSamehadaInstance(t.Name(), common.BufferPoolMaxFrameNumForTest)
+	sl := skip_list.NewSkipList(shi.GetBufferPoolManager(), types.Timestamp)
+
+	// keys are hours before and after Unix epoch
+	base := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	insVals := make([]int, 0)
+	for i := -125; i < 125; i++ {
+		insVals = append(insVals, i)
+	}
+	rand.Shuffle(len(insVals), func(i, j int) { insVals[i], insVals[j] = insVals[j], insVals[i] })
+	for ii, insVal := range insVals {
+		key := types.NewTimestamp(base.Add(time.Duration(insVal) * time.Hour))
+		sl.Insert(&key, uint32(ii))
+	}
+
+	for ii, insVal := range insVals {
+		res, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewTimestamp(base.Add(time.Duration(insVal) * time.Hour))))
+		testingpkg.SimpleAssert(t, uint32(ii) == res)
+	}
+
+	// range scan of one day
+	cnt := 0
+	var lastKey *types.Value = nil
+	startValP := samehada_util.GetPonterOfValue(types.NewTimestamp(base.Add(-24 * time.Hour)))
+	endValP := samehada_util.GetPonterOfValue(types.NewTimestamp(base.Add(-1 * time.Hour)))
+	itr := sl.Iterator(startValP, endValP)
+	for done, _, key, _ := itr.Next(); !done; done, _, key, _ = itr.Next() {
+		testingpkg.SimpleAssert(t, key.CompareGreaterThanOrEqual(*startValP) && key.CompareLessThanOrEqual(*endValP))
+		testingpkg.SimpleAssert(t, lastKey == nil || lastKey.CompareLessThan(*key))
+		lastKey = key
+		cnt++
+	}
+	testingpkg.SimpleAssert(t, cnt == 24)
+
+	shi.Shutdown(false)
+}
+
 func TestSkipListInsertAndDeleteAll(t *testing.T) {
 	t.Parallel()
 	if !common.EnableOnMemStorage {
@@ -448,7 +539,7 @@ func TestSkipListInsertAndDeleteAll(t *testing.T) {
 	// Get entries
 	for i := 0; i < 5000; i++ {
 		//fmt.Printf("get entry i=%d key=%d\n", i, i*11)
-		res := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
+		res, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
 		if res == math.MaxUint32 {
 			t.Errorf("result should not be nil")
 		} else {
@@ -459,12 +550,12 @@ func TestSkipListInsertAndDeleteAll(t *testing.T) {
 	// delete all values
 	for i := (5000 - 1); i >= 0; i-- {
 		// delete
-		isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewInteger(int32(i*11))), uint32(i*11))
+		isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewInteger(int32(i*11))), uint32(i*11))
 		common.ShPrintf(common.DEBUG_INFO, "i=%d i*11=%d\n", i, i*11)
 		testingpkg.SimpleAssert(t, isDeleted == true)
 
 		// check no existance after delete
-		res := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
+		res, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
 		common.ShPrintf(common.DEBUG_INFO, "i=%d i*11=%d res=%d\n", i, i*11, res)
 		testingpkg.SimpleAssert(t, math.MaxUint32 == res)
 	}
@@ -482,7 +573,7 @@ func TestSkipListInsertAndDeleteAll(t *testing.T) {
 	// Get entries
 	for i := 0; i < 5000; i++ {
 		//fmt.Printf("get entry i=%d key=%d\n", i, i*11)
-		res := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
+		res, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
 		if res == math.MaxUint32 {
 			t.Errorf("result should not be nil")
 		} else {
@@ -493,12 +584,12 @@ func TestSkipListInsertAndDeleteAll(t *testing.T) {
 	// delete all values
 	for i := 0; i < 5000; i++ {
 		// delete
-		isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewInteger(int32(i*11))), uint32(i*11))
+		isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewInteger(int32(i*11))), uint32(i*11))
 		common.ShPrintf(common.DEBUG_INFO, "i=%d i*11=%d\n", i, i*11)
 		testingpkg.SimpleAssert(t, isDeleted == true)
 
 		// check no existance after delete
-		res := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
+		res, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(int32(i * 11))))
 		common.ShPrintf(common.DEBUG_INFO, "i=%d i*11=%d res=%d\n", i, i*11, res)
 		testingpkg.SimpleAssert(t, math.MaxUint32 == res)
 	}
@@ -721,7 +812,7 @@ func removeRandom[T int32 | float32 | string](t *testing.T, sl *skip_list.SkipLi
 
 			pairVal := samehada_util.GetValueForSkipListEntry(insVal)
 
-			isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(insVal)), pairVal)
+			isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(insVal)), pairVal)
 			if isAlreadyRemoved(insVal, *removedVals) {
 				fmt.Printf("delete duplicated value should not be occur! opStep=%d, ii=%d tmpIdx=%d insVal=%v len(*insVals)=%d len(*removedVals)=%d\n", opStep, ii, tmpIdx, insVal, len(*insVals), len(*removedVals))
 				panic("delete duplicated value should not be occur!")
@@ -734,7 +825,7 @@ func removeRandom[T int32 | float32 | string](t *testing.T, sl *skip_list.SkipLi
 			testingpkg.SimpleAssert(t, isDeleted == true || isAlreadyRemoved(insVal, *removedVals))
 
 			// check removed val does not exist
-			isDeleted = sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(insVal)), pairVal)
+			isDeleted, _ = sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(insVal)), pairVal)
 			if isDeleted != false {
 				fmt.Printf("isDeleted should not be true! opStep=%d, ii=%d tmpIdx=%d insVal=%v len(*insVals)=%d len(*removedVals)=%d\n", opStep, ii, tmpIdx, insVal, len(*insVals), len(*removedVals))
 				panic("isDeleted should be false!")
@@ -855,7 +946,7 @@ func testSkipListMix[T int32 | float32 | string](t *testing.T, keyType types.Typ
 				if len(removedVals) != 0 {
 					tmpIdx := int(rand.Intn(len(removedVals)))
 					tmpVal := removedVals[tmpIdx]
-					isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(tmpVal)), samehada_util.GetValueForSkipListEntry(tmpVal))
+					isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(tmpVal)), samehada_util.GetValueForSkipListEntry(tmpVal))
 					testingpkg.SimpleAssert(t, isDeleted == false)
 					if entriesOnListNum != countSkipListContent(sl) || entriesOnListNum != int32(len(insVals)) || removedEntriesNum != int32(len(removedVals)) {
 						fmt.Printf("entries num on list is strange! %d != (%d or %d) / %d != %d\n", entriesOnListNum, countSkipListContent(sl), len(insVals), removedEntriesNum, len(removedVals))
@@ -879,7 +970,7 @@ func testSkipListMix[T int32 | float32 | string](t *testing.T, keyType types.Typ
 			if len(insVals) > 0 {
 				tmpIdx := int(rand.Intn(len(insVals)))
 				//fmt.Printf("sl.GetValue at testSkipListMix: ii=%d, tmpIdx=%d insVals[tmpIdx]=%d len(*insVals)=%d len(*removedVals)=%d\n", ii, tmpIdx, insVals[tmpIdx], len(insVals), len(removedVals))
-				gotVal := sl.GetValue(samehada_util.GetPonterOfValue(types.NewValue(insVals[tmpIdx])))
+				gotVal, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewValue(insVals[tmpIdx])))
 				if entriesOnListNum != countSkipListContent(sl) || entriesOnListNum != int32(len(insVals)) || removedEntriesNum != int32(len(removedVals)) {
 					fmt.Printf("entries num on list is strange! %d != (%d or %d) / %d != %d\n", entriesOnListNum, countSkipListContent(sl), len(insVals), removedEntriesNum, len(removedVals))
 					panic("entries num on list is strange!")
@@ -1026,7 +1117,7 @@ func testSkipListMixParallel[T int32 | float32 | string](t *testing.T, keyType t
 
 					removedValsMutex.RUnlock()
 					common.ShPrintf(common.DEBUGGING, "Remove(fail) op start.")
-					isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(tmpVal)), samehada_util.GetValueForSkipListEntry(tmpVal))
+					isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(tmpVal)), samehada_util.GetValueForSkipListEntry(tmpVal))
 					common.SH_Assert(isDeleted == false, "delete should be fail!")
 					ch <- 1
 				}()
@@ -1046,7 +1137,7 @@ func testSkipListMixParallel[T int32 | float32 | string](t *testing.T, keyType t
 					pairVal := samehada_util.GetValueForSkipListEntry(insVal)
 
 					common.ShPrintf(common.DEBUGGING, "Remove(success) op start.")
-					isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(insVal)), pairVal)
+					isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(insVal)), pairVal)
 					if isDeleted == true {
 						removedValsMutex.Lock()
 						removedVals = append(removedVals, insVal)
@@ -1079,7 +1170,7 @@ func testSkipListMixParallel[T int32 | float32 | string](t *testing.T, keyType t
 				insValsMutex.RUnlock()
 
 				common.ShPrintf(common.DEBUGGING, "Get op start.")
-				gotVal := sl.GetValue(&getTgt)
+				gotVal, _ := sl.GetValue(&getTgt)
 				if gotVal == math.MaxUint32 {
 					removedValsMutex.RLock()
 					if ok := isAlreadyRemoved(getTgtBase, removedVals); !ok {
@@ -1224,7 +1315,7 @@ func testSkipListMixParallelBulk[T int32 | float32 | string](t *testing.T, keyTy
 
 						removedValsMutex.RUnlock()
 						common.ShPrintf(common.DEBUGGING, "Remove(fail) op start.")
-						isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(tmpVal)), samehada_util.GetValueForSkipListEntry(tmpVal))
+						isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(tmpVal)), samehada_util.GetValueForSkipListEntry(tmpVal))
 						common.SH_Assert(isDeleted == false, "delete should be fail!")
 						ch <- 1
 					}
@@ -1247,7 +1338,7 @@ func testSkipListMixParallelBulk[T int32 | float32 | string](t *testing.T, keyTy
 						pairVal := samehada_util.GetValueForSkipListEntry(insVal)
 
 						common.ShPrintf(common.DEBUGGING, "Remove(success) op start.")
-						isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(insVal)), pairVal)
+						isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(insVal)), pairVal)
 						if isDeleted == true {
 							removedValsMutex.Lock()
 							removedVals = append(removedVals, insVal)
@@ -1283,7 +1374,7 @@ func testSkipListMixParallelBulk[T int32 | float32 | string](t *testing.T, keyTy
 					insValsMutex.RUnlock()
 
 					common.ShPrintf(common.DEBUGGING, "Get op start.")
-					gotVal := sl.GetValue(&getTgt)
+					gotVal, _ := sl.GetValue(&getTgt)
 					if gotVal == math.MaxUint32 {
 						removedValsMutex.RLock()
 						if ok := isAlreadyRemoved(getTgtBase, removedVals); !ok {
@@ -1443,7 +1534,7 @@ func testSkipListMixParallelStride[T int32 | float32 | string](t *testing.T, key
 						removedValsForRemoveMutex.RUnlock()
 
 						common.ShPrintf(common.DEBUGGING, "Remove(fail) op start.")
-						isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(delVal)), samehada_util.GetValueForSkipListEntry(delVal))
+						isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(delVal)), samehada_util.GetValueForSkipListEntry(delVal))
 						common.SH_Assert(isDeleted == false, "delete should be fail!")
 					}
 					ch <- 1
@@ -1480,7 +1571,7 @@ func testSkipListMixParallelStride[T int32 | float32 | string](t *testing.T, key
 						removedValsForGetAndRemove[delVal] = delVal
 						removedValsForGetMutex.Unlock()
 
-						isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(delVal)), pairVal)
+						isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(delVal)), pairVal)
 						if isDeleted == true {
 							// append to map after doing remove op for other fail remove op thread
 							removedValsForRemoveMutex.Lock()
@@ -1520,7 +1611,7 @@ func testSkipListMixParallelStride[T int32 | float32 | string](t *testing.T, key
 					correctVal := samehada_util.GetValueForSkipListEntry(getTgt)
 
 					common.ShPrintf(common.DEBUGGING, "Get op start.")
-					gotVal := sl.GetValue(&getTgtVal)
+					gotVal, _ := sl.GetValue(&getTgtVal)
 					if gotVal == math.MaxUint32 {
 						removedValsForGetMutex.RLock()
 						if _, ok := removedValsForGetAndRemove[getTgt]; !ok {
@@ -1681,7 +1772,7 @@ func testSkipListMixParallelStrideAddedIterator[T int32 | float32 | string](t *t
 						removedValsForRemoveMutex.RUnlock()
 
 						common.ShPrintf(common.DEBUGGING, "Remove(fail) op start.")
-						isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(delVal)), samehada_util.GetValueForSkipListEntry(delVal))
+						isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(delVal)), samehada_util.GetValueForSkipListEntry(delVal))
 						common.SH_Assert(isDeleted == false, "delete should be fail!")
 					}
 					ch <- 1
@@ -1718,7 +1809,7 @@ func testSkipListMixParallelStrideAddedIterator[T int32 | float32 | string](t *t
 						removedValsForGetAndRemove[delVal] = delVal
 						removedValsForGetMutex.Unlock()
 
-						isDeleted := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(delVal)), pairVal)
+						isDeleted, _ := sl.Remove(samehada_util.GetPonterOfValue(types.NewValue(delVal)), pairVal)
 						if isDeleted == true {
 							// append to map after doing remove op for other fail remove op thread
 							removedValsForRemoveMutex.Lock()
@@ -1758,7 +1849,7 @@ func testSkipListMixParallelStrideAddedIterator[T int32 | float32 | string](t *t
 					correctVal := samehada_util.GetValueForSkipListEntry(getTgt)
 
 					common.ShPrintf(common.DEBUGGING, "Get op start.")
-					gotVal := sl.GetValue(&getTgtVal)
+					gotVal, _ := sl.GetValue(&getTgtVal)
 					if gotVal == math.MaxUint32 {
 						removedValsForGetMutex.RLock()
 						if _, ok := removedValsForGetAndRemove[getTgt]; !ok {
@@ -2050,7 +2141,7 @@ func TestSkipListMixParallelStrideAddedIteratorVarchar(t *testing.T) {
 func testSkipListInsertGetEven(t *testing.T, sl *skip_list.SkipList, ch chan string) {
 	for ii := int32(0); ii < 10000; ii = ii + 2 {
 		sl.Insert(samehada_util.GetPonterOfValue(types.NewInteger(ii)), uint32(ii))
-		gotVal := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(ii)))
+		gotVal, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(ii)))
 		if gotVal == math.MaxUint32 {
 			t.Fail()
 			fmt.Printf("value %d is not found!\n", ii)
@@ -2064,7 +2155,7 @@ func testSkipListInsertGetEven(t *testing.T, sl *skip_list.SkipList, ch chan str
 func testSkipListInsertGetOdd(t *testing.T, sl *skip_list.SkipList, ch chan string) {
 	for ii := int32(1); ii < 10000; ii = ii + 2 {
 		sl.Insert(samehada_util.GetPonterOfValue(types.NewInteger(ii)), uint32(ii))
-		gotVal := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(ii)))
+		gotVal, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(ii)))
 		if gotVal == math.MaxUint32 {
 			fmt.Printf("value %d is not found!\n", ii)
 		}
@@ -2078,7 +2169,7 @@ func testSkipListInsertGetEvenSeparate(t *testing.T, sl *skip_list.SkipList, ch
 		sl.Insert(samehada_util.GetPonterOfValue(types.NewInteger(ii)), uint32(ii))
 	}
 	for ii := int32(0); ii < 100000; ii = ii + 2 {
-		gotVal := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(ii)))
+		gotVal, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(ii)))
 		if gotVal == math.MaxUint32 {
 			t.Fail()
 			fmt.Printf("value %d is not found!\n", ii)
@@ -2095,7 +2186,7 @@ func testSkipListInsertGetOddSeparate(t *testing.T, sl *skip_list.SkipList, ch c
 	}
 
 	for ii := int32(1); ii < 100000; ii = ii + 2 {
-		gotVal := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(ii)))
+		gotVal, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(ii)))
 		if gotVal == math.MaxUint32 {
 			fmt.Printf("value %d is not found!\n", ii)
 		}
@@ -2110,7 +2201,7 @@ func testSkipListInsertGetInsert3stride1and3(t *testing.T, sl *skip_list.SkipLis
 	//        ^  ^  ^
 	for ii := int32(0); ii < 10000; ii++ {
 		sl.Insert(samehada_util.GetPonterOfValue(types.NewInteger(3*ii)), uint32(3*ii))
-		gotVal := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(3 * ii)))
+		gotVal, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(3 * ii)))
 		if gotVal == math.MaxUint32 {
 			fmt.Printf("value %d is not found!\n", ii)
 		}
@@ -2119,7 +2210,7 @@ func testSkipListInsertGetInsert3stride1and3(t *testing.T, sl *skip_list.SkipLis
 	//          ^  ^  ^
 	for ii := int32(0); ii < 10000; ii++ {
 		sl.Insert(samehada_util.GetPonterOfValue(types.NewInteger(3*ii+2)), uint32(3*ii+2))
-		gotVal := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(3*ii + 2)))
+		gotVal, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(3*ii + 2)))
 		if gotVal == math.MaxUint32 {
 			fmt.Printf("value %d is not found!\n", ii)
 		}
@@ -2133,7 +2224,7 @@ func testSkipListInsertGetRemove3stride2(t *testing.T, sl *skip_list.SkipList, c
 	//         ^  ^  ^
 	for ii := int32(0); ii < 10000; ii++ {
 		sl.Insert(samehada_util.GetPonterOfValue(types.NewInteger(3*ii+1)), uint32(3*ii+1))
-		gotVal := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(3*ii + 1)))
+		gotVal, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(3*ii + 1)))
 		if gotVal == math.MaxUint32 {
 			fmt.Printf("value %d is not found!\n", ii)
 			panic("inserted value not found!")
@@ -2143,7 +2234,7 @@ func testSkipListInsertGetRemove3stride2(t *testing.T, sl *skip_list.SkipList, c
 	//        ^^ ^^ ^^
 	for ii := int32(10000 - 1); ii >= 0; ii-- {
 		sl.Remove(samehada_util.GetPonterOfValue(types.NewInteger(3*ii+1)), uint32(3*ii+1))
-		gotVal := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(3*ii + 1)))
+		gotVal, _ := sl.GetValue(samehada_util.GetPonterOfValue(types.NewInteger(3*ii + 1)))
 		if gotVal != math.MaxUint32 {
 			fmt.Printf("value %d should be not found!\n", 3*ii+1)
 			panic("remove should be failed!")
@@ -0,0 +1,44 @@
+package errors
+
+type ConstraintKind int32
+
+const (
+	CONSTRAINT_PRIMARY_KEY ConstraintKind = iota
+	CONSTRAINT_UNIQUE
+	CONSTRAINT_NOT_NULL
+	CONSTRAINT_FOREIGN_KEY
+	CONSTRAINT_CHECK
+)
+
+func (k ConstraintKind) String() string {
+	switch k {
+	case CONSTRAINT_PRIMARY_KEY:
+		return "PRIMARY KEY"
+	case CONSTRAINT_UNIQUE:
+		return "UNIQUE"
+	case CONSTRAINT_NOT_NULL:
+		return "NOT NULL"
+	case CONSTRAINT_FOREIGN_KEY:
+		return "FOREIGN KEY"
+	case CONSTRAINT_CHECK:
+		return "CHECK"
+	default:
+		return "unknown"
+	}
+}
+
+// ConstraintViolationError is returned when a statement tries to store data
+// which breaks a constraint defined on the table
+type ConstraintViolationError struct {
+	Kind       ConstraintKind
+	TableName  string
+	ColumnName string
+}
+
+func NewConstraintViolationError(kind ConstraintKind, tableName string, columnName string) *ConstraintViolationError {
+	return &ConstraintViolationError{kind, tableName, columnName}
+}
+
+func (e *ConstraintViolationError) Error() string {
+	return e.Kind.String() + " constraint on " + e.TableName + "." + e.ColumnName + " is violated"
+}
@@ -0,0 +1,20 @@
+package errors
+
+// InternalError is returned when execution of a statement stops by an unexpected failure in the DB
+// (ex: a bug). the transaction which executes the statement is aborted.
+// Cause is the error or the value of panic which is converted to error
+type InternalError struct {
+	Cause error
+}
+
+func NewInternalError(cause error) *InternalError {
+	return &InternalError{cause}
+}
+
+func (e *InternalError) Error() string {
+	return "internal error: " + e.Cause.Error()
+}
+
+func (e *InternalError) Unwrap() error {
+	return e.Cause
+}
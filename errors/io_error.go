@@ -0,0 +1,19 @@
+package errors
+
+// IOError is returned when read or write of db file fails
+type IOError struct {
+	Op  string
+	Err error
+}
+
+func NewIOError(op string, err error) *IOError {
+	return &IOError{op, err}
+}
+
+func (e *IOError) Error() string {
+	return e.Op + " failed: " + e.Err.Error()
+}
+
+func (e *IOError) Unwrap() error {
+	return e.Err
+}
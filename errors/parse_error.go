@@ -0,0 +1,15 @@
+package errors
+
+// ParseError is returned when SQL text can't be parsed or the statement is not supported
+type ParseError struct {
+	SQL string
+	Msg string
+}
+
+func NewParseError(sql string, msg string) *ParseError {
+	return &ParseError{sql, msg}
+}
+
+func (e *ParseError) Error() string {
+	return "parse error: " + e.Msg
+}
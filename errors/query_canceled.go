@@ -0,0 +1,20 @@
+package errors
+
+// QueryCanceledError is returned when execution of a statement is canceled or exceeds its timeout.
+// the transaction which executes the statement is aborted.
+// Cause is error of the context (context.Canceled or context.DeadlineExceeded)
+type QueryCanceledError struct {
+	Cause error
+}
+
+func NewQueryCanceledError(cause error) *QueryCanceledError {
+	return &QueryCanceledError{cause}
+}
+
+func (e *QueryCanceledError) Error() string {
+	return "query is canceled: " + e.Cause.Error()
+}
+
+func (e *QueryCanceledError) Unwrap() error {
+	return e.Cause
+}
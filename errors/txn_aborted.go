@@ -0,0 +1,23 @@
+package errors
+
+// TxnAbortedError is returned when the transaction which executes a statement is aborted
+// (ex: lock conflict). changes made by the transaction are rolled back.
+// Cause is nil when there is no error which caused the abort
+type TxnAbortedError struct {
+	Cause error
+}
+
+func NewTxnAbortedError(cause error) *TxnAbortedError {
+	return &TxnAbortedError{cause}
+}
+
+func (e *TxnAbortedError) Error() string {
+	if e.Cause == nil {
+		return "transaction is aborted."
+	}
+	return "transaction is aborted: " + e.Cause.Error()
+}
+
+func (e *TxnAbortedError) Unwrap() error {
+	return e.Cause
+}
@@ -0,0 +1,15 @@
+package errors
+
+// TypeMismatchError is returned when a value or an expression has type which can't be used there.
+// values which can't be converted to type of the destination are also reported with this
+type TypeMismatchError struct {
+	Msg string
+}
+
+func NewTypeMismatchError(msg string) *TypeMismatchError {
+	return &TypeMismatchError{msg}
+}
+
+func (e *TypeMismatchError) Error() string {
+	return e.Msg
+}
@@ -0,0 +1,32 @@
+package errors
+
+// UnknownTableError is returned when a statement refers a table which does not exist
+type UnknownTableError struct {
+	TableName string
+}
+
+func NewUnknownTableError(tableName string) *UnknownTableError {
+	return &UnknownTableError{tableName}
+}
+
+func (e *UnknownTableError) Error() string {
+	return "table " + e.TableName + " not found."
+}
+
+// UnknownColumnError is returned when a statement refers a column which does not exist.
+// TableName is empty when the table can't be identified (ex: column in an expression)
+type UnknownColumnError struct {
+	TableName  string
+	ColumnName string
+}
+
+func NewUnknownColumnError(tableName string, columnName string) *UnknownColumnError {
+	return &UnknownColumnError{tableName, columnName}
+}
+
+func (e *UnknownColumnError) Error() string {
+	if e.TableName == "" {
+		return "column " + e.ColumnName + " does not exist."
+	}
+	return "column " + e.ColumnName + " does not exist on " + e.TableName + "."
+}
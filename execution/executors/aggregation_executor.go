@@ -3,7 +3,6 @@ package executors
 import (
 	"fmt"
 	"github.com/ryogrid/SamehadaDB/catalog"
-	"math"
 
 	"github.com/ryogrid/SamehadaDB/container/hash"
 	"github.com/ryogrid/SamehadaDB/execution/expression"
@@ -101,35 +100,42 @@ func HashValuesOnAggregateKey(key *plans.AggregateKey) uint32 {
 /** @return the initial aggregrate value for this aggregation executor */
 func (ht *SimpleAggregationHashTable) GenerateInitialAggregateValue() *plans.AggregateValue {
 	var values []*types.Value
-	for _, agg_type := range ht.agg_types_ {
+	states := make([]expression.AggregateFunction, len(ht.agg_types_))
+	for ii, agg_type := range ht.agg_types_ {
 		switch agg_type {
 		case plans.COUNT_AGGREGATE:
 			// Count starts at zero.
 			new_elem := types.NewInteger(0)
 			values = append(values, &new_elem)
-		case plans.SUM_AGGREGATE:
-			// Sum starts at zero.
-			new_elem := types.NewInteger(0)
-			values = append(values, &new_elem)
-		case plans.MIN_AGGREGATE:
-			// Min starts at INT_MAX.
-			new_elem := types.NewInteger(math.MaxInt32)
+		case plans.SUM_AGGREGATE, plans.MIN_AGGREGATE, plans.MAX_AGGREGATE:
+			// Sum, Min and Max start at NULL and first non-NULL input is set.
+			// the result is NULL when the group has no non-NULL input
+			new_elem := types.NewNull()
 			values = append(values, &new_elem)
-		case plans.MAX_AGGREGATE:
-			// Max starts at INT_MIN.
-			new_elem := types.NewInteger(math.MinInt32)
+		case plans.USER_DEFINED_AGGREGATE:
+			// the result is set at finalize
+			new_elem := types.NewNull()
 			values = append(values, &new_elem)
+			states[ii] = ht.agg_exprs_[ii].(*expression.UserAggregateCall).NewState()
 		}
 	}
-	return &plans.AggregateValue{Aggregates_: values}
+	return &plans.AggregateValue{Aggregates_: values, States_: states}
 }
 
 /** Combines the input into the aggregation result. */
 func (aht *SimpleAggregationHashTable) CombineAggregateValues(result *plans.AggregateValue, input *plans.AggregateValue) {
 	for i := 0; i < len(aht.agg_exprs_); i++ {
+		if aht.agg_types_[i] != plans.COUNT_AGGREGATE && aht.agg_types_[i] != plans.USER_DEFINED_AGGREGATE &&
+			result.Aggregates_[i].IsNull() {
+			result.Aggregates_[i] = input.Aggregates_[i]
+			continue
+		}
 		switch aht.agg_types_[i] {
 		case plans.COUNT_AGGREGATE:
-			// Count increases by one.
+			// Count increases by one. NULL is not counted
+			if input.Aggregates_[i].IsNull() {
+				continue
+			}
 			add_val := types.NewInteger(1)
 			result.Aggregates_[i] = result.Aggregates_[i].Add(&add_val)
 		case plans.SUM_AGGREGATE:
@@ -141,6 +147,21 @@ func (aht *SimpleAggregationHashTable) CombineAggregateValues(result *plans.Aggr
 		case plans.MAX_AGGREGATE:
 			// Max is just the max.
 			result.Aggregates_[i] = result.Aggregates_[i].Max(input.Aggregates_[i])
+		case plans.USER_DEFINED_AGGREGATE:
+			result.States_[i].Merge(input.States_[i])
+		}
+	}
+}
+
+// FinalizeUserAggregates sets results of user defined aggregate functions to aggregate values.
+// it should be called after all tuples are combined
+func (aht *SimpleAggregationHashTable) FinalizeUserAggregates() {
+	for _, val := range aht.ht_val {
+		for ii, state := range val.States_ {
+			if state != nil {
+				result := state.Final()
+				val.Aggregates_[ii] = &result
+			}
 		}
 	}
 }
@@ -166,6 +187,13 @@ func (aht *SimpleAggregationHashTable) InsertCombine(agg_key *plans.AggregateKey
 	}
 }
 
+// InsertEmptyGroup inserts agg_key with initial aggregate value
+func (aht *SimpleAggregationHashTable) InsertEmptyGroup(agg_key *plans.AggregateKey) {
+	hashval_of_aggkey := HashValuesOnAggregateKey(agg_key)
+	aht.ht_val[hashval_of_aggkey] = aht.GenerateInitialAggregateValue()
+	aht.ht_key[hashval_of_aggkey] = agg_key
+}
+
 /** @return iterator to the start of the hash table */
 func (aht *SimpleAggregationHashTable) Begin() *AggregateHTIterator {
 	var agg_key_list []*plans.AggregateKey = make([]*plans.AggregateKey, 0)
@@ -192,6 +220,7 @@ type AggregationExecutor struct {
 	/** Simple aggregation hash table iterator. */
 	aht_iterator_ *AggregateHTIterator
 	exprs_        []expression.Expression
+	err_          error // error returned from child at Init
 }
 
 /**
@@ -203,7 +232,7 @@ type AggregationExecutor struct {
 func NewAggregationExecutor(exec_ctx *ExecutorContext, plan *plans.AggregationPlanNode,
 	child Executor) *AggregationExecutor {
 	aht := NewSimpleAggregationHashTable(plan.GetAggregates(), plan.GetAggregateTypes())
-	return &AggregationExecutor{exec_ctx, plan, []Executor{child}, aht, nil, []expression.Expression{}}
+	return &AggregationExecutor{exec_ctx, plan, []Executor{child}, aht, nil, []expression.Expression{}, nil}
 }
 
 func (e *AggregationExecutor) GetOutputSchema() *schema.Schema { return e.plan_.OutputSchema() }
@@ -214,15 +243,25 @@ func (e *AggregationExecutor) Init() {
 	child_exec := e.child_[0]
 	output_column_cnt := int(e.GetOutputSchema().GetColumnCount())
 	for i := 0; i < output_column_cnt; i++ {
-		agg_expr := e.GetOutputSchema().GetColumn(uint32(i)).GetExpr().(expression.AggregateValueExpression)
-		e.exprs_ = append(e.exprs_, &agg_expr)
+		switch agg_expr := e.GetOutputSchema().GetColumn(uint32(i)).GetExpr().(type) {
+		case expression.AggregateValueExpression:
+			e.exprs_ = append(e.exprs_, &agg_expr)
+		default:
+			// expression which refers group by terms and aggregates (planned from SQL)
+			e.exprs_ = append(e.exprs_, agg_expr.(expression.Expression))
+		}
 	}
 	insert_call_cnt := 0
 	for {
+		if err := e.context.checkCanceled(); err != nil {
+			e.err_ = err
+			break
+		}
 		tuple_, done, err := child_exec.Next()
 		if err != nil || done {
 			if err != nil {
 				fmt.Println(err)
+				e.err_ = err
 			}
 			break
 		}
@@ -233,11 +272,19 @@ func (e *AggregationExecutor) Init() {
 		}
 	}
 	fmt.Printf("insert_call_cnt %d\n", insert_call_cnt)
+	if insert_call_cnt == 0 && len(e.plan_.GetGroupBys()) == 0 {
+		// aggregation without GROUP BY returns a row even if there is no tuple
+		e.aht_.InsertEmptyGroup(&plans.AggregateKey{Group_bys_: []*types.Value{}})
+	}
+	e.aht_.FinalizeUserAggregates()
 	e.aht_iterator_ = e.aht_.Begin()
 }
 
 func (e *AggregationExecutor) Next() (*tuple.Tuple, Done, error) {
-	for !e.aht_iterator_.IsNextEnd() && e.plan_.GetHaving() != nil && !e.plan_.GetHaving().EvaluateAggregate(e.aht_iterator_.Key().Group_bys_, e.aht_iterator_.Val().Aggregates_).ToBoolean() {
+	if e.err_ != nil {
+		return nil, true, e.err_
+	}
+	for !e.aht_iterator_.IsEnd() && e.plan_.GetHaving() != nil && !e.plan_.GetHaving().EvaluateAggregate(e.aht_iterator_.Key().Group_bys_, e.aht_iterator_.Val().Aggregates_).ToBoolean() {
 		e.aht_iterator_.Next()
 	}
 	if e.aht_iterator_.IsEnd() {
@@ -245,7 +292,12 @@ func (e *AggregationExecutor) Next() (*tuple.Tuple, Done, error) {
 	}
 	var values []types.Value = make([]types.Value, 0)
 	for i := 0; i < len(e.exprs_); i++ {
-		values = append(values, e.exprs_[i].EvaluateAggregate(e.aht_iterator_.Key().Group_bys_, e.aht_iterator_.Val().Aggregates_))
+		value := e.exprs_[i].EvaluateAggregate(e.aht_iterator_.Key().Group_bys_, e.aht_iterator_.Val().Aggregates_)
+		// NULL should have type of the column to be serialized
+		if colType := e.GetOutputSchema().GetColumn(uint32(i)).GetType(); value.IsNull() && value.ValueType() != colType {
+			value = types.NewNullOfType(colType)
+		}
+		values = append(values, value)
 	}
 	tuple_ := tuple.NewTupleFromSchema(values, e.GetOutputSchema())
 	e.aht_iterator_.Next()
@@ -265,12 +317,21 @@ func (e *AggregationExecutor) MakeKey(tuple_ *tuple.Tuple) *plans.AggregateKey {
 /** @return the tuple as an AggregateValue */
 func (e *AggregationExecutor) MakeVal(tuple_ *tuple.Tuple) *plans.AggregateValue {
 	var vals []*types.Value = make([]*types.Value, 0)
+	states := make([]expression.AggregateFunction, len(e.plan_.GetAggregates()))
 	//for (  &ex	pr : plan_.GetAggregates()) {
-	for _, expr := range e.plan_.GetAggregates() {
+	for ii, expr := range e.plan_.GetAggregates() {
+		if userAgg, ok := expr.(*expression.UserAggregateCall); ok {
+			// state of the tuple is merged to state of the group
+			states[ii] = userAgg.NewState()
+			states[ii].Step(userAgg.EvaluateArgs(tuple_, e.child_[0].GetOutputSchema())...)
+			null_val := types.NewNull()
+			vals = append(vals, &null_val)
+			continue
+		}
 		tmp_val := expr.Evaluate(tuple_, e.child_[0].GetOutputSchema())
 		vals = append(vals, &tmp_val)
 	}
-	return &plans.AggregateValue{Aggregates_: vals}
+	return &plans.AggregateValue{Aggregates_: vals, States_: states}
 }
 
 func (e *AggregationExecutor) GetTableMetaData() *catalog.TableMetadata {
@@ -0,0 +1,87 @@
+package executors
+
+import (
+	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+)
+
+// scan of common table expression which is materialized to tmpTupleStore.
+// the first scan materializes result of child and other scans of same CTE read it
+
+type CTEScanExecutor struct {
+	context *ExecutorContext
+	plan    *plans.CTEScanPlanNode
+	child   Executor
+	tuples  *tmpTupleStore
+	curIdx  int
+}
+
+func NewCTEScanExecutor(context *ExecutorContext, plan *plans.CTEScanPlanNode, child Executor) Executor {
+	return &CTEScanExecutor{context, plan, child, nil, 0}
+}
+
+func (e *CTEScanExecutor) Init() {
+	e.tuples = nil
+	e.curIdx = 0
+}
+
+func (e *CTEScanExecutor) Next() (*tuple.Tuple, Done, error) {
+	if e.tuples == nil {
+		if err := e.materialize(); err != nil {
+			return nil, true, err
+		}
+	}
+
+	if err := e.context.checkCanceled(); err != nil {
+		return nil, true, err
+	}
+	if e.curIdx >= e.tuples.Len() {
+		return nil, true, nil
+	}
+	ret, err := e.tuples.Get(e.curIdx)
+	if err != nil {
+		return nil, true, err
+	}
+	e.curIdx++
+	return ret, false, nil
+}
+
+func (e *CTEScanExecutor) materialize() error {
+	cteName := e.plan.GetCTEName()
+	if e.tuples = e.context.cteTuples[cteName]; e.tuples != nil {
+		return nil
+	}
+
+	store := e.context.newTmpTupleStore()
+	e.child.Init()
+	for {
+		if err := e.context.checkCanceled(); err != nil {
+			return err
+		}
+		tuple_, done, err := e.child.Next()
+		if err != nil {
+			return err
+		}
+		if done {
+			break
+		}
+		if tuple_ == nil {
+			continue
+		}
+		if err := store.Append(tuple_); err != nil {
+			return err
+		}
+	}
+	e.context.cteTuples[cteName] = store
+	e.tuples = store
+	return nil
+}
+
+func (e *CTEScanExecutor) GetOutputSchema() *schema.Schema {
+	return e.plan.OutputSchema()
+}
+
+// can not be used
+func (e *CTEScanExecutor) GetTableMetaData() *catalog.TableMetadata { return nil }
@@ -2,7 +2,6 @@ package executors
 
 import (
 	"errors"
-	"fmt"
 
 	"github.com/ryogrid/SamehadaDB/catalog"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
@@ -41,33 +40,18 @@ func (e *DeleteExecutor) Init() {
 func (e *DeleteExecutor) Next() (*tuple.Tuple, Done, error) {
 
 	// iterates through the table heap trying to select a tuple that matches the predicate
-	for t, done, err := e.child.Next(); !done; t, done, err = e.child.Next() {
-		if t == nil {
-			err_ := errors.New("e.it.Next returned nil")
-			return nil, true, err_
-		}
+	// error is returned with done flag (ex: cancellation of execution)
+	for t, done, err := e.child.Next(); !done || err != nil; t, done, err = e.child.Next() {
 		if err != nil {
 			return nil, true, err
 		}
-
-		rid := t.GetRID()
-		tableMetadata := e.child.GetTableMetaData()
-		is_marked := tableMetadata.Table().MarkDelete(rid, tableMetadata.OID(), e.txn)
-		if !is_marked {
-			err := errors.New("marking tuple deleted failed. PageId:SlotNum = " + string(rid.GetPageId()) + ":" + fmt.Sprint(rid.GetSlotNum()))
-			return nil, false, err
+		if t == nil {
+			err_ := errors.New("e.it.Next returned nil")
+			return nil, true, err_
 		}
 
-		colNum := tableMetadata.GetColumnNum()
-		for ii := 0; ii < int(colNum); ii++ {
-			ret := tableMetadata.GetIndex(ii)
-			if ret == nil {
-				continue
-			} else {
-				index_ := ret
-				//index_.DeleteEntry(e.it.Current(), *rid, e.txn)
-				index_.DeleteEntry(t, *rid, e.txn)
-			}
+		if err := deleteTupleAndIndexEntries(e.context, e.child.GetTableMetaData(), t); err != nil {
+			return nil, true, err
 		}
 
 		return t, false, nil
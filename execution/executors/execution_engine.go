@@ -19,32 +19,107 @@ type ExecutionEngine struct {
 }
 
 func (e *ExecutionEngine) Execute(plan plans.Plan, context *ExecutorContext) []*tuple.Tuple {
-	executor := e.CreateExecutor(plan, context)
-	executor.Init()
+	tuples, _ := e.ExecuteWithError(plan, context)
+	return tuples
+}
+
+// ExecuteWithError is same as Execute except that error returned from executors is passed to caller.
+// when error is returned, transaction is set to aborted state
+func (e *ExecutionEngine) ExecuteWithError(plan plans.Plan, context *ExecutorContext) ([]*tuple.Tuple, error) {
+	cursor := e.Open(plan, context)
+	// temporary tuples materialized on execution are not needed after it
+	defer cursor.Close()
 
 	tuples := []*tuple.Tuple{}
 	for {
-		tuple, done, err := executor.Next()
+		tuple, done, err := cursor.Next()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+		tuples = append(tuples, tuple)
+	}
+
+	return tuples, nil
+}
+
+// ExecuteWithoutResult is same as ExecuteWithError except that result tuples are not kept on memory.
+// it returns number of them. it is suitable for DML whose result is used only as count of affected rows
+func (e *ExecutionEngine) ExecuteWithoutResult(plan plans.Plan, context *ExecutorContext) (int64, error) {
+	cursor := e.Open(plan, context)
+	defer cursor.Close()
+
+	var cnt int64 = 0
+	for {
+		_, done, err := cursor.Next()
 		if err != nil {
-			context.txn.SetState(access.ABORTED)
-			return nil
+			return 0, err
 		}
 		if done {
 			break
 		}
+		cnt++
+	}
 
+	return cnt, nil
+}
+
+// Open creates executors for plan and returns Cursor which pulls result tuples from them one by one.
+// tuples are not materialized on memory except ones which executors need to hold (ex: sort, hash join).
+// Close of the returned Cursor must be called after use
+func (e *ExecutionEngine) Open(plan plans.Plan, context *ExecutorContext) *Cursor {
+	executor := e.CreateExecutor(plan, context)
+	executor.Init()
+	return &Cursor{executor, context, false}
+}
+
+// Cursor pulls result tuples from root executor lazily
+type Cursor struct {
+	executor Executor
+	context  *ExecutorContext
+	closed   bool
+}
+
+// Next returns next result tuple. done is true when all tuples are returned.
+// when error is returned, transaction is set to aborted state
+func (c *Cursor) Next() (*tuple.Tuple, Done, error) {
+	if c.closed {
+		return nil, true, nil
+	}
+	for {
+		tuple, done, err := c.executor.Next()
+		if err != nil {
+			c.context.txn.SetState(access.ABORTED)
+			return nil, true, err
+		}
+		if done {
+			return nil, true, nil
+		}
 		if tuple != nil {
-			tuples = append(tuples, tuple)
+			return tuple, false, nil
 		}
 	}
+}
 
-	return tuples
+// Close releases temporary tuples materialized on execution.
+// transaction is not finished by Close. it is caller's responsibility
+func (c *Cursor) Close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.context.releaseTmpTupleStores()
 }
 
 func (e *ExecutionEngine) CreateExecutor(plan plans.Plan, context *ExecutorContext) Executor {
 	switch p := plan.(type) {
 	case *plans.InsertPlanNode:
-		return NewInsertExecutor(context, p)
+		if len(p.GetChildren()) == 0 {
+			return NewInsertExecutor(context, p, nil)
+		}
+		return NewInsertExecutor(context, p, e.CreateExecutor(plan.GetChildAt(0), context))
 	case *plans.SeqScanPlanNode:
 		return NewSeqScanExecutor(context, p)
 	case *plans.PointScanWithIndexPlanNode:
@@ -65,6 +140,23 @@ func (e *ExecutionEngine) CreateExecutor(plan plans.Plan, context *ExecutorConte
 		return NewOrderbyExecutor(context, p, e.CreateExecutor(plan.GetChildAt(0), context))
 	case *plans.FilterPlanNode:
 		return NewFilterExecutor(context, p, e.CreateExecutor(plan.GetChildAt(0), context))
+	case *plans.ProjectionPlanNode:
+		return NewProjectionExecutor(context, p, e.CreateExecutor(plan.GetChildAt(0), context))
+	case *plans.CTEScanPlanNode:
+		return NewCTEScanExecutor(context, p, e.CreateExecutor(plan.GetChildAt(0), context))
+	case *plans.RecursiveCTEPlanNode:
+		newRecursive := func() Executor { return e.CreateExecutor(p.GetRecursivePlan(), context) }
+		return NewRecursiveCTEExecutor(context, p, e.CreateExecutor(p.GetAnchorPlan(), context), newRecursive)
+	case *plans.WorkTableScanPlanNode:
+		return NewWorkTableScanExecutor(context, p)
+	case *plans.UnionAllPlanNode:
+		return NewUnionAllExecutor(context, p, e.CreateExecutor(p.GetLeftPlan(), context), e.CreateExecutor(p.GetRightPlan(), context))
+	case *plans.SetOperationPlanNode:
+		return NewSetOperationExecutor(context, p, e.CreateExecutor(p.GetLeftPlan(), context), e.CreateExecutor(p.GetRightPlan(), context))
+	case *plans.WindowPlanNode:
+		return NewWindowExecutor(context, p, e.CreateExecutor(p.GetChildPlan(), context))
+	case *plans.OneRowPlanNode:
+		return NewOneRowExecutor(context, p)
 	}
 	return nil
 }
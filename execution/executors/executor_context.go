@@ -4,7 +4,9 @@
 package executors
 
 import (
+	"context"
 	"github.com/ryogrid/SamehadaDB/catalog"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/storage/access"
 	"github.com/ryogrid/SamehadaDB/storage/buffer"
 )
@@ -16,10 +18,17 @@ type ExecutorContext struct {
 	catalog *catalog.Catalog
 	bpm     *buffer.BufferPoolManager
 	txn     *access.Transaction
+	// tuples of common table expressions shared by executors. key is name of CTE
+	cteTuples  map[string]*tmpTupleStore
+	workTables map[string]*tmpTupleStore
+	// all stores created on the context. they are released after execution
+	tmpTupleStores []*tmpTupleStore
+	// checked in loops of executors which take long time to stop execution when it is canceled
+	ctx context.Context
 }
 
 func NewExecutorContext(catalog *catalog.Catalog, bpm *buffer.BufferPoolManager, txn *access.Transaction) *ExecutorContext {
-	return &ExecutorContext{catalog, bpm, txn}
+	return &ExecutorContext{catalog, bpm, txn, make(map[string]*tmpTupleStore), make(map[string]*tmpTupleStore), nil, context.Background()}
 }
 
 func (e *ExecutorContext) GetCatalog() *catalog.Catalog {
@@ -37,3 +46,35 @@ func (e *ExecutorContext) GetTransaction() *access.Transaction {
 func (e *ExecutorContext) SetTransaction(txn *access.Transaction) {
 	e.txn = txn
 }
+
+func (e *ExecutorContext) GetContext() context.Context {
+	return e.ctx
+}
+
+func (e *ExecutorContext) SetContext(ctx context.Context) {
+	e.ctx = ctx
+}
+
+// checkCanceled returns error when execution is canceled or timed out through the context
+func (e *ExecutorContext) checkCanceled() error {
+	if err := e.ctx.Err(); err != nil {
+		return samehada_errors.NewQueryCanceledError(err)
+	}
+	return nil
+}
+
+func (e *ExecutorContext) newTmpTupleStore() *tmpTupleStore {
+	store := newTmpTupleStore(e.bpm)
+	e.tmpTupleStores = append(e.tmpTupleStores, store)
+	return store
+}
+
+// releaseTmpTupleStores deallocates pages used by all tmpTupleStore created on the context
+func (e *ExecutorContext) releaseTmpTupleStores() {
+	for _, store := range e.tmpTupleStores {
+		store.Clear()
+	}
+	e.tmpTupleStores = nil
+	e.cteTuples = make(map[string]*tmpTupleStore)
+	e.workTables = make(map[string]*tmpTupleStore)
+}
@@ -4,6 +4,7 @@
 package executor_test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ryogrid/SamehadaDB/execution/executors"
 	"github.com/ryogrid/SamehadaDB/samehada"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/ryogrid/SamehadaDB/catalog"
 	"github.com/ryogrid/SamehadaDB/common"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
 	"github.com/ryogrid/SamehadaDB/recovery"
@@ -2459,3 +2461,286 @@ func TestInsertNullValueAndSeqScanWithNullComparison(t *testing.T) {
 		})
 	}
 }
+
+func TestUniqueConstraintWithConcurrentInsert(t *testing.T) {
+	if !common.EnableOnMemStorage {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	shi := samehada.NewSamehadaInstance(t.Name(), common.BufferPoolMaxFrameNumForTest)
+	shi.GetLogManager().ActivateLogging()
+	testingpkg.Assert(t, shi.GetLogManager().IsEnabledLogging(), "")
+
+	txn_mgr := shi.GetTransactionManager()
+	txn := txn_mgr.Begin(nil)
+
+	c := catalog.BootstrapCatalog(shi.GetBufferPoolManager(), shi.GetLogManager(), shi.GetLockManager(), txn)
+
+	columnA := column.NewColumn("a", types.Integer, true, index_constants.INDEX_KIND_HASH, types.PageID(-1), nil)
+	columnA.SetIsUnique(true)
+	columnB := column.NewColumn("b", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	schema_ := schema.NewSchema([]*column.Column{columnA, columnB})
+
+	tableMetadata := c.CreateTable("test_1", schema_, txn)
+	txn_mgr.Commit(txn)
+
+	const PARALLEL_EXEC_CNT int = 20
+
+	// all transactions try to insert same key
+	ch := make(chan int32)
+	for ii := 0; ii < PARALLEL_EXEC_CNT; ii++ {
+		go func(ii int) {
+			txn_ := txn_mgr.Begin(nil)
+			rows := [][]types.Value{{types.NewInteger(10), types.NewVarchar(fmt.Sprintf("txn-%d", ii))}}
+			insertPlanNode := plans.NewInsertPlanNode(rows, tableMetadata.OID())
+
+			executionEngine := &executors.ExecutionEngine{}
+			executorContext := executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn_)
+			executionEngine.ExecuteWithError(insertPlanNode, executorContext)
+			ch <- handleFnishTxn(c, txn_mgr, txn_)
+		}(ii)
+	}
+	commitedCnt := int32(0)
+	for ii := 0; ii < PARALLEL_EXEC_CNT; ii++ {
+		commitedCnt += <-ch
+	}
+	testingpkg.Assert(t, commitedCnt <= 1, "only one transaction can insert the key")
+
+	txn = txn_mgr.Begin(nil)
+	executionEngine := &executors.ExecutionEngine{}
+	executorContext := executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn)
+	results := executionEngine.Execute(plans.NewSeqScanPlanNode(schema_, nil, tableMetadata.OID()), executorContext)
+	txn_mgr.Commit(txn)
+	testingpkg.Assert(t, int32(len(results)) == commitedCnt, "stored tuple count should be same with commited transaction count")
+
+	// after commit, duplicated insertion fails with constraint violation
+	if commitedCnt == 1 {
+		txn = txn_mgr.Begin(nil)
+		rows := [][]types.Value{{types.NewInteger(10), types.NewVarchar("after")}}
+		executorContext = executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn)
+		_, err := executionEngine.ExecuteWithError(plans.NewInsertPlanNode(rows, tableMetadata.OID()), executorContext)
+		var cvErr *samehada_errors.ConstraintViolationError
+		testingpkg.Assert(t, errors.As(err, &cvErr), "constraint violation error should be returned")
+		testingpkg.Assert(t, cvErr.Kind == samehada_errors.CONSTRAINT_UNIQUE, "")
+		txn_mgr.Abort(c, txn)
+	}
+
+	// remove db file and log file
+	shi.Shutdown(true)
+}
+
+func TestForeignKeyLocksReferencedTuple(t *testing.T) {
+	if !common.EnableOnMemStorage {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	shi := samehada.NewSamehadaInstance(t.Name(), common.BufferPoolMaxFrameNumForTest)
+	shi.GetLogManager().ActivateLogging()
+	testingpkg.Assert(t, shi.GetLogManager().IsEnabledLogging(), "")
+
+	txn_mgr := shi.GetTransactionManager()
+	txn := txn_mgr.Begin(nil)
+
+	c := catalog.BootstrapCatalog(shi.GetBufferPoolManager(), shi.GetLogManager(), shi.GetLockManager(), txn)
+
+	parentColumnA := column.NewColumn("a", types.Integer, true, index_constants.INDEX_KIND_HASH, types.PageID(-1), nil)
+	parentColumnA.SetIsUnique(true)
+	parentSchema := schema.NewSchema([]*column.Column{parentColumnA})
+	parentMetadata := c.CreateTable("parent", parentSchema, txn)
+
+	childColumnA := column.NewColumn("a", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	childColumnB := column.NewColumn("b", types.Integer, true, index_constants.INDEX_KIND_HASH, types.PageID(-1), nil)
+	childColumnB.SetForeignKey(column.NewForeignKey(parentMetadata.OID(), "a", column.FK_ACTION_CASCADE, column.FK_ACTION_RESTRICT))
+	childSchema := schema.NewSchema([]*column.Column{childColumnA, childColumnB})
+	childMetadata := c.CreateTable("child", childSchema, txn)
+
+	executionEngine := &executors.ExecutionEngine{}
+	executorContext := executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn)
+	_, err := executionEngine.ExecuteWithError(plans.NewInsertPlanNode([][]types.Value{{types.NewInteger(1)}, {types.NewInteger(2)}}, parentMetadata.OID()), executorContext)
+	testingpkg.Assert(t, err == nil, "")
+	txn_mgr.Commit(txn)
+
+	// txn1 inserts child tuple which references parent tuple (a = 1). it is not committed yet
+	txn1 := txn_mgr.Begin(nil)
+	executorContext1 := executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn1)
+	_, err = executionEngine.ExecuteWithError(plans.NewInsertPlanNode([][]types.Value{{types.NewInteger(100), types.NewInteger(1)}}, childMetadata.OID()), executorContext1)
+	testingpkg.Assert(t, err == nil, "")
+
+	// txn2 can't delete the referenced parent tuple because txn1 locks it
+	txn2 := txn_mgr.Begin(nil)
+	executorContext2 := executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn2)
+	tmpColVal := new(expression.ColumnValue)
+	tmpColVal.SetTupleIndex(0)
+	tmpColVal.SetColIndex(parentSchema.GetColIndex("a"))
+	expression_ := expression.NewComparison(tmpColVal, expression.NewConstantValue(types.NewInteger(1), types.Integer), expression.Equal, types.Boolean)
+	deletePlanNode := plans.NewDeletePlanNode(plans.NewSeqScanPlanNode(parentSchema, expression_, parentMetadata.OID()))
+	_, err = executionEngine.ExecuteWithError(deletePlanNode, executorContext2)
+	testingpkg.Assert(t, err != nil, "deletion of locked parent tuple should fail")
+	testingpkg.Assert(t, txn2.GetState() == access.ABORTED, "")
+	txn_mgr.Abort(c, txn2)
+	txn_mgr.Commit(txn1)
+
+	// referencing tuple which references not existing parent tuple can't be inserted
+	txn3 := txn_mgr.Begin(nil)
+	executorContext3 := executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn3)
+	_, err = executionEngine.ExecuteWithError(plans.NewInsertPlanNode([][]types.Value{{types.NewInteger(101), types.NewInteger(3)}}, childMetadata.OID()), executorContext3)
+	var cvErr *samehada_errors.ConstraintViolationError
+	testingpkg.Assert(t, errors.As(err, &cvErr), "constraint violation error should be returned")
+	testingpkg.Assert(t, cvErr.Kind == samehada_errors.CONSTRAINT_FOREIGN_KEY, "")
+	txn_mgr.Abort(c, txn3)
+
+	// after commit of txn1, the parent tuple can be deleted and the child tuple is deleted by cascade
+	txn4 := txn_mgr.Begin(nil)
+	executorContext4 := executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn4)
+	_, err = executionEngine.ExecuteWithError(deletePlanNode, executorContext4)
+	testingpkg.Assert(t, err == nil, "")
+	results := executionEngine.Execute(plans.NewSeqScanPlanNode(childSchema, nil, childMetadata.OID()), executorContext4)
+	testingpkg.Assert(t, len(results) == 0, "child tuple should be deleted by cascade")
+	txn_mgr.Commit(txn4)
+
+	// remove db file and log file
+	shi.Shutdown(true)
+}
+
+func TestBulkInsertWithIndex(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	diskManager := disk.NewDiskManagerTest()
+	log_mgr := recovery.NewLogManager(&diskManager)
+	bpm := buffer.NewBufferPoolManager(uint32(common.BufferPoolMaxFrameNumForTest), diskManager, log_mgr)
+
+	txn_mgr := access.NewTransactionManager(access.NewLockManager(access.REGULAR, access.DETECTION), log_mgr)
+	txn := txn_mgr.Begin(nil)
+
+	c := catalog.BootstrapCatalog(bpm, log_mgr, access.NewLockManager(access.REGULAR, access.PREVENTION), txn)
+
+	columnA := column.NewColumn("a", types.Integer, true, index_constants.INDEX_KIND_HASH, types.PageID(-1), nil)
+	columnB := column.NewColumn("b", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	schema_ := schema.NewSchema([]*column.Column{columnA, columnB})
+
+	tableMetadata := c.CreateTable("test_1", schema_, txn)
+
+	// more than one batch and many pages are used
+	rowNum := common.BulkInsertBatchSize*2 + 100
+	rows := make([][]types.Value, 0)
+	for ii := 0; ii < rowNum; ii++ {
+		rows = append(rows, []types.Value{types.NewInteger(int32(ii)), types.NewVarchar(fmt.Sprintf("value%d", ii))})
+	}
+
+	executionEngine := &executors.ExecutionEngine{}
+	executorContext := executors.NewExecutorContext(c, bpm, txn)
+	_, err := executionEngine.ExecuteWithError(plans.NewInsertPlanNode(rows, tableMetadata.OID()), executorContext)
+	testingpkg.Ok(t, err)
+
+	txn_mgr.Commit(txn)
+
+	txn = txn_mgr.Begin(nil)
+	executorContext.SetTransaction(txn)
+	results := executionEngine.Execute(plans.NewSeqScanPlanNode(schema_, nil, tableMetadata.OID()), executorContext)
+	testingpkg.Equals(t, rowNum, len(results))
+	for ii, tuple_ := range results {
+		testingpkg.Equals(t, int32(ii), tuple_.GetValue(schema_, 0).ToInteger())
+	}
+
+	cases := []executors.IndexPointScanTestCase{{
+		"select b ... WHERE a = 0",
+		executionEngine,
+		executorContext,
+		tableMetadata,
+		[]executors.Column{{"b", types.Varchar}},
+		executors.Predicate{"a", expression.Equal, 0},
+		[]executors.Assertion{{"b", "value0"}},
+		1,
+	}, {
+		"select b ... WHERE a = last",
+		executionEngine,
+		executorContext,
+		tableMetadata,
+		[]executors.Column{{"b", types.Varchar}},
+		executors.Predicate{"a", expression.Equal, rowNum - 1},
+		[]executors.Assertion{{"b", fmt.Sprintf("value%d", rowNum-1)}},
+		1,
+	}}
+
+	for _, test := range cases {
+		t.Run(test.Description, func(t *testing.T) {
+			executors.ExecuteIndexPointScanTestCase(t, test, index_constants.INDEX_KIND_HASH)
+		})
+	}
+	txn_mgr.Commit(txn)
+
+	common.TempSuppressOnMemStorage = false
+	diskManager.ShutDown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestUpsertLocksConflictingTuple(t *testing.T) {
+	if !common.EnableOnMemStorage {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	shi := samehada.NewSamehadaInstance(t.Name(), common.BufferPoolMaxFrameNumForTest)
+	shi.GetLogManager().ActivateLogging()
+	testingpkg.Assert(t, shi.GetLogManager().IsEnabledLogging(), "")
+
+	txn_mgr := shi.GetTransactionManager()
+	txn := txn_mgr.Begin(nil)
+
+	c := catalog.BootstrapCatalog(shi.GetBufferPoolManager(), shi.GetLogManager(), shi.GetLockManager(), txn)
+
+	columnA := column.NewColumn("a", types.Integer, true, index_constants.INDEX_KIND_HASH, types.PageID(-1), nil)
+	columnA.SetIsUnique(true)
+	columnB := column.NewColumn("b", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	schema_ := schema.NewSchema([]*column.Column{columnA, columnB})
+
+	tableMetadata := c.CreateTable("test_1", schema_, txn)
+	executionEngine := &executors.ExecutionEngine{}
+	_, err := executionEngine.ExecuteWithError(plans.NewInsertPlanNode([][]types.Value{{types.NewInteger(10), types.NewVarchar("init")}}, tableMetadata.OID()), executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn))
+	testingpkg.Ok(t, err)
+	txn_mgr.Commit(txn)
+
+	// ON DUPLICATE KEY UPDATE b = VALUES(b)
+	upsert := func(txn_ *access.Transaction, a int32, b string) error {
+		insertPlanNode := plans.NewInsertPlanNode([][]types.Value{{types.NewInteger(a), types.NewVarchar(b)}}, tableMetadata.OID())
+		insertPlanNode.(*plans.InsertPlanNode).SetOnConflictAction(&plans.OnConflictAction{UpdateColIdxs: []int{1}, UpdateValues: []types.Value{types.NewNull()}, UpdateSrcColIdxs: []int{1}})
+		_, err_ := executionEngine.ExecuteWithError(insertPlanNode, executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn_))
+		return err_
+	}
+
+	// updated tuple is locked until txn1 finishes. so update of txn2 is not lost silently
+	txn1 := txn_mgr.Begin(nil)
+	testingpkg.Ok(t, upsert(txn1, 10, "txn1"))
+	txn2 := txn_mgr.Begin(nil)
+	testingpkg.Assert(t, upsert(txn2, 10, "txn2") != nil, "upsert of locked tuple should fail")
+	testingpkg.Equals(t, int32(0), handleFnishTxn(c, txn_mgr, txn2))
+	testingpkg.Equals(t, int32(1), handleFnishTxn(c, txn_mgr, txn1))
+
+	// tuple inserted by upsert is also locked
+	txn3 := txn_mgr.Begin(nil)
+	testingpkg.Ok(t, upsert(txn3, 20, "txn3"))
+	txn4 := txn_mgr.Begin(nil)
+	testingpkg.Assert(t, upsert(txn4, 20, "txn4") != nil, "upsert of locked tuple should fail")
+	testingpkg.Equals(t, int32(0), handleFnishTxn(c, txn_mgr, txn4))
+	testingpkg.Equals(t, int32(1), handleFnishTxn(c, txn_mgr, txn3))
+
+	txn = txn_mgr.Begin(nil)
+	testingpkg.Ok(t, upsert(txn, 20, "txn5"))
+	executorContext := executors.NewExecutorContext(c, shi.GetBufferPoolManager(), txn)
+	results := executionEngine.Execute(plans.NewSeqScanPlanNode(schema_, nil, tableMetadata.OID()), executorContext)
+	txn_mgr.Commit(txn)
+	testingpkg.Equals(t, 2, len(results))
+	for _, tuple_ := range results {
+		if tuple_.GetValue(schema_, 0).ToInteger() == 10 {
+			testingpkg.Equals(t, "txn1", tuple_.GetValue(schema_, 1).ToVarchar())
+		} else {
+			testingpkg.Equals(t, "txn5", tuple_.GetValue(schema_, 1).ToVarchar())
+		}
+	}
+
+	// remove db file and log file
+	shi.Shutdown(true)
+}
@@ -1,10 +1,12 @@
 package executor_test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ryogrid/SamehadaDB/catalog"
 	"github.com/ryogrid/SamehadaDB/common"
 	"github.com/ryogrid/SamehadaDB/container/hash"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/execution/executors"
 	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
@@ -1490,3 +1492,88 @@ func TestSkipListPrallelTxnStrideVarchar(t *testing.T) {
 	//}
 	testSkipListParallelTxnStrideRoot[string](t, types.Varchar)
 }
+
+// readFailDiskManager makes ReadPage fail while fail is set
+type readFailDiskManager struct {
+	disk.DiskManager
+	fail int32
+}
+
+func (d *readFailDiskManager) ReadPage(pageID types.PageID, pageData []byte) error {
+	if atomic.LoadInt32(&d.fail) == 1 {
+		return errors.New("injected read error")
+	}
+	return d.DiskManager.ReadPage(pageID, pageData)
+}
+
+func TestSkipListIndexScanReadError(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	diskManager := &readFailDiskManager{DiskManager: disk.NewDiskManagerTest()}
+	log_mgr := recovery.NewLogManager(&diskManager.DiskManager)
+	bpm := buffer.NewBufferPoolManager(uint32(32), diskManager, log_mgr)
+
+	txn_mgr := access.NewTransactionManager(access.NewLockManager(access.REGULAR, access.DETECTION), log_mgr)
+	txn := txn_mgr.Begin(nil)
+
+	c := catalog.BootstrapCatalog(bpm, log_mgr, access.NewLockManager(access.REGULAR, access.PREVENTION), txn)
+
+	columnA := column.NewColumn("a", types.Integer, true, index_constants.INDEX_KIND_SKIP_LIST, types.PageID(-1), nil)
+	columnB := column.NewColumn("b", types.Varchar, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	schema_ := schema.NewSchema([]*column.Column{columnA, columnB})
+	tableMetadata := c.CreateTable("test_1", schema_, txn)
+
+	// enough rows for index pages to be evicted from the small buffer pool
+	rows := make([][]types.Value, 0)
+	for ii := 0; ii < 3000; ii++ {
+		rows = append(rows, []types.Value{types.NewInteger(int32(ii)), types.NewVarchar(fmt.Sprintf("padding of row %d ......................", ii))})
+	}
+	executionEngine := &executors.ExecutionEngine{}
+	executorContext := executors.NewExecutorContext(c, bpm, txn)
+	executionEngine.Execute(plans.NewInsertPlanNode(rows, tableMetadata.OID()), executorContext)
+	txn_mgr.Commit(txn)
+	bpm.FlushAllPages()
+
+	outSchema := schema.NewSchema([]*column.Column{column.NewColumn("a", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)})
+	colVal := expression.NewColumnValue(0, tableMetadata.Schema().GetColIndex("a"), types.Integer)
+	pointScanPlan := func(key int32) plans.Plan {
+		pred := expression.NewComparison(colVal, expression.NewConstantValue(types.NewInteger(key), types.Integer), expression.Equal, types.Boolean)
+		return plans.NewPointScanWithIndexPlanNode(outSchema, pred.(*expression.Comparison), tableMetadata.OID())
+	}
+	rangeStart := types.NewInteger(100)
+	rangeEnd := types.NewInteger(2900)
+	rangeScanPlan := plans.NewRangeScanWithIndexPlanNode(outSchema, tableMetadata.OID(), int32(tableMetadata.Schema().GetColIndex("a")), nil, &rangeStart, &rangeEnd)
+
+	for _, plan := range []plans.Plan{pointScanPlan(2500), rangeScanPlan} {
+		// evict index pages by reading whole table
+		txn = txn_mgr.Begin(nil)
+		executorContext.SetTransaction(txn)
+		_, err := executionEngine.ExecuteWithError(plans.NewSeqScanPlanNode(outSchema, nil, tableMetadata.OID()), executorContext)
+		testingpkg.Ok(t, err)
+		txn_mgr.Commit(txn)
+
+		txn = txn_mgr.Begin(nil)
+		executorContext.SetTransaction(txn)
+		atomic.StoreInt32(&diskManager.fail, 1)
+		_, err = executionEngine.ExecuteWithError(plan, executorContext)
+		atomic.StoreInt32(&diskManager.fail, 0)
+
+		var ioErr *samehada_errors.IOError
+		testingpkg.Assert(t, errors.As(err, &ioErr), "IOError should be returned but got %v", err)
+		testingpkg.Assert(t, txn.GetState() == access.ABORTED, "transaction should be aborted")
+		txn_mgr.Abort(c, txn)
+
+		// latches and pins taken before the failure must have been released
+		txn = txn_mgr.Begin(nil)
+		executorContext.SetTransaction(txn)
+		results, err := executionEngine.ExecuteWithError(plan, executorContext)
+		testingpkg.Ok(t, err)
+		testingpkg.Assert(t, len(results) > 0, "index scan after read error should succeed")
+		txn_mgr.Commit(txn)
+	}
+
+	common.TempSuppressOnMemStorage = false
+	diskManager.ShutDown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
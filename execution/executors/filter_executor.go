@@ -27,9 +27,10 @@ func (e *FilterExecutor) Init() {
 }
 
 func (e *FilterExecutor) Next() (*tuple.Tuple, Done, error) {
-	for t, done, err := e.child.Next(); !done; t, done, err = e.child.Next() {
+	// error is returned with done=true
+	for t, done, err := e.child.Next(); !done || err != nil; t, done, err = e.child.Next() {
 		if err != nil {
-			return nil, done, err
+			return nil, true, err
 		}
 		if t == nil && done == false {
 			err := errors.New("e.child.Next returned nil unexpectedly.")
@@ -52,13 +53,13 @@ func (e *FilterExecutor) GetOutputSchema() *schema.Schema {
 
 // select evaluates an expression on the tuple
 func (e *FilterExecutor) selects(tuple *tuple.Tuple, predicate expression.Expression) bool {
-	return predicate == nil || predicate.Evaluate(tuple, e.GetOutputSchema()).ToBoolean()
+	return predicate == nil || predicate.Evaluate(tuple, e.child.GetOutputSchema()).ToBoolean()
 }
 
 // project applies the projection operator defined by the output schema
 // It transform the tuple into a new tuple that corresponds to the output schema
 func (e *FilterExecutor) projects(tuple_ *tuple.Tuple) *tuple.Tuple {
-	srcOutSchema := e.plan.OutputSchema()
+	srcOutSchema := e.child.GetOutputSchema()
 	filterSchema := e.plan.GetSelectColumns()
 
 	values := []types.Value{}
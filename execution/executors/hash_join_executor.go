@@ -31,6 +31,7 @@ type HashJoinExecutor struct {
 	output_exprs_    []expression.Expression
 	tmp_page_ids_    []types.PageID
 	right_tuple_     tuple.Tuple
+	err_             error // error returned from left child or cancellation of execution at Init
 }
 
 /**
@@ -89,7 +90,18 @@ func (e *HashJoinExecutor) Init() {
 	var tmp_page *hash.TmpTuplePage = nil
 	var tmp_page_id types.PageID = common.InvalidPageID
 	var tmp_tuple hash.TmpTuple
-	for left_tuple, done, _ := e.left_.Next(); !done; left_tuple, done, _ = e.left_.Next() {
+	for {
+		left_tuple, done, err := e.left_.Next()
+		if err == nil {
+			err = e.context.checkCanceled()
+		}
+		if err != nil {
+			e.err_ = err
+			break
+		}
+		if done {
+			break
+		}
 		if left_tuple == nil {
 			return
 		}
@@ -114,32 +126,43 @@ func (e *HashJoinExecutor) Init() {
 			e.jht_.Insert(hash.HashValue(&valueAsKey), &tmp_tuple)
 		}
 	}
+	// unpin the last tmp page. otherwise it can't be deleted at the end of join
+	if tmp_page_id != common.InvalidPageID {
+		e.context.GetBufferPoolManager().UnpinPage(tmp_page_id, true)
+	}
 }
 
 // TODO: (SDB) need to refactor HashJoinExecutor::Next method to use GetExpr method of Column class
 //
 //	current impl is avoiding the method because it does not exist when this code was wrote
 func (e *HashJoinExecutor) Next() (*tuple.Tuple, Done, error) {
+	if e.err_ != nil {
+		e.deleteTmpPages()
+		return nil, true, e.err_
+	}
 	inner_next_cnt := 0
 	for {
+		if err := e.context.checkCanceled(); err != nil {
+			e.deleteTmpPages()
+			return nil, true, err
+		}
 		for int(e.index_) == len(e.tmp_tuples_) {
 			// we have traversed all possible join combination of the current right tuple
 			// move to the next right tuple
 			e.tmp_tuples_ = []hash.TmpTuple{}
 			e.index_ = 0
 			var done Done = false
+			var err error
 			var tmp_tuple *tuple.Tuple
-			if tmp_tuple, done, _ = e.right_.Next(); done {
+			if tmp_tuple, done, err = e.right_.Next(); err != nil || done {
 				//if tmp_tuple == nil {
 				//	err := errors.New("e.right_.Next returned nil")
 				//	return nil, false, err
 				//}
 
 				// hash join finished, delete all the tmp page we created
-				for _, tmp_page_id := range e.tmp_page_ids_ {
-					e.context.GetBufferPoolManager().DeletePage(tmp_page_id)
-				}
-				return tmp_tuple, true, nil
+				e.deleteTmpPages()
+				return tmp_tuple, true, err
 			}
 			inner_next_cnt++
 			e.right_tuple_ = *tmp_tuple
@@ -152,14 +175,20 @@ func (e *HashJoinExecutor) Next() (*tuple.Tuple, Done, error) {
 		// traverse corresponding left tuples stored in the tmp pages util we find one satisfying the predicate with current right tuple
 		left_tmp_tuple := e.tmp_tuples_[e.index_]
 		var left_tuple tuple.Tuple
-		e.FetchTupleFromTmpTuplePage(&left_tuple, &left_tmp_tuple)
+		if err := e.FetchTupleFromTmpTuplePage(&left_tuple, &left_tmp_tuple); err != nil {
+			e.deleteTmpPages()
+			return nil, true, err
+		}
 		for !e.IsValidCombination(&left_tuple, &e.right_tuple_) {
 			e.index_++
 			if int(e.index_) == len(e.tmp_tuples_) {
 				break
 			}
 			left_tmp_tuple = e.tmp_tuples_[e.index_]
-			e.FetchTupleFromTmpTuplePage(&left_tuple, &left_tmp_tuple)
+			if err := e.FetchTupleFromTmpTuplePage(&left_tuple, &left_tmp_tuple); err != nil {
+				e.deleteTmpPages()
+				return nil, true, err
+			}
 		}
 		if int(e.index_) < len(e.tmp_tuples_) {
 			// valid combination found
@@ -171,15 +200,24 @@ func (e *HashJoinExecutor) Next() (*tuple.Tuple, Done, error) {
 	}
 }
 
-func (e *HashJoinExecutor) FetchTupleFromTmpTuplePage(tuple_ *tuple.Tuple, tmp_tuple *hash.TmpTuple) {
-	tmp_page := hash.CastPageAsTmpTuplePage(e.context.GetBufferPoolManager().FetchPage(tmp_tuple.GetPageId()))
-	if tmp_page == nil {
-		panic("fail to fetch tmp page when doing hash join")
+// deleteTmpPages deletes tmp pages which store left tuples. they are not needed after join is finished or failed
+func (e *HashJoinExecutor) deleteTmpPages() {
+	for _, tmp_page_id := range e.tmp_page_ids_ {
+		e.context.GetBufferPoolManager().DeletePage(tmp_page_id)
+	}
+	e.tmp_page_ids_ = nil
+}
+
+func (e *HashJoinExecutor) FetchTupleFromTmpTuplePage(tuple_ *tuple.Tuple, tmp_tuple *hash.TmpTuple) error {
+	tmp_page, err := fetchTmpTuplePage(e.context.GetBufferPoolManager(), tmp_tuple.GetPageId())
+	if err != nil {
+		return err
 	}
 	// tmp_page content is copied and accessed from currrent transaction only
 	// so tuple locking is not needed
 	tmp_page.Get(tuple_, tmp_tuple.GetOffset())
 	e.context.GetBufferPoolManager().UnpinPage(tmp_tuple.GetPageId(), false)
+	return nil
 }
 
 func (e *HashJoinExecutor) IsValidCombination(left_tuple *tuple.Tuple, right_tuple *tuple.Tuple) bool {
@@ -4,10 +4,17 @@
 package executors
 
 import (
+	"errors"
+	"math"
+
 	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/common"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/access"
+	"github.com/ryogrid/SamehadaDB/storage/page"
 	"github.com/ryogrid/SamehadaDB/storage/table/schema"
 	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
 )
 
 /**
@@ -17,47 +24,330 @@ import (
 type InsertExecutor struct {
 	context       *ExecutorContext
 	plan          *plans.InsertPlanNode
+	child         Executor // nil when raw insert
 	tableMetadata *catalog.TableMetadata
+	results       []*tuple.Tuple // inserted (or updated by upsert) tuples of current batch which are not returned yet
+	isStarted     bool
+	isAllRead     bool // all rows to be inserted are read from the plan or the child
 }
 
-func NewInsertExecutor(context *ExecutorContext, plan *plans.InsertPlanNode) Executor {
+func NewInsertExecutor(context *ExecutorContext, plan *plans.InsertPlanNode, child Executor) Executor {
 	tableMetadata := context.GetCatalog().GetTableByOID(plan.GetTableOID())
 	//catalog := context.GetCatalog()
 
-	return &InsertExecutor{context, plan, tableMetadata}
+	return &InsertExecutor{context, plan, child, tableMetadata, nil, false, false}
 }
 
 func (e *InsertExecutor) Init() {
-
+	if e.child != nil {
+		e.child.Init()
+	}
 }
 
-// Next inserts the tuples into the tables
-// Note that Insert does not return any tuple
-// We return an error if the insert failed for any reason, and return nil if all inserts succeeded.
+// Next inserts tuples batch by batch and returns the inserted tuples one by one.
+// upsert returns the updated tuple instead when conflict occurs. returned tuples are used for
+// RETURNING clause and count of affected rows.
+// We return an error if the insert failed for any reason.
 func (e *InsertExecutor) Next() (*tuple.Tuple, Done, error) {
-	// let's assume it is raw insert
-
-	for _, values := range e.plan.GetRawValues() {
-		tuple_ := tuple.NewTupleFromSchema(values, e.tableMetadata.Schema())
-		tableHeap := e.tableMetadata.Table()
-		rid, err := tableHeap.InsertTuple(tuple_, e.context.txn, e.tableMetadata.OID())
+	for len(e.results) == 0 {
+		rows, err := e.nextRows()
 		if err != nil {
 			return nil, true, err
 		}
+		if len(rows) == 0 {
+			return nil, true, nil
+		}
+		if err := e.insertRows(rows); err != nil {
+			return nil, true, err
+		}
+	}
+
+	ret := e.results[0]
+	e.results = e.results[1:]
+	return ret, false, nil
+}
+
+func (e *InsertExecutor) insertRows(rows [][]types.Value) error {
+	tuples := make([]*tuple.Tuple, 0, len(rows))
+	for _, values := range rows {
+		tuples = append(tuples, e.tableMetadata.ComputeGeneratedColumns(tuple.NewTupleFromSchema(values, e.tableMetadata.Schema())))
+	}
+
+	if e.plan.GetOnConflictAction() != nil {
+		// tuples are inserted one by one because a tuple may conflict with tuples inserted before it
+		for _, tuple_ := range tuples {
+			affected, err := e.upsertTuple(tuple_)
+			if err != nil {
+				return err
+			}
+			if affected != nil {
+				e.results = append(e.results, affected)
+			}
+		}
+		return nil
+	}
+
+	if (e.child != nil || len(tuples) >= common.BulkInsertBatchSize) && !e.isSelfReferencing() {
+		for head := 0; head < len(tuples); head += common.BulkInsertBatchSize {
+			tail := head + common.BulkInsertBatchSize
+			if tail > len(tuples) {
+				tail = len(tuples)
+			}
+			if err := e.bulkInsertTuplesAndIndexEntries(tuples[head:tail]); err != nil {
+				return err
+			}
+		}
+		e.results = tuples
+		return nil
+	}
+
+	for _, tuple_ := range tuples {
+		if err := e.insertTupleAndIndexEntries(tuple_); err != nil {
+			return err
+		}
+	}
+	e.results = tuples
+
+	return nil
+}
+
+// nextRows returns values of all columns of tuples to be inserted next. empty list is returned
+// when all tuples are inserted. tuples of the child are read and inserted in batches of
+// common.BulkInsertBatchSize tuples, so they are not kept on memory at once
+func (e *InsertExecutor) nextRows() ([][]types.Value, error) {
+	if e.isAllRead {
+		return nil, nil
+	}
+	if e.child == nil {
+		e.isAllRead = true
+		return e.plan.GetRawValues(), nil
+	}
+	if !e.isStarted {
+		e.isStarted = true
+		if e.readsTargetTable(e.plan.GetChildAt(0)) {
+			// tuples of the child are read before insertion starts. otherwise tuples inserted
+			// to the table which is also read by the child can be read again
+			return e.readChildRows(math.MaxInt32)
+		}
+	}
+	return e.readChildRows(common.BulkInsertBatchSize)
+}
+
+// readChildRows reads at most maxNum tuples from the child
+func (e *InsertExecutor) readChildRows(maxNum int) ([][]types.Value, error) {
+	columns := e.tableMetadata.Schema().GetColumns()
+	childSchema := e.child.GetOutputSchema()
+	rows := make([][]types.Value, 0)
+	for len(rows) < maxNum {
+		tuple_, done, err := e.child.Next()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			e.isAllRead = true
+			break
+		}
+		if tuple_ == nil {
+			continue
+		}
+
+		row := make([]types.Value, len(columns))
+		isSpecified := make([]bool, len(columns))
+		for ii, colIdx := range e.plan.GetTargetColIdxs() {
+			val := tuple_.GetValue(childSchema, uint32(ii))
+			if val.IsNull() {
+				val = types.NewNullOfType(columns[colIdx].GetType())
+			}
+			row[colIdx] = val
+			isSpecified[colIdx] = true
+		}
+		if err := e.context.GetCatalog().FillUnspecifiedValues(e.tableMetadata, row, isSpecified); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readsTargetTable returns true when plan scans the table which tuples are inserted into
+func (e *InsertExecutor) readsTargetTable(plan plans.Plan) bool {
+	switch plan.(type) {
+	case *plans.SeqScanPlanNode, *plans.PointScanWithIndexPlanNode, *plans.RangeScanWithIndexPlanNode:
+		if plan.GetTableOID() == e.plan.GetTableOID() {
+			return true
+		}
+	}
+	for _, child := range plan.GetChildren() {
+		if e.readsTargetTable(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// tuples of the table which references itself may reference tuples inserted at same time.
+// they can't be checked in batch
+func (e *InsertExecutor) isSelfReferencing() bool {
+	for _, col := range e.tableMetadata.Schema().GetColumns() {
+		if col.ForeignKey() != nil && col.ForeignKey().RefTableOID == e.tableMetadata.OID() {
+			return true
+		}
+	}
+	return false
+}
 
-		colNum := e.tableMetadata.GetColumnNum()
-		for ii := 0; ii < int(colNum); ii++ {
-			ret := e.tableMetadata.GetIndex(ii)
-			if ret == nil {
-				continue
-			} else {
-				index_ := ret
-				index_.InsertEntry(tuple_, *rid, e.context.txn)
+// bulkInsertTuplesAndIndexEntries inserts tuples with TableHeap::InsertTuples and
+// inserts their index entries at once after constraints of all tuples are checked
+func (e *InsertExecutor) bulkInsertTuplesAndIndexEntries(tuples []*tuple.Tuple) error {
+	for idx := range tuples {
+		if err := e.checkConstraintsExceptUnique(tuples[idx]); err != nil {
+			return err
+		}
+	}
+	if e.tableMetadata.HasUniqueConstraint() {
+		// check of constraints and insertion of index entries must not be interleaved with other transactions
+		e.tableMetadata.LockUniqueCheck()
+		defer e.tableMetadata.UnlockUniqueCheck()
+		if err := e.tableMetadata.CheckUniqueConstraintsInBatch(tuples); err != nil {
+			return err
+		}
+		for _, tuple_ := range tuples {
+			if err := e.tableMetadata.CheckUniqueConstraints(tuple_, nil, nil, e.context.txn); err != nil {
+				return err
 			}
 		}
 	}
 
-	return nil, true, nil
+	rids, err := e.tableMetadata.Table().InsertTuples(tuples, e.context.txn, e.tableMetadata.OID())
+	if err != nil {
+		return err
+	}
+
+	colNum := e.tableMetadata.GetColumnNum()
+	for ii := 0; ii < int(colNum); ii++ {
+		index_ := e.tableMetadata.GetIndex(ii)
+		if index_ == nil {
+			continue
+		}
+		index_.BulkInsertEntries(tuples, rids, e.context.txn)
+	}
+	return nil
+}
+
+func (e *InsertExecutor) insertTupleAndIndexEntries(tuple_ *tuple.Tuple) error {
+	if err := e.checkConstraintsExceptUnique(tuple_); err != nil {
+		return err
+	}
+	if e.tableMetadata.HasUniqueConstraint() {
+		// check of constraints and insertion of index entries must not be interleaved with other transactions
+		e.tableMetadata.LockUniqueCheck()
+		defer e.tableMetadata.UnlockUniqueCheck()
+		if err := e.tableMetadata.CheckUniqueConstraints(tuple_, nil, nil, e.context.txn); err != nil {
+			return err
+		}
+	}
+	return e.insertTupleAndIndexEntriesWithoutCheck(tuple_)
+}
+
+// upsertTuple inserts tuple_ or takes OnConflictAction of the plan when a stored tuple
+// has same value on PRIMARY KEY or UNIQUE column.
+// probe of the conflicting tuple and insertion are not interleaved with other transactions.
+// the conflicting tuple is locked in exclusive mode before the lock for unique check is released.
+// so concurrent updates of the tuple are not lost.
+// returned tuple is the inserted or updated tuple. it is nil when nothing is done
+func (e *InsertExecutor) upsertTuple(tuple_ *tuple.Tuple) (*tuple.Tuple, error) {
+	action := e.plan.GetOnConflictAction()
+
+	e.tableMetadata.LockUniqueCheck()
+	conflicted, err := e.tableMetadata.FindConflictingTuple(tuple_, e.context.txn)
+	if err != nil {
+		e.tableMetadata.UnlockUniqueCheck()
+		return nil, err
+	}
+	if conflicted == nil {
+		defer e.tableMetadata.UnlockUniqueCheck()
+		if err := e.checkConstraintsExceptUnique(tuple_); err != nil {
+			return nil, err
+		}
+		if err := e.insertTupleAndIndexEntriesWithoutCheck(tuple_); err != nil {
+			return nil, err
+		}
+		return tuple_, nil
+	}
+	if action.DoNothing {
+		e.tableMetadata.UnlockUniqueCheck()
+		return nil, nil
+	}
+	err = lockTupleExclusive(e.context, conflicted.GetRID())
+	e.tableMetadata.UnlockUniqueCheck()
+	if err != nil {
+		return nil, err
+	}
+
+	schema_ := e.tableMetadata.Schema()
+	values := make([]types.Value, 0)
+	for colIdx := uint32(0); colIdx < schema_.GetColumnCount(); colIdx++ {
+		values = append(values, conflicted.GetValue(schema_, colIdx))
+	}
+	for ii, colIdx := range action.UpdateColIdxs {
+		if srcColIdx := action.UpdateSrcColIdxs[ii]; srcColIdx != -1 {
+			values[colIdx] = tuple_.GetValue(schema_, uint32(srcColIdx))
+		} else {
+			values[colIdx] = action.UpdateValues[ii]
+		}
+	}
+	return updateTupleAndIndexEntries(e.context, e.tableMetadata, conflicted, values, action.UpdateColIdxs)
+}
+
+// lockTupleExclusive acquires exclusive lock of the tuple located at rid.
+// when the lock can't be acquired, txn is aborted and error is returned
+func lockTupleExclusive(context *ExecutorContext, rid *page.RID) error {
+	txn := context.GetTransaction()
+	if !context.GetCatalog().Log_manager.IsEnabledLogging() || txn.IsExclusiveLocked(rid) {
+		return nil
+	}
+	lockManager := context.GetCatalog().Lock_manager
+	var locked bool
+	if txn.IsSharedLocked(rid) {
+		locked = lockManager.LockUpgrade(txn, rid)
+	} else {
+		locked = lockManager.LockExclusive(txn, rid)
+	}
+	if !locked {
+		txn.SetState(access.ABORTED)
+		return errors.New("could not acquire an exclusive lock on the conflicting tuple.")
+	}
+	return nil
+}
+
+func (e *InsertExecutor) checkConstraintsExceptUnique(tuple_ *tuple.Tuple) error {
+	if err := e.tableMetadata.CheckNotNullConstraints(tuple_, nil); err != nil {
+		return err
+	}
+	if err := e.tableMetadata.CheckCheckConstraints(tuple_); err != nil {
+		return err
+	}
+	return e.context.GetCatalog().CheckForeignKeyConstraints(e.tableMetadata, tuple_, nil, e.context.txn)
+}
+
+func (e *InsertExecutor) insertTupleAndIndexEntriesWithoutCheck(tuple_ *tuple.Tuple) error {
+	tableHeap := e.tableMetadata.Table()
+	rid, err := tableHeap.InsertTuple(tuple_, e.context.txn, e.tableMetadata.OID())
+	if err != nil {
+		return err
+	}
+
+	colNum := e.tableMetadata.GetColumnNum()
+	for ii := 0; ii < int(colNum); ii++ {
+		ret := e.tableMetadata.GetIndex(ii)
+		if ret == nil {
+			continue
+		} else {
+			index_ := ret
+			index_.InsertEntry(tuple_, *rid, e.context.txn)
+		}
+	}
+	return nil
 }
 
 func (e *InsertExecutor) GetOutputSchema() *schema.Schema {
@@ -30,7 +30,7 @@ func (e *LimitExecutor) Init() {
 }
 
 func (e *LimitExecutor) Next() (*tuple.Tuple, Done, error) {
-	for t, done, err := e.child.Next(); !done; t, done, err = e.child.Next() {
+	for t, done, err := e.child.Next(); !done || err != nil; t, done, err = e.child.Next() {
 		if err != nil {
 			return nil, done, err
 		}
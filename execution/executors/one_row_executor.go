@@ -0,0 +1,40 @@
+package executors
+
+import (
+	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+// returns one tuple which has no column
+
+type OneRowExecutor struct {
+	context *ExecutorContext
+	plan    *plans.OneRowPlanNode
+	done    bool
+}
+
+func NewOneRowExecutor(context *ExecutorContext, plan *plans.OneRowPlanNode) Executor {
+	return &OneRowExecutor{context, plan, false}
+}
+
+func (e *OneRowExecutor) Init() {
+	e.done = false
+}
+
+func (e *OneRowExecutor) Next() (*tuple.Tuple, Done, error) {
+	if e.done {
+		return nil, true, nil
+	}
+	e.done = true
+	return tuple.NewTupleFromSchema([]types.Value{}, e.plan.OutputSchema()), false, nil
+}
+
+func (e *OneRowExecutor) GetOutputSchema() *schema.Schema {
+	return e.plan.OutputSchema()
+}
+
+// can not be used
+func (e *OneRowExecutor) GetTableMetaData() *catalog.TableMetadata { return nil }
@@ -20,7 +20,8 @@ type OrderbyExecutor struct {
 	/** The child executor whose tuples we are aggregating. */
 	child_       []Executor
 	sort_tuples_ []*tuple.Tuple
-	cur_idx_     int // target tuple index on Next method
+	cur_idx_     int   // target tuple index on Next method
+	err_         error // error returned from child or cancellation of execution at Init
 }
 
 /**
@@ -31,7 +32,7 @@ type OrderbyExecutor struct {
  */
 func NewOrderbyExecutor(exec_ctx *ExecutorContext, plan *plans.OrderbyPlanNode,
 	child Executor) *OrderbyExecutor {
-	return &OrderbyExecutor{exec_ctx, plan, []Executor{child}, make([]*tuple.Tuple, 0), 0}
+	return &OrderbyExecutor{exec_ctx, plan, []Executor{child}, make([]*tuple.Tuple, 0), 0, nil}
 }
 
 func (e *OrderbyExecutor) GetOutputSchema() *schema.Schema { return e.plan_.OutputSchema() }
@@ -45,10 +46,16 @@ func (e *OrderbyExecutor) Init() {
 	sort_values := make([][]*types.Value, 0)
 	inserted_tuple_cnt := int32(0)
 	for {
+		if err := e.context.checkCanceled(); err != nil {
+			e.err_ = err
+			return
+		}
 		tuple_, done, err := child_exec.Next()
 		if err != nil || done {
 			if err != nil {
 				fmt.Println(err)
+				e.err_ = err
+				return
 			}
 			break
 		}
@@ -94,6 +101,11 @@ func (e *OrderbyExecutor) Init() {
 		return false
 	})
 	fmt.Printf("inserted_tuple_cnt %d\n", inserted_tuple_cnt)
+	// sorting many tuples takes long time
+	if err := e.context.checkCanceled(); err != nil {
+		e.err_ = err
+		return
+	}
 	// arrange tuple array (apply sort result)
 	tuple_cnt := len(e.sort_tuples_)
 	var tmp_tuples []*tuple.Tuple = make([]*tuple.Tuple, tuple_cnt)
@@ -106,6 +118,9 @@ func (e *OrderbyExecutor) Init() {
 }
 
 func (e *OrderbyExecutor) Next() (*tuple.Tuple, Done, error) {
+	if e.err_ != nil {
+		return nil, true, e.err_
+	}
 	if e.cur_idx_ < len(e.sort_tuples_) {
 		ret := e.sort_tuples_[e.cur_idx_]
 		e.cur_idx_++
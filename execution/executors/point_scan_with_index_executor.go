@@ -66,7 +66,15 @@ func (e *PointScanWithIndexExecutor) Init() {
 
 	dummyTuple := tuple.GenTupleForIndexSearch(schema_, uint32(indexColNum), samehada_util.GetPonterOfValue(comparison.GetRightSideValue(nil, schema_)))
 	rids := index_.ScanKey(dummyTuple, e.txn)
+	if e.txn.GetAbortCause() != nil {
+		// read of index page failed. error is returned by Next
+		return
+	}
 	for _, rid := range rids {
+		if e.context.checkCanceled() != nil {
+			// error is returned by Next
+			return
+		}
 		tuple_ := e.tableMetadata.Table().GetTuple(&rid, e.txn)
 		if tuple_ == nil {
 			e.foundTuples = make([]*tuple.Tuple, 0)
@@ -77,6 +85,12 @@ func (e *PointScanWithIndexExecutor) Init() {
 }
 
 func (e *PointScanWithIndexExecutor) Next() (*tuple.Tuple, Done, error) {
+	if err := e.context.checkCanceled(); err != nil {
+		return nil, true, err
+	}
+	if cause := e.txn.GetAbortCause(); cause != nil {
+		return nil, true, cause
+	}
 	if len(e.foundTuples) > 0 {
 		tuple_ := e.foundTuples[0]
 		e.foundTuples = e.foundTuples[1:]
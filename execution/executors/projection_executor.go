@@ -0,0 +1,54 @@
+package executors
+
+import (
+	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/execution/expression"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+// evaluate expressions for each tuple returned from child. it is used for RETURNING clause
+// and renaming of columns
+
+type ProjectionExecutor struct {
+	context *ExecutorContext
+	plan    *plans.ProjectionPlanNode
+	child   Executor
+}
+
+func NewProjectionExecutor(context *ExecutorContext, plan *plans.ProjectionPlanNode, child Executor) Executor {
+	return &ProjectionExecutor{context, plan, child}
+}
+
+func (e *ProjectionExecutor) Init() {
+	e.child.Init()
+}
+
+func (e *ProjectionExecutor) Next() (*tuple.Tuple, Done, error) {
+	t, done, err := e.child.Next()
+	if err != nil || done || t == nil {
+		return nil, done, err
+	}
+
+	// DML executors have no output schema and return tuples of the table
+	srcSchema := e.child.GetOutputSchema()
+	if srcSchema == nil {
+		srcSchema = e.child.GetTableMetaData().Schema()
+	}
+	outSchema := e.plan.OutputSchema()
+	values := make([]types.Value, 0)
+	for _, col := range outSchema.GetColumns() {
+		values = append(values, col.GetExpr().(expression.Expression).Evaluate(t, srcSchema))
+	}
+	return tuple.NewTupleFromSchema(values, outSchema), false, nil
+}
+
+func (e *ProjectionExecutor) GetOutputSchema() *schema.Schema {
+	return e.plan.OutputSchema()
+}
+
+func (e *ProjectionExecutor) GetTableMetaData() *catalog.TableMetadata {
+	return e.child.GetTableMetaData()
+}
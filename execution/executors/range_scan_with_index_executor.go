@@ -97,9 +97,20 @@ func (e *RangeScanWithIndexExecutor) Init() {
 func (e *RangeScanWithIndexExecutor) Next() (*tuple.Tuple, Done, error) {
 	// iterates through the RIDs got from index
 	var tuple_ *tuple.Tuple = nil
-	for done, _, key, rid := e.ridItr.Next(); !done; done, _, key, rid = e.ridItr.Next() {
+	for done, err, key, rid := e.ridItr.Next(); !done || err != nil; done, err, key, rid = e.ridItr.Next() {
+		if err != nil {
+			// read of index page failed
+			return nil, true, err
+		}
+		if err := e.context.checkCanceled(); err != nil {
+			return nil, true, err
+		}
 		tuple_ = e.tableMetadata.Table().GetTuple(rid, e.txn)
 		if tuple_ == nil {
+			if cause := e.txn.GetAbortCause(); cause != nil {
+				// read of table page failed
+				return nil, true, cause
+			}
 			err := errors.New("e.ridItr.Next returned nil")
 			return nil, true, err
 		}
@@ -0,0 +1,132 @@
+package executors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/common"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+)
+
+// RecursiveCTEExecutor evaluates recursive common table expression until fixpoint.
+// tuples returned from anchor are the first working table. recursive part reads the working table
+// and its new tuples become next working table. evaluation finishes when the working table is empty
+
+type RecursiveCTEExecutor struct {
+	context *ExecutorContext
+	plan    *plans.RecursiveCTEPlanNode
+	anchor  Executor
+	// executor of recursive part is created for each iteration because executors can't be reinitialized
+	newRecursive func() Executor
+	results      *tmpTupleStore
+	seen         map[string]bool // tuples already returned. used for removing duplicates at UNION
+	curIdx       int
+}
+
+func NewRecursiveCTEExecutor(context *ExecutorContext, plan *plans.RecursiveCTEPlanNode, anchor Executor, newRecursive func() Executor) Executor {
+	return &RecursiveCTEExecutor{context, plan, anchor, newRecursive, nil, nil, 0}
+}
+
+func (e *RecursiveCTEExecutor) Init() {
+	e.anchor.Init()
+	e.results = nil
+	e.curIdx = 0
+}
+
+func (e *RecursiveCTEExecutor) Next() (*tuple.Tuple, Done, error) {
+	if e.results == nil {
+		if err := e.evaluate(); err != nil {
+			return nil, true, err
+		}
+	}
+
+	if err := e.context.checkCanceled(); err != nil {
+		return nil, true, err
+	}
+	if e.curIdx >= e.results.Len() {
+		return nil, true, nil
+	}
+	ret, err := e.results.Get(e.curIdx)
+	if err != nil {
+		return nil, true, err
+	}
+	e.curIdx++
+	return ret, false, nil
+}
+
+func (e *RecursiveCTEExecutor) evaluate() error {
+	e.results = e.context.newTmpTupleStore()
+	e.seen = make(map[string]bool)
+	cteName := e.plan.GetCTEName()
+
+	workTable := e.context.newTmpTupleStore()
+	if err := e.appendNewTuples(e.anchor, workTable); err != nil {
+		return err
+	}
+	for depth := 0; workTable.Len() > 0; depth++ {
+		if err := e.context.checkCanceled(); err != nil {
+			return err
+		}
+		if depth >= common.MaxCTERecursionDepth {
+			return errors.New(fmt.Sprintf("recursion of CTE %s exceeded the limit of %d iterations.", cteName, common.MaxCTERecursionDepth))
+		}
+		e.context.workTables[cteName] = workTable
+		nextWorkTable := e.context.newTmpTupleStore()
+		recursive := e.newRecursive()
+		recursive.Init()
+		if err := e.appendNewTuples(recursive, nextWorkTable); err != nil {
+			return err
+		}
+		workTable.Clear()
+		workTable = nextWorkTable
+	}
+	delete(e.context.workTables, cteName)
+	return nil
+}
+
+// appendNewTuples appends tuples returned from child to results and workTable.
+// tuples are converted to output schema and duplicated tuples are skipped at UNION
+func (e *RecursiveCTEExecutor) appendNewTuples(child Executor, workTable *tmpTupleStore) error {
+	outSchema := e.plan.OutputSchema()
+	for {
+		if err := e.context.checkCanceled(); err != nil {
+			return err
+		}
+		tuple_, done, err := child.Next()
+		if err != nil {
+			return err
+		}
+		if done {
+			break
+		}
+		if tuple_ == nil {
+			continue
+		}
+
+		newTuple := convTupleToSchema(tuple_, child.GetOutputSchema(), outSchema)
+		if !e.plan.IsUnionAll() {
+			key := string(newTuple.Data())
+			if e.seen[key] {
+				continue
+			}
+			e.seen[key] = true
+		}
+		if err := e.results.Append(newTuple); err != nil {
+			return err
+		}
+		if err := workTable.Append(newTuple); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *RecursiveCTEExecutor) GetOutputSchema() *schema.Schema {
+	return e.plan.OutputSchema()
+}
+
+// can not be used
+func (e *RecursiveCTEExecutor) GetTableMetaData() *catalog.TableMetadata { return nil }
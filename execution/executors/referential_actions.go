@@ -0,0 +1,235 @@
+package executors
+
+import (
+	"fmt"
+	"github.com/ryogrid/SamehadaDB/catalog"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/samehada/samehada_util"
+	"github.com/ryogrid/SamehadaDB/storage/access"
+	"github.com/ryogrid/SamehadaDB/storage/page"
+	"github.com/ryogrid/SamehadaDB/storage/table/column"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+// deleteTupleAndIndexEntries marks t deleted and removes its index entries.
+// after that, ON DELETE actions of FOREIGN KEY constraints which reference the table are applied.
+// this is used by DeleteExecutor and ON DELETE CASCADE
+func deleteTupleAndIndexEntries(context *ExecutorContext, tableMetadata *catalog.TableMetadata, t *tuple.Tuple) error {
+	rid := t.GetRID()
+	is_marked := tableMetadata.Table().MarkDelete(rid, tableMetadata.OID(), context.GetTransaction())
+	if !is_marked {
+		return tupleOperationError(context.GetTransaction(), "marking tuple deleted", rid)
+	}
+
+	colNum := tableMetadata.GetColumnNum()
+	for ii := 0; ii < int(colNum); ii++ {
+		ret := tableMetadata.GetIndex(ii)
+		if ret == nil {
+			continue
+		} else {
+			index_ := ret
+			index_.DeleteEntry(t, *rid, context.GetTransaction())
+		}
+	}
+
+	// index entries of t are already removed. so, self referencing tuples don't cause infinite loop
+	return applyReferentialActions(context, tableMetadata, t, nil, nil)
+}
+
+// updateTupleAndIndexEntries updates t with values and maintains index entries.
+// only columns included in updateColIdxs are updated (nil means all columns).
+// after that, ON UPDATE actions of FOREIGN KEY constraints which reference updated columns are applied.
+// this is used by UpdateExecutor, ON DELETE SET NULL and ON UPDATE CASCADE/SET NULL
+func updateTupleAndIndexEntries(context *ExecutorContext, tableMetadata *catalog.TableMetadata, t *tuple.Tuple, values []types.Value, updateColIdxs []int) (*tuple.Tuple, error) {
+	if tableMetadata.HasGeneratedColumn() {
+		// generated columns are recomputed and updated with other columns
+		values, updateColIdxs = addGeneratedColumnValues(tableMetadata, t, values, updateColIdxs)
+	}
+	new_tuple, err := updateTupleAndIndexEntriesWithCheck(context, tableMetadata, t, values, updateColIdxs)
+	if err != nil {
+		return nil, err
+	}
+
+	// actions are applied after unlock of unique check because they may update same table
+	if err := applyReferentialActions(context, tableMetadata, t, new_tuple, updateColIdxs); err != nil {
+		return nil, err
+	}
+	// new_tuple may have dummy values on columns which are not updated
+	return mergeUpdatedValues(tableMetadata, t, values, updateColIdxs), nil
+}
+
+// t is tuple before update
+func updateTupleAndIndexEntriesWithCheck(context *ExecutorContext, tableMetadata *catalog.TableMetadata, t *tuple.Tuple, values []types.Value, updateColIdxs []int) (*tuple.Tuple, error) {
+	txn := context.GetTransaction()
+	rid := t.GetRID()
+	new_tuple := tuple.NewTupleFromSchema(values, tableMetadata.Schema())
+
+	if err := tableMetadata.CheckNotNullConstraints(new_tuple, updateColIdxs); err != nil {
+		return nil, err
+	}
+	if err := tableMetadata.CheckCheckConstraints(mergeUpdatedValues(tableMetadata, t, values, updateColIdxs)); err != nil {
+		return nil, err
+	}
+	if err := context.GetCatalog().CheckForeignKeyConstraints(tableMetadata, new_tuple, updateColIdxs, txn); err != nil {
+		return nil, err
+	}
+	if tableMetadata.HasUniqueConstraint() {
+		// check of constraints and update of index entries must not be interleaved with other transactions
+		tableMetadata.LockUniqueCheck()
+		defer tableMetadata.UnlockUniqueCheck()
+		if err := tableMetadata.CheckUniqueConstraints(new_tuple, updateColIdxs, rid, txn); err != nil {
+			return nil, err
+		}
+	}
+
+	var is_updated bool = false
+	var new_rid *page.RID = nil
+	if updateColIdxs == nil {
+		is_updated, new_rid = tableMetadata.Table().UpdateTuple(new_tuple, nil, nil, tableMetadata.OID(), *rid, txn)
+	} else {
+		is_updated, new_rid = tableMetadata.Table().UpdateTuple(new_tuple, updateColIdxs, tableMetadata.Schema(), tableMetadata.OID(), *rid, txn)
+	}
+
+	if !is_updated {
+		return nil, tupleOperationError(txn, "tuple update", rid)
+	}
+
+	colNum := tableMetadata.GetColumnNum()
+	for ii := 0; ii < int(colNum); ii++ {
+		ret := tableMetadata.GetIndex(ii)
+		if ret == nil {
+			continue
+		} else {
+			index_ := ret
+			if updateColIdxs == nil || samehada_util.IsContainList[int](updateColIdxs, ii) {
+				if new_rid != nil {
+					// when tuple is moved page location on update, RID is changed to new value
+					index_.DeleteEntry(t, *rid, txn)
+					index_.InsertEntry(new_tuple, *new_rid, txn)
+				} else {
+					index_.DeleteEntry(t, *rid, txn)
+					index_.InsertEntry(new_tuple, *rid, txn)
+				}
+			} else {
+				if new_rid != nil {
+					// when tuple is moved page location on update, RID is changed to new value
+					index_.InsertEntry(t, *new_rid, txn)
+				} else {
+					// update is not needed
+				}
+			}
+		}
+	}
+
+	return new_tuple, nil
+}
+
+// tupleOperationError returns error which is reported when operation on the tuple at rid fails.
+// the operation fails when txn is aborted (ex: lock conflict). otherwise, failure is a bug
+func tupleOperationError(txn *access.Transaction, operation string, rid *page.RID) error {
+	if txn.GetState() == access.ABORTED {
+		return samehada_errors.NewTxnAbortedError(txn.GetAbortCause())
+	}
+	return samehada_errors.NewInternalError(fmt.Errorf("%s failed. PageId:SlotNum = %d:%d", operation, rid.GetPageId(), rid.GetSlotNum()))
+}
+
+// mergeUpdatedValues returns tuple which has values after update.
+// values of columns not included in updateColIdxs are taken from t
+func mergeUpdatedValues(tableMetadata *catalog.TableMetadata, t *tuple.Tuple, values []types.Value, updateColIdxs []int) *tuple.Tuple {
+	schema_ := tableMetadata.Schema()
+	if updateColIdxs == nil {
+		return tuple.NewTupleFromSchema(values, schema_)
+	}
+	merged := make([]types.Value, 0)
+	for colIdx := 0; colIdx < int(tableMetadata.GetColumnNum()); colIdx++ {
+		if samehada_util.IsContainList[int](updateColIdxs, colIdx) {
+			merged = append(merged, values[colIdx])
+		} else {
+			merged = append(merged, t.GetValue(schema_, uint32(colIdx)))
+		}
+	}
+	return tuple.NewTupleFromSchema(merged, schema_)
+}
+
+// addGeneratedColumnValues returns values whose elements of generated columns are recomputed
+// and column indexes to be updated which include generated columns
+func addGeneratedColumnValues(tableMetadata *catalog.TableMetadata, t *tuple.Tuple, values []types.Value, updateColIdxs []int) ([]types.Value, []int) {
+	schema_ := tableMetadata.Schema()
+	computed := tableMetadata.ComputeGeneratedColumns(mergeUpdatedValues(tableMetadata, t, values, updateColIdxs))
+	newValues := make([]types.Value, 0)
+	for colIdx := 0; colIdx < int(tableMetadata.GetColumnNum()); colIdx++ {
+		newValues = append(newValues, computed.GetValue(schema_, uint32(colIdx)))
+	}
+	if updateColIdxs == nil {
+		return newValues, nil
+	}
+	// updateColIdxs may be shared with plan. so it is copied
+	newColIdxs := append(make([]int, 0), updateColIdxs...)
+	for colIdx, col := range schema_.GetColumns() {
+		if col.IsGenerated() && !samehada_util.IsContainList[int](newColIdxs, colIdx) {
+			newColIdxs = append(newColIdxs, colIdx)
+		}
+	}
+	return newValues, newColIdxs
+}
+
+// applyReferentialActions applies RESTRICT, CASCADE and SET NULL actions to tuples
+// which reference oldTuple of parent table.
+// when newTuple is nil, oldTuple is deleted. otherwise oldTuple is updated to newTuple and
+// only referenced columns included in updateColIdxs and whose value is changed are processed.
+// referencing tuples are found with index of FOREIGN KEY column and locked through TableHeap.
+func applyReferentialActions(context *ExecutorContext, parent *catalog.TableMetadata, oldTuple *tuple.Tuple, newTuple *tuple.Tuple, updateColIdxs []int) error {
+	txn := context.GetTransaction()
+	for _, ref := range context.GetCatalog().GetReferencingForeignKeys(parent) {
+		oldVal := oldTuple.GetValue(parent.Schema(), ref.ParentColIdx)
+		if oldVal.IsNull() {
+			continue
+		}
+		action := ref.ForeignKey.OnDelete
+		if newTuple != nil {
+			if updateColIdxs != nil && !samehada_util.IsContainList[int](updateColIdxs, int(ref.ParentColIdx)) {
+				continue
+			}
+			if newTuple.GetValue(parent.Schema(), ref.ParentColIdx).CompareEquals(oldVal) {
+				continue
+			}
+			action = ref.ForeignKey.OnUpdate
+		}
+
+		children, err := ref.ChildTable.FindTuplesByValue(int(ref.ChildColIdx), oldVal, txn)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			switch action {
+			case column.FK_ACTION_RESTRICT:
+				return samehada_errors.NewConstraintViolationError(samehada_errors.CONSTRAINT_FOREIGN_KEY, ref.ChildTable.GetTableName(), ref.ChildTable.Schema().GetColumn(ref.ChildColIdx).GetColumnName())
+			case column.FK_ACTION_CASCADE:
+				if newTuple == nil {
+					err = deleteTupleAndIndexEntries(context, ref.ChildTable, child)
+				} else {
+					err = updateChildColumn(context, ref.ChildTable, child, ref.ChildColIdx, newTuple.GetValue(parent.Schema(), ref.ParentColIdx))
+				}
+			case column.FK_ACTION_SET_NULL:
+				colType := ref.ChildTable.Schema().GetColumn(ref.ChildColIdx).GetType()
+				err = updateChildColumn(context, ref.ChildTable, child, ref.ChildColIdx, types.NewNullOfType(colType))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func updateChildColumn(context *ExecutorContext, child *catalog.TableMetadata, t *tuple.Tuple, colIdx uint32, val types.Value) error {
+	// values of columns which are not updated are dummy
+	values := make([]types.Value, child.GetColumnNum())
+	for idx, _ := range values {
+		values[idx] = types.NewNull()
+	}
+	values[colIdx] = val
+	_, err := updateTupleAndIndexEntries(context, child, t, values, []int{int(colIdx)})
+	return err
+}
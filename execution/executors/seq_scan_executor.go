@@ -46,6 +46,9 @@ func (e *SeqScanExecutor) Next() (*tuple.Tuple, Done, error) {
 
 	// iterates through the table heap trying to select a tuple that matches the predicate
 	for t := e.it.Current(); !e.it.End(); t = e.it.Next() {
+		if err := e.context.checkCanceled(); err != nil {
+			return nil, true, err
+		}
 		if t == nil {
 			err := errors.New("e.it.Next returned nil")
 			return nil, true, err
@@ -0,0 +1,132 @@
+package executors
+
+import (
+	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+)
+
+// SetOperationExecutor executes UNION, INTERSECT [ALL] and EXCEPT [ALL] with hash table whose key is data of tuple.
+// for UNION, tuples of left and right are returned when same tuple has not been returned.
+// for INTERSECT and EXCEPT, tuples of right are counted at first and tuples of left are checked with the counts
+
+type SetOperationExecutor struct {
+	context      *ExecutorContext
+	plan         *plans.SetOperationPlanNode
+	left         Executor
+	right        Executor
+	isLeftEnded  bool            // UNION only
+	rightCounts  map[string]int  // INTERSECT and EXCEPT only
+	emitted      map[string]bool // returned tuples. not used at INTERSECT ALL and EXCEPT ALL
+	isRightBuilt bool
+}
+
+func NewSetOperationExecutor(context *ExecutorContext, plan *plans.SetOperationPlanNode, left Executor, right Executor) Executor {
+	return &SetOperationExecutor{context, plan, left, right, false, nil, nil, false}
+}
+
+func (e *SetOperationExecutor) Init() {
+	e.left.Init()
+	e.right.Init()
+	e.isLeftEnded = false
+	e.rightCounts = make(map[string]int)
+	e.emitted = make(map[string]bool)
+	e.isRightBuilt = false
+}
+
+func (e *SetOperationExecutor) Next() (*tuple.Tuple, Done, error) {
+	if e.plan.GetSetOperationType() == plans.UNION_OPERATION {
+		return e.nextUnion()
+	}
+
+	if !e.isRightBuilt {
+		e.isRightBuilt = true
+		if err := e.countRightTuples(); err != nil {
+			return nil, true, err
+		}
+	}
+	isIntersect := e.plan.GetSetOperationType() == plans.INTERSECT_OPERATION
+	for {
+		tuple_, err := e.nextChildTuple(e.left)
+		if err != nil || tuple_ == nil {
+			return nil, true, err
+		}
+		key := string(tuple_.Data())
+		count := e.rightCounts[key]
+		if e.plan.IsAll() {
+			if count > 0 {
+				e.rightCounts[key] = count - 1
+			}
+			if (count > 0) == isIntersect {
+				return tuple_, false, nil
+			}
+			continue
+		}
+		if (count > 0) == isIntersect && !e.emitted[key] {
+			e.emitted[key] = true
+			return tuple_, false, nil
+		}
+	}
+}
+
+func (e *SetOperationExecutor) nextUnion() (*tuple.Tuple, Done, error) {
+	for {
+		child := e.left
+		if e.isLeftEnded {
+			child = e.right
+		}
+		tuple_, err := e.nextChildTuple(child)
+		if err != nil {
+			return nil, true, err
+		}
+		if tuple_ == nil {
+			if e.isLeftEnded {
+				return nil, true, nil
+			}
+			e.isLeftEnded = true
+			continue
+		}
+		key := string(tuple_.Data())
+		if !e.emitted[key] {
+			e.emitted[key] = true
+			return tuple_, false, nil
+		}
+	}
+}
+
+func (e *SetOperationExecutor) countRightTuples() error {
+	for {
+		tuple_, err := e.nextChildTuple(e.right)
+		if err != nil {
+			return err
+		}
+		if tuple_ == nil {
+			return nil
+		}
+		e.rightCounts[string(tuple_.Data())]++
+	}
+}
+
+// nextChildTuple returns next tuple of child which is converted to output schema. nil is returned at end
+func (e *SetOperationExecutor) nextChildTuple(child Executor) (*tuple.Tuple, error) {
+	for {
+		if err := e.context.checkCanceled(); err != nil {
+			return nil, err
+		}
+		tuple_, done, err := child.Next()
+		if err != nil || done {
+			return nil, err
+		}
+		if tuple_ != nil {
+			return convTupleToSchema(tuple_, child.GetOutputSchema(), e.plan.OutputSchema()), nil
+		}
+	}
+}
+
+func (e *SetOperationExecutor) GetOutputSchema() *schema.Schema {
+	return e.plan.OutputSchema()
+}
+
+// can not be used
+func (e *SetOperationExecutor) GetTableMetaData() *catalog.TableMetadata { return nil }
@@ -0,0 +1,86 @@
+package executors
+
+import (
+	"errors"
+
+	"github.com/ryogrid/SamehadaDB/common"
+	"github.com/ryogrid/SamehadaDB/container/hash"
+	"github.com/ryogrid/SamehadaDB/storage/buffer"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+// tmpTupleStore keeps tuples which are produced on query execution in TmpTuplePages.
+// it is accessed from current transaction only. so tuple locking is not needed
+type tmpTupleStore struct {
+	bpm       *buffer.BufferPoolManager
+	pageIDs   []types.PageID
+	tmpTuples []hash.TmpTuple
+	curPage   *hash.TmpTuplePage // last page which is pinned while tuples are appended
+}
+
+func newTmpTupleStore(bpm *buffer.BufferPoolManager) *tmpTupleStore {
+	return &tmpTupleStore{bpm, make([]types.PageID, 0), make([]hash.TmpTuple, 0), nil}
+}
+
+func (s *tmpTupleStore) Append(tuple_ *tuple.Tuple) error {
+	var tmpTuple hash.TmpTuple
+	if s.curPage == nil || !s.curPage.Insert(tuple_, &tmpTuple) {
+		if s.curPage != nil {
+			s.bpm.UnpinPage(s.curPage.GetPageId(), true)
+		}
+		s.curPage = hash.CastPageAsTmpTuplePage(s.bpm.NewPage())
+		if s.curPage == nil {
+			return errors.New("failed to allocate a page for temporary tuples.")
+		}
+		s.curPage.Init(s.curPage.GetPageId(), common.PageSize)
+		s.pageIDs = append(s.pageIDs, s.curPage.GetPageId())
+		if !s.curPage.Insert(tuple_, &tmpTuple) {
+			return errors.New("tuple is too large to be stored in a page for temporary tuples.")
+		}
+	}
+	s.tmpTuples = append(s.tmpTuples, tmpTuple)
+	return nil
+}
+
+func (s *tmpTupleStore) Len() int {
+	return len(s.tmpTuples)
+}
+
+func (s *tmpTupleStore) Get(idx int) (*tuple.Tuple, error) {
+	tmpTuple := s.tmpTuples[idx]
+	tmpPage, err := fetchTmpTuplePage(s.bpm, tmpTuple.GetPageId())
+	if err != nil {
+		return nil, err
+	}
+	ret := new(tuple.Tuple)
+	tmpPage.Get(ret, tmpTuple.GetOffset())
+	s.bpm.UnpinPage(tmpTuple.GetPageId(), false)
+	return ret, nil
+}
+
+// fetchTmpTuplePage fetches the page which stores temporary tuples.
+// *samehada_errors.IOError is returned when the page was evicted and read of it fails
+func fetchTmpTuplePage(bpm *buffer.BufferPoolManager, pageID types.PageID) (*hash.TmpTuplePage, error) {
+	pg, err := bpm.FetchPageWithError(pageID)
+	if pg == nil {
+		if err == nil {
+			err = errors.New("failed to fetch a page for temporary tuples.")
+		}
+		return nil, err
+	}
+	return hash.CastPageAsTmpTuplePage(pg), nil
+}
+
+// Clear removes all tuples and deallocates pages
+func (s *tmpTupleStore) Clear() {
+	if s.curPage != nil {
+		s.bpm.UnpinPage(s.curPage.GetPageId(), true)
+		s.curPage = nil
+	}
+	for _, pageID := range s.pageIDs {
+		s.bpm.DeletePage(pageID)
+	}
+	s.pageIDs = make([]types.PageID, 0)
+	s.tmpTuples = make([]hash.TmpTuple, 0)
+}
@@ -0,0 +1,73 @@
+package executors
+
+import (
+	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+// UnionAllExecutor returns all tuples of left child and then all tuples of right child
+
+type UnionAllExecutor struct {
+	context     *ExecutorContext
+	plan        *plans.UnionAllPlanNode
+	left        Executor
+	right       Executor
+	isLeftEnded bool
+}
+
+func NewUnionAllExecutor(context *ExecutorContext, plan *plans.UnionAllPlanNode, left Executor, right Executor) Executor {
+	return &UnionAllExecutor{context, plan, left, right, false}
+}
+
+func (e *UnionAllExecutor) Init() {
+	e.left.Init()
+	e.right.Init()
+	e.isLeftEnded = false
+}
+
+func (e *UnionAllExecutor) Next() (*tuple.Tuple, Done, error) {
+	for {
+		if err := e.context.checkCanceled(); err != nil {
+			return nil, true, err
+		}
+		child := e.left
+		if e.isLeftEnded {
+			child = e.right
+		}
+		tuple_, done, err := child.Next()
+		if err != nil {
+			return nil, true, err
+		}
+		if done {
+			if e.isLeftEnded {
+				return nil, true, nil
+			}
+			e.isLeftEnded = true
+			continue
+		}
+		if tuple_ == nil {
+			continue
+		}
+		return convTupleToSchema(tuple_, child.GetOutputSchema(), e.plan.OutputSchema()), false, nil
+	}
+}
+
+// convTupleToSchema rebuilds tuple_ with outSchema which has same column types with srcSchema.
+// tuples which have same values have same data after conversion
+func convTupleToSchema(tuple_ *tuple.Tuple, srcSchema *schema.Schema, outSchema *schema.Schema) *tuple.Tuple {
+	values := make([]types.Value, 0)
+	for colIdx := uint32(0); colIdx < outSchema.GetColumnCount(); colIdx++ {
+		values = append(values, tuple_.GetValue(srcSchema, colIdx))
+	}
+	return tuple.NewTupleFromSchema(values, outSchema)
+}
+
+func (e *UnionAllExecutor) GetOutputSchema() *schema.Schema {
+	return e.plan.OutputSchema()
+}
+
+// can not be used
+func (e *UnionAllExecutor) GetTableMetaData() *catalog.TableMetadata { return nil }
@@ -2,15 +2,14 @@ package executors
 
 import (
 	"errors"
-	"fmt"
-	"github.com/ryogrid/SamehadaDB/samehada/samehada_util"
-	"github.com/ryogrid/SamehadaDB/storage/page"
 
 	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
 	"github.com/ryogrid/SamehadaDB/storage/access"
 	"github.com/ryogrid/SamehadaDB/storage/table/schema"
 	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
 )
 
 /**
@@ -43,62 +42,23 @@ func (e *UpdateExecutor) Init() {
 func (e *UpdateExecutor) Next() (*tuple.Tuple, Done, error) {
 
 	// t is tuple before update
-	for t, done, err := e.child.Next(); !done; t, done, err = e.child.Next() {
+	// error is returned with done flag (ex: cancellation of execution)
+	for t, done, err := e.child.Next(); !done || err != nil; t, done, err = e.child.Next() {
+		if err != nil {
+			return nil, true, err
+		}
 		if t == nil {
 			err_ := errors.New("e.it.Next returned nil")
 			return nil, true, err_
 		}
+
+		values, err := e.evaluateUpdateValues(t)
 		if err != nil {
 			return nil, true, err
 		}
-
-		rid := t.GetRID()
-		values := e.plan.GetRawValues()
-		new_tuple := tuple.NewTupleFromSchema(values, e.child.GetTableMetaData().Schema())
-
-		var is_updated bool = false
-		var new_rid *page.RID = nil
-		if e.plan.GetUpdateColIdxs() == nil {
-			is_updated, new_rid = e.child.GetTableMetaData().Table().UpdateTuple(new_tuple, nil, nil, e.child.GetTableMetaData().OID(), *rid, e.txn)
-		} else {
-			is_updated, new_rid = e.child.GetTableMetaData().Table().UpdateTuple(new_tuple, e.plan.GetUpdateColIdxs(), e.child.GetTableMetaData().Schema(), e.child.GetTableMetaData().OID(), *rid, e.txn)
-		}
-
-		if !is_updated {
-			err := errors.New("tuple update failed. PageId:SlotNum = " + string(rid.GetPageId()) + ":" + fmt.Sprint(rid.GetSlotNum()))
-			return nil, false, err
-		}
-
-		colNum := e.child.GetTableMetaData().GetColumnNum()
-		updateIdxs := e.plan.GetUpdateColIdxs()
-		for ii := 0; ii < int(colNum); ii++ {
-			ret := e.child.GetTableMetaData().GetIndex(ii)
-			if ret == nil {
-				continue
-			} else {
-				index_ := ret
-				if updateIdxs == nil || samehada_util.IsContainList[int](updateIdxs, ii) {
-					if new_rid != nil {
-						// when tuple is moved page location on update, RID is changed to new value
-						index_.DeleteEntry(t, *rid, e.txn)
-						fmt.Println("UpdateExecuter: index entry insert with new_rid.")
-						index_.InsertEntry(new_tuple, *new_rid, e.txn)
-					} else {
-						index_.DeleteEntry(t, *rid, e.txn)
-						index_.InsertEntry(new_tuple, *rid, e.txn)
-					}
-				} else {
-					if new_rid != nil {
-						// when tuple is moved page location on update, RID is changed to new value
-						fmt.Println("UpdateExecuter: index entry insert with new_rid. value update of index entry occurs.")
-						index_.InsertEntry(t, *new_rid, e.txn)
-					} else {
-						// update is not needed
-
-						//index_.InsertEntry(t, *rid, e.txn)
-					}
-				}
-			}
+		new_tuple, err := updateTupleAndIndexEntries(e.context, e.child.GetTableMetaData(), t, values, e.plan.GetUpdateColIdxs())
+		if err != nil {
+			return nil, true, err
 		}
 
 		return new_tuple, false, nil
@@ -107,6 +67,29 @@ func (e *UpdateExecutor) Next() (*tuple.Tuple, Done, error) {
 	return nil, true, nil
 }
 
+// evaluateUpdateValues returns values passed to updateTupleAndIndexEntries.
+// columns which are updated with expressions get values evaluated with t
+func (e *UpdateExecutor) evaluateUpdateValues(t *tuple.Tuple) ([]types.Value, error) {
+	exprs := e.plan.GetUpdateExprs()
+	if exprs == nil {
+		return e.plan.GetRawValues(), nil
+	}
+	schema_ := e.child.GetTableMetaData().Schema()
+	values := make([]types.Value, len(e.plan.GetRawValues()))
+	copy(values, e.plan.GetRawValues())
+	for ii, colIdx := range e.plan.GetUpdateColIdxs() {
+		if exprs[ii] == nil {
+			continue
+		}
+		val, err := expression.AdjustValueForColumn(exprs[ii].Evaluate(t, schema_), schema_.GetColumn(uint32(colIdx)))
+		if err != nil {
+			return nil, err
+		}
+		values[colIdx] = val
+	}
+	return values, nil
+}
+
 //// select evaluates an expression on the tuple
 //func (e *UpdateExecutor) selects(tuple *tuple.Tuple, predicate expression.Expression) bool {
 //	return predicate == nil || predicate.Evaluate(tuple, e.child.GetTableMetaData().Schema()).ToBoolean()
@@ -0,0 +1,321 @@
+package executors
+
+import (
+	"errors"
+	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+	"sort"
+)
+
+// WindowExecutor evaluates window functions. all tuples of child are read at Init
+// and for each window function, they are sorted by partition columns and ORDER BY columns.
+// values of the function are calculated partition by partition
+
+type WindowExecutor struct {
+	context *ExecutorContext
+	plan    *plans.WindowPlanNode
+	child   Executor
+	rows    [][]types.Value // values of child tuple followed by values of window functions
+	order   []int           // order of rows to be returned
+	curIdx  int
+	err     error // error occured at Init
+}
+
+func NewWindowExecutor(context *ExecutorContext, plan *plans.WindowPlanNode, child Executor) Executor {
+	return &WindowExecutor{context, plan, child, nil, nil, 0, nil}
+}
+
+func (e *WindowExecutor) Init() {
+	e.child.Init()
+	e.rows = make([][]types.Value, 0)
+	e.order = nil
+	e.curIdx = 0
+	e.err = nil
+
+	childSchema := e.child.GetOutputSchema()
+	colNum := int(childSchema.GetColumnCount())
+	for {
+		if err := e.context.checkCanceled(); err != nil {
+			e.err = err
+			return
+		}
+		tuple_, done, err := e.child.Next()
+		if err != nil {
+			e.err = err
+			return
+		}
+		if done {
+			break
+		}
+		if tuple_ == nil {
+			continue
+		}
+		row := make([]types.Value, 0, colNum+len(e.plan.GetWindowFuncs()))
+		for ii := 0; ii < colNum; ii++ {
+			row = append(row, tuple_.GetValue(childSchema, uint32(ii)))
+		}
+		e.rows = append(e.rows, row)
+	}
+
+	for ii, windowFunc := range e.plan.GetWindowFuncs() {
+		order := e.sortRows(windowFunc)
+		// sorting many rows takes long time
+		if err := e.context.checkCanceled(); err != nil {
+			e.err = err
+			return
+		}
+		if ii == 0 {
+			e.order = order
+		}
+		if err := e.evalWindowFunc(windowFunc, order); err != nil {
+			e.err = err
+			return
+		}
+	}
+	if e.order == nil {
+		e.order = make([]int, 0)
+	}
+}
+
+func (e *WindowExecutor) Next() (*tuple.Tuple, Done, error) {
+	if e.err != nil {
+		return nil, true, e.err
+	}
+	if err := e.context.checkCanceled(); err != nil {
+		return nil, true, err
+	}
+	if e.curIdx >= len(e.order) {
+		return nil, true, nil
+	}
+	row := e.rows[e.order[e.curIdx]]
+	e.curIdx++
+	outSchema := e.plan.OutputSchema()
+	for ii := range row {
+		// NULL should have type of the column to be serialized
+		if colType := outSchema.GetColumn(uint32(ii)).GetType(); row[ii].IsNull() && row[ii].ValueType() != colType {
+			row[ii] = types.NewNullOfType(colType)
+		}
+	}
+	return tuple.NewTupleFromSchema(row, outSchema), false, nil
+}
+
+// sortRows returns indexes of rows which are sorted by partition columns and ORDER BY columns of windowFunc
+func (e *WindowExecutor) sortRows(windowFunc *plans.WindowFunc) []int {
+	order := make([]int, len(e.rows))
+	for ii := range order {
+		order[ii] = ii
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		rowI := e.rows[order[i]]
+		rowJ := e.rows[order[j]]
+		for _, colIdx := range windowFunc.PartitionColIdxs {
+			if !rowI[colIdx].CompareEquals(rowJ[colIdx]) {
+				return isLessValue(rowI[colIdx], rowJ[colIdx])
+			}
+		}
+		for ii, colIdx := range windowFunc.OrderColIdxs {
+			if !rowI[colIdx].CompareEquals(rowJ[colIdx]) {
+				if windowFunc.OrderTypes[ii] == plans.DESC {
+					return isLessValue(rowJ[colIdx], rowI[colIdx])
+				}
+				return isLessValue(rowI[colIdx], rowJ[colIdx])
+			}
+		}
+		return false
+	})
+	return order
+}
+
+// NULL is treated as smaller than other values
+func isLessValue(left types.Value, right types.Value) bool {
+	if left.IsNull() || right.IsNull() {
+		return left.IsNull() && !right.IsNull()
+	}
+	return left.CompareLessThan(right)
+}
+
+func isSameColValues(left []types.Value, right []types.Value, colIdxs []int) bool {
+	for _, colIdx := range colIdxs {
+		if !left[colIdx].CompareEquals(right[colIdx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// evalWindowFunc appends value of windowFunc to each row. order is sorted indexes of rows
+func (e *WindowExecutor) evalWindowFunc(windowFunc *plans.WindowFunc, order []int) error {
+	for partStart := 0; partStart < len(order); {
+		if err := e.context.checkCanceled(); err != nil {
+			return err
+		}
+		partEnd := partStart + 1
+		for partEnd < len(order) && isSameColValues(e.rows[order[partStart]], e.rows[order[partEnd]], windowFunc.PartitionColIdxs) {
+			partEnd++
+		}
+		partition := order[partStart:partEnd]
+
+		// peerStarts[i] and peerEnds[i] are range of rows which are peers of i-th row in the partition
+		peerStarts := make([]int, len(partition))
+		peerEnds := make([]int, len(partition))
+		denseRanks := make([]int, len(partition))
+		for peerStart, denseRank := 0, 1; peerStart < len(partition); denseRank++ {
+			peerEnd := peerStart + 1
+			for peerEnd < len(partition) && isSameColValues(e.rows[partition[peerStart]], e.rows[partition[peerEnd]], windowFunc.OrderColIdxs) {
+				peerEnd++
+			}
+			for ii := peerStart; ii < peerEnd; ii++ {
+				peerStarts[ii] = peerStart
+				peerEnds[ii] = peerEnd
+				denseRanks[ii] = denseRank
+			}
+			peerStart = peerEnd
+		}
+
+		for ii, rowIdx := range partition {
+			var val types.Value
+			switch windowFunc.FuncType {
+			case plans.ROW_NUMBER_WINDOW_FUNC:
+				val = types.NewInteger(int32(ii + 1))
+			case plans.RANK_WINDOW_FUNC:
+				val = types.NewInteger(int32(peerStarts[ii] + 1))
+			case plans.DENSE_RANK_WINDOW_FUNC:
+				val = types.NewInteger(int32(denseRanks[ii]))
+			case plans.LAG_WINDOW_FUNC, plans.LEAD_WINDOW_FUNC:
+				tgtIdx := ii - windowFunc.Offset
+				if windowFunc.FuncType == plans.LEAD_WINDOW_FUNC {
+					tgtIdx = ii + windowFunc.Offset
+				}
+				if 0 <= tgtIdx && tgtIdx < len(partition) {
+					val = e.rows[partition[tgtIdx]][windowFunc.ArgColIdx]
+				} else if windowFunc.DefaultValue != nil {
+					val = *windowFunc.DefaultValue
+				} else {
+					val = types.NewNull()
+				}
+			default:
+				frameStart, frameEnd := getWindowFrame(windowFunc, ii, len(partition), peerStarts[ii], peerEnds[ii])
+				var err error
+				if val, err = e.aggregateFrame(windowFunc, partition, frameStart, frameEnd); err != nil {
+					return err
+				}
+			}
+			e.rows[rowIdx] = append(e.rows[rowIdx], val)
+		}
+		partStart = partEnd
+	}
+	return nil
+}
+
+// getWindowFrame returns range of frame of idx-th row in a partition which has partSize rows
+func getWindowFrame(windowFunc *plans.WindowFunc, idx int, partSize int, peerStart int, peerEnd int) (int, int) {
+	frame := windowFunc.Frame
+	if frame == nil {
+		if len(windowFunc.OrderColIdxs) == 0 {
+			return 0, partSize
+		}
+		return 0, peerEnd
+	}
+
+	boundToIdx := func(bound plans.WindowFrameBound, isStart bool) int {
+		switch bound.Type {
+		case plans.UNBOUNDED_PRECEDING:
+			return 0
+		case plans.PRECEDING:
+			return idx - bound.Offset
+		case plans.FOLLOWING:
+			return idx + bound.Offset
+		case plans.UNBOUNDED_FOLLOWING:
+			return partSize - 1
+		default: // CURRENT_ROW
+			if frame.IsRows {
+				return idx
+			} else if isStart {
+				return peerStart
+			}
+			return peerEnd - 1
+		}
+	}
+	start := boundToIdx(frame.Start, true)
+	end := boundToIdx(frame.End, false) + 1
+	if start < 0 {
+		start = 0
+	}
+	if end > partSize {
+		end = partSize
+	}
+	if start > end {
+		start = end
+	}
+	return start, end
+}
+
+// aggregateFrame calculates FIRST_VALUE, SUM, COUNT or AVG over partition[frameStart:frameEnd]
+func (e *WindowExecutor) aggregateFrame(windowFunc *plans.WindowFunc, partition []int, frameStart int, frameEnd int) (types.Value, error) {
+	if windowFunc.FuncType == plans.FIRST_VALUE_WINDOW_FUNC {
+		if frameStart >= frameEnd {
+			return types.NewNull(), nil
+		}
+		return e.rows[partition[frameStart]][windowFunc.ArgColIdx], nil
+	}
+
+	count := 0
+	var sum *types.Value = nil
+	for _, rowIdx := range partition[frameStart:frameEnd] {
+		if windowFunc.ArgColIdx == -1 {
+			// COUNT(*)
+			count++
+			continue
+		}
+		val := e.rows[rowIdx][windowFunc.ArgColIdx]
+		if val.IsNull() {
+			continue
+		}
+		count++
+		if windowFunc.FuncType == plans.COUNT_WINDOW_FUNC {
+			continue
+		}
+		if !val.ValueType().IsNumeric() {
+			return types.Value{}, errors.New("SUM and AVG window functions can be applied to numeric column only.")
+		}
+		if sum == nil {
+			sum = &val
+		} else {
+			sum = sum.Add(&val)
+		}
+	}
+
+	switch windowFunc.FuncType {
+	case plans.COUNT_WINDOW_FUNC:
+		return types.NewInteger(int32(count)), nil
+	case plans.SUM_WINDOW_FUNC:
+		if sum == nil {
+			return types.NewNull(), nil
+		}
+		return *sum, nil
+	default: // AVG
+		if sum == nil {
+			return types.NewNull(), nil
+		}
+		if sum.ValueType().IsIntegerFamily() {
+			return types.NewFloat(float32(sum.ToInt64()) / float32(count)), nil
+		}
+		if sum.ValueType() == types.Decimal {
+			avg, ok := types.DivDecimal(*sum, types.NewInteger(int32(count)))
+			if !ok {
+				return types.NewNull(), nil
+			}
+			return avg, nil
+		}
+		return types.NewFloat(sum.ToFloat() / float32(count)), nil
+	}
+}
+
+func (e *WindowExecutor) GetOutputSchema() *schema.Schema {
+	return e.plan.OutputSchema()
+}
+
+func (e *WindowExecutor) GetTableMetaData() *catalog.TableMetadata { return e.child.GetTableMetaData() }
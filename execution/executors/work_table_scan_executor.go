@@ -0,0 +1,44 @@
+package executors
+
+import (
+	"github.com/ryogrid/SamehadaDB/catalog"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+)
+
+// scan of working table which is set by RecursiveCTEExecutor of same CTE
+
+type WorkTableScanExecutor struct {
+	context *ExecutorContext
+	plan    *plans.WorkTableScanPlanNode
+	curIdx  int
+}
+
+func NewWorkTableScanExecutor(context *ExecutorContext, plan *plans.WorkTableScanPlanNode) Executor {
+	return &WorkTableScanExecutor{context, plan, 0}
+}
+
+func (e *WorkTableScanExecutor) Init() {
+	e.curIdx = 0
+}
+
+func (e *WorkTableScanExecutor) Next() (*tuple.Tuple, Done, error) {
+	workTable := e.context.workTables[e.plan.GetCTEName()]
+	if workTable == nil || e.curIdx >= workTable.Len() {
+		return nil, true, nil
+	}
+	ret, err := workTable.Get(e.curIdx)
+	if err != nil {
+		return nil, true, err
+	}
+	e.curIdx++
+	return ret, false, nil
+}
+
+func (e *WorkTableScanExecutor) GetOutputSchema() *schema.Schema {
+	return e.plan.OutputSchema()
+}
+
+// can not be used
+func (e *WorkTableScanExecutor) GetTableMetaData() *catalog.TableMetadata { return nil }
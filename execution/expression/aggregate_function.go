@@ -0,0 +1,108 @@
+package expression
+
+import (
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+	"strings"
+	"sync"
+)
+
+// AggregateFunction is the state of a user defined aggregate function for a group.
+// a new object is created for each group and each input tuple. the object created for
+// a tuple is passed to Step with values of arguments and then merged to the object of the group
+type AggregateFunction interface {
+	// Init resets the state. it is called before Step and Merge
+	Init()
+	// Step adds values of arguments of a tuple. NULL arguments are also passed
+	Step(args ...types.Value)
+	// Merge adds the state of other to the receiver. other is created by same UserAggregateFunction
+	Merge(other AggregateFunction)
+	// Final returns result of the aggregation
+	Final() types.Value
+}
+
+// UserAggregateFunction is an aggregate function which can be called in SELECT fields and HAVING clause
+// of aggregation query. it is looked up from the registry with its name case-insensitively
+type UserAggregateFunction struct {
+	Name string
+	// ReturnType checks types of arguments and returns type of the result. it is called at planning.
+	// types.Invalid is passed for NULL literal
+	ReturnType func(argTypes []types.TypeID) (types.TypeID, error)
+	// New returns the state object
+	New func() AggregateFunction
+}
+
+var aggregateFunctions = make(map[string]*UserAggregateFunction)
+var aggregateFunctionsMutex sync.RWMutex
+
+// RegisterAggregateFunction adds f to the registry. function which has same name is replaced
+func RegisterAggregateFunction(f *UserAggregateFunction) {
+	aggregateFunctionsMutex.Lock()
+	defer aggregateFunctionsMutex.Unlock()
+	aggregateFunctions[strings.ToLower(f.Name)] = f
+}
+
+// GetAggregateFunction returns nil when function named name is not registered
+func GetAggregateFunction(name string) *UserAggregateFunction {
+	aggregateFunctionsMutex.RLock()
+	defer aggregateFunctionsMutex.RUnlock()
+	return aggregateFunctions[strings.ToLower(name)]
+}
+
+/**
+ * UserAggregateCall is input of a user defined aggregate function in AggregationPlanNode.
+ * it is not evaluated directly. AggregationExecutor evaluates the arguments and passes them to the state
+ */
+type UserAggregateCall struct {
+	*AbstractExpression
+	function *UserAggregateFunction
+	args     []Expression
+}
+
+// retType should be the type returned from ReturnType of function
+func NewUserAggregateCall(function *UserAggregateFunction, args []Expression, retType types.TypeID) Expression {
+	return &UserAggregateCall{&AbstractExpression{[2]Expression{}, retType}, function, args}
+}
+
+// NewState returns initialized state of the function
+func (c *UserAggregateCall) NewState() AggregateFunction {
+	state := c.function.New()
+	state.Init()
+	return state
+}
+
+// EvaluateArgs returns values of arguments for tuple_
+func (c *UserAggregateCall) EvaluateArgs(tuple_ *tuple.Tuple, schema_ *schema.Schema) []types.Value {
+	args := make([]types.Value, 0, len(c.args))
+	for _, arg := range c.args {
+		args = append(args, arg.Evaluate(tuple_, schema_))
+	}
+	return args
+}
+
+func (c *UserAggregateCall) Evaluate(tuple_ *tuple.Tuple, schema_ *schema.Schema) types.Value {
+	panic("user defined aggregate function should be evaluated by AggregationExecutor.")
+}
+
+func (c *UserAggregateCall) EvaluateJoin(left_tuple *tuple.Tuple, left_schema *schema.Schema, right_tuple *tuple.Tuple, right_schema *schema.Schema) types.Value {
+	panic("user defined aggregate function should be evaluated by AggregationExecutor.")
+}
+
+func (c *UserAggregateCall) EvaluateAggregate(group_bys []*types.Value, aggregates []*types.Value) types.Value {
+	panic("user defined aggregate function should be evaluated by AggregationExecutor.")
+}
+
+// returns nil when child_idx is out of range
+func (c *UserAggregateCall) GetChildAt(child_idx uint32) Expression {
+	if int(child_idx) >= len(c.args) {
+		return nil
+	}
+	return c.args[child_idx]
+}
+
+func (c *UserAggregateCall) GetChildren() []Expression {
+	return c.args
+}
+
+func (c *UserAggregateCall) GetReturnType() types.TypeID { return c.ret_type }
@@ -0,0 +1,134 @@
+package expression
+
+import (
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+type ArithmeticOpType int
+
+/** ArithmeticOpType represents the type of arithmetic operation that we want to perform. */
+const (
+	ADD ArithmeticOpType = iota
+	SUB
+	MUL
+	DIV
+)
+
+/**
+ * ArithmeticOp represents arithmetic operation of two numeric expressions.
+ * when one side is Float, value of the other side is converted to Float.
+ * when one side is Decimal, integer value of the other side is calculated as Decimal exactly.
+ * integer values are calculated as int64 and truncated to width of the return type.
+ * result is NULL when either side is NULL, divisor is zero or Decimal result overflows.
+ */
+type ArithmeticOp struct {
+	*AbstractExpression
+	arithmeticOpType ArithmeticOpType
+}
+
+// retType should be Float if either side returns Float, Decimal if either side returns Decimal.
+// otherwise wider integer type of both sides
+func NewArithmeticOp(left Expression, right Expression, arithmeticOpType ArithmeticOpType, retType types.TypeID) Expression {
+	return &ArithmeticOp{&AbstractExpression{[2]Expression{left, right}, retType}, arithmeticOpType}
+}
+
+func (c *ArithmeticOp) Evaluate(tuple_ *tuple.Tuple, schema_ *schema.Schema) types.Value {
+	lhs := c.children[0].Evaluate(tuple_, schema_)
+	rhs := c.children[1].Evaluate(tuple_, schema_)
+	return c.performArithmeticOp(lhs, rhs)
+}
+
+func toFloat(val types.Value) float32 {
+	switch val.ValueType() {
+	case types.Float:
+		return val.ToFloat()
+	case types.Decimal:
+		f, _ := val.ToBigRat().Float32()
+		return f
+	}
+	return float32(val.ToInt64())
+}
+
+func (c *ArithmeticOp) performArithmeticOp(lhs types.Value, rhs types.Value) types.Value {
+	if lhs.IsNull() || rhs.IsNull() {
+		return types.NewNullOfType(c.ret_type)
+	}
+
+	if c.ret_type == types.Decimal {
+		var ret types.Value
+		var ok bool
+		switch c.arithmeticOpType {
+		case ADD:
+			ret, ok = types.AddDecimal(lhs, rhs)
+		case SUB:
+			ret, ok = types.SubDecimal(lhs, rhs)
+		case MUL:
+			ret, ok = types.MulDecimal(lhs, rhs)
+		case DIV:
+			ret, ok = types.DivDecimal(lhs, rhs)
+		default:
+			panic("illegal arithmeticOpType is passed!")
+		}
+		if !ok {
+			return types.NewNullOfType(types.Decimal)
+		}
+		return ret
+	} else if c.ret_type == types.Float {
+		l := toFloat(lhs)
+		r := toFloat(rhs)
+		switch c.arithmeticOpType {
+		case ADD:
+			return types.NewFloat(l + r)
+		case SUB:
+			return types.NewFloat(l - r)
+		case MUL:
+			return types.NewFloat(l * r)
+		case DIV:
+			if r == 0 {
+				return types.NewNullOfType(types.Float)
+			}
+			return types.NewFloat(l / r)
+		}
+	} else {
+		l := lhs.ToInt64()
+		r := rhs.ToInt64()
+		switch c.arithmeticOpType {
+		case ADD:
+			return types.NewIntegerOfType(c.ret_type, l+r)
+		case SUB:
+			return types.NewIntegerOfType(c.ret_type, l-r)
+		case MUL:
+			return types.NewIntegerOfType(c.ret_type, l*r)
+		case DIV:
+			if r == 0 {
+				return types.NewNullOfType(c.ret_type)
+			}
+			return types.NewIntegerOfType(c.ret_type, l/r)
+		}
+	}
+	panic("illegal arithmeticOpType is passed!")
+}
+
+func (c *ArithmeticOp) GetArithmeticOpType() ArithmeticOpType {
+	return c.arithmeticOpType
+}
+
+func (c *ArithmeticOp) EvaluateJoin(left_tuple *tuple.Tuple, left_schema *schema.Schema, right_tuple *tuple.Tuple, right_schema *schema.Schema) types.Value {
+	lhs := c.GetChildAt(0).EvaluateJoin(left_tuple, left_schema, right_tuple, right_schema)
+	rhs := c.GetChildAt(1).EvaluateJoin(left_tuple, left_schema, right_tuple, right_schema)
+	return c.performArithmeticOp(lhs, rhs)
+}
+
+func (c *ArithmeticOp) EvaluateAggregate(group_bys []*types.Value, aggregates []*types.Value) types.Value {
+	lhs := c.GetChildAt(0).EvaluateAggregate(group_bys, aggregates)
+	rhs := c.GetChildAt(1).EvaluateAggregate(group_bys, aggregates)
+	return c.performArithmeticOp(lhs, rhs)
+}
+
+func (c *ArithmeticOp) GetChildAt(child_idx uint32) Expression {
+	return c.children[child_idx]
+}
+
+func (c *ArithmeticOp) GetReturnType() types.TypeID { return c.ret_type }
@@ -0,0 +1,315 @@
+package expression
+
+import (
+	"errors"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/types"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// scalar functions which are registered at startup
+
+func builtinScalarFunctions() []*ScalarFunction {
+	return []*ScalarFunction{
+		// NULL handling
+		{"coalesce", commonTypeOfArgs("coalesce", 1, -1), evalCoalesce},
+		{"nullif", commonTypeOfArgs("nullif", 2, 2), evalNullIf},
+		// string functions
+		{"upper", fixedArgTypes("upper", types.Varchar, types.Varchar), mapVarchar(strings.ToUpper)},
+		{"lower", fixedArgTypes("lower", types.Varchar, types.Varchar), mapVarchar(strings.ToLower)},
+		{"length", fixedArgTypes("length", types.Integer, types.Varchar), evalLength},
+		{"char_length", fixedArgTypes("char_length", types.Integer, types.Varchar), evalLength},
+		{"substr", substrReturnType("substr"), evalSubstr},
+		{"substring", substrReturnType("substring"), evalSubstr},
+		{"concat", concatReturnType, evalConcat},
+		{"trim", fixedArgTypes("trim", types.Varchar, types.Varchar), mapVarchar(strings.TrimSpace)},
+		{"ltrim", fixedArgTypes("ltrim", types.Varchar, types.Varchar), mapVarchar(func(s string) string { return strings.TrimLeft(s, " \t\r\n") })},
+		{"rtrim", fixedArgTypes("rtrim", types.Varchar, types.Varchar), mapVarchar(func(s string) string { return strings.TrimRight(s, " \t\r\n") })},
+		{"replace", fixedArgTypes("replace", types.Varchar, types.Varchar, types.Varchar, types.Varchar), evalReplace},
+		// numeric functions
+		{"abs", numericArgType("abs", false), evalAbs},
+		{"round", numericArgType("round", true), evalRound},
+		{"floor", numericArgType("floor", false), mapFloat(math.Floor, types.FloorDecimal)},
+		{"ceil", numericArgType("ceil", false), mapFloat(math.Ceil, types.CeilDecimal)},
+		{"ceiling", numericArgType("ceiling", false), mapFloat(math.Ceil, types.CeilDecimal)},
+		{"mod", commonTypeOfArgs("mod", 2, 2), evalMod},
+		// date/time functions
+		{"now", fixedArgTypes("now", types.Timestamp), evalNow},
+		{"current_timestamp", fixedArgTypes("current_timestamp", types.Timestamp), evalNow},
+		{"localtimestamp", fixedArgTypes("localtimestamp", types.Timestamp), evalNow},
+		{"current_date", fixedArgTypes("current_date", types.Date), evalCurrentDate},
+		{"curdate", fixedArgTypes("curdate", types.Date), evalCurrentDate},
+		{"date_trunc", dateTruncReturnType, evalDateTrunc},
+		{"extract", extractReturnType, evalExtract},
+		{"date_add", dateAddReturnType("date_add"), evalDateAdd(1)},
+		{"date_sub", dateAddReturnType("date_sub"), evalDateAdd(-1)},
+		{"datediff", datediffReturnType, evalDatediff},
+		{"timestampdiff", timestampdiffReturnType, evalTimestampdiff},
+		// JSON functions
+		{"json_extract", fixedArgTypes("json_extract", types.JSON, types.Varchar, types.Varchar), evalJSONExtract},
+		{"json_unquote", fixedArgTypes("json_unquote", types.Varchar, types.Varchar), evalJSONUnquote},
+		{"json_valid", fixedArgTypes("json_valid", types.Boolean, types.Varchar), evalJSONValid},
+	}
+}
+
+func isNumeric(typeId types.TypeID) bool {
+	return typeId.IsNumeric()
+}
+
+// toReturnType converts integer value to Float, Decimal or wider integer type when retType is so
+func toReturnType(val types.Value, retType types.TypeID) types.Value {
+	if isNumeric(retType) && isNumeric(val.ValueType()) && val.ValueType() != retType && !val.IsNull() {
+		ret, _ := CastValue(val, retType)
+		return ret
+	}
+	return val
+}
+
+func hasNullArg(args []types.Value) bool {
+	for _, arg := range args {
+		if arg.IsNull() {
+			return true
+		}
+	}
+	return false
+}
+
+// fixedArgTypes returns ReturnType of function which takes arguments of argTypes and returns retType
+func fixedArgTypes(name string, retType types.TypeID, argTypes ...types.TypeID) func([]types.TypeID) (types.TypeID, error) {
+	return func(passed []types.TypeID) (types.TypeID, error) {
+		if len(passed) != len(argTypes) {
+			return types.Invalid, errors.New("wrong number of arguments are passed to " + name + ".")
+		}
+		for ii, argType := range passed {
+			// TEXT, BLOB and JSON can be passed as Varchar
+			if argType != argTypes[ii] && argType != types.Invalid && !(argTypes[ii] == types.Varchar && argType.IsString()) {
+				return types.Invalid, samehada_errors.NewTypeMismatchError("argument of " + name + " must be " + argTypes[ii].String() + ".")
+			}
+		}
+		return retType, nil
+	}
+}
+
+// commonTypeOfArgs returns ReturnType of function whose arguments have same type. numeric values are mixed as Float
+// if either one is Float, as Decimal if either one is Decimal and as wider integer type otherwise.
+// maxArgs is -1 when the number of arguments is not limited
+func commonTypeOfArgs(name string, minArgs int, maxArgs int) func([]types.TypeID) (types.TypeID, error) {
+	return func(passed []types.TypeID) (types.TypeID, error) {
+		if len(passed) < minArgs || (maxArgs != -1 && len(passed) > maxArgs) {
+			return types.Invalid, errors.New("wrong number of arguments are passed to " + name + ".")
+		}
+		retType := types.Invalid
+		for _, argType := range passed {
+			switch {
+			case argType == types.Invalid || argType == retType:
+			case retType == types.Invalid:
+				retType = argType
+			case isNumeric(argType) && isNumeric(retType):
+				if argType == types.Float || retType == types.Float {
+					retType = types.Float
+				} else if argType == types.Decimal || retType == types.Decimal {
+					retType = types.Decimal
+				} else {
+					retType = types.WiderIntegerType(argType, retType)
+				}
+			default:
+				return types.Invalid, samehada_errors.NewTypeMismatchError("arguments of " + name + " must have same type.")
+			}
+		}
+		if retType == types.Invalid {
+			return types.Invalid, errors.New("type of arguments of " + name + " can not be decided.")
+		}
+		if name == "mod" && !isNumeric(retType) {
+			return types.Invalid, samehada_errors.NewTypeMismatchError("arguments of mod must be numeric.")
+		}
+		return retType, nil
+	}
+}
+
+// numericArgType returns ReturnType of function which takes a numeric argument and returns same type.
+// second Integer argument is allowed when hasOptionalInt is true
+func numericArgType(name string, hasOptionalInt bool) func([]types.TypeID) (types.TypeID, error) {
+	return func(passed []types.TypeID) (types.TypeID, error) {
+		if len(passed) != 1 && !(hasOptionalInt && len(passed) == 2) {
+			return types.Invalid, errors.New("wrong number of arguments are passed to " + name + ".")
+		}
+		if !isNumeric(passed[0]) || (len(passed) == 2 && passed[1] != types.Integer) {
+			return types.Invalid, samehada_errors.NewTypeMismatchError("arguments of " + name + " must be numeric.")
+		}
+		return passed[0], nil
+	}
+}
+
+func substrReturnType(name string) func([]types.TypeID) (types.TypeID, error) {
+	withLen := fixedArgTypes(name, types.Varchar, types.Varchar, types.Integer, types.Integer)
+	withoutLen := fixedArgTypes(name, types.Varchar, types.Varchar, types.Integer)
+	return func(passed []types.TypeID) (types.TypeID, error) {
+		if len(passed) == 2 {
+			return withoutLen(passed)
+		}
+		return withLen(passed)
+	}
+}
+
+// arguments of CONCAT are converted to Varchar
+func concatReturnType(passed []types.TypeID) (types.TypeID, error) {
+	if len(passed) == 0 {
+		return types.Invalid, errors.New("wrong number of arguments are passed to concat.")
+	}
+	return types.Varchar, nil
+}
+
+func mapVarchar(f func(string) string) func([]types.Value, types.TypeID) types.Value {
+	return func(args []types.Value, retType types.TypeID) types.Value {
+		if hasNullArg(args) {
+			return types.NewNullOfType(retType)
+		}
+		return types.NewVarchar(f(args[0].ToVarchar()))
+	}
+}
+
+// integer argument is returned as is. Decimal argument is calculated with decimalFunc
+func mapFloat(f func(float64) float64, decimalFunc func(types.Value) types.Value) func([]types.Value, types.TypeID) types.Value {
+	return func(args []types.Value, retType types.TypeID) types.Value {
+		if hasNullArg(args) || retType.IsIntegerFamily() {
+			return args[0]
+		}
+		if retType == types.Decimal {
+			return decimalFunc(args[0])
+		}
+		return types.NewFloat(float32(f(float64(args[0].ToFloat()))))
+	}
+}
+
+func evalCoalesce(args []types.Value, retType types.TypeID) types.Value {
+	for _, arg := range args {
+		if !arg.IsNull() {
+			return toReturnType(arg, retType)
+		}
+	}
+	return types.NewNullOfType(retType)
+}
+
+func evalNullIf(args []types.Value, retType types.TypeID) types.Value {
+	left := toReturnType(args[0], retType)
+	right := toReturnType(args[1], retType)
+	if !left.IsNull() && !right.IsNull() && left.CompareEquals(right) {
+		return types.NewNullOfType(retType)
+	}
+	return left
+}
+
+func evalLength(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	return types.NewInteger(int32(utf8.RuneCountInString(args[0].ToVarchar())))
+}
+
+// SUBSTR(str, pos [, len]). pos starts from 1 and characters before 1 are counted in len
+func evalSubstr(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	runes := []rune(args[0].ToVarchar())
+	start := int(args[1].ToInteger())
+	end := len(runes) + 1
+	if len(args) == 3 {
+		length := int(args[2].ToInteger())
+		if length < 0 {
+			return types.NewNullOfType(retType)
+		}
+		if start+length < end {
+			end = start + length
+		}
+	}
+	if start < 1 {
+		start = 1
+	}
+	if start >= end {
+		return types.NewVarchar("")
+	}
+	return types.NewVarchar(string(runes[start-1 : end-1]))
+}
+
+func evalConcat(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	var sb strings.Builder
+	for _, arg := range args {
+		str, _ := CastValue(arg, types.Varchar)
+		sb.WriteString(str.ToVarchar())
+	}
+	return types.NewVarchar(sb.String())
+}
+
+func evalReplace(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	return types.NewVarchar(strings.ReplaceAll(args[0].ToVarchar(), args[1].ToVarchar(), args[2].ToVarchar()))
+}
+
+func evalAbs(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	if retType.IsIntegerFamily() {
+		if args[0].ToInt64() < 0 {
+			return types.NewIntegerOfType(retType, -args[0].ToInt64())
+		}
+		return args[0]
+	}
+	if retType == types.Decimal {
+		return types.AbsDecimal(args[0])
+	}
+	return types.NewFloat(float32(math.Abs(float64(args[0].ToFloat()))))
+}
+
+// ROUND(x [, digits]). halves are rounded away from zero
+func evalRound(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	digits := 0
+	if len(args) == 2 {
+		digits = int(args[1].ToInteger())
+	}
+	if retType == types.Decimal {
+		return types.RoundDecimal(args[0], int32(digits))
+	}
+	scale := math.Pow(10, float64(digits))
+	if retType.IsIntegerFamily() {
+		return types.NewIntegerOfType(retType, int64(math.Round(float64(args[0].ToInt64())*scale)/scale))
+	}
+	return types.NewFloat(float32(math.Round(float64(args[0].ToFloat())*scale) / scale))
+}
+
+// MOD(x, y) has sign of x. NULL is returned when y is zero
+func evalMod(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	if retType.IsIntegerFamily() {
+		if args[1].ToInt64() == 0 {
+			return types.NewNullOfType(retType)
+		}
+		return types.NewIntegerOfType(retType, args[0].ToInt64()%args[1].ToInt64())
+	}
+	if retType == types.Decimal {
+		ret, ok := types.ModDecimal(args[0], args[1])
+		if !ok {
+			return types.NewNullOfType(retType)
+		}
+		return ret
+	}
+	left := toReturnType(args[0], retType).ToFloat()
+	right := toReturnType(args[1], retType).ToFloat()
+	if right == 0 {
+		return types.NewNullOfType(retType)
+	}
+	return types.NewFloat(float32(math.Mod(float64(left), float64(right))))
+}
@@ -0,0 +1,90 @@
+package expression
+
+import (
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+/**
+ * CaseWhen returns result of first condition which is evaluated as true.
+ * value of elseResult is returned when no condition is true. it is NULL when elseResult is nil.
+ * CASE x WHEN v THEN ... is represented with conditions x = v
+ */
+type CaseWhen struct {
+	*AbstractExpression
+	conditions []Expression
+	results    []Expression
+	elseResult Expression
+}
+
+// result expressions should return retType. Integer results are converted to Float when retType is Float
+func NewCaseWhen(conditions []Expression, results []Expression, elseResult Expression, retType types.TypeID) Expression {
+	return &CaseWhen{&AbstractExpression{[2]Expression{}, retType}, conditions, results, elseResult}
+}
+
+func (c *CaseWhen) Evaluate(tuple_ *tuple.Tuple, schema_ *schema.Schema) types.Value {
+	return c.evaluateWith(func(expr Expression) types.Value {
+		return expr.Evaluate(tuple_, schema_)
+	})
+}
+
+func (c *CaseWhen) EvaluateJoin(left_tuple *tuple.Tuple, left_schema *schema.Schema, right_tuple *tuple.Tuple, right_schema *schema.Schema) types.Value {
+	return c.evaluateWith(func(expr Expression) types.Value {
+		return expr.EvaluateJoin(left_tuple, left_schema, right_tuple, right_schema)
+	})
+}
+
+func (c *CaseWhen) EvaluateAggregate(group_bys []*types.Value, aggregates []*types.Value) types.Value {
+	return c.evaluateWith(func(expr Expression) types.Value {
+		return expr.EvaluateAggregate(group_bys, aggregates)
+	})
+}
+
+// conditions and results are evaluated lazily with eval
+func (c *CaseWhen) evaluateWith(eval func(Expression) types.Value) types.Value {
+	for ii, cond := range c.conditions {
+		condVal := eval(cond)
+		if !condVal.IsNull() && condVal.ToBoolean() {
+			return c.toReturnType(eval(c.results[ii]))
+		}
+	}
+	if c.elseResult == nil {
+		return types.NewNullOfType(c.ret_type)
+	}
+	return c.toReturnType(eval(c.elseResult))
+}
+
+func (c *CaseWhen) toReturnType(val types.Value) types.Value {
+	if val.IsNull() {
+		return types.NewNullOfType(c.ret_type)
+	}
+	if c.ret_type != val.ValueType() {
+		// integer value is converted to Float or wider integer type
+		if ret, ok := CastValue(val, c.ret_type); ok {
+			return ret
+		}
+	}
+	return val
+}
+
+// children are conditions, results and else result in this order. nil is returned when child_idx is out of range
+func (c *CaseWhen) GetChildAt(child_idx uint32) Expression {
+	children := c.GetChildren()
+	if int(child_idx) >= len(children) {
+		return nil
+	}
+	return children[child_idx]
+}
+
+func (c *CaseWhen) GetChildren() []Expression {
+	children := make([]Expression, 0, len(c.conditions)*2+1)
+	children = append(children, c.conditions...)
+	children = append(children, c.results...)
+	if c.elseResult != nil {
+		children = append(children, c.elseResult)
+	}
+	return children
+}
+
+func (c *CaseWhen) GetReturnType() types.TypeID { return c.ret_type }
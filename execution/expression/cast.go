@@ -0,0 +1,333 @@
+package expression
+
+import (
+	"errors"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/storage/table/column"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Cast converts value of child expression to type of CAST(x AS type).
+ * NULL is returned when the value can not be converted.
+ * CAST of a constant is evaluated when the query is planned and the error is reported then.
+ */
+type Cast struct {
+	*AbstractExpression
+	// precision and scale of CAST(x AS DECIMAL(p, s)). -1 when these are not specified
+	decimalPrecision int32
+	decimalScale     int32
+}
+
+func NewCast(child Expression, castType types.TypeID) Expression {
+	return &Cast{&AbstractExpression{[2]Expression{child, nil}, castType}, -1, -1}
+}
+
+// NewDecimalCast returns CAST(x AS DECIMAL(precision, scale)).
+// NULL is returned when the value doesn't fit to the precision
+func NewDecimalCast(child Expression, precision int32, scale int32) Expression {
+	return &Cast{&AbstractExpression{[2]Expression{child, nil}, types.Decimal}, precision, scale}
+}
+
+func (c *Cast) Evaluate(tuple_ *tuple.Tuple, schema_ *schema.Schema) types.Value {
+	return c.cast(c.children[0].Evaluate(tuple_, schema_))
+}
+
+func (c *Cast) EvaluateJoin(left_tuple *tuple.Tuple, left_schema *schema.Schema, right_tuple *tuple.Tuple, right_schema *schema.Schema) types.Value {
+	return c.cast(c.children[0].EvaluateJoin(left_tuple, left_schema, right_tuple, right_schema))
+}
+
+func (c *Cast) EvaluateAggregate(group_bys []*types.Value, aggregates []*types.Value) types.Value {
+	return c.cast(c.children[0].EvaluateAggregate(group_bys, aggregates))
+}
+
+func (c *Cast) cast(val types.Value) types.Value {
+	if ret, ok := CastValue(val, c.ret_type); ok {
+		if c.decimalScale < 0 || ret.IsNull() {
+			return ret
+		}
+		if ret.FitsDecimal(c.decimalPrecision, c.decimalScale) {
+			return ret.RescaleDecimal(c.decimalScale)
+		}
+	}
+	return types.NewNullOfType(c.ret_type)
+}
+
+func (c *Cast) GetChildAt(child_idx uint32) Expression {
+	return c.children[child_idx]
+}
+
+func (c *Cast) GetReturnType() types.TypeID { return c.ret_type }
+
+// CastValue converts val to castType. false is returned when val can not be converted.
+// NULL is converted to NULL of castType
+func CastValue(val types.Value, castType types.TypeID) (types.Value, bool) {
+	ret, err := ConvertValue(val, castType)
+	return ret, err == nil
+}
+
+// ConvertValue is same as CastValue but returns error which describes why val can not be converted.
+// Float and Decimal value converted to integer type is rounded
+func ConvertValue(val types.Value, castType types.TypeID) (types.Value, error) {
+	if val.IsNull() {
+		return types.NewNullOfType(castType), nil
+	}
+	if val.ValueType() == castType {
+		return val, nil
+	}
+	if val.ValueType() == types.Text || val.ValueType() == types.Blob || val.ValueType() == types.JSON {
+		// TEXT, BLOB and JSON are converted as Varchar
+		val = types.NewVarchar(val.ToVarchar())
+		if castType == types.Varchar {
+			return val, nil
+		}
+	}
+
+	switch castType {
+	case types.Tinyint, types.Smallint, types.Integer, types.BigInt:
+		var i int64
+		switch val.ValueType() {
+		case types.Tinyint, types.Smallint, types.Integer, types.BigInt:
+			i = val.ToInt64()
+		case types.Float:
+			f := math.Round(float64(val.ToFloat()))
+			if math.IsNaN(f) || f >= math.MaxInt64 || f < math.MinInt64 {
+				return types.Value{}, newOutOfRangeError(val, castType)
+			}
+			i = int64(f)
+		case types.Decimal:
+			rounded := types.RoundDecimal(val, 0).ToBigRat()
+			if !rounded.Num().IsInt64() {
+				return types.Value{}, newOutOfRangeError(val, castType)
+			}
+			i = rounded.Num().Int64()
+		case types.Varchar:
+			var err error
+			i, err = strconv.ParseInt(strings.TrimSpace(val.ToVarchar()), 10, 64)
+			if errors.Is(err, strconv.ErrRange) {
+				return types.Value{}, newOutOfRangeError(val, castType)
+			} else if err != nil {
+				return types.Value{}, newInvalidFormatError(val, castType)
+			}
+		case types.Boolean:
+			if val.ToBoolean() {
+				i = 1
+			}
+		default:
+			return types.Value{}, newNotConvertibleError(val, castType)
+		}
+		minVal, maxVal := integerTypeRange(castType)
+		if i < minVal || i > maxVal {
+			return types.Value{}, newOutOfRangeError(val, castType)
+		}
+		return types.NewIntegerOfType(castType, i), nil
+	case types.Float:
+		switch val.ValueType() {
+		case types.Tinyint, types.Smallint, types.Integer, types.BigInt:
+			return types.NewFloat(float32(val.ToInt64())), nil
+		case types.Decimal:
+			f, _ := val.ToBigRat().Float32()
+			if math.IsInf(float64(f), 0) {
+				return types.Value{}, newOutOfRangeError(val, castType)
+			}
+			return types.NewFloat(f), nil
+		case types.Varchar:
+			f, err := strconv.ParseFloat(strings.TrimSpace(val.ToVarchar()), 32)
+			if errors.Is(err, strconv.ErrRange) {
+				return types.Value{}, newOutOfRangeError(val, castType)
+			} else if err != nil {
+				return types.Value{}, newInvalidFormatError(val, castType)
+			}
+			return types.NewFloat(float32(f)), nil
+		}
+	case types.Decimal:
+		switch val.ValueType() {
+		case types.Tinyint, types.Smallint, types.Integer, types.BigInt:
+			return val.ToDecimal(), nil
+		case types.Float:
+			f := val.ToFloat()
+			if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+				return types.Value{}, newOutOfRangeError(val, castType)
+			}
+			ret := val.ToDecimal()
+			if ret.DecimalDigits() > types.DecimalMaxPrecision {
+				return types.Value{}, newOutOfRangeError(val, castType)
+			}
+			return ret, nil
+		case types.Varchar:
+			d, err := types.NewDecimalFromString(val.ToVarchar())
+			if err != nil {
+				return types.Value{}, newInvalidFormatError(val, castType)
+			}
+			return d, nil
+		}
+	case types.Timestamp:
+		switch val.ValueType() {
+		case types.Date:
+			return types.NewTimestamp(val.ToTime()), nil
+		case types.Varchar:
+			ts, err := types.ParseTimestamp(val.ToVarchar())
+			if err != nil {
+				return types.Value{}, newInvalidFormatError(val, castType)
+			}
+			return ts, nil
+		}
+	case types.Date:
+		switch val.ValueType() {
+		case types.Timestamp:
+			return types.NewDate(val.ToTime()), nil
+		case types.Varchar:
+			d, err := types.ParseDate(val.ToVarchar())
+			if err != nil {
+				return types.Value{}, newInvalidFormatError(val, castType)
+			}
+			return d, nil
+		}
+	case types.Varchar:
+		switch val.ValueType() {
+		case types.Float:
+			return types.NewVarchar(strconv.FormatFloat(float64(val.ToFloat()), 'f', -1, 32)), nil
+		case types.Tinyint, types.Smallint, types.Integer, types.BigInt, types.Decimal, types.Boolean, types.Timestamp, types.Date:
+			return types.NewVarchar(val.ToString()), nil
+		}
+	case types.Text, types.Blob:
+		str, err := ConvertValue(val, types.Varchar)
+		if err != nil {
+			return types.Value{}, err
+		}
+		if castType == types.Text {
+			return types.NewText(str.ToVarchar()), nil
+		}
+		return types.NewBlob([]byte(str.ToVarchar())), nil
+	case types.JSON:
+		switch val.ValueType() {
+		case types.Varchar:
+			doc, err := types.ParseJSON(val.ToVarchar())
+			if err != nil {
+				return types.Value{}, newInvalidFormatError(val, castType)
+			}
+			return doc, nil
+		case types.Tinyint, types.Smallint, types.Integer, types.BigInt, types.Decimal, types.Float, types.Boolean:
+			// number and boolean are JSON scalar as they are
+			str, err := ConvertValue(val, types.Varchar)
+			if err != nil {
+				return types.Value{}, err
+			}
+			return types.ParseJSON(str.ToVarchar())
+		}
+	case types.Boolean:
+		switch val.ValueType() {
+		case types.Tinyint, types.Smallint, types.Integer, types.BigInt:
+			return types.NewBoolean(val.ToInt64() != 0), nil
+		case types.Varchar:
+			b, err := strconv.ParseBool(strings.TrimSpace(val.ToVarchar()))
+			if err != nil {
+				return types.Value{}, newInvalidFormatError(val, castType)
+			}
+			return types.NewBoolean(b), nil
+		}
+	}
+	return types.Value{}, newNotConvertibleError(val, castType)
+}
+
+// IsImplicitlyConvertible returns true when value of fromType can be stored to or compared with
+// value of toType without CAST. numeric types are promoted to each other, integer is used as BOOLEAN
+// and string (literal) is parsed as numeric, date/time and BOOLEAN value
+func IsImplicitlyConvertible(fromType types.TypeID, toType types.TypeID) bool {
+	switch {
+	case fromType == toType:
+		return true
+	case fromType.IsNumeric() && toType.IsNumeric(),
+		fromType.IsString() && toType.IsString(),
+		fromType.IsTemporal() && toType.IsTemporal():
+		return true
+	case fromType.IsIntegerFamily() && toType == types.Boolean:
+		return true
+	case fromType == types.Varchar && (toType.IsNumeric() || toType.IsTemporal() || toType == types.Boolean):
+		return true
+	}
+	return false
+}
+
+// CoerceLiteralForComparison converts literal val to compType when val is compared with value of compType.
+// numeric, date/time and string values are compared as they are because comparison works across the types of each family.
+// error is returned when val is invalid as compType or the types can not be compared
+func CoerceLiteralForComparison(val types.Value, compType types.TypeID) (types.Value, error) {
+	valType := val.ValueType()
+	if val.IsNull() || valType == compType || (valType.IsNumeric() && compType.IsNumeric()) ||
+		(valType.IsTemporal() && compType.IsTemporal()) || (valType.IsString() && compType.IsString()) {
+		return val, nil
+	}
+	if !IsImplicitlyConvertible(valType, compType) {
+		return types.Value{}, samehada_errors.NewTypeMismatchError(valType.String() + " value can not be compared with " + compType.String() + " value.")
+	}
+	return ConvertValue(val, compType)
+}
+
+func newOutOfRangeError(val types.Value, castType types.TypeID) error {
+	return samehada_errors.NewTypeMismatchError("value " + quotedValueString(val) + " is out of range of " + castType.String() + ".")
+}
+
+func newInvalidFormatError(val types.Value, castType types.TypeID) error {
+	return samehada_errors.NewTypeMismatchError("value " + quotedValueString(val) + " is invalid as " + castType.String() + ".")
+}
+
+func newNotConvertibleError(val types.Value, castType types.TypeID) error {
+	return samehada_errors.NewTypeMismatchError(val.ValueType().String() + " value can not be converted to " + castType.String() + ".")
+}
+
+func quotedValueString(val types.Value) string {
+	if val.ValueType().IsString() {
+		return "'" + val.ToVarchar() + "'"
+	}
+	return val.ToString()
+}
+
+// integerTypeRange returns minimum and maximum value of integer type typeId
+func integerTypeRange(typeId types.TypeID) (int64, int64) {
+	switch typeId {
+	case types.Tinyint:
+		return math.MinInt8, math.MaxInt8
+	case types.Smallint:
+		return math.MinInt16, math.MaxInt16
+	case types.Integer:
+		return math.MinInt32, math.MaxInt32
+	default:
+		return math.MinInt64, math.MaxInt64
+	}
+}
+
+// AdjustValueForColumn checks that type of val matches col and returns the value to be stored.
+// NULL is converted to NULL of column type. value of other type is converted to column type when
+// it is implicitly convertible (numeric promotion, string literal to number, date/time and so on)
+func AdjustValueForColumn(val types.Value, col *column.Column) (types.Value, error) {
+	if val.IsNull() {
+		return types.NewNullOfType(col.GetType()), nil
+	}
+	if val.ValueType() != col.GetType() {
+		if !IsImplicitlyConvertible(val.ValueType(), col.GetType()) {
+			return types.Value{}, samehada_errors.NewTypeMismatchError("column " + col.GetColumnName() + " is " + col.GetType().String() + " but " + val.ValueType().String() + " value is passed.")
+		}
+		converted, err := ConvertValue(val, col.GetType())
+		if err != nil {
+			return types.Value{}, samehada_errors.NewTypeMismatchError("can not store to column " + col.GetColumnName() + ": " + err.Error())
+		}
+		val = converted
+	}
+	if col.GetType() == types.Decimal {
+		if !val.FitsDecimal(col.DecimalPrecision(), col.DecimalScale()) {
+			return types.Value{}, samehada_errors.NewTypeMismatchError("value " + val.ToString() + " is out of range of DECIMAL(" + strconv.Itoa(int(col.DecimalPrecision())) + ", " + strconv.Itoa(int(col.DecimalScale())) + ") column " + col.GetColumnName() + ".")
+		}
+		// digits after the scale are rounded
+		return val.RescaleDecimal(col.DecimalScale()), nil
+	}
+	if col.GetType() == types.Varchar && len(val.ToVarchar()) > math.MaxUint16 {
+		return types.Value{}, samehada_errors.NewTypeMismatchError("value is too long for VARCHAR column " + col.GetColumnName() + ". use TEXT instead.")
+	}
+	return val, nil
+}
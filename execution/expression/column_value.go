@@ -34,6 +34,10 @@ func (c *ColumnValue) SetColIndex(colIndex uint32) {
 	c.colIndex = colIndex
 }
 
+func (c *ColumnValue) GetColIndex() uint32 {
+	return c.colIndex
+}
+
 func (c *ColumnValue) EvaluateJoin(left_tuple *tuple.Tuple, left_schema *schema.Schema, right_tuple *tuple.Tuple, right_schema *schema.Schema) types.Value {
 	if c.tupleIndexForJoin == 0 {
 		return left_tuple.GetValue(left_schema, c.colIndex)
@@ -27,10 +27,21 @@ const (
 type Comparison struct {
 	*AbstractExpression
 	comparisonType ComparisonType
+	// when true, result is NULL if an operand is NULL
+	isNullUnknown bool
 }
 
+// NewComparison returns comparison which treats NULL as a value. NULL equals to NULL only.
+// IS NULL and IS TRUE are built on this
 func NewComparison(left Expression, right Expression, comparisonType ComparisonType, colType types.TypeID) Expression {
-	ret := &Comparison{&AbstractExpression{[2]Expression{left, right}, colType}, comparisonType}
+	ret := &Comparison{&AbstractExpression{[2]Expression{left, right}, colType}, comparisonType, false}
+	return ret
+}
+
+// NewSQLComparison returns comparison of SQL operators. same as standard SQL,
+// comparison with NULL is unknown and the result is NULL
+func NewSQLComparison(left Expression, right Expression, comparisonType ComparisonType, colType types.TypeID) Expression {
+	ret := &Comparison{&AbstractExpression{[2]Expression{left, right}, colType}, comparisonType, true}
 	return ret
 }
 
@@ -40,6 +51,13 @@ func (c *Comparison) Evaluate(tuple_ *tuple.Tuple, schema_ *schema.Schema) types
 	}
 	lhs := c.children[0].Evaluate(tuple_, schema_)
 	rhs := c.children[1].Evaluate(tuple_, schema_)
+	return c.compare(lhs, rhs)
+}
+
+func (c *Comparison) compare(lhs types.Value, rhs types.Value) types.Value {
+	if c.isNullUnknown && (lhs.IsNull() || rhs.IsNull()) {
+		return types.NewNullOfType(types.Boolean)
+	}
 	return types.NewBoolean(c.performComparison(lhs, rhs))
 }
 
@@ -78,13 +96,13 @@ func (c *Comparison) GetComparisonType() ComparisonType {
 func (c *Comparison) EvaluateJoin(left_tuple *tuple.Tuple, left_schema *schema.Schema, right_tuple *tuple.Tuple, right_schema *schema.Schema) types.Value {
 	lhs := c.GetChildAt(0).EvaluateJoin(left_tuple, left_schema, right_tuple, right_schema)
 	rhs := c.GetChildAt(1).EvaluateJoin(left_tuple, left_schema, right_tuple, right_schema)
-	return types.NewBoolean(c.performComparison(lhs, rhs))
+	return c.compare(lhs, rhs)
 }
 
 func (c *Comparison) EvaluateAggregate(group_bys []*types.Value, aggregates []*types.Value) types.Value {
 	lhs := c.GetChildAt(0).EvaluateAggregate(group_bys, aggregates)
 	rhs := c.GetChildAt(1).EvaluateAggregate(group_bys, aggregates)
-	return types.NewBoolean(c.performComparison(lhs, rhs))
+	return c.compare(lhs, rhs)
 }
 
 func (c *Comparison) GetChildAt(child_idx uint32) Expression {
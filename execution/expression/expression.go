@@ -19,3 +19,39 @@ type Expression interface {
 	EvaluateJoin(*tuple.Tuple, *schema.Schema, *tuple.Tuple, *schema.Schema) types.Value
 	EvaluateAggregate([]*types.Value, []*types.Value) types.Value
 }
+
+// expression which has more than two children (function call, CASE)
+type multiChildExpression interface {
+	GetChildren() []Expression
+}
+
+// GetColIndexesOfExpression returns indexes of columns which are referred in expr.
+// duplicated index is not included
+func GetColIndexesOfExpression(expr Expression) []uint32 {
+	ret := make([]uint32, 0)
+	var collect func(Expression)
+	collect = func(e Expression) {
+		if e == nil {
+			return
+		}
+		if colVal, ok := e.(*ColumnValue); ok {
+			for _, idx := range ret {
+				if idx == colVal.colIndex {
+					return
+				}
+			}
+			ret = append(ret, colVal.colIndex)
+			return
+		}
+		if multi, ok := e.(multiChildExpression); ok {
+			for _, child := range multi.GetChildren() {
+				collect(child)
+			}
+			return
+		}
+		collect(e.GetChildAt(0))
+		collect(e.GetChildAt(1))
+	}
+	collect(expr)
+	return ret
+}
@@ -0,0 +1,71 @@
+package expression
+
+import (
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+/**
+ * FuncCall calls a ScalarFunction with values of argument expressions.
+ */
+type FuncCall struct {
+	*AbstractExpression
+	function *ScalarFunction
+	args     []Expression
+}
+
+// retType should be the type returned from ReturnType of function
+func NewFuncCall(function *ScalarFunction, args []Expression, retType types.TypeID) Expression {
+	return &FuncCall{&AbstractExpression{[2]Expression{}, retType}, function, args}
+}
+
+func (c *FuncCall) Evaluate(tuple_ *tuple.Tuple, schema_ *schema.Schema) types.Value {
+	args := make([]types.Value, 0, len(c.args))
+	for _, arg := range c.args {
+		args = append(args, arg.Evaluate(tuple_, schema_))
+	}
+	return c.call(args)
+}
+
+func (c *FuncCall) call(args []types.Value) types.Value {
+	ret := c.function.Eval(args, c.ret_type)
+	if ret.IsNull() {
+		return types.NewNullOfType(c.ret_type)
+	}
+	return ret
+}
+
+func (c *FuncCall) EvaluateJoin(left_tuple *tuple.Tuple, left_schema *schema.Schema, right_tuple *tuple.Tuple, right_schema *schema.Schema) types.Value {
+	args := make([]types.Value, 0, len(c.args))
+	for _, arg := range c.args {
+		args = append(args, arg.EvaluateJoin(left_tuple, left_schema, right_tuple, right_schema))
+	}
+	return c.call(args)
+}
+
+func (c *FuncCall) EvaluateAggregate(group_bys []*types.Value, aggregates []*types.Value) types.Value {
+	args := make([]types.Value, 0, len(c.args))
+	for _, arg := range c.args {
+		args = append(args, arg.EvaluateAggregate(group_bys, aggregates))
+	}
+	return c.call(args)
+}
+
+// returns nil when child_idx is out of range
+func (c *FuncCall) GetChildAt(child_idx uint32) Expression {
+	if int(child_idx) >= len(c.args) {
+		return nil
+	}
+	return c.args[child_idx]
+}
+
+func (c *FuncCall) GetChildren() []Expression {
+	return c.args
+}
+
+func (c *FuncCall) GetFunction() *ScalarFunction {
+	return c.function
+}
+
+func (c *FuncCall) GetReturnType() types.TypeID { return c.ret_type }
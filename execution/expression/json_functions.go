@@ -0,0 +1,34 @@
+package expression
+
+import (
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+// JSON functions. doc -> 'path' is parsed as JSON_EXTRACT(doc, 'path') and
+// doc ->> 'path' is parsed as JSON_UNQUOTE(JSON_EXTRACT(doc, 'path')).
+// string arguments are parsed as JSON document and invalid document or path results NULL
+
+func evalJSONExtract(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	ret, err := types.JSONExtract(args[0], args[1].ToVarchar())
+	if err != nil {
+		return types.NewNullOfType(retType)
+	}
+	return ret
+}
+
+func evalJSONUnquote(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	return types.JSONUnquote(args[0])
+}
+
+func evalJSONValid(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	return types.NewBoolean(args[0].ValueType() == types.JSON || types.IsValidJSON(args[0].ToVarchar()))
+}
@@ -37,20 +37,33 @@ func NewLogicalOp(left Expression, right Expression, logicalOpType LogicalOpType
 func (c *LogicalOp) Evaluate(tuple *tuple.Tuple, schema *schema.Schema) types.Value {
 	if c.logicalOpType == NOT {
 		lhs := c.children[0].Evaluate(tuple, schema)
-		return types.NewBoolean(!lhs.ToBoolean())
+		return performNot(lhs)
 	} else {
 		lhs := c.children[0].Evaluate(tuple, schema)
 		rhs := c.children[1].Evaluate(tuple, schema)
-		return types.NewBoolean(c.performLogicalOp(lhs, rhs))
+		return c.performLogicalOp(lhs, rhs)
 	}
 }
 
-func (c *LogicalOp) performLogicalOp(lhs types.Value, rhs types.Value) bool {
+// NULL is unknown. NOT NULL is NULL
+func performNot(val types.Value) types.Value {
+	if val.IsNull() {
+		return types.NewNullOfType(types.Boolean)
+	}
+	return types.NewBoolean(!val.ToBoolean())
+}
+
+// three-valued logic. FALSE AND NULL is FALSE, TRUE OR NULL is TRUE and other combinations with NULL are NULL
+func (c *LogicalOp) performLogicalOp(lhs types.Value, rhs types.Value) types.Value {
 	switch c.logicalOpType {
 	case AND:
-		return lhs.ToBoolean() && rhs.ToBoolean()
+		if (!lhs.IsNull() && !lhs.ToBoolean()) || (!rhs.IsNull() && !rhs.ToBoolean()) {
+			return types.NewBoolean(false)
+		}
 	case OR:
-		return lhs.ToBoolean() || rhs.ToBoolean()
+		if (!lhs.IsNull() && lhs.ToBoolean()) || (!rhs.IsNull() && rhs.ToBoolean()) {
+			return types.NewBoolean(true)
+		}
 	case NOT:
 		fmt.Println(c.logicalOpType)
 		panic("NOT op is not valid!")
@@ -58,6 +71,11 @@ func (c *LogicalOp) performLogicalOp(lhs types.Value, rhs types.Value) bool {
 		fmt.Println(c.logicalOpType)
 		panic("unknown logicalOpType is passed!")
 	}
+	if lhs.IsNull() || rhs.IsNull() {
+		return types.NewNullOfType(types.Boolean)
+	}
+	// both sides are TRUE on AND and FALSE on OR
+	return types.NewBoolean(c.logicalOpType == AND)
 }
 
 func (c *LogicalOp) GetLogicalOpType() LogicalOpType {
@@ -67,22 +85,22 @@ func (c *LogicalOp) GetLogicalOpType() LogicalOpType {
 func (c *LogicalOp) EvaluateJoin(left_tuple *tuple.Tuple, left_schema *schema.Schema, right_tuple *tuple.Tuple, right_schema *schema.Schema) types.Value {
 	if c.logicalOpType == NOT {
 		lhs := c.GetChildAt(0).EvaluateJoin(left_tuple, left_schema, right_tuple, left_schema)
-		return types.NewBoolean(!lhs.ToBoolean())
+		return performNot(lhs)
 	} else {
 		lhs := c.GetChildAt(0).EvaluateJoin(left_tuple, left_schema, right_tuple, left_schema)
 		rhs := c.GetChildAt(1).EvaluateJoin(left_tuple, left_schema, right_tuple, right_schema)
-		return types.NewBoolean(c.performLogicalOp(lhs, rhs))
+		return c.performLogicalOp(lhs, rhs)
 	}
 }
 
 func (c *LogicalOp) EvaluateAggregate(group_bys []*types.Value, aggregates []*types.Value) types.Value {
 	if c.logicalOpType == NOT {
 		lhs := c.GetChildAt(0).EvaluateAggregate(group_bys, aggregates)
-		return types.NewBoolean(!lhs.ToBoolean())
+		return performNot(lhs)
 	} else {
 		lhs := c.GetChildAt(0).EvaluateAggregate(group_bys, aggregates)
 		rhs := c.GetChildAt(1).EvaluateAggregate(group_bys, aggregates)
-		return types.NewBoolean(c.performLogicalOp(lhs, rhs))
+		return c.performLogicalOp(lhs, rhs)
 	}
 }
 
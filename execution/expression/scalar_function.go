@@ -0,0 +1,42 @@
+package expression
+
+import (
+	"github.com/ryogrid/SamehadaDB/types"
+	"strings"
+	"sync"
+)
+
+// ScalarFunction is a function which can be called in expressions of SQL.
+// it is looked up from the registry with its name case-insensitively
+type ScalarFunction struct {
+	Name string
+	// ReturnType checks types of arguments and returns type of the result. it is called at planning.
+	// types.Invalid is passed for NULL literal
+	ReturnType func(argTypes []types.TypeID) (types.TypeID, error)
+	// Eval is called with evaluated arguments. NULL arguments are also passed.
+	// retType is the type returned from ReturnType. NULL should be returned for invalid input
+	Eval func(args []types.Value, retType types.TypeID) types.Value
+}
+
+var scalarFunctions = make(map[string]*ScalarFunction)
+var scalarFunctionsMutex sync.RWMutex
+
+func init() {
+	for _, f := range builtinScalarFunctions() {
+		RegisterScalarFunction(f)
+	}
+}
+
+// RegisterScalarFunction adds f to the registry. function which has same name is replaced
+func RegisterScalarFunction(f *ScalarFunction) {
+	scalarFunctionsMutex.Lock()
+	defer scalarFunctionsMutex.Unlock()
+	scalarFunctions[strings.ToLower(f.Name)] = f
+}
+
+// GetScalarFunction returns nil when function named name is not registered
+func GetScalarFunction(name string) *ScalarFunction {
+	scalarFunctionsMutex.RLock()
+	defer scalarFunctionsMutex.RUnlock()
+	return scalarFunctions[strings.ToLower(name)]
+}
@@ -0,0 +1,76 @@
+package expression
+
+import (
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/storage/tuple"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+// SequenceAccessor advances and reads sequences. catalog.Catalog implements this
+type SequenceAccessor interface {
+	SequenceExists(name string) bool
+	NextVal(name string) (int64, error)
+	CurrVal(name string) (int64, error)
+}
+
+// EvaluationError is passed to panic when evaluation of an expression fails and the failure
+// can't be represented with NULL (ex: NEXTVAL of exhausted sequence).
+// Err is returned as error of the statement when the panic is recovered
+type EvaluationError struct {
+	Err error
+}
+
+func (e *EvaluationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *EvaluationError) Unwrap() error {
+	return e.Err
+}
+
+/**
+ * SequenceFuncCall is NEXTVAL or CURRVAL of a sequence. NEXTVAL advances the sequence
+ * each time it is evaluated, so it is evaluated for each tuple.
+ */
+type SequenceFuncCall struct {
+	*AbstractExpression
+	isNextVal bool
+	seqName   string
+	seqs      SequenceAccessor
+}
+
+func NewSequenceFuncCall(isNextVal bool, seqName string, seqs SequenceAccessor) Expression {
+	return &SequenceFuncCall{&AbstractExpression{[2]Expression{}, types.BigInt}, isNextVal, seqName, seqs}
+}
+
+func (c *SequenceFuncCall) Evaluate(tuple_ *tuple.Tuple, schema_ *schema.Schema) types.Value {
+	return c.call()
+}
+
+func (c *SequenceFuncCall) EvaluateJoin(left_tuple *tuple.Tuple, left_schema *schema.Schema, right_tuple *tuple.Tuple, right_schema *schema.Schema) types.Value {
+	return c.call()
+}
+
+func (c *SequenceFuncCall) EvaluateAggregate(group_bys []*types.Value, aggregates []*types.Value) types.Value {
+	return c.call()
+}
+
+func (c *SequenceFuncCall) call() types.Value {
+	var val int64
+	var err error
+	if c.isNextVal {
+		val, err = c.seqs.NextVal(c.seqName)
+	} else {
+		val, err = c.seqs.CurrVal(c.seqName)
+	}
+	if err != nil {
+		panic(&EvaluationError{err})
+	}
+	return types.NewBigInt(val)
+}
+
+func (c *SequenceFuncCall) GetChildAt(child_idx uint32) Expression {
+	return c.children[child_idx]
+}
+
+func (c *SequenceFuncCall) GetReturnType() types.TypeID { return c.ret_type }
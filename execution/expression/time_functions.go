@@ -0,0 +1,142 @@
+package expression
+
+import (
+	"errors"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/types"
+	"strings"
+	"time"
+)
+
+// date/time functions. unit of time is passed as lower case Varchar value like 'day'.
+// parser converts unit keyword of EXTRACT and INTERVAL to it
+
+func isTemporalArg(argType types.TypeID) bool {
+	return argType.IsTemporal() || argType == types.Invalid
+}
+
+// DATE_TRUNC(unit, t) returns type of t
+func dateTruncReturnType(passed []types.TypeID) (types.TypeID, error) {
+	if len(passed) != 2 {
+		return types.Invalid, errors.New("wrong number of arguments are passed to date_trunc.")
+	}
+	if passed[0] != types.Varchar || !passed[1].IsTemporal() {
+		return types.Invalid, samehada_errors.NewTypeMismatchError("arguments of date_trunc must be unit string and TIMESTAMP or DATE.")
+	}
+	return passed[1], nil
+}
+
+// EXTRACT(unit FROM t)
+func extractReturnType(passed []types.TypeID) (types.TypeID, error) {
+	if len(passed) != 2 {
+		return types.Invalid, errors.New("wrong number of arguments are passed to extract.")
+	}
+	if passed[0] != types.Varchar || !isTemporalArg(passed[1]) {
+		return types.Invalid, samehada_errors.NewTypeMismatchError("argument of extract must be TIMESTAMP or DATE.")
+	}
+	return types.Integer, nil
+}
+
+// t + INTERVAL n unit is parsed as DATE_ADD(t, n, unit). result has type of t
+func dateAddReturnType(name string) func([]types.TypeID) (types.TypeID, error) {
+	return func(passed []types.TypeID) (types.TypeID, error) {
+		if len(passed) != 3 {
+			return types.Invalid, errors.New("wrong number of arguments are passed to " + name + ".")
+		}
+		if !passed[0].IsTemporal() {
+			return types.Invalid, samehada_errors.NewTypeMismatchError("first argument of " + name + " must be TIMESTAMP or DATE.")
+		}
+		if !passed[1].IsIntegerFamily() && passed[1] != types.Invalid {
+			return types.Invalid, samehada_errors.NewTypeMismatchError("interval of " + name + " must be integer.")
+		}
+		return passed[0], nil
+	}
+}
+
+// DATEDIFF(a, b) returns the number of days from b to a
+func datediffReturnType(passed []types.TypeID) (types.TypeID, error) {
+	if len(passed) != 2 {
+		return types.Invalid, errors.New("wrong number of arguments are passed to datediff.")
+	}
+	if !isTemporalArg(passed[0]) || !isTemporalArg(passed[1]) {
+		return types.Invalid, samehada_errors.NewTypeMismatchError("arguments of datediff must be TIMESTAMP or DATE.")
+	}
+	return types.Integer, nil
+}
+
+// TIMESTAMPDIFF(unit, a, b) returns the number of whole units from a to b
+func timestampdiffReturnType(passed []types.TypeID) (types.TypeID, error) {
+	if len(passed) != 3 {
+		return types.Invalid, errors.New("wrong number of arguments are passed to timestampdiff.")
+	}
+	if passed[0] != types.Varchar || !isTemporalArg(passed[1]) || !isTemporalArg(passed[2]) {
+		return types.Invalid, samehada_errors.NewTypeMismatchError("arguments of timestampdiff must be unit and TIMESTAMP or DATE.")
+	}
+	return types.BigInt, nil
+}
+
+func evalNow(args []types.Value, retType types.TypeID) types.Value {
+	return types.NewTimestamp(time.Now())
+}
+
+func evalCurrentDate(args []types.Value, retType types.TypeID) types.Value {
+	return types.NewDate(time.Now())
+}
+
+// unsupported unit results NULL
+
+func evalDateTrunc(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	ret, err := types.TruncTime(args[1], strings.ToLower(args[0].ToVarchar()))
+	if err != nil {
+		return types.NewNullOfType(retType)
+	}
+	return ret
+}
+
+func evalExtract(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	field, err := types.ExtractTimeField(args[1], strings.ToLower(args[0].ToVarchar()))
+	if err != nil {
+		return types.NewNullOfType(retType)
+	}
+	return types.NewInteger(int32(field))
+}
+
+// sign is -1 for DATE_SUB
+func evalDateAdd(sign int64) func([]types.Value, types.TypeID) types.Value {
+	return func(args []types.Value, retType types.TypeID) types.Value {
+		if hasNullArg(args) {
+			return types.NewNullOfType(retType)
+		}
+		ret, err := types.AddInterval(args[0], sign*args[1].ToInt64(), strings.ToLower(args[2].ToVarchar()))
+		if err != nil {
+			return types.NewNullOfType(retType)
+		}
+		return ret
+	}
+}
+
+func evalDatediff(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	// time of the day is ignored
+	days, _ := types.TimeDiff(types.NewDate(args[1].ToTime()), types.NewDate(args[0].ToTime()), "day")
+	return types.NewInteger(int32(days))
+}
+
+func evalTimestampdiff(args []types.Value, retType types.TypeID) types.Value {
+	if hasNullArg(args) {
+		return types.NewNullOfType(retType)
+	}
+	diff, err := types.TimeDiff(args[1], args[2], strings.ToLower(args[0].ToVarchar()))
+	if err != nil {
+		return types.NewNullOfType(retType)
+	}
+	return types.NewBigInt(diff)
+}
@@ -16,6 +16,8 @@ const (
 	SUM_AGGREGATE
 	MIN_AGGREGATE
 	MAX_AGGREGATE
+	// aggregate expression is expression.UserAggregateCall
+	USER_DEFINED_AGGREGATE
 )
 
 /**
@@ -94,4 +96,7 @@ type AggregateKey struct {
 
 type AggregateValue struct {
 	Aggregates_ []*types.Value
+	// states of user defined aggregate functions. element is nil when the aggregate is not user defined.
+	// the result of Final is set to Aggregates_ after all tuples are combined
+	States_ []expression.AggregateFunction
 }
@@ -0,0 +1,31 @@
+package plans
+
+import (
+	"math"
+
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+)
+
+// scan of common table expression which is referenced more than once in a query.
+// result of child is materialized at first scan and it is shared by all scans which have same name
+
+type CTEScanPlanNode struct {
+	*AbstractPlanNode
+	cteName string
+}
+
+func NewCTEScanPlanNode(child Plan, outSchema *schema.Schema, cteName string) Plan {
+	return &CTEScanPlanNode{&AbstractPlanNode{outSchema, []Plan{child}}, cteName}
+}
+
+func (p *CTEScanPlanNode) GetType() PlanType {
+	return CTEScan
+}
+
+func (p *CTEScanPlanNode) GetCTEName() string {
+	return p.cteName
+}
+
+func (p *CTEScanPlanNode) GetTableOID() uint32 {
+	return math.MaxUint32
+}
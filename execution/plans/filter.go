@@ -13,9 +13,10 @@ type FilterPlanNode struct {
 	predicate     expression.Expression
 }
 
+// predicate is evaluated with output schema of child and
+// output tuples are projected to selectColumns
 func NewFilterPlanNode(child Plan, selectColumns *schema.Schema, predicate expression.Expression) Plan {
-	childOutSchema := child.OutputSchema()
-	return &FilterPlanNode{&AbstractPlanNode{childOutSchema, []Plan{child}}, selectColumns, predicate}
+	return &FilterPlanNode{&AbstractPlanNode{selectColumns, []Plan{child}}, selectColumns, predicate}
 }
 
 func (p *FilterPlanNode) GetType() PlanType {
@@ -7,6 +7,19 @@ import (
 	"github.com/ryogrid/SamehadaDB/types"
 )
 
+// OnConflictAction is action taken when a tuple to be inserted has same value with
+// a stored tuple on PRIMARY KEY or UNIQUE column (UPSERT)
+type OnConflictAction struct {
+	// the tuple is not inserted and the stored tuple is not changed
+	DoNothing bool
+	// ON DUPLICATE KEY UPDATE. UpdateColIdxs[i]-th column of the stored tuple is updated with
+	// UpdateValues[i]. when UpdateSrcColIdxs[i] is not -1, value of UpdateSrcColIdxs[i]-th column
+	// of the tuple to be inserted is used instead
+	UpdateColIdxs    []int
+	UpdateValues     []types.Value
+	UpdateSrcColIdxs []int
+}
+
 /**
  * InsertPlanNode identifies a table that should be inserted into.
  * The values to be inserted are either embedded into the InsertPlanNode itself, i.e. a "raw insert",
@@ -16,11 +29,21 @@ type InsertPlanNode struct {
 	*AbstractPlanNode
 	rawValues [][]types.Value
 	tableOID  uint32
+	// when values come from the child, i-th column of the child's output is stored
+	// to tgtColIdxs[i]-th column of the table
+	tgtColIdxs []int
+	// nil when conflict is treated as constraint violation
+	onConflict *OnConflictAction
 }
 
 // NewInsertPlanNode creates a new insert plan node for inserting raw values
 func NewInsertPlanNode(rawValues [][]types.Value, oid uint32) Plan {
-	return &InsertPlanNode{&AbstractPlanNode{nil, nil}, rawValues, oid}
+	return &InsertPlanNode{&AbstractPlanNode{nil, nil}, rawValues, oid, nil, nil}
+}
+
+// NewInsertSelectPlanNode creates a new insert plan node for inserting tuples returned from child
+func NewInsertSelectPlanNode(child Plan, tgtColIdxs []int, oid uint32) Plan {
+	return &InsertPlanNode{&AbstractPlanNode{nil, []Plan{child}}, nil, oid, tgtColIdxs, nil}
 }
 
 // GetTableOID returns the identifier of the table that should be inserted into
@@ -33,6 +56,19 @@ func (p *InsertPlanNode) GetRawValues() [][]types.Value {
 	return p.rawValues
 }
 
+// GetTargetColIdxs returns indexes of columns which values of the child's output are stored to
+func (p *InsertPlanNode) GetTargetColIdxs() []int {
+	return p.tgtColIdxs
+}
+
+func (p *InsertPlanNode) GetOnConflictAction() *OnConflictAction {
+	return p.onConflict
+}
+
+func (p *InsertPlanNode) SetOnConflictAction(action *OnConflictAction) {
+	p.onConflict = action
+}
+
 func (p *InsertPlanNode) GetType() PlanType {
 	return Insert
 }
@@ -0,0 +1,27 @@
+package plans
+
+import (
+	"math"
+
+	"github.com/ryogrid/SamehadaDB/storage/table/column"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+)
+
+// returns one tuple which has no column. it is source of SELECT without FROM clause
+// and expressions of select fields are evaluated on the tuple
+
+type OneRowPlanNode struct {
+	*AbstractPlanNode
+}
+
+func NewOneRowPlanNode() Plan {
+	return &OneRowPlanNode{&AbstractPlanNode{schema.NewSchema([]*column.Column{}), []Plan{}}}
+}
+
+func (p *OneRowPlanNode) GetType() PlanType {
+	return OneRow
+}
+
+func (p *OneRowPlanNode) GetTableOID() uint32 {
+	return math.MaxUint32
+}
@@ -21,6 +21,14 @@ const (
 	Aggregation
 	Orderby
 	Filter
+	Projection
+	CTEScan
+	RecursiveCTE
+	WorkTableScan
+	UnionAll
+	SetOperation
+	Window
+	OneRow
 )
 
 type Plan interface {
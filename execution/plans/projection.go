@@ -0,0 +1,26 @@
+package plans
+
+import (
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+)
+
+// evaluate expressions for each tuple returned from child Plan(Executor).
+// it is used for RETURNING clause of INSERT, UPDATE and DELETE and renaming of columns
+
+type ProjectionPlanNode struct {
+	*AbstractPlanNode
+}
+
+// each column of outSchema should have expression which is evaluated with
+// output schema of child or schema of the table which child modifies when child is DML
+func NewProjectionPlanNode(child Plan, outSchema *schema.Schema) Plan {
+	return &ProjectionPlanNode{&AbstractPlanNode{outSchema, []Plan{child}}}
+}
+
+func (p *ProjectionPlanNode) GetType() PlanType {
+	return Projection
+}
+
+func (p *ProjectionPlanNode) GetTableOID() uint32 {
+	return p.children[0].GetTableOID()
+}
@@ -0,0 +1,46 @@
+package plans
+
+import (
+	"math"
+
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+)
+
+// evaluation of recursive common table expression.
+// tuples of anchor plan are set to working table at first. then recursive plan which reads
+// the working table with WorkTableScanPlanNode is executed repeatedly. its result replaces the working table
+// until no new tuple is returned
+
+type RecursiveCTEPlanNode struct {
+	*AbstractPlanNode
+	cteName    string
+	isUnionAll bool // duplicated tuples are not removed
+}
+
+func NewRecursiveCTEPlanNode(anchor Plan, recursive Plan, outSchema *schema.Schema, cteName string, isUnionAll bool) Plan {
+	return &RecursiveCTEPlanNode{&AbstractPlanNode{outSchema, []Plan{anchor, recursive}}, cteName, isUnionAll}
+}
+
+func (p *RecursiveCTEPlanNode) GetType() PlanType {
+	return RecursiveCTE
+}
+
+func (p *RecursiveCTEPlanNode) GetAnchorPlan() Plan {
+	return p.GetChildAt(0)
+}
+
+func (p *RecursiveCTEPlanNode) GetRecursivePlan() Plan {
+	return p.GetChildAt(1)
+}
+
+func (p *RecursiveCTEPlanNode) GetCTEName() string {
+	return p.cteName
+}
+
+func (p *RecursiveCTEPlanNode) IsUnionAll() bool {
+	return p.isUnionAll
+}
+
+func (p *RecursiveCTEPlanNode) GetTableOID() uint32 {
+	return math.MaxUint32
+}
@@ -0,0 +1,53 @@
+package plans
+
+import (
+	"math"
+
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+)
+
+type SetOperationType int32
+
+const (
+	UNION_OPERATION SetOperationType = iota
+	INTERSECT_OPERATION
+	EXCEPT_OPERATION
+)
+
+// set operation between tuples returned from two children which uses hash table of tuples.
+// UNION removes duplicates, INTERSECT and EXCEPT check membership of left tuples in right tuples.
+// UNION ALL is executed by UnionAllPlanNode
+
+type SetOperationPlanNode struct {
+	*AbstractPlanNode
+	opType SetOperationType
+	isAll  bool // duplicates are kept (INTERSECT ALL, EXCEPT ALL)
+}
+
+func NewSetOperationPlanNode(left Plan, right Plan, outSchema *schema.Schema, opType SetOperationType, isAll bool) Plan {
+	return &SetOperationPlanNode{&AbstractPlanNode{outSchema, []Plan{left, right}}, opType, isAll}
+}
+
+func (p *SetOperationPlanNode) GetType() PlanType {
+	return SetOperation
+}
+
+func (p *SetOperationPlanNode) GetLeftPlan() Plan {
+	return p.GetChildAt(0)
+}
+
+func (p *SetOperationPlanNode) GetRightPlan() Plan {
+	return p.GetChildAt(1)
+}
+
+func (p *SetOperationPlanNode) GetSetOperationType() SetOperationType {
+	return p.opType
+}
+
+func (p *SetOperationPlanNode) IsAll() bool {
+	return p.isAll
+}
+
+func (p *SetOperationPlanNode) GetTableOID() uint32 {
+	return math.MaxUint32
+}
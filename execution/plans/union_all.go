@@ -0,0 +1,34 @@
+package plans
+
+import (
+	"math"
+
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+)
+
+// concatenation of tuples returned from two children (UNION ALL).
+// output schemas of children must have same number of columns and same types
+
+type UnionAllPlanNode struct {
+	*AbstractPlanNode
+}
+
+func NewUnionAllPlanNode(left Plan, right Plan, outSchema *schema.Schema) Plan {
+	return &UnionAllPlanNode{&AbstractPlanNode{outSchema, []Plan{left, right}}}
+}
+
+func (p *UnionAllPlanNode) GetType() PlanType {
+	return UnionAll
+}
+
+func (p *UnionAllPlanNode) GetLeftPlan() Plan {
+	return p.GetChildAt(0)
+}
+
+func (p *UnionAllPlanNode) GetRightPlan() Plan {
+	return p.GetChildAt(1)
+}
+
+func (p *UnionAllPlanNode) GetTableOID() uint32 {
+	return math.MaxUint32
+}
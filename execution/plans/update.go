@@ -1,6 +1,7 @@
 package plans
 
 import (
+	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/types"
 )
 
@@ -11,6 +12,9 @@ type UpdatePlanNode struct {
 	*AbstractPlanNode
 	rawValues       []types.Value
 	update_col_idxs []int
+	// updateExprs[i] is evaluated with the tuple before update and the value is stored to
+	// update_col_idxs[i]-th column instead of rawValues. nil when the column gets a constant
+	updateExprs []expression.Expression
 	//predicate       expression.Expression
 	//tableOID        uint32
 }
@@ -21,7 +25,7 @@ type UpdatePlanNode struct {
 // func NewUpdatePlanNode(rawValues []types.Value, update_col_idxs []int, predicate expression.Expression, oid uint32) Plan {
 func NewUpdatePlanNode(rawValues []types.Value, update_col_idxs []int, child Plan) Plan {
 	//return &UpdatePlanNode{&AbstractPlanNode{nil, nil}, rawValues, update_col_idxs, predicate, oid}
-	return &UpdatePlanNode{&AbstractPlanNode{nil, []Plan{child}}, rawValues, update_col_idxs}
+	return &UpdatePlanNode{&AbstractPlanNode{nil, []Plan{child}}, rawValues, update_col_idxs, nil}
 }
 
 func (p *UpdatePlanNode) GetTableOID() uint32 {
@@ -44,3 +48,13 @@ func (p *UpdatePlanNode) GetRawValues() []types.Value {
 func (p *UpdatePlanNode) GetUpdateColIdxs() []int {
 	return p.update_col_idxs
 }
+
+func (p *UpdatePlanNode) GetUpdateExprs() []expression.Expression {
+	return p.updateExprs
+}
+
+// SetUpdateExprs sets expressions which are evaluated for each tuple.
+// exprs should have the same length as update_col_idxs
+func (p *UpdatePlanNode) SetUpdateExprs(exprs []expression.Expression) {
+	p.updateExprs = exprs
+}
@@ -0,0 +1,90 @@
+package plans
+
+import (
+	"github.com/ryogrid/SamehadaDB/common"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+type WindowFuncType int32
+
+const (
+	ROW_NUMBER_WINDOW_FUNC WindowFuncType = iota
+	RANK_WINDOW_FUNC
+	DENSE_RANK_WINDOW_FUNC
+	LAG_WINDOW_FUNC
+	LEAD_WINDOW_FUNC
+	FIRST_VALUE_WINDOW_FUNC
+	SUM_WINDOW_FUNC
+	COUNT_WINDOW_FUNC
+	AVG_WINDOW_FUNC
+)
+
+type WindowFrameBoundType int32
+
+const (
+	UNBOUNDED_PRECEDING WindowFrameBoundType = iota
+	PRECEDING
+	CURRENT_ROW
+	FOLLOWING
+	UNBOUNDED_FOLLOWING
+)
+
+// WindowFrameBound is start or end of a frame. Offset is used at PRECEDING and FOLLOWING
+type WindowFrameBound struct {
+	Type   WindowFrameBoundType
+	Offset int
+}
+
+// WindowFrame is rows which are aggregated for each row. bounds of RANGE frame are peers of the row
+// (rows which have same values on ORDER BY columns) and offsets are not supported
+type WindowFrame struct {
+	IsRows bool
+	Start  WindowFrameBound
+	End    WindowFrameBound
+}
+
+// WindowFunc is a window function and its window. column indexes are ones of output schema of child
+type WindowFunc struct {
+	FuncType  WindowFuncType
+	ArgColIdx int // -1 when the function has no column argument (ROW_NUMBER, COUNT(*) etc.)
+	// LAG and LEAD only
+	Offset       int
+	DefaultValue *types.Value // NULL is returned when this is nil
+	// rows are partitioned by these columns and sorted in each partition
+	PartitionColIdxs []int
+	OrderColIdxs     []int
+	OrderTypes       []OrderbyType
+	// nil means default frame. it is whole of partition without ORDER BY
+	// and rows from start of partition to last peer of the row with ORDER BY
+	Frame *WindowFrame
+}
+
+/**
+ * WindowPlanNode evaluates window functions over tuples of child.
+ * output schema is columns of child followed by one column for each window function.
+ * tuples are returned in order of partition and ORDER BY of first window function
+ */
+type WindowPlanNode struct {
+	*AbstractPlanNode
+	windowFuncs []*WindowFunc
+}
+
+func NewWindowPlanNode(child Plan, outSchema *schema.Schema, windowFuncs []*WindowFunc) Plan {
+	return &WindowPlanNode{&AbstractPlanNode{outSchema, []Plan{child}}, windowFuncs}
+}
+
+func (p *WindowPlanNode) GetType() PlanType { return Window }
+
+func (p *WindowPlanNode) GetChildPlan() Plan {
+	common.SH_Assert(len(p.GetChildren()) == 1, "Window expected to only have one child.")
+	return p.GetChildAt(0)
+}
+
+func (p *WindowPlanNode) GetWindowFuncs() []*WindowFunc {
+	return p.windowFuncs
+}
+
+func (p *WindowPlanNode) GetTableOID() uint32 {
+	return p.children[0].GetTableOID()
+}
@@ -0,0 +1,31 @@
+package plans
+
+import (
+	"math"
+
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+)
+
+// scan of working table of recursive common table expression.
+// it is placed in recursive plan of RecursiveCTEPlanNode which has same name
+
+type WorkTableScanPlanNode struct {
+	*AbstractPlanNode
+	cteName string
+}
+
+func NewWorkTableScanPlanNode(outSchema *schema.Schema, cteName string) Plan {
+	return &WorkTableScanPlanNode{&AbstractPlanNode{outSchema, []Plan{}}, cteName}
+}
+
+func (p *WorkTableScanPlanNode) GetType() PlanType {
+	return WorkTableScan
+}
+
+func (p *WorkTableScanPlanNode) GetCTEName() string {
+	return p.cteName
+}
+
+func (p *WorkTableScanPlanNode) GetTableOID() uint32 {
+	return math.MaxUint32
+}
@@ -10,6 +10,8 @@ import (
 type AggFuncVisitor struct {
 	ColumnName_ *string
 	TableName_  *string
+	// first error occurred in visiting. visiting is stopped when it is set
+	err error
 }
 
 func (v *AggFuncVisitor) Enter(in ast.Node) (ast.Node, bool) {
@@ -29,7 +31,11 @@ func (v *AggFuncVisitor) Enter(in ast.Node) (ast.Node, bool) {
 		v.ColumnName_ = &colname
 		return in, true
 	case *driver.ValueExpr:
-		val := ValueExprToValue(node)
+		val, err := ValueExprToValue(node)
+		if err != nil {
+			v.err = err
+			return in, true
+		}
 		if val.ValueType() == types.Integer {
 			// val is 1 (Integer) means wildcard maybe...
 			colname := "*"
@@ -42,5 +48,5 @@ func (v *AggFuncVisitor) Enter(in ast.Node) (ast.Node, bool) {
 }
 
 func (v *AggFuncVisitor) Leave(in ast.Node) (ast.Node, bool) {
-	return in, true
+	return in, v.err == nil
 }
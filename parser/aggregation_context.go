@@ -0,0 +1,215 @@
+package parser
+
+import (
+	"errors"
+	"github.com/pingcap/parser/ast"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/execution/expression"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/types"
+	"strings"
+)
+
+// AggregationContext converts expressions of SELECT fields and HAVING clause of aggregation query.
+// GROUP BY terms and aggregate function calls in them are converted to AggregateValueExpression
+// and the aggregate function calls are collected to Aggregates_ and AggregateTypes_
+type AggregationContext struct {
+	srcSchema       *schema.Schema
+	groupByStrs     []string
+	groupByColNames []string // lower case. empty string when the term is not a column
+	groupByTypes    []types.TypeID
+	GroupBys_       []expression.Expression // evaluated on tuples of srcSchema
+	aggStrs         []string
+	aggTypes        []types.TypeID
+	Aggregates_     []expression.Expression // evaluated on tuples of srcSchema
+	AggregateTypes_ []plans.AggregationType
+}
+
+// NewAggregationContext makes a context of aggregation over tuples of srcSchema.
+// groupByStrs are SQL text of GROUP BY terms
+func NewAggregationContext(groupByStrs []*string, srcSchema *schema.Schema) (*AggregationContext, error) {
+	ctx := &AggregationContext{srcSchema: srcSchema}
+	for _, groupByStr := range groupByStrs {
+		node, err := parseExprStr(*groupByStr)
+		if err != nil {
+			return nil, err
+		}
+		expr, exprType, err := (&exprBuilder{srcSchema, nil, nil}).exprNodeToExpression(node)
+		if err != nil {
+			return nil, err
+		}
+		colName := ""
+		if colNameExpr, ok := node.(*ast.ColumnNameExpr); ok {
+			colName = colNameExpr.Name.Name.L
+		}
+		groupByStr, err := ExprNodeToString(node)
+		if err != nil {
+			return nil, err
+		}
+		ctx.groupByStrs = append(ctx.groupByStrs, groupByStr)
+		ctx.groupByColNames = append(ctx.groupByColNames, colName)
+		ctx.groupByTypes = append(ctx.groupByTypes, exprType)
+		ctx.GroupBys_ = append(ctx.GroupBys_, expr)
+	}
+	return ctx, nil
+}
+
+// ExprStrToExpression converts SQL text of an expression which refers GROUP BY terms and aggregate functions
+// to expression.Expression which is evaluated with EvaluateAggregate
+func (ctx *AggregationContext) ExprStrToExpression(exprStr string) (expression.Expression, types.TypeID, error) {
+	node, err := parseExprStr(exprStr)
+	if err != nil {
+		return nil, types.Invalid, err
+	}
+	return (&exprBuilder{ctx.srcSchema, ctx, nil}).exprNodeToExpression(node)
+}
+
+// termToExpression converts node when it is a GROUP BY term or an aggregate function call.
+// ok is false when node should be converted as usual expression
+func (ctx *AggregationContext) termToExpression(node ast.ExprNode) (expr expression.Expression, exprType types.TypeID, ok bool, err error) {
+	nodeStr, err := ExprNodeToString(node)
+	if err != nil {
+		return nil, types.Invalid, false, err
+	}
+	colNameExpr, isColumn := node.(*ast.ColumnNameExpr)
+	for ii, groupByStr := range ctx.groupByStrs {
+		if nodeStr == groupByStr || (isColumn && colNameExpr.Name.Name.L == ctx.groupByColNames[ii]) {
+			return expression.NewAggregateValueExpression(true, uint32(ii), ctx.groupByTypes[ii]), ctx.groupByTypes[ii], true, nil
+		}
+	}
+	if isColumn {
+		return nil, types.Invalid, false, errors.New("column " + colNameExpr.Name.Name.O + " must appear in GROUP BY clause or be used in aggregate function.")
+	}
+
+	for ii, aggStr := range ctx.aggStrs {
+		if nodeStr == aggStr {
+			return expression.NewAggregateValueExpression(false, uint32(ii), ctx.aggTypes[ii]), ctx.aggTypes[ii], true, nil
+		}
+	}
+	var aggExpr expression.Expression
+	var aggType plans.AggregationType
+	switch node := node.(type) {
+	case *ast.AggregateFuncExpr:
+		if strings.ToLower(node.F) == "avg" {
+			expr, exprType, err = ctx.avgToExpression(node)
+			return expr, exprType, err == nil, err
+		}
+		aggExpr, aggType, exprType, err = ctx.builtinAggregate(node)
+	case *ast.FuncCallExpr:
+		function := expression.GetAggregateFunction(node.FnName.L)
+		if function == nil {
+			return nil, types.Invalid, false, nil
+		}
+		aggExpr, exprType, err = ctx.userAggregate(function, node.Args)
+		aggType = plans.USER_DEFINED_AGGREGATE
+	default:
+		return nil, types.Invalid, false, nil
+	}
+	if err != nil {
+		return nil, types.Invalid, false, err
+	}
+	ctx.aggStrs = append(ctx.aggStrs, nodeStr)
+	ctx.aggTypes = append(ctx.aggTypes, exprType)
+	ctx.Aggregates_ = append(ctx.Aggregates_, aggExpr)
+	ctx.AggregateTypes_ = append(ctx.AggregateTypes_, aggType)
+	return expression.NewAggregateValueExpression(false, uint32(len(ctx.aggStrs)-1), exprType), exprType, true, nil
+}
+
+// COUNT(*) is same as COUNT(1)
+func (ctx *AggregationContext) builtinAggregate(node *ast.AggregateFuncExpr) (expression.Expression, plans.AggregationType, types.TypeID, error) {
+	funcName := strings.ToLower(node.F)
+	if node.Distinct || len(node.Args) != 1 {
+		return nil, 0, types.Invalid, samehada_errors.NewParseError("", "DISTINCT and multiple arguments of "+funcName+" are not supported.")
+	}
+	arg, argType, err := (&exprBuilder{ctx.srcSchema, nil, nil}).exprNodeToExpression(node.Args[0])
+	if err != nil {
+		return nil, 0, types.Invalid, err
+	}
+	var aggType plans.AggregationType
+	switch funcName {
+	case "count":
+		return arg, plans.COUNT_AGGREGATE, types.Integer, nil
+	case "sum":
+		aggType = plans.SUM_AGGREGATE
+	case "min":
+		aggType = plans.MIN_AGGREGATE
+	case "max":
+		aggType = plans.MAX_AGGREGATE
+	default:
+		return nil, 0, types.Invalid, samehada_errors.NewParseError("", "aggregate function "+funcName+" is not supported.")
+	}
+	if argType.IsTemporal() && aggType != plans.SUM_AGGREGATE {
+		// MIN and MAX of date/time values
+		return arg, aggType, argType, nil
+	}
+	if !isNumericType(argType) {
+		return nil, 0, types.Invalid, samehada_errors.NewTypeMismatchError("argument of " + funcName + " must be numeric.")
+	}
+	return arg, aggType, argType, nil
+}
+
+// AVG(x) is evaluated as SUM(x) / COUNT(x). result is Decimal when x is Decimal. otherwise Float
+func (ctx *AggregationContext) avgToExpression(node *ast.AggregateFuncExpr) (expression.Expression, types.TypeID, error) {
+	sumExpr, sumType, _, err := ctx.termToExpression(&ast.AggregateFuncExpr{F: "sum", Args: node.Args, Distinct: node.Distinct})
+	if err != nil {
+		return nil, types.Invalid, err
+	}
+	countExpr, _, _, err := ctx.termToExpression(&ast.AggregateFuncExpr{F: "count", Args: node.Args, Distinct: node.Distinct})
+	if err != nil {
+		return nil, types.Invalid, err
+	}
+	retType := types.Float
+	if sumType == types.Decimal {
+		retType = types.Decimal
+	}
+	return expression.NewArithmeticOp(sumExpr, countExpr, expression.DIV, retType), retType, nil
+}
+
+func (ctx *AggregationContext) userAggregate(function *expression.UserAggregateFunction, argNodes []ast.ExprNode) (expression.Expression, types.TypeID, error) {
+	builder := &exprBuilder{ctx.srcSchema, nil, nil}
+	args := make([]expression.Expression, 0)
+	argTypes := make([]types.TypeID, 0)
+	for _, argNode := range argNodes {
+		arg, argType, err := builder.argNodeToExpression(argNode)
+		if err != nil {
+			return nil, types.Invalid, err
+		}
+		args = append(args, arg)
+		argTypes = append(argTypes, argType)
+	}
+	retType, err := function.ReturnType(argTypes)
+	if err != nil {
+		return nil, types.Invalid, err
+	}
+	return expression.NewUserAggregateCall(function, args, retType), retType, nil
+}
+
+// HasAggregateFunction returns true when SQL text of an expression has call of aggregate function
+func HasAggregateFunction(exprStr string) bool {
+	node, err := parseExprStr(exprStr)
+	if err != nil {
+		return false
+	}
+	finder := new(aggregateFuncFinder)
+	node.Accept(finder)
+	return finder.found
+}
+
+type aggregateFuncFinder struct {
+	found bool
+}
+
+func (v *aggregateFuncFinder) Enter(in ast.Node) (ast.Node, bool) {
+	switch node := in.(type) {
+	case *ast.AggregateFuncExpr:
+		v.found = true
+	case *ast.FuncCallExpr:
+		v.found = v.found || expression.GetAggregateFunction(node.FnName.L) != nil
+	}
+	return in, v.found
+}
+
+func (v *aggregateFuncFinder) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
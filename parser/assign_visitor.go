@@ -9,6 +9,8 @@ import (
 type AssignVisitor struct {
 	Colname_ *string
 	Value_   *types.Value
+	// first error occurred in visiting. visiting is stopped when it is set
+	err error
 }
 
 func (v *AssignVisitor) Enter(in ast.Node) (ast.Node, bool) {
@@ -22,7 +24,7 @@ func (v *AssignVisitor) Enter(in ast.Node) (ast.Node, bool) {
 		v.Colname_ = &colname
 		return in, true
 	case *driver.ValueExpr:
-		v.Value_ = ValueExprToValue(node)
+		v.Value_, v.err = ValueExprToValue(node)
 		return in, true
 	default:
 	}
@@ -31,5 +33,5 @@ func (v *AssignVisitor) Enter(in ast.Node) (ast.Node, bool) {
 }
 
 func (v *AssignVisitor) Leave(in ast.Node) (ast.Node, bool) {
-	return in, true
+	return in, v.err == nil
 }
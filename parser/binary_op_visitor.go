@@ -4,6 +4,7 @@ import (
 	"github.com/pingcap/parser/ast"
 	"github.com/pingcap/parser/opcode"
 	driver "github.com/pingcap/tidb/types/parser_driver"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/types"
 )
@@ -11,6 +12,8 @@ import (
 type BinaryOpVisitor struct {
 	QueryInfo_          *QueryInfo
 	BinaryOpExpression_ *BinaryOpExpression
+	// first error occurred in visiting. visiting is stopped when it is set
+	err error
 }
 
 func (v *BinaryOpVisitor) Enter(in ast.Node) (ast.Node, bool) {
@@ -19,12 +22,22 @@ func (v *BinaryOpVisitor) Enter(in ast.Node) (ast.Node, bool) {
 
 	switch node := in.(type) {
 	case *ast.BinaryOperationExpr:
-		l_visitor := &BinaryOpVisitor{v.QueryInfo_, new(BinaryOpExpression)}
+		l_visitor := &BinaryOpVisitor{v.QueryInfo_, new(BinaryOpExpression), nil}
 		node.L.Accept(l_visitor)
-		r_visitor := &BinaryOpVisitor{v.QueryInfo_, new(BinaryOpExpression)}
+		r_visitor := &BinaryOpVisitor{v.QueryInfo_, new(BinaryOpExpression), nil}
 		node.R.Accept(r_visitor)
+		if v.err = l_visitor.err; v.err == nil {
+			v.err = r_visitor.err
+		}
+		if v.err != nil {
+			return in, true
+		}
 
-		logicType, compType := GetTypesForBOperationExpr(node.Op)
+		logicType, compType, err := GetTypesForBOperationExpr(node.Op)
+		if err != nil {
+			v.err = err
+			return in, true
+		}
 		v.BinaryOpExpression_.LogicalOperationType_ = logicType
 		v.BinaryOpExpression_.ComparisonOperationType_ = compType
 
@@ -49,8 +62,12 @@ func (v *BinaryOpVisitor) Enter(in ast.Node) (ast.Node, bool) {
 		}
 		return in, true
 	case *ast.IsNullExpr:
-		cdv := &ChildDataVisitor{make([]interface{}, 0)}
+		cdv := &ChildDataVisitor{make([]interface{}, 0), nil}
 		node.Accept(cdv)
+		if cdv.err != nil {
+			v.err = cdv.err
+			return in, true
+		}
 
 		v.BinaryOpExpression_.LogicalOperationType_ = -1
 		if node.Not {
@@ -72,7 +89,12 @@ func (v *BinaryOpVisitor) Enter(in ast.Node) (ast.Node, bool) {
 	case *driver.ValueExpr:
 		v.BinaryOpExpression_.LogicalOperationType_ = -1
 		v.BinaryOpExpression_.ComparisonOperationType_ = -1
-		v.BinaryOpExpression_.Left_ = ValueExprToValue(node)
+		val, err := ValueExprToValue(node)
+		if err != nil {
+			v.err = err
+			return in, true
+		}
+		v.BinaryOpExpression_.Left_ = val
 		return in, true
 	default:
 	}
@@ -81,28 +103,28 @@ func (v *BinaryOpVisitor) Enter(in ast.Node) (ast.Node, bool) {
 }
 
 func (v *BinaryOpVisitor) Leave(in ast.Node) (ast.Node, bool) {
-	return in, true
+	return in, v.err == nil
 }
 
-func GetTypesForBOperationExpr(opcode_ opcode.Op) (expression.LogicalOpType, expression.ComparisonType) {
+func GetTypesForBOperationExpr(opcode_ opcode.Op) (expression.LogicalOpType, expression.ComparisonType, error) {
 	switch opcode_ {
 	case opcode.EQ:
-		return -1, expression.Equal
+		return -1, expression.Equal, nil
 	case opcode.GT:
-		return -1, expression.GreaterThan
+		return -1, expression.GreaterThan, nil
 	case opcode.GE:
-		return -1, expression.GreaterThanOrEqual
+		return -1, expression.GreaterThanOrEqual, nil
 	case opcode.LT:
-		return -1, expression.LessThan
+		return -1, expression.LessThan, nil
 	case opcode.LE:
-		return -1, expression.LessThanOrEqual
+		return -1, expression.LessThanOrEqual, nil
 	case opcode.NE:
-		return -1, expression.NotEqual
+		return -1, expression.NotEqual, nil
 	case opcode.LogicAnd:
-		return expression.AND, -1
+		return expression.AND, -1, nil
 	case opcode.LogicOr:
-		return expression.OR, -1
+		return expression.OR, -1, nil
 	default:
-		panic("unknown opcode")
+		return -1, -1, samehada_errors.NewParseError("", "not supported operator: "+opcode_.String())
 	}
 }
@@ -7,6 +7,8 @@ import (
 
 type ChildDataVisitor struct {
 	ChildDatas_ []interface{}
+	// first error occurred in visiting. visiting is stopped when it is set
+	err error
 }
 
 func (v *ChildDataVisitor) Enter(in ast.Node) (ast.Node, bool) {
@@ -19,7 +21,11 @@ func (v *ChildDataVisitor) Enter(in ast.Node) (ast.Node, bool) {
 		v.ChildDatas_ = append(v.ChildDatas_, &colname)
 		return in, true
 	case *driver.ValueExpr:
-		val := ValueExprToValue(node)
+		val, err := ValueExprToValue(node)
+		if err != nil {
+			v.err = err
+			return in, true
+		}
 		v.ChildDatas_ = append(v.ChildDatas_, val)
 		return in, true
 	default:
@@ -28,5 +34,5 @@ func (v *ChildDataVisitor) Enter(in ast.Node) (ast.Node, bool) {
 }
 
 func (v *ChildDataVisitor) Leave(in ast.Node) (ast.Node, bool) {
-	return in, true
+	return in, v.err == nil
 }
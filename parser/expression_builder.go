@@ -0,0 +1,564 @@
+package parser
+
+import (
+	"errors"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/format"
+	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/opcode"
+	driver "github.com/pingcap/tidb/types/parser_driver"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/execution/expression"
+	"github.com/ryogrid/SamehadaDB/storage/table/column"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/types"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ExprNodeToString returns SQL text of expr.
+// CHECK constraint and generation expression of generated column are stored to catalog as SQL text
+func ExprNodeToString(expr ast.ExprNode) (string, error) {
+	return nodeToString(expr)
+}
+
+func nodeToString(node ast.Node) (string, error) {
+	var sb strings.Builder
+	if err := node.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return "", errors.New("restore of node failed: " + err.Error())
+	}
+	return sb.String(), nil
+}
+
+// nodeToMessageString returns SQL text of node for error messages. text of node which can't be restored is empty
+func nodeToMessageString(node ast.Node) string {
+	ret, _ := nodeToString(node)
+	return ret
+}
+
+// ExprStrToExpression converts SQL text of an expression to expression.Expression
+// whose ColumnValue objects refer columns of schema_.
+// returned TypeID is type of the value evaluated from the expression
+func ExprStrToExpression(exprStr string, schema_ *schema.Schema) (expression.Expression, types.TypeID, error) {
+	return ExprStrToExpressionWithSequences(exprStr, schema_, nil)
+}
+
+// ExprStrToExpressionWithSequences is same as ExprStrToExpression except that NEXTVAL and CURRVAL
+// of sequences accessed with seqs can be used. they can't be used when seqs is nil
+// (ex: CHECK constraint and generation expression which must be evaluated to same value every time)
+func ExprStrToExpressionWithSequences(exprStr string, schema_ *schema.Schema, seqs expression.SequenceAccessor) (expression.Expression, types.TypeID, error) {
+	node, err := parseExprStr(exprStr)
+	if err != nil {
+		return nil, types.Invalid, err
+	}
+	return (&exprBuilder{schema_, nil, seqs}).exprNodeToExpression(node)
+}
+
+// ConstExprToValue evaluates expression which doesn't refer columns (ex: -5, 0 - 5) at parsing.
+// literal with unary minus is converted directly, so minimum value of BIGINT can be written
+func ConstExprToValue(node ast.ExprNode) (*types.Value, error) {
+	if unary, ok := node.(*ast.UnaryOperationExpr); ok && unary.Op == opcode.Minus {
+		if valExpr, ok := unary.V.(*driver.ValueExpr); ok {
+			return NegatedValueExprToValue(valExpr)
+		}
+	}
+	emptySchema := schema.NewSchema([]*column.Column{})
+	expr, _, err := (&exprBuilder{emptySchema, nil, nil}).exprNodeToExpression(node)
+	if err != nil {
+		return nil, err
+	}
+	val := expr.Evaluate(nil, emptySchema)
+	if val.IsNull() {
+		return nil, samehada_errors.NewParseError("", "value of "+nodeToMessageString(node)+" can't be calculated.")
+	}
+	return &val, nil
+}
+
+func parseExprStr(exprStr string) (ast.ExprNode, error) {
+	sqlStr := "SELECT " + exprStr + ";"
+	astNode, err := parse(&sqlStr)
+	if err != nil {
+		return nil, samehada_errors.NewParseError(exprStr, err.Error())
+	}
+	selectStmt, ok := (*astNode).(*ast.SelectStmt)
+	if !ok || selectStmt.Fields == nil || len(selectStmt.Fields.Fields) != 1 {
+		return nil, samehada_errors.NewParseError(exprStr, "invalid expression: "+exprStr)
+	}
+	return selectStmt.Fields.Fields[0].Expr, nil
+}
+
+// EqualityOperandStrs returns SQL text of both operands when exprStr is equality comparison like "a = b".
+// texts are restored in the same format as ExprNodeToString
+func EqualityOperandStrs(exprStr string) (string, string, bool) {
+	node, err := parseExprStr(exprStr)
+	if err != nil {
+		return "", "", false
+	}
+	binOp, ok := node.(*ast.BinaryOperationExpr)
+	if !ok || binOp.Op != opcode.EQ {
+		return "", "", false
+	}
+	leftStr, err := ExprNodeToString(binOp.L)
+	if err != nil {
+		return "", "", false
+	}
+	rightStr, err := ExprNodeToString(binOp.R)
+	if err != nil {
+		return "", "", false
+	}
+	return leftStr, rightStr, true
+}
+
+// exprBuilder converts ast.ExprNode to expression.Expression. when agg is not nil,
+// GROUP BY terms and aggregate function calls are converted with agg
+type exprBuilder struct {
+	schema_ *schema.Schema
+	agg     *AggregationContext
+	seqs    expression.SequenceAccessor
+}
+
+func (b *exprBuilder) exprNodeToExpression(node ast.ExprNode) (expression.Expression, types.TypeID, error) {
+	if b.agg != nil {
+		if expr, exprType, ok, err := b.agg.termToExpression(node); ok || err != nil {
+			return expr, exprType, err
+		}
+	}
+	switch node := node.(type) {
+	case *ast.ParenthesesExpr:
+		return b.exprNodeToExpression(node.Expr)
+	case *ast.ColumnNameExpr:
+		colName := node.Name.Name.String()
+		colIdx := b.schema_.GetColIndex(colName)
+		if colIdx == math.MaxUint32 && node.Name.Table.O != "" {
+			// column of joined tuples is named as "table.column"
+			colIdx = b.schema_.GetColIndex(node.Name.String())
+		}
+		if colIdx == math.MaxUint32 {
+			return nil, types.Invalid, samehada_errors.NewUnknownColumnError("", colName)
+		}
+		colType := b.schema_.GetColumn(colIdx).GetType()
+		return expression.NewColumnValue(0, colIdx, colType), colType, nil
+	case *driver.ValueExpr:
+		val, err := ValueExprToValue(node)
+		if err != nil {
+			return nil, types.Invalid, samehada_errors.NewParseError("", err.Error())
+		}
+		if val.IsNull() {
+			return nil, types.Invalid, samehada_errors.NewParseError("", "NULL literal is not supported in expression.")
+		}
+		return expression.NewConstantValue(*val, val.ValueType()), val.ValueType(), nil
+	case *ast.UnaryOperationExpr:
+		if valExpr, ok := node.V.(*driver.ValueExpr); ok && node.Op == opcode.Minus && isNumericLiteral(valExpr) {
+			// negative literal. minimum value of BIGINT can't be written as 0 - x
+			val, err := NegatedValueExprToValue(valExpr)
+			if err != nil {
+				return nil, types.Invalid, samehada_errors.NewParseError("", err.Error())
+			}
+			return expression.NewConstantValue(*val, val.ValueType()), val.ValueType(), nil
+		}
+		child, childType, err := b.exprNodeToExpression(node.V)
+		if err != nil {
+			return nil, types.Invalid, err
+		}
+		switch node.Op {
+		case opcode.Not:
+			if childType != types.Boolean {
+				return nil, types.Invalid, samehada_errors.NewTypeMismatchError("operand of NOT must be boolean.")
+			}
+			return expression.NewLogicalOp(child, nil, expression.NOT, types.Boolean), types.Boolean, nil
+		case opcode.Minus:
+			if !isNumericType(childType) {
+				return nil, types.Invalid, samehada_errors.NewTypeMismatchError("operand of unary minus must be numeric.")
+			}
+			// -x is evaluated as 0 - x
+			zero := expression.NewConstantValue(types.NewInteger(0), types.Integer)
+			return expression.NewArithmeticOp(zero, child, expression.SUB, childType), childType, nil
+		case opcode.Plus:
+			return child, childType, nil
+		}
+	case *ast.BinaryOperationExpr:
+		left, leftType, err := b.exprNodeToExpression(node.L)
+		if err != nil {
+			return nil, types.Invalid, err
+		}
+		right, rightType, err := b.exprNodeToExpression(node.R)
+		if err != nil {
+			return nil, types.Invalid, err
+		}
+		switch node.Op {
+		case opcode.LogicAnd, opcode.LogicOr:
+			if leftType != types.Boolean || rightType != types.Boolean {
+				return nil, types.Invalid, samehada_errors.NewTypeMismatchError("operands of " + node.Op.String() + " must be boolean.")
+			}
+			logicType, _, _ := GetTypesForBOperationExpr(node.Op)
+			return expression.NewLogicalOp(left, right, logicType, types.Boolean), types.Boolean, nil
+		case opcode.EQ, opcode.NE, opcode.GT, opcode.GE, opcode.LT, opcode.LE:
+			if isNumericType(leftType) && isNumericType(rightType) && leftType != rightType {
+				if leftType == types.Float || rightType == types.Float {
+					// the other side is converted to Float
+					left, leftType = toFloatExpression(left, leftType)
+					right, rightType = toFloatExpression(right, rightType)
+				} else {
+					// integer and Decimal values are compared exactly
+					rightType = leftType
+				}
+			}
+			if leftType != rightType {
+				// literal is converted to type of the other side here. invalid literal is reported as error
+				if right, rightType, err = coerceConstantForComparison(right, rightType, leftType); err != nil {
+					return nil, types.Invalid, err
+				}
+				if left, leftType, err = coerceConstantForComparison(left, leftType, rightType); err != nil {
+					return nil, types.Invalid, err
+				}
+			}
+			if leftType.IsTemporal() && rightType == types.Varchar {
+				// string is compared as date/time value
+				right, rightType = expression.NewCast(right, leftType), leftType
+			} else if leftType == types.Varchar && rightType.IsTemporal() {
+				left, leftType = expression.NewCast(left, rightType), rightType
+			} else if leftType == types.Boolean && rightType.IsIntegerFamily() {
+				// 0 is FALSE and others are TRUE
+				right, rightType = expression.NewCast(right, types.Boolean), types.Boolean
+			} else if leftType.IsIntegerFamily() && rightType == types.Boolean {
+				left, leftType = expression.NewCast(left, types.Boolean), types.Boolean
+			} else if leftType.IsNumeric() && (rightType == types.Varchar || rightType == types.JSON) {
+				// string and JSON value are compared as number. ex: doc->>'$.age' > 20
+				right, rightType = stringToNumericExpression(right, leftType), leftType
+			} else if (leftType == types.Varchar || leftType == types.JSON) && rightType.IsNumeric() {
+				left, leftType = stringToNumericExpression(left, rightType), rightType
+			} else if leftType.IsTemporal() && rightType.IsTemporal() {
+				// Date is compared as midnight of the day
+				rightType = leftType
+			} else if leftType.IsString() && rightType.IsString() {
+				// Varchar, TEXT and BLOB are compared as string
+				rightType = leftType
+			}
+			if leftType != rightType {
+				return nil, types.Invalid, samehada_errors.NewTypeMismatchError("types of compared values are " + leftType.String() + " and " + rightType.String() + ".")
+			}
+			_, compType, _ := GetTypesForBOperationExpr(node.Op)
+			return expression.NewSQLComparison(left, right, compType, types.Boolean), types.Boolean, nil
+		case opcode.Mod:
+			return funcCallToExpression("mod", []expression.Expression{left, right}, []types.TypeID{leftType, rightType})
+		case opcode.Plus, opcode.Minus, opcode.Mul, opcode.Div:
+			if !isNumericType(leftType) || !isNumericType(rightType) {
+				return nil, types.Invalid, samehada_errors.NewTypeMismatchError("operands of " + node.Op.String() + " must be numeric.")
+			}
+			opType, err := getArithmeticOpType(node.Op)
+			if err != nil {
+				return nil, types.Invalid, err
+			}
+			retType := numericResultType(leftType, rightType)
+			return expression.NewArithmeticOp(left, right, opType, retType), retType, nil
+		}
+	case *ast.FuncCallExpr:
+		if node.FnName.L == ast.DateLiteral || node.FnName.L == ast.TimestampLiteral {
+			val := TemporalFuncToValue(node)
+			if val == nil || !val.ValueType().IsTemporal() {
+				return nil, types.Invalid, samehada_errors.NewParseError("", "invalid date/time literal: "+nodeToMessageString(node))
+			}
+			return expression.NewConstantValue(*val, val.ValueType()), val.ValueType(), nil
+		}
+		if funcType, seqName, isSeqFunc, err := sequenceFuncOf(node); isSeqFunc {
+			if err != nil {
+				return nil, types.Invalid, err
+			}
+			return b.sequenceFuncToExpression(node.FnName.O, funcType, seqName)
+		}
+		args := make([]expression.Expression, 0)
+		argTypes := make([]types.TypeID, 0)
+		for _, argNode := range node.Args {
+			arg, argType, err := b.argNodeToExpression(argNode)
+			if err != nil {
+				return nil, types.Invalid, err
+			}
+			args = append(args, arg)
+			argTypes = append(argTypes, argType)
+		}
+		if node.FnName.L == ast.JSONExtract && len(args) == 2 {
+			// constant path is validated here because invalid path is evaluated as NULL
+			if path, ok := args[1].(*expression.ConstantValue); ok && argTypes[1] == types.Varchar {
+				if err := types.ValidateJSONPath(path.Evaluate(nil, nil).ToVarchar()); err != nil {
+					return nil, types.Invalid, err
+				}
+			}
+		}
+		return funcCallToExpression(node.FnName.L, args, argTypes)
+	case *ast.FuncCastExpr:
+		child, _, err := b.argNodeToExpression(node.Expr)
+		if err != nil {
+			return nil, types.Invalid, err
+		}
+		castType, ok := castTargetType(node.Tp.Tp)
+		if !ok {
+			return nil, types.Invalid, samehada_errors.NewParseError("", "not supported type of CAST: "+nodeToMessageString(node))
+		}
+		if castType == types.Decimal && node.Tp.Flen >= 0 {
+			precision, scale := int32(node.Tp.Flen), int32(types.DecimalDefaultScale)
+			if node.Tp.Decimal >= 0 {
+				scale = int32(node.Tp.Decimal)
+			}
+			if err := types.ValidateDecimalPrecisionAndScale(precision, scale); err != nil {
+				return nil, types.Invalid, err
+			}
+			typeName := "DECIMAL(" + strconv.Itoa(int(precision)) + ", " + strconv.Itoa(int(scale)) + ")"
+			return castConstant(expression.NewDecimalCast(child, precision, scale), child, castType, typeName)
+		}
+		return castConstant(expression.NewCast(child, castType), child, castType, castType.String())
+	case *ast.CaseExpr:
+		return b.caseExprToExpression(node)
+	case *ast.IsTruthExpr:
+		child, childType, err := b.exprNodeToExpression(node.Expr)
+		if err != nil {
+			return nil, types.Invalid, err
+		}
+		if childType != types.Boolean {
+			return nil, types.Invalid, samehada_errors.NewTypeMismatchError("operand of IS TRUE and IS FALSE must be boolean.")
+		}
+		// NULL is not equal to non-NULL value. so x IS TRUE is FALSE and x IS NOT TRUE is TRUE when x is NULL
+		compType := expression.Equal
+		if node.Not {
+			compType = expression.NotEqual
+		}
+		truth := expression.NewConstantValue(types.NewBoolean(node.True != 0), types.Boolean)
+		return expression.NewComparison(child, truth, compType, types.Boolean), types.Boolean, nil
+	case *ast.IsNullExpr:
+		child, childType, err := b.exprNodeToExpression(node.Expr)
+		if err != nil {
+			return nil, types.Invalid, err
+		}
+		compType := expression.Equal
+		if node.Not {
+			compType = expression.NotEqual
+		}
+		nullVal := expression.NewConstantValue(types.NewNullOfType(childType), childType)
+		return expression.NewComparison(child, nullVal, compType, types.Boolean), types.Boolean, nil
+	}
+	return nil, types.Invalid, samehada_errors.NewParseError("", "not supported expression: "+nodeToMessageString(node))
+}
+
+// argNodeToExpression is same as exprNodeToExpression except that NULL literal is allowed.
+// type of NULL literal is types.Invalid
+func (b *exprBuilder) argNodeToExpression(node ast.ExprNode) (expression.Expression, types.TypeID, error) {
+	if valueExpr, ok := node.(*driver.ValueExpr); ok {
+		val, err := ValueExprToValue(valueExpr)
+		if err != nil {
+			return nil, types.Invalid, samehada_errors.NewParseError("", err.Error())
+		}
+		if val.IsNull() {
+			return expression.NewConstantValue(*val, types.Invalid), types.Invalid, nil
+		}
+	}
+	if timeUnitExpr, ok := node.(*ast.TimeUnitExpr); ok {
+		// unit of EXTRACT and INTERVAL is passed as lower case string
+		unit := strings.ToLower(timeUnitExpr.Unit.String())
+		if !types.IsSupportedTimeUnit(unit) {
+			return nil, types.Invalid, samehada_errors.NewParseError("", "time unit "+timeUnitExpr.Unit.String()+" is not supported.")
+		}
+		return expression.NewConstantValue(types.NewVarchar(unit), types.Varchar), types.Varchar, nil
+	}
+	return b.exprNodeToExpression(node)
+}
+
+// sequenceFuncToExpression makes NEXTVAL or CURRVAL call. existence of the sequence is checked at planning
+func (b *exprBuilder) sequenceFuncToExpression(funcName string, funcType SequenceFuncType, seqName string) (expression.Expression, types.TypeID, error) {
+	if b.seqs == nil {
+		return nil, types.Invalid, samehada_errors.NewParseError("", funcName+" can't be used here.")
+	}
+	if !b.seqs.SequenceExists(seqName) {
+		return nil, types.Invalid, errors.New("sequence " + seqName + " does not exist.")
+	}
+	return expression.NewSequenceFuncCall(funcType == NEXTVAL, seqName, b.seqs), types.BigInt, nil
+}
+
+// funcCallToExpression makes call of scalar function which is registered with name
+func funcCallToExpression(name string, args []expression.Expression, argTypes []types.TypeID) (expression.Expression, types.TypeID, error) {
+	function := expression.GetScalarFunction(name)
+	if function == nil {
+		return nil, types.Invalid, samehada_errors.NewParseError("", "function "+name+" is not supported.")
+	}
+	retType, err := function.ReturnType(argTypes)
+	if err != nil {
+		return nil, types.Invalid, err
+	}
+	return expression.NewFuncCall(function, args, retType), retType, nil
+}
+
+// CASE x WHEN v THEN ... is converted to CASE WHEN x = v THEN ...
+func (b *exprBuilder) caseExprToExpression(node *ast.CaseExpr) (expression.Expression, types.TypeID, error) {
+	var value expression.Expression = nil
+	valueType := types.Invalid
+	if node.Value != nil {
+		var err error
+		if value, valueType, err = b.exprNodeToExpression(node.Value); err != nil {
+			return nil, types.Invalid, err
+		}
+	}
+
+	conditions := make([]expression.Expression, 0)
+	results := make([]expression.Expression, 0)
+	resultTypes := make([]types.TypeID, 0)
+	for _, when := range node.WhenClauses {
+		cond, condType, err := b.exprNodeToExpression(when.Expr)
+		if err != nil {
+			return nil, types.Invalid, err
+		}
+		if value != nil {
+			left, leftType := value, valueType
+			if isNumericType(leftType) && isNumericType(condType) && leftType != condType {
+				if leftType == types.Float || condType == types.Float {
+					left, leftType = toFloatExpression(left, leftType)
+					cond, condType = toFloatExpression(cond, condType)
+				} else {
+					condType = leftType
+				}
+			}
+			if leftType != condType {
+				return nil, types.Invalid, samehada_errors.NewTypeMismatchError("types of compared values are " + leftType.String() + " and " + condType.String() + ".")
+			}
+			cond, condType = expression.NewSQLComparison(left, cond, expression.Equal, types.Boolean), types.Boolean
+		}
+		if condType != types.Boolean {
+			return nil, types.Invalid, samehada_errors.NewTypeMismatchError("condition of WHEN must be boolean.")
+		}
+		result, resultType, err := b.argNodeToExpression(when.Result)
+		if err != nil {
+			return nil, types.Invalid, err
+		}
+		conditions = append(conditions, cond)
+		results = append(results, result)
+		resultTypes = append(resultTypes, resultType)
+	}
+
+	var elseResult expression.Expression = nil
+	if node.ElseClause != nil {
+		var elseType types.TypeID
+		var err error
+		if elseResult, elseType, err = b.argNodeToExpression(node.ElseClause); err != nil {
+			return nil, types.Invalid, err
+		}
+		resultTypes = append(resultTypes, elseType)
+	}
+
+	retType := types.Invalid
+	for _, resultType := range resultTypes {
+		switch {
+		case resultType == types.Invalid || resultType == retType:
+		case retType == types.Invalid:
+			retType = resultType
+		case isNumericType(resultType) && isNumericType(retType):
+			retType = numericResultType(resultType, retType)
+		default:
+			return nil, types.Invalid, samehada_errors.NewTypeMismatchError("results of CASE must have same type.")
+		}
+	}
+	if retType == types.Invalid {
+		return nil, types.Invalid, errors.New("type of CASE can not be decided.")
+	}
+	return expression.NewCaseWhen(conditions, results, elseResult, retType), retType, nil
+}
+
+// castTargetType returns type corresponding to type name of CAST(x AS type)
+func castTargetType(tp byte) (types.TypeID, bool) {
+	switch tp {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		return types.Integer, true
+	case mysql.TypeFloat, mysql.TypeDouble:
+		return types.Float, true
+	case mysql.TypeNewDecimal:
+		return types.Decimal, true
+	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString:
+		return types.Varchar, true
+	case mysql.TypeTimestamp, mysql.TypeDatetime:
+		return types.Timestamp, true
+	case mysql.TypeDate:
+		return types.Date, true
+	case mysql.TypeJSON:
+		return types.JSON, true
+	}
+	return types.Invalid, false
+}
+
+// castConstant evaluates CAST of a constant at plan time so that the value which can not be
+// converted is reported as error. cast is returned as it is when child is not a constant
+func castConstant(cast expression.Expression, child expression.Expression, castType types.TypeID, typeName string) (expression.Expression, types.TypeID, error) {
+	constant, ok := child.(*expression.ConstantValue)
+	if !ok {
+		return cast, castType, nil
+	}
+	val := constant.Evaluate(nil, nil)
+	if _, err := expression.ConvertValue(val, castType); err != nil {
+		return nil, types.Invalid, err
+	}
+	ret := cast.Evaluate(nil, nil)
+	if ret.IsNull() {
+		// converted value doesn't fit to precision of DECIMAL(p, s)
+		return nil, types.Invalid, samehada_errors.NewTypeMismatchError("value " + val.ToString() + " is out of range of " + typeName + ".")
+	}
+	return expression.NewConstantValue(ret, castType), castType, nil
+}
+
+// coerceConstantForComparison converts expr to otherType when expr is a constant compared with value of otherType.
+// constant compared with JSON value is not converted because JSON value is converted to type of the constant
+func coerceConstantForComparison(expr expression.Expression, exprType types.TypeID, otherType types.TypeID) (expression.Expression, types.TypeID, error) {
+	constant, ok := expr.(*expression.ConstantValue)
+	if !ok || otherType == types.JSON {
+		return expr, exprType, nil
+	}
+	val, err := expression.CoerceLiteralForComparison(constant.Evaluate(nil, nil), otherType)
+	if err != nil {
+		return nil, types.Invalid, err
+	}
+	return expression.NewConstantValue(val, val.ValueType()), val.ValueType(), nil
+}
+
+func isNumericType(typeId types.TypeID) bool {
+	return typeId.IsNumeric()
+}
+
+// numericResultType returns type of calculation result of two numeric values.
+// it is Float if either side is Float, Decimal if either side is Decimal. otherwise wider integer type
+func numericResultType(leftType types.TypeID, rightType types.TypeID) types.TypeID {
+	if leftType == types.Float || rightType == types.Float {
+		return types.Float
+	}
+	if leftType == types.Decimal || rightType == types.Decimal {
+		return types.Decimal
+	}
+	return types.WiderIntegerType(leftType, rightType)
+}
+
+// stringToNumericExpression converts string expr to number which is compared with value of numType.
+// it is converted to Decimal for exact comparison unless numType is Float. invalid number becomes NULL
+func stringToNumericExpression(expr expression.Expression, numType types.TypeID) expression.Expression {
+	if numType == types.Float {
+		return expression.NewCast(expr, types.Float)
+	}
+	return expression.NewCast(expr, types.Decimal)
+}
+
+func toFloatExpression(expr expression.Expression, exprType types.TypeID) (expression.Expression, types.TypeID) {
+	if exprType == types.Float {
+		return expr, exprType
+	}
+	// x + 0.0 is evaluated as Float
+	zero := expression.NewConstantValue(types.NewFloat(0), types.Float)
+	return expression.NewArithmeticOp(expr, zero, expression.ADD, types.Float), types.Float
+}
+
+func getArithmeticOpType(opcode_ opcode.Op) (expression.ArithmeticOpType, error) {
+	switch opcode_ {
+	case opcode.Plus:
+		return expression.ADD, nil
+	case opcode.Minus:
+		return expression.SUB, nil
+	case opcode.Mul:
+		return expression.MUL, nil
+	case opcode.Div:
+		return expression.DIV, nil
+	default:
+		return 0, samehada_errors.NewParseError("", "not supported operator: "+opcode_.String())
+	}
+}
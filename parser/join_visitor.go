@@ -6,6 +6,8 @@ import (
 
 type JoinVisitor struct {
 	QueryInfo_ *QueryInfo
+	// first error occurred in visiting. visiting is stopped when it is set
+	err error
 }
 
 func (v *JoinVisitor) Enter(in ast.Node) (ast.Node, bool) {
@@ -18,8 +20,9 @@ func (v *JoinVisitor) Enter(in ast.Node) (ast.Node, bool) {
 		v.QueryInfo_.JoinTables_ = append(v.QueryInfo_.JoinTables_, &tblname)
 		return in, true
 	case *ast.BinaryOperationExpr:
-		bv := &BinaryOpVisitor{v.QueryInfo_, new(BinaryOpExpression)}
+		bv := &BinaryOpVisitor{v.QueryInfo_, new(BinaryOpExpression), nil}
 		node.Accept(bv)
+		v.err = bv.err
 		v.QueryInfo_.OnExpressions_ = bv.BinaryOpExpression_
 		return in, true
 	default:
@@ -28,5 +31,5 @@ func (v *JoinVisitor) Enter(in ast.Node) (ast.Node, bool) {
 }
 
 func (v *JoinVisitor) Leave(in ast.Node) (ast.Node, bool) {
-	return in, true
+	return in, v.err == nil
 }
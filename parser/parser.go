@@ -1,55 +1,393 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"github.com/pingcap/parser"
 	"github.com/pingcap/parser/ast"
 	_ "github.com/pingcap/tidb/types/parser_driver"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/execution/plans"
 	"github.com/ryogrid/SamehadaDB/types"
+	"regexp"
+	"strings"
 )
 
 type QueryInfo struct {
-	QueryType_           *QueryType
-	SelectFields_        []*SelectFieldExpression // SELECT
-	SetExpressions_      []*SetExpression         // UPDATE
-	NewTable_            *string                  // CREATE TABLE
-	ColDefExpressions_   []*ColDefExpression      // CREATE TABLE
-	IndexDefExpressions_ []*IndexDefExpression    // CREATE TABLE
-	TargetCols_          []*string                // INSERT
-	Values_              []*types.Value           // INSERT
-	OnExpressions_       *BinaryOpExpression      // SELECT (with JOIN)
-	JoinTables_          []*string                // SELECT
-	WhereExpression_     *BinaryOpExpression      // SELECT, UPDATE, DELETE
-	LimitNum_            int32                    // SELECT
-	OffsetNum_           int32                    // SELECT
-	OrderByExpressions_  []*OrderByExpression     // SELECT
+	QueryType_                *QueryType
+	SelectFields_             []*SelectFieldExpression   // SELECT
+	SetExpressions_           []*SetExpression           // UPDATE
+	NewTable_                 *string                    // CREATE TABLE
+	ColDefExpressions_        []*ColDefExpression        // CREATE TABLE
+	IndexDefExpressions_      []*IndexDefExpression      // CREATE TABLE, CREATE INDEX
+	ForeignKeyDefExpressions_ []*ForeignKeyDefExpression // CREATE TABLE
+	CheckExprs_               []*string                  // CREATE TABLE (SQL text of CHECK constraints specified as table constraint)
+	TargetCols_               []*string                  // INSERT
+	Values_                   []*types.Value             // INSERT
+	InsertSelectSQL_          *string                    // INSERT (SQL text of SELECT statement of INSERT ... SELECT)
+	UpsertSetExpressions_     []*SetExpression           // INSERT (ON DUPLICATE KEY UPDATE)
+	OnConflictDoNothing_      bool                       // INSERT (ON CONFLICT DO NOTHING, INSERT IGNORE)
+	OnExpressions_            *BinaryOpExpression        // SELECT (with JOIN)
+	JoinTables_               []*string                  // SELECT, ALTER TABLE, CREATE INDEX
+	WhereExpression_          *BinaryOpExpression        // SELECT, UPDATE, DELETE
+	LimitNum_                 int32                      // SELECT
+	OffsetNum_                int32                      // SELECT
+	OrderByExpressions_       []*OrderByExpression       // SELECT
+	AlterTableExpressions_    []*AlterTableExpression    // ALTER TABLE
+	SequenceDef_              *SequenceDefExpression     // CREATE SEQUENCE
+	SequenceFuncExpressions_  []*SequenceFuncExpression  // INSERT
+	ViewDef_                  *ViewDefExpression         // CREATE [MATERIALIZED] VIEW, REFRESH MATERIALIZED VIEW
+	ReturningExprs_           []*ReturningExpression     // INSERT, UPDATE, DELETE
+	CTEs_                     []*CTEExpression           // SELECT (WITH clause)
+	// SELECT (UNION, INTERSECT, EXCEPT). ORDER BY and LIMIT are applied to combined result
+	SetOperation_ *SetOperationExpression
+	// SELECT, UPDATE, DELETE (SQL text of WHERE clause which can not be represented with WhereExpression_.
+	// for example, it has function calls or arithmetic operations)
+	WhereExprStr_ *string
+	// SELECT (SQL text of terms of GROUP BY clause and HAVING clause)
+	GroupByExprStrs_ []*string
+	HavingExprStr_   *string
 }
 
-func extractInfoFromAST(rootNode *ast.StmtNode) *QueryInfo {
+func extractInfoFromAST(rootNode *ast.StmtNode) (*QueryInfo, error) {
 	v := NewRootSQLVisitor()
 	(*rootNode).Accept(v)
-	return v.QueryInfo_
+	return v.QueryInfo_, v.err
 }
 
 func parse(sqlStr *string) (*ast.StmtNode, error) {
 	p := parser.New()
+	// OVER clause is not parsed without this
+	p.EnableWindowFunc(true)
 
 	stmtNodes, _, err := p.Parse(*sqlStr, "", "")
 	if err != nil {
 		return nil, err
 	}
+	if len(stmtNodes) == 0 {
+		return nil, errors.New("statement is empty.")
+	}
 
 	return &stmtNodes[0], nil
 }
 
-func ProcessSQLStr(sqlStr *string) *QueryInfo {
+// statements about materialized view, ON CONFLICT clause, RETURNING clause and WITH clause are not supported
+// by the SQL parser. so they are rewritten or processed before parsing
+var withClauseRegexp = regexp.MustCompile(`(?is)^\s*WITH\s+(RECURSIVE\s+)?`)
+var selectStmtRegexp = regexp.MustCompile(`(?is)^\s*(\(|SELECT\b)`)
+var cteHeadRegexp = regexp.MustCompile(`(?is)^\s*` + "`?" + `(\w+)` + "`?" + `\s*(\(([^)]*)\))?\s*AS\s*\(`)
+var createMaterializedViewRegexp = regexp.MustCompile(`(?is)^\s*CREATE\s+MATERIALIZED\s+VIEW\s+`)
+var returningRegexp = regexp.MustCompile(`(?is)^(\s*(?:INSERT|UPDATE|DELETE)\s.*?)\s+RETURNING\s+(.+?)\s*;?\s*$`)
+var onConflictDoNothingRegexp = regexp.MustCompile(`(?is)\s+ON\s+CONFLICT\s*(\([^)]*\))?\s*DO\s+NOTHING\s*(;\s*)?$`)
+var refreshMaterializedViewRegexp = regexp.MustCompile(`(?is)^\s*REFRESH\s+MATERIALIZED\s+VIEW\s+` + "`?" + `(\w+)` + "`?" + `\s*;?\s*$`)
+
+// sequence name passed as string literal (NEXTVAL('seq')) is rewritten to identifier
+// because the SQL parser accepts only table name as argument of NEXTVAL
+var quotedSequenceNameRegexp = regexp.MustCompile(`(?i)\b(NEXTVAL|CURRVAL|LASTVAL)\s*\(\s*'(\w+)'\s*\)`)
+
+// ProcessSQLStr parses sqlStr and returns information of the statement.
+// *samehada_errors.ParseError is returned when sqlStr is invalid or the statement is not supported
+func ProcessSQLStr(sqlStr *string) (*QueryInfo, error) {
+	origSQL := *sqlStr
+	if quotedSequenceNameRegexp.MatchString(*sqlStr) {
+		rewritten := quotedSequenceNameRegexp.ReplaceAllString(*sqlStr, "$1(`$2`)")
+		sqlStr = &rewritten
+	}
+	if matched := refreshMaterializedViewRegexp.FindStringSubmatch(*sqlStr); matched != nil {
+		qinfo := NewRootSQLVisitor().QueryInfo_
+		*qinfo.QueryType_ = REFRESH_MATERIALIZED_VIEW
+		viewName := matched[1]
+		qinfo.ViewDef_ = &ViewDefExpression{ViewName_: &viewName, IsMaterialized_: true}
+		return qinfo, nil
+	}
+	var ctes []*CTEExpression = nil
+	if withClauseRegexp.MatchString(*sqlStr) {
+		var err error
+		var mainSQL string
+		if ctes, mainSQL, err = extractCTEs(*sqlStr); err != nil {
+			return nil, samehada_errors.NewParseError(origSQL, err.Error())
+		}
+		sqlStr = &mainSQL
+	}
+	if selectStmtRegexp.MatchString(*sqlStr) {
+		if setOp, tail := extractSetOperation(*sqlStr); setOp != nil {
+			// ORDER BY and LIMIT of combined result are parsed with dummy SELECT statement
+			dummySQL := "SELECT * FROM dummy " + tail + ";"
+			qinfo, err := ProcessSQLStr(&dummySQL)
+			if err != nil {
+				return nil, samehada_errors.NewParseError(origSQL, err.(*samehada_errors.ParseError).Msg)
+			}
+			qinfo.JoinTables_ = make([]*string, 0)
+			qinfo.SetOperation_ = setOp
+			qinfo.CTEs_ = ctes
+			return qinfo, nil
+		}
+	}
+	isMaterialized := false
+	if loc := createMaterializedViewRegexp.FindStringIndex(*sqlStr); loc != nil {
+		rewritten := "CREATE VIEW " + (*sqlStr)[loc[1]:]
+		sqlStr = &rewritten
+		isMaterialized = true
+	}
+
+	var returningExprs []*ReturningExpression = nil
+	if matched := returningRegexp.FindStringSubmatch(*sqlStr); matched != nil {
+		var err error
+		if returningExprs, err = parseReturningExprs(matched[2]); err != nil {
+			return nil, samehada_errors.NewParseError(origSQL, err.Error())
+		}
+		rewritten := matched[1] + ";"
+		sqlStr = &rewritten
+	}
+
+	isDoNothing := false
+	if loc := onConflictDoNothingRegexp.FindStringIndex(*sqlStr); loc != nil {
+		rewritten := (*sqlStr)[:loc[0]] + ";"
+		sqlStr = &rewritten
+		isDoNothing = true
+	}
+
 	astNode, err := parse(sqlStr)
 	if err != nil {
-		fmt.Printf("parse error: %v\n", err.Error())
-		return nil
+		return nil, samehada_errors.NewParseError(origSQL, err.Error())
+	}
+	if !isSupportedStmt(*astNode) {
+		return nil, samehada_errors.NewParseError(origSQL, "not supported statement.")
 	}
 
-	return extractInfoFromAST(astNode)
+	qinfo, err := extractInfoFromAST(astNode)
+	if err != nil {
+		return nil, samehada_errors.NewParseError(origSQL, errorMessage(err))
+	}
+	if isMaterialized {
+		qinfo.ViewDef_.IsMaterialized_ = true
+	}
+	if isDoNothing {
+		qinfo.OnConflictDoNothing_ = true
+	}
+	qinfo.ReturningExprs_ = returningExprs
+	qinfo.CTEs_ = ctes
+	return qinfo, nil
+}
+
+// errorMessage returns message of err without "parse error: " prefix of ParseError
+func errorMessage(err error) string {
+	if parseErr, ok := err.(*samehada_errors.ParseError); ok {
+		return parseErr.Msg
+	}
+	return err.Error()
+}
+
+// isSupportedStmt returns true when QueryInfo can be extracted from the statement
+func isSupportedStmt(stmt ast.StmtNode) bool {
+	switch stmt.(type) {
+	case *ast.SelectStmt, *ast.CreateTableStmt, *ast.InsertStmt, *ast.DeleteStmt, *ast.UpdateStmt,
+		*ast.AlterTableStmt, *ast.CreateSequenceStmt, *ast.CreateViewStmt, *ast.CreateIndexStmt:
+		return true
+	}
+	return false
+}
+
+// extractCTEs returns definitions of CTEs in WITH clause and SQL text of the statement which follows the clause.
+// body of recursive CTE is split to anchor part and recursive part at first UNION [ALL]
+func extractCTEs(sqlStr string) ([]*CTEExpression, string, error) {
+	loc := withClauseRegexp.FindStringSubmatchIndex(sqlStr)
+	isRecursive := loc[2] != -1
+	pos := loc[1]
+
+	ctes := make([]*CTEExpression, 0)
+	for {
+		matched := cteHeadRegexp.FindStringSubmatch(sqlStr[pos:])
+		if matched == nil {
+			return nil, "", errors.New("invalid WITH clause: " + sqlStr)
+		}
+		bodyStart := pos + len(matched[0])
+		bodyEnd := findClosingParen(sqlStr, bodyStart)
+		if bodyEnd == -1 {
+			return nil, "", errors.New("parenthesis is not closed in WITH clause: " + sqlStr)
+		}
+
+		name := matched[1]
+		cte := &CTEExpression{Name_: &name, ColNames_: make([]*string, 0)}
+		if matched[2] != "" {
+			for _, colNameStr := range strings.Split(matched[3], ",") {
+				colName := strings.Trim(strings.TrimSpace(colNameStr), "`")
+				cte.ColNames_ = append(cte.ColNames_, &colName)
+			}
+		}
+		body := strings.TrimSpace(sqlStr[bodyStart:bodyEnd])
+		cte.SelectSQL_ = &body
+		if isRecursive {
+			if loc := findTopLevelKeyword(body, setOperationRegexp); loc != nil && strings.EqualFold(body[loc[2]:loc[3]], "UNION") {
+				anchorSQL := strings.TrimSpace(body[:loc[0]])
+				recursiveSQL := strings.TrimSpace(body[loc[1]:])
+				isAll := loc[6] != -1 && strings.EqualFold(body[loc[6]:loc[7]], "ALL")
+				// CTE which does not reference itself is not recursive even if RECURSIVE is specified
+				if regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(name) + `\b`).MatchString(recursiveSQL) {
+					cte.SelectSQL_ = &anchorSQL
+					cte.RecursiveSQL_ = &recursiveSQL
+					cte.IsUnionAll_ = isAll
+				}
+			}
+		}
+		ctes = append(ctes, cte)
+
+		pos = bodyEnd + 1
+		rest := strings.TrimLeft(sqlStr[pos:], " \t\r\n")
+		if !strings.HasPrefix(rest, ",") {
+			return ctes, rest, nil
+		}
+		pos = len(sqlStr) - len(rest) + 1
+	}
+}
+
+// findClosingParen returns index of the parenthesis which closes the one just before start.
+// parentheses in quoted strings are ignored. -1 is returned when it is not found
+func findClosingParen(str string, start int) int {
+	depth := 1
+	var quote byte = 0
+	for ii := start; ii < len(str); ii++ {
+		ch := str[ii]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				return ii
+			}
+		}
+	}
+	return -1
+}
+
+var setOperationRegexp = regexp.MustCompile(`(?i)^(UNION|INTERSECT|EXCEPT)(\s+(ALL|DISTINCT))?\b`)
+var orderByOrLimitRegexp = regexp.MustCompile(`(?i)^(ORDER\s+BY|LIMIT)\b`)
+
+// findTopLevelKeyword returns match of keywordRegexp which is found first at head of a word and
+// not in parentheses or quoted strings. returned indexes are same format with FindStringSubmatchIndex.
+// nil is returned when it is not found
+func findTopLevelKeyword(str string, keywordRegexp *regexp.Regexp) []int {
+	depth := 0
+	var quote byte = 0
+	for ii := 0; ii < len(str); ii++ {
+		ch := str[ii]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case depth == 0 && (ii == 0 || !isWordChar(str[ii-1])):
+			if loc := keywordRegexp.FindStringSubmatchIndex(str[ii:]); loc != nil {
+				for jj := range loc {
+					if loc[jj] != -1 {
+						loc[jj] += ii
+					}
+				}
+				return loc
+			}
+		}
+	}
+	return nil
+}
+
+// extractSetOperation splits SELECT statements combined with UNION, INTERSECT or EXCEPT.
+// ORDER BY and LIMIT after the last statement are applied to combined result. they are returned as tail.
+// nil is returned when sqlStr has no set operation
+func extractSetOperation(sqlStr string) (*SetOperationExpression, string) {
+	rest := strings.TrimRight(strings.TrimSpace(sqlStr), "; \t\r\n")
+	setOp := &SetOperationExpression{make([]*string, 0), make([]plans.SetOperationType, 0), make([]bool, 0)}
+	for {
+		loc := findTopLevelKeyword(rest, setOperationRegexp)
+		if loc == nil {
+			break
+		}
+		operand := trimParens(rest[:loc[0]])
+		setOp.SelectSQLs_ = append(setOp.SelectSQLs_, &operand)
+		switch strings.ToUpper(rest[loc[2]:loc[3]]) {
+		case "UNION":
+			setOp.Operations_ = append(setOp.Operations_, plans.UNION_OPERATION)
+		case "INTERSECT":
+			setOp.Operations_ = append(setOp.Operations_, plans.INTERSECT_OPERATION)
+		case "EXCEPT":
+			setOp.Operations_ = append(setOp.Operations_, plans.EXCEPT_OPERATION)
+		}
+		setOp.IsAll_ = append(setOp.IsAll_, loc[6] != -1 && strings.EqualFold(rest[loc[6]:loc[7]], "ALL"))
+		rest = rest[loc[1]:]
+	}
+	if len(setOp.Operations_) == 0 {
+		return nil, ""
+	}
+
+	tail := ""
+	if loc := findTopLevelKeyword(rest, orderByOrLimitRegexp); loc != nil {
+		tail = rest[loc[0]:]
+		rest = rest[:loc[0]]
+	}
+	operand := trimParens(rest)
+	setOp.SelectSQLs_ = append(setOp.SelectSQLs_, &operand)
+	return setOp, tail
+}
+
+// trimParens removes white spaces and parentheses which enclose whole of str
+func trimParens(str string) string {
+	str = strings.TrimSpace(str)
+	for strings.HasPrefix(str, "(") && findClosingParen(str, 1) == len(str)-1 {
+		str = strings.TrimSpace(str[1 : len(str)-1])
+	}
+	return str
+}
+
+func isWordChar(ch byte) bool {
+	return ch == '_' || ('0' <= ch && ch <= '9') || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z')
+}
+
+// expressions of RETURNING clause are parsed as select fields
+func parseReturningExprs(exprsStr string) ([]*ReturningExpression, error) {
+	sqlStr := "SELECT " + exprsStr + ";"
+	astNode, err := parse(&sqlStr)
+	if err != nil {
+		return nil, err
+	}
+	selectStmt, ok := (*astNode).(*ast.SelectStmt)
+	if !ok || selectStmt.Fields == nil || selectStmt.From != nil {
+		return nil, errors.New("invalid RETURNING clause: " + exprsStr)
+	}
+
+	ret := make([]*ReturningExpression, 0)
+	for _, field := range selectStmt.Fields.Fields {
+		if field.WildCard != nil {
+			exprStr := "*"
+			ret = append(ret, &ReturningExpression{&exprStr, nil})
+			continue
+		}
+		exprStr, err := nodeToString(field.Expr)
+		if err != nil {
+			return nil, err
+		}
+		alias := field.AsName.O
+		if alias == "" {
+			if colNameExpr, ok := field.Expr.(*ast.ColumnNameExpr); ok {
+				alias = colNameExpr.Name.Name.O
+			} else {
+				alias = strings.TrimSpace(field.Text())
+			}
+		}
+		ret = append(ret, &ReturningExpression{&exprStr, &alias})
+	}
+	return ret, nil
 }
 
 // for utity func on develop phase
@@ -3,6 +3,7 @@ package parser
 import (
 	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/column"
 	"github.com/ryogrid/SamehadaDB/types"
 )
 
@@ -16,23 +17,134 @@ type BinaryOpExpression struct {
 type SetExpression struct {
 	ColName_     *string
 	UpdateValue_ *types.Value
+	// UPDATE. SQL text of the value when it is not a literal (ex: NEXTVAL(seq), col + 1).
+	// it is evaluated for each tuple and UpdateValue_ is nil
+	UpdateExprStr_ *string
+	// ON DUPLICATE KEY UPDATE col = VALUES(ValuesOf_). nil when UpdateValue_ is used
+	ValuesOf_ *string
 }
 
 type ColDefExpression struct {
-	ColName_ *string
-	ColType_ *types.TypeID
+	ColName_         *string
+	ColType_         *types.TypeID
+	IsPrimaryKey_    bool
+	IsUnique_        bool
+	IsNotNull_       bool
+	DefaultValue_    *types.Value             // nil if DEFAULT is not specified
+	DefaultSeq_      *string                  // name of sequence when DEFAULT is NEXTVAL of it. DefaultValue_ is nil then
+	ForeignKey_      *ForeignKeyDefExpression // nil if REFERENCES is not specified
+	CheckExpr_       *string                  // SQL text of CHECK constraint. nil if not specified
+	GeneratedExpr_   *string                  // SQL text of generation expression. nil if the column is not generated column
+	IsAutoIncrement_ bool
+	// precision and scale of DECIMAL column
+	DecimalPrecision_ int32
+	DecimalScale_     int32
+}
+
+type AlterTableExpression struct {
+	AlterType_ AlterTableType
+	ColDef_    *ColDefExpression // ADD COLUMN
+	ColName_   *string           // DROP COLUMN, RENAME COLUMN
+	NewName_   *string           // RENAME COLUMN, RENAME TABLE
 }
 
 type IndexDefExpression struct {
-	IndexName_ *string
-	Colnames_  []*string
+	IndexName_    *string
+	Colnames_     []*string
+	KeyExprStrs_  []*string // SQL text of key parts which are expression like INDEX ((doc->>'$.name'))
+	IsPrimaryKey_ bool      // PRIMARY KEY (...) constraint
+	IsUnique_     bool      // UNIQUE (...) constraint
+}
+
+type ForeignKeyDefExpression struct {
+	Colnames_    []*string // referencing columns
+	RefTable_    *string
+	RefColnames_ []*string
+	OnDelete_    column.ReferentialAction
+	OnUpdate_    column.ReferentialAction
+}
+
+type SequenceDefExpression struct {
+	SeqName_     *string
+	StartWith_   *int64 // nil if START WITH is not specified
+	IncrementBy_ int64
+	// MINVALUE, MAXVALUE, CYCLE and other options are not supported
+	HasUnsupportedOption_ bool
+}
+
+type ViewDefExpression struct {
+	ViewName_       *string
+	SelectSQL_      *string // SQL text of SELECT statement. nil at REFRESH MATERIALIZED VIEW
+	IsMaterialized_ bool
+	HasColumnList_  bool // column list of view is not supported
+}
+
+// a common table expression defined in WITH clause
+type CTEExpression struct {
+	Name_     *string
+	ColNames_ []*string // names of output columns. empty when not specified
+	// SQL text of SELECT statement. it is the non-recursive (anchor) part when the CTE is recursive
+	SelectSQL_ *string
+	// SQL text of SELECT statement which references the CTE itself. nil when the CTE is not recursive
+	RecursiveSQL_ *string
+	IsUnionAll_   bool // anchor part and recursive part are combined with UNION ALL
+}
+
+// SELECT statements combined with UNION, INTERSECT or EXCEPT.
+// Operations_[i] is applied between SelectSQLs_[i] and SelectSQLs_[i+1]
+type SetOperationExpression struct {
+	SelectSQLs_ []*string
+	Operations_ []plans.SetOperationType
+	IsAll_      []bool
+}
+
+// an expression of RETURNING clause. ExprStr_ is "*" when all columns are returned
+type ReturningExpression struct {
+	ExprStr_ *string
+	Alias_   *string // name of output column. nil at "*"
+}
+
+// NEXTVAL or CURRVAL in VALUES of INSERT
+type SequenceFuncExpression struct {
+	FuncType_ SequenceFuncType
+	SeqName_  *string
+	ValueIdx_ int // index of QueryInfo.Values_ which is replaced with returned value
 }
 
 type SelectFieldExpression struct {
 	IsAgg_     bool
 	AggType_   plans.AggregationType
 	TableName_ *string // if specified
-	ColName_   *string
+	ColName_   *string // alias or text of the expression when the field is a window function or ExprStr_ is set
+	// not nil when the field is a window function
+	WindowFunc_ *WindowFuncExpression
+	// SQL text of the field when it is not column or window function
+	ExprStr_ *string
+	Alias_   *string // name specified with AS. nil if not specified
+}
+
+// a window function in select fields. column names may be qualified with table name ("table.column")
+type WindowFuncExpression struct {
+	FuncName_    *string // lower case
+	ArgColName_  *string // nil when the function takes no column (ROW_NUMBER, COUNT(*) etc.)
+	Offset_      int32   // LAG and LEAD. 1 if not specified
+	DefaultVal_  *types.Value
+	PartitionBy_ []*string
+	OrderBy_     []*OrderByExpression
+	Frame_       *WindowFrameExpression // nil if not specified
+	// named window, DISTINCT and arguments which are not column or constant are not supported
+	HasUnsupportedOption_ bool
+}
+
+type WindowFrameExpression struct {
+	IsRows_ bool // ROWS or RANGE
+	Start_  *WindowFrameBoundExpression
+	End_    *WindowFrameBoundExpression
+}
+
+type WindowFrameBoundExpression struct {
+	BoundType_ plans.WindowFrameBoundType
+	Offset_    int32 // PRECEDING and FOLLOWING
 }
 
 type OrderByExpression struct {
@@ -1,16 +1,20 @@
 package parser
 
 import (
+	"errors"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/storage/table/column"
 	testingpkg "github.com/ryogrid/SamehadaDB/testing"
 	"github.com/ryogrid/SamehadaDB/types"
+	"math"
 	"testing"
 )
 
 func TestSinglePredicateSelectQuery(t *testing.T) {
 	sqlStr := "SELECT a FROM t WHERE a = 'daylight';"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "a")
 	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "t")
@@ -20,7 +24,7 @@ func TestSinglePredicateSelectQuery(t *testing.T) {
 	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Right_.(*types.Value).ToVarchar() == "daylight")
 
 	sqlStr = "SELECT a, b FROM t WHERE a = 10;"
-	queryInfo = ProcessSQLStr(&sqlStr)
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "a")
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[1].ColName_ == "b")
@@ -31,7 +35,7 @@ func TestSinglePredicateSelectQuery(t *testing.T) {
 	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Right_.(*types.Value).ToInteger() == 10)
 
 	sqlStr = "SELECT a, b FROM t WHERE a > 10.5;"
-	queryInfo = ProcessSQLStr(&sqlStr)
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "a")
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[1].ColName_ == "b")
@@ -39,7 +43,7 @@ func TestSinglePredicateSelectQuery(t *testing.T) {
 	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.ComparisonOperationType_ == expression.GreaterThan)
 	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.LogicalOperationType_ == -1)
 	testingpkg.SimpleAssert(t, *queryInfo.WhereExpression_.Left_.(*string) == "a")
-	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Right_.(*types.Value).ToFloat() == 10.5)
+	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Right_.(*types.Value).ToString() == "10.5")
 }
 
 func TestMultiPredicateSelectQuery(t *testing.T) {
@@ -58,7 +62,7 @@ func TestMultiPredicateSelectQuery(t *testing.T) {
 	//          |---- 50 (*types.Value)
 
 	sqlStr := "SELECT a, b FROM t WHERE a = 10 AND b = 20 AND c != 'daylight' OR d = 50;"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "a")
 	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "t")
@@ -118,7 +122,7 @@ func TestMultiPredicateSelectQuery(t *testing.T) {
 	//                   |---- 50 (*types.Value)
 
 	sqlStr = "SELECT a, b FROM t WHERE a = 10 AND b = 20 AND (c != 'daylight' OR d = 50);"
-	queryInfo = ProcessSQLStr(&sqlStr)
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "a")
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[1].ColName_ == "b")
@@ -167,7 +171,7 @@ func TestMultiPredicateSelectQuery(t *testing.T) {
 
 func TestWildcardSelectQuery(t *testing.T) {
 	sqlStr := "SELECT * FROM t WHERE a = 10;"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "*")
 	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "t")
@@ -179,7 +183,7 @@ func TestWildcardSelectQuery(t *testing.T) {
 
 func TestAggFuncSelectQuery(t *testing.T) {
 	sqlStr := "SELECT count(*), max(b), min(c), sum(d), b FROM t WHERE a = 10;"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 
 	testingpkg.SimpleAssert(t, queryInfo.SelectFields_[0].IsAgg_ == true)
@@ -207,7 +211,7 @@ func TestAggFuncSelectQuery(t *testing.T) {
 
 func TestLimitOffsetSelectQuery(t *testing.T) {
 	sqlStr := "SELECT a, b FROM t WHERE a = 10 LIMIT 100 OFFSET 200;"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "a")
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[1].ColName_ == "b")
@@ -222,7 +226,7 @@ func TestLimitOffsetSelectQuery(t *testing.T) {
 	testingpkg.SimpleAssert(t, queryInfo.OffsetNum_ == 200)
 
 	sqlStr = "SELECT a, b FROM t WHERE a = 10;"
-	queryInfo = ProcessSQLStr(&sqlStr)
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, queryInfo.LimitNum_ == -1)
 	testingpkg.SimpleAssert(t, queryInfo.OffsetNum_ == -1)
 }
@@ -230,7 +234,7 @@ func TestLimitOffsetSelectQuery(t *testing.T) {
 func TestIsNullIsNotNullSelectQuery(t *testing.T) {
 	// (a IS NULL) AND (b > 10)
 	sqlStr := "SELECT a, b FROM t WHERE a IS NULL AND b > 10;"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "a")
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[1].ColName_ == "b")
@@ -260,7 +264,7 @@ func TestIsNullIsNotNullSelectQuery(t *testing.T) {
 
 	// (a IS NOT NULL) AND (b > 10)
 	sqlStr = "SELECT a, b FROM t WHERE a IS NOT NULL AND b > 10;"
-	queryInfo = ProcessSQLStr(&sqlStr)
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "a")
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[1].ColName_ == "b")
@@ -292,7 +296,7 @@ func TestIsNullIsNotNullSelectQuery(t *testing.T) {
 
 func TestSimpleJoinSelectQuery(t *testing.T) {
 	sqlStr := "SELECT staff.a, staff.b, staff.c, friend.d FROM staff INNER JOIN friend ON staff.c = friend.c WHERE friend.d = 10;"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].TableName_ == "staff")
@@ -318,7 +322,7 @@ func TestSimpleJoinSelectQuery(t *testing.T) {
 	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Right_.(*types.Value).ToInteger() == 10)
 
 	sqlStr = "SELECT staff.a, staff.b, staff.c, friend.d, e FROM staff INNER JOIN friend ON staff.c = friend.c WHERE friend.d = 10;"
-	queryInfo = ProcessSQLStr(&sqlStr)
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
 
 	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].TableName_ == "staff")
@@ -348,7 +352,7 @@ func TestSimpleJoinSelectQuery(t *testing.T) {
 
 func TestSimpleCreateTableQuery(t *testing.T) {
 	sqlStr := "CREATE TABLE name_age_list(name VARCHAR(256), age INT);"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_TABLE)
 	testingpkg.SimpleAssert(t, *queryInfo.NewTable_ == "name_age_list")
 	testingpkg.SimpleAssert(t, *queryInfo.ColDefExpressions_[0].ColName_ == "name")
@@ -359,7 +363,7 @@ func TestSimpleCreateTableQuery(t *testing.T) {
 
 func TestCreateTableWithIndexDefQuery(t *testing.T) {
 	sqlStr := "CREATE TABLE name_age_list(id INT, name VARCHAR(256), age FLOAT, index id_idx (id), index name_age_idx (name, age));"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_TABLE)
 
 	testingpkg.SimpleAssert(t, *queryInfo.NewTable_ == "name_age_list")
@@ -380,7 +384,7 @@ func TestCreateTableWithIndexDefQuery(t *testing.T) {
 
 func TestInsertQuery(t *testing.T) {
 	sqlStr := "INSERT INTO syain(name) VALUES ('鈴木');"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == INSERT)
 
 	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "syain")
@@ -389,7 +393,7 @@ func TestInsertQuery(t *testing.T) {
 	testingpkg.SimpleAssert(t, queryInfo.Values_[0].ToVarchar() == "鈴木")
 
 	sqlStr = "INSERT INTO syain(id,name,romaji) VALUES (1,'鈴木','suzuki');"
-	queryInfo = ProcessSQLStr(&sqlStr)
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == INSERT)
 
 	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "syain")
@@ -400,11 +404,28 @@ func TestInsertQuery(t *testing.T) {
 	testingpkg.SimpleAssert(t, queryInfo.Values_[1].ToVarchar() == "鈴木")
 	testingpkg.SimpleAssert(t, *queryInfo.TargetCols_[2] == "romaji")
 	testingpkg.SimpleAssert(t, queryInfo.Values_[2].ToVarchar() == "suzuki")
+
+	// negative values and constant expressions
+	sqlStr = "INSERT INTO nums(a, b, c, d) VALUES (-5, -9223372036854775808, 0 - 5, -2147483648);"
+	queryInfo, err := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, err == nil && len(queryInfo.Values_) == 4)
+	testingpkg.SimpleAssert(t, queryInfo.Values_[0].ValueType() == types.Integer && queryInfo.Values_[0].ToInteger() == -5)
+	testingpkg.SimpleAssert(t, queryInfo.Values_[1].ValueType() == types.BigInt && queryInfo.Values_[1].ToBigInt() == math.MinInt64)
+	testingpkg.SimpleAssert(t, queryInfo.Values_[2].ToInteger() == -5)
+	testingpkg.SimpleAssert(t, queryInfo.Values_[3].ValueType() == types.Integer && queryInfo.Values_[3].ToInteger() == math.MinInt32)
+	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Left_ == nil)
+
+	sqlStr = "INSERT INTO nums(a) VALUES (-9223372036854775809);"
+	_, err = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, err != nil)
+	sqlStr = "INSERT INTO nums(a) VALUES (-'x');"
+	_, err = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, err != nil)
 }
 
 func TestSimpleDeleteQuery(t *testing.T) {
 	sqlStr := "DELETE FROM users WHERE id = 10;"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == DELETE)
 
 	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "users")
@@ -417,7 +438,7 @@ func TestSimpleDeleteQuery(t *testing.T) {
 
 func TestSimpleUpdateQuery(t *testing.T) {
 	sqlStr := "UPDATE employees SET title = 'Mr.' WHERE gender = 'M';"
-	queryInfo := ProcessSQLStr(&sqlStr)
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == UPDATE)
 
 	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "employees")
@@ -430,7 +451,7 @@ func TestSimpleUpdateQuery(t *testing.T) {
 	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Right_.(*types.Value).ToVarchar() == "M")
 
 	sqlStr = "UPDATE employees SET title = 'Mr.', gflag = 7 WHERE gender = 'M';"
-	queryInfo = ProcessSQLStr(&sqlStr)
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
 	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == UPDATE)
 
 	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "employees")
@@ -444,3 +465,382 @@ func TestSimpleUpdateQuery(t *testing.T) {
 	testingpkg.SimpleAssert(t, *queryInfo.WhereExpression_.Left_.(*string) == "gender")
 	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Right_.(*types.Value).ToVarchar() == "M")
 }
+
+func TestAlterTableQuery(t *testing.T) {
+	sqlStr := "ALTER TABLE users ADD COLUMN age INT DEFAULT 20;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == ALTER_TABLE)
+	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "users")
+	testingpkg.SimpleAssert(t, queryInfo.AlterTableExpressions_[0].AlterType_ == ADD_COLUMN)
+	testingpkg.SimpleAssert(t, *queryInfo.AlterTableExpressions_[0].ColDef_.ColName_ == "age")
+	testingpkg.SimpleAssert(t, *queryInfo.AlterTableExpressions_[0].ColDef_.ColType_ == types.Integer)
+	testingpkg.SimpleAssert(t, queryInfo.AlterTableExpressions_[0].ColDef_.DefaultValue_.ToInteger() == 20)
+
+	sqlStr = "ALTER TABLE users DROP COLUMN age, RENAME COLUMN name TO fullname;"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == ALTER_TABLE)
+	testingpkg.SimpleAssert(t, queryInfo.AlterTableExpressions_[0].AlterType_ == DROP_COLUMN)
+	testingpkg.SimpleAssert(t, *queryInfo.AlterTableExpressions_[0].ColName_ == "age")
+	testingpkg.SimpleAssert(t, queryInfo.AlterTableExpressions_[1].AlterType_ == RENAME_COLUMN)
+	testingpkg.SimpleAssert(t, *queryInfo.AlterTableExpressions_[1].ColName_ == "name")
+	testingpkg.SimpleAssert(t, *queryInfo.AlterTableExpressions_[1].NewName_ == "fullname")
+
+	sqlStr = "ALTER TABLE users RENAME TO members;"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == ALTER_TABLE)
+	testingpkg.SimpleAssert(t, queryInfo.AlterTableExpressions_[0].AlterType_ == RENAME_TABLE)
+	testingpkg.SimpleAssert(t, *queryInfo.AlterTableExpressions_[0].NewName_ == "members")
+}
+
+func TestCreateTableWithConstraintsQuery(t *testing.T) {
+	sqlStr := "CREATE TABLE users (id INT PRIMARY KEY, email VARCHAR(256) UNIQUE, name VARCHAR(256));"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_TABLE)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[0].IsPrimaryKey_ && queryInfo.ColDefExpressions_[0].IsUnique_)
+	testingpkg.SimpleAssert(t, !queryInfo.ColDefExpressions_[1].IsPrimaryKey_ && queryInfo.ColDefExpressions_[1].IsUnique_)
+	testingpkg.SimpleAssert(t, !queryInfo.ColDefExpressions_[2].IsPrimaryKey_ && !queryInfo.ColDefExpressions_[2].IsUnique_)
+
+	sqlStr = "CREATE TABLE users (id INT, email VARCHAR(256), PRIMARY KEY (id), UNIQUE (email));"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, len(queryInfo.IndexDefExpressions_) == 2)
+	testingpkg.SimpleAssert(t, queryInfo.IndexDefExpressions_[0].IsPrimaryKey_ && queryInfo.IndexDefExpressions_[0].IsUnique_)
+	testingpkg.SimpleAssert(t, *queryInfo.IndexDefExpressions_[0].Colnames_[0] == "id")
+	testingpkg.SimpleAssert(t, !queryInfo.IndexDefExpressions_[1].IsPrimaryKey_ && queryInfo.IndexDefExpressions_[1].IsUnique_)
+	testingpkg.SimpleAssert(t, *queryInfo.IndexDefExpressions_[1].Colnames_[0] == "email")
+}
+
+func TestCreateIndexQuery(t *testing.T) {
+	sqlStr := "CREATE INDEX users_name ON users (name);"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_INDEX)
+	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "users")
+	testingpkg.SimpleAssert(t, *queryInfo.IndexDefExpressions_[0].IndexName_ == "users_name")
+	testingpkg.SimpleAssert(t, *queryInfo.IndexDefExpressions_[0].Colnames_[0] == "name")
+	testingpkg.SimpleAssert(t, !queryInfo.IndexDefExpressions_[0].IsUnique_)
+
+	sqlStr = "CREATE UNIQUE INDEX users_email ON users (email);"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_INDEX)
+	testingpkg.SimpleAssert(t, queryInfo.IndexDefExpressions_[0].IsUnique_)
+}
+
+func TestCreateTableWithNotNullAndDefaultQuery(t *testing.T) {
+	sqlStr := "CREATE TABLE users (id INT NOT NULL, name VARCHAR(256) DEFAULT 'no name', score FLOAT DEFAULT -1.5, memo VARCHAR(256) DEFAULT NULL);"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_TABLE)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[0].IsNotNull_)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[0].DefaultValue_ == nil)
+	testingpkg.SimpleAssert(t, !queryInfo.ColDefExpressions_[1].IsNotNull_)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[1].DefaultValue_.ToVarchar() == "no name")
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[2].DefaultValue_.ToString() == "-1.5")
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[3].DefaultValue_.IsNull())
+
+	sqlStr = "INSERT INTO users(id, memo) VALUES (1, NULL);"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, queryInfo.Values_[0].ToInteger() == 1)
+	testingpkg.SimpleAssert(t, queryInfo.Values_[1].IsNull())
+}
+
+func TestCreateTableWithForeignKeyQuery(t *testing.T) {
+	sqlStr := "CREATE TABLE orders (id INT PRIMARY KEY, user_id INT REFERENCES users(id) ON DELETE CASCADE, memo VARCHAR(256));"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_TABLE)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[0].ForeignKey_ == nil)
+	fkDef := queryInfo.ColDefExpressions_[1].ForeignKey_
+	testingpkg.SimpleAssert(t, *fkDef.Colnames_[0] == "user_id")
+	testingpkg.SimpleAssert(t, *fkDef.RefTable_ == "users")
+	testingpkg.SimpleAssert(t, *fkDef.RefColnames_[0] == "id")
+	testingpkg.SimpleAssert(t, fkDef.OnDelete_ == column.FK_ACTION_CASCADE)
+	testingpkg.SimpleAssert(t, fkDef.OnUpdate_ == column.FK_ACTION_RESTRICT)
+
+	sqlStr = "CREATE TABLE orders (id INT, user_id INT, FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE);"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, len(queryInfo.IndexDefExpressions_) == 0)
+	testingpkg.SimpleAssert(t, len(queryInfo.ForeignKeyDefExpressions_) == 1)
+	fkDef = queryInfo.ForeignKeyDefExpressions_[0]
+	testingpkg.SimpleAssert(t, *fkDef.Colnames_[0] == "user_id")
+	testingpkg.SimpleAssert(t, *fkDef.RefTable_ == "users")
+	testingpkg.SimpleAssert(t, *fkDef.RefColnames_[0] == "id")
+	testingpkg.SimpleAssert(t, fkDef.OnDelete_ == column.FK_ACTION_SET_NULL)
+	testingpkg.SimpleAssert(t, fkDef.OnUpdate_ == column.FK_ACTION_CASCADE)
+}
+
+func TestCreateTableWithCheckAndGeneratedColumnQuery(t *testing.T) {
+	sqlStr := "CREATE TABLE items (price INT, qty INT CHECK (qty >= 0), total INT AS (price * qty) STORED, CHECK (price > 0 AND price < 1000));"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_TABLE)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[0].CheckExpr_ == nil)
+	testingpkg.SimpleAssert(t, *queryInfo.ColDefExpressions_[1].CheckExpr_ == "`qty`>=0")
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[1].GeneratedExpr_ == nil)
+	testingpkg.SimpleAssert(t, *queryInfo.ColDefExpressions_[2].GeneratedExpr_ == "`price`*`qty`")
+	testingpkg.SimpleAssert(t, len(queryInfo.CheckExprs_) == 1)
+	testingpkg.SimpleAssert(t, *queryInfo.CheckExprs_[0] == "`price`>0 AND `price`<1000")
+}
+
+func TestSequenceQuery(t *testing.T) {
+	sqlStr := "CREATE SEQUENCE order_seq START WITH 100 INCREMENT BY 10;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_SEQUENCE)
+	testingpkg.SimpleAssert(t, *queryInfo.SequenceDef_.SeqName_ == "order_seq")
+	testingpkg.SimpleAssert(t, *queryInfo.SequenceDef_.StartWith_ == 100)
+	testingpkg.SimpleAssert(t, queryInfo.SequenceDef_.IncrementBy_ == 10)
+
+	sqlStr = "CREATE TABLE orders (id SERIAL, seq INT AUTO_INCREMENT, memo VARCHAR(256));"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[0].IsAutoIncrement_)
+	testingpkg.SimpleAssert(t, *queryInfo.ColDefExpressions_[0].ColType_ == types.BigInt)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[1].IsAutoIncrement_)
+	testingpkg.SimpleAssert(t, !queryInfo.ColDefExpressions_[2].IsAutoIncrement_)
+
+	sqlStr = "INSERT INTO orders(id, memo) VALUES (NEXTVAL(order_seq), 'a'), (CURRVAL(order_seq), 'b');"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, len(queryInfo.Values_) == 4)
+	testingpkg.SimpleAssert(t, len(queryInfo.SequenceFuncExpressions_) == 2)
+	testingpkg.SimpleAssert(t, queryInfo.SequenceFuncExpressions_[0].FuncType_ == NEXTVAL)
+	testingpkg.SimpleAssert(t, *queryInfo.SequenceFuncExpressions_[0].SeqName_ == "order_seq")
+	testingpkg.SimpleAssert(t, queryInfo.SequenceFuncExpressions_[0].ValueIdx_ == 0)
+	testingpkg.SimpleAssert(t, queryInfo.SequenceFuncExpressions_[1].FuncType_ == CURRVAL)
+	testingpkg.SimpleAssert(t, queryInfo.SequenceFuncExpressions_[1].ValueIdx_ == 2)
+	testingpkg.SimpleAssert(t, queryInfo.Values_[3].ToVarchar() == "b")
+
+	// quoted sequence name
+	sqlStr = "INSERT INTO orders(id) VALUES (NEXTVAL('order_seq'));"
+	queryInfo, err := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, err == nil && len(queryInfo.SequenceFuncExpressions_) == 1)
+	testingpkg.SimpleAssert(t, *queryInfo.SequenceFuncExpressions_[0].SeqName_ == "order_seq")
+
+	sqlStr = "UPDATE orders SET id = NEXTVAL('order_seq') WHERE memo = 'a';"
+	queryInfo, err = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, err == nil && len(queryInfo.SetExpressions_) == 1)
+	testingpkg.SimpleAssert(t, *queryInfo.SetExpressions_[0].ColName_ == "id")
+	testingpkg.SimpleAssert(t, queryInfo.SetExpressions_[0].UpdateValue_ == nil)
+	testingpkg.SimpleAssert(t, *queryInfo.SetExpressions_[0].UpdateExprStr_ == "NEXTVAL(`order_seq`)")
+
+	sqlStr = "CREATE TABLE invoices (id INT DEFAULT NEXTVAL('order_seq'), memo VARCHAR(256));"
+	queryInfo, err = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, err == nil && *queryInfo.ColDefExpressions_[0].DefaultSeq_ == "order_seq")
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[0].DefaultValue_ == nil)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[1].DefaultSeq_ == nil)
+
+	sqlStr = "CREATE TABLE invoices (id INT DEFAULT CURRVAL('order_seq'));"
+	_, err = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, err != nil)
+}
+
+func TestViewQuery(t *testing.T) {
+	sqlStr := "CREATE VIEW expensive AS SELECT name, price FROM items WHERE price > 100;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_VIEW)
+	testingpkg.SimpleAssert(t, *queryInfo.ViewDef_.ViewName_ == "expensive")
+	testingpkg.SimpleAssert(t, !queryInfo.ViewDef_.IsMaterialized_)
+	testingpkg.SimpleAssert(t, *queryInfo.ViewDef_.SelectSQL_ == "SELECT `name`,`price` FROM `items` WHERE `price`>100")
+
+	sqlStr = "CREATE MATERIALIZED VIEW expensive_mv AS SELECT name FROM items WHERE price > 100;"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_VIEW)
+	testingpkg.SimpleAssert(t, *queryInfo.ViewDef_.ViewName_ == "expensive_mv")
+	testingpkg.SimpleAssert(t, queryInfo.ViewDef_.IsMaterialized_)
+
+	sqlStr = "REFRESH MATERIALIZED VIEW expensive_mv;"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == REFRESH_MATERIALIZED_VIEW)
+	testingpkg.SimpleAssert(t, *queryInfo.ViewDef_.ViewName_ == "expensive_mv")
+}
+
+func TestInsertSelectQuery(t *testing.T) {
+	sqlStr := "INSERT INTO archive(id, name) SELECT id, name FROM items WHERE price > 100;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == INSERT)
+	testingpkg.SimpleAssert(t, len(queryInfo.JoinTables_) == 1)
+	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "archive")
+	testingpkg.SimpleAssert(t, len(queryInfo.TargetCols_) == 2)
+	testingpkg.SimpleAssert(t, *queryInfo.TargetCols_[1] == "name")
+	testingpkg.SimpleAssert(t, len(queryInfo.Values_) == 0)
+	testingpkg.SimpleAssert(t, *queryInfo.InsertSelectSQL_ == "SELECT `id`,`name` FROM `items` WHERE `price`>100")
+	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Left_ == nil)
+}
+
+func TestUpsertQuery(t *testing.T) {
+	sqlStr := "INSERT INTO stocks(id, name, qty) VALUES (1, 'apple', 10) ON DUPLICATE KEY UPDATE qty = VALUES(qty), name = 'updated';"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == INSERT)
+	testingpkg.SimpleAssert(t, len(queryInfo.Values_) == 3)
+	testingpkg.SimpleAssert(t, len(queryInfo.SetExpressions_) == 0)
+	testingpkg.SimpleAssert(t, len(queryInfo.UpsertSetExpressions_) == 2)
+	testingpkg.SimpleAssert(t, *queryInfo.UpsertSetExpressions_[0].ColName_ == "qty")
+	testingpkg.SimpleAssert(t, *queryInfo.UpsertSetExpressions_[0].ValuesOf_ == "qty")
+	testingpkg.SimpleAssert(t, *queryInfo.UpsertSetExpressions_[1].ColName_ == "name")
+	testingpkg.SimpleAssert(t, queryInfo.UpsertSetExpressions_[1].UpdateValue_.ToVarchar() == "updated")
+	testingpkg.SimpleAssert(t, !queryInfo.OnConflictDoNothing_)
+
+	sqlStr = "INSERT INTO stocks(id, name, qty) VALUES (1, 'apple', 10) ON CONFLICT (id) DO NOTHING;"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == INSERT)
+	testingpkg.SimpleAssert(t, len(queryInfo.Values_) == 3)
+	testingpkg.SimpleAssert(t, queryInfo.OnConflictDoNothing_)
+
+	sqlStr = "INSERT IGNORE INTO stocks(id, name, qty) VALUES (1, 'apple', 10);"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, queryInfo.OnConflictDoNothing_)
+}
+
+func TestReturningQuery(t *testing.T) {
+	sqlStr := "INSERT INTO items(name, price) VALUES ('pen', 100) RETURNING id, price * 2 AS dbl;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == INSERT)
+	testingpkg.SimpleAssert(t, len(queryInfo.Values_) == 2)
+	testingpkg.SimpleAssert(t, len(queryInfo.ReturningExprs_) == 2)
+	testingpkg.SimpleAssert(t, *queryInfo.ReturningExprs_[0].Alias_ == "id")
+	testingpkg.SimpleAssert(t, *queryInfo.ReturningExprs_[1].Alias_ == "dbl")
+
+	sqlStr = "UPDATE items SET price = 200 WHERE name = 'pen' RETURNING *;"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == UPDATE)
+	testingpkg.SimpleAssert(t, len(queryInfo.SetExpressions_) == 1)
+	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_ != nil)
+	testingpkg.SimpleAssert(t, len(queryInfo.ReturningExprs_) == 1)
+	testingpkg.SimpleAssert(t, *queryInfo.ReturningExprs_[0].ExprStr_ == "*")
+
+	sqlStr = "DELETE FROM items WHERE price > 10 RETURNING name"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == DELETE)
+	testingpkg.SimpleAssert(t, len(queryInfo.ReturningExprs_) == 1)
+	testingpkg.SimpleAssert(t, *queryInfo.ReturningExprs_[0].Alias_ == "name")
+
+	sqlStr = "INSERT INTO items(name, price) VALUES ('pen', 100);"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, len(queryInfo.ReturningExprs_) == 0)
+}
+
+func TestCTEQuery(t *testing.T) {
+	sqlStr := "WITH fruits AS (SELECT id, name FROM categories WHERE name = 'a)b'), stocked(cid, q) AS (SELECT category_id, qty FROM stocks) SELECT * FROM fruits;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
+	testingpkg.SimpleAssert(t, *queryInfo.JoinTables_[0] == "fruits")
+	testingpkg.SimpleAssert(t, len(queryInfo.CTEs_) == 2)
+	testingpkg.SimpleAssert(t, *queryInfo.CTEs_[0].Name_ == "fruits")
+	testingpkg.SimpleAssert(t, len(queryInfo.CTEs_[0].ColNames_) == 0)
+	testingpkg.SimpleAssert(t, *queryInfo.CTEs_[0].SelectSQL_ == "SELECT id, name FROM categories WHERE name = 'a)b'")
+	testingpkg.SimpleAssert(t, queryInfo.CTEs_[0].RecursiveSQL_ == nil)
+	testingpkg.SimpleAssert(t, *queryInfo.CTEs_[1].Name_ == "stocked")
+	testingpkg.SimpleAssert(t, len(queryInfo.CTEs_[1].ColNames_) == 2)
+	testingpkg.SimpleAssert(t, *queryInfo.CTEs_[1].ColNames_[0] == "cid" && *queryInfo.CTEs_[1].ColNames_[1] == "q")
+
+	sqlStr = "WITH RECURSIVE sub(id) AS (SELECT id FROM categories WHERE id = 1 UNION ALL SELECT categories.id FROM categories JOIN sub ON categories.parent_id = sub.id) SELECT * FROM sub;"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, len(queryInfo.CTEs_) == 1)
+	testingpkg.SimpleAssert(t, *queryInfo.CTEs_[0].SelectSQL_ == "SELECT id FROM categories WHERE id = 1")
+	testingpkg.SimpleAssert(t, *queryInfo.CTEs_[0].RecursiveSQL_ == "SELECT categories.id FROM categories JOIN sub ON categories.parent_id = sub.id")
+	testingpkg.SimpleAssert(t, queryInfo.CTEs_[0].IsUnionAll_)
+
+	sqlStr = "WITH fruits AS (SELECT id FROM categories SELECT * FROM fruits;"
+	queryInfo, err := ProcessSQLStr(&sqlStr)
+	var parseErr *samehada_errors.ParseError
+	testingpkg.SimpleAssert(t, queryInfo == nil && errors.As(err, &parseErr))
+}
+
+func TestSetOperationQuery(t *testing.T) {
+	sqlStr := "SELECT id FROM t1 WHERE name = 'union' UNION SELECT id FROM t2 INTERSECT ALL (SELECT id FROM t3) EXCEPT SELECT id FROM t4 ORDER BY id DESC LIMIT 3 OFFSET 1;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == SELECT)
+	testingpkg.SimpleAssert(t, len(queryInfo.JoinTables_) == 0)
+	setOp := queryInfo.SetOperation_
+	testingpkg.SimpleAssert(t, len(setOp.SelectSQLs_) == 4)
+	testingpkg.SimpleAssert(t, *setOp.SelectSQLs_[0] == "SELECT id FROM t1 WHERE name = 'union'")
+	testingpkg.SimpleAssert(t, *setOp.SelectSQLs_[2] == "SELECT id FROM t3")
+	testingpkg.SimpleAssert(t, *setOp.SelectSQLs_[3] == "SELECT id FROM t4")
+	testingpkg.SimpleAssert(t, setOp.Operations_[0] == plans.UNION_OPERATION && !setOp.IsAll_[0])
+	testingpkg.SimpleAssert(t, setOp.Operations_[1] == plans.INTERSECT_OPERATION && setOp.IsAll_[1])
+	testingpkg.SimpleAssert(t, setOp.Operations_[2] == plans.EXCEPT_OPERATION && !setOp.IsAll_[2])
+	testingpkg.SimpleAssert(t, len(queryInfo.OrderByExpressions_) == 1)
+	testingpkg.SimpleAssert(t, *queryInfo.OrderByExpressions_[0].ColName_ == "id" && queryInfo.OrderByExpressions_[0].IsDesc_)
+	testingpkg.SimpleAssert(t, queryInfo.LimitNum_ == 3 && queryInfo.OffsetNum_ == 1)
+
+	sqlStr = "SELECT id FROM t1 UNION ALL SELECT id FROM t2;"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, len(queryInfo.SetOperation_.SelectSQLs_) == 2)
+	testingpkg.SimpleAssert(t, queryInfo.SetOperation_.IsAll_[0])
+	testingpkg.SimpleAssert(t, queryInfo.LimitNum_ == -1 && len(queryInfo.OrderByExpressions_) == 0)
+
+	sqlStr = "SELECT id FROM t1 WHERE name = 'x union y';"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, queryInfo.SetOperation_ == nil)
+}
+
+func TestWindowFuncQuery(t *testing.T) {
+	sqlStr := "SELECT name, LEAD(score, 2, 0) OVER (PARTITION BY team ORDER BY score DESC ROWS BETWEEN 1 PRECEDING AND UNBOUNDED FOLLOWING) AS next_score FROM scores;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, len(queryInfo.SelectFields_) == 2)
+	testingpkg.SimpleAssert(t, queryInfo.SelectFields_[0].WindowFunc_ == nil)
+	sfield := queryInfo.SelectFields_[1]
+	testingpkg.SimpleAssert(t, *sfield.ColName_ == "next_score")
+	windowFunc := sfield.WindowFunc_
+	testingpkg.SimpleAssert(t, *windowFunc.FuncName_ == "lead")
+	testingpkg.SimpleAssert(t, *windowFunc.ArgColName_ == "score")
+	testingpkg.SimpleAssert(t, windowFunc.Offset_ == 2 && windowFunc.DefaultVal_.ToInteger() == 0)
+	testingpkg.SimpleAssert(t, len(windowFunc.PartitionBy_) == 1 && *windowFunc.PartitionBy_[0] == "team")
+	testingpkg.SimpleAssert(t, len(windowFunc.OrderBy_) == 1 && *windowFunc.OrderBy_[0].ColName_ == "score" && windowFunc.OrderBy_[0].IsDesc_)
+	testingpkg.SimpleAssert(t, windowFunc.Frame_.IsRows_)
+	testingpkg.SimpleAssert(t, windowFunc.Frame_.Start_.BoundType_ == plans.PRECEDING && windowFunc.Frame_.Start_.Offset_ == 1)
+	testingpkg.SimpleAssert(t, windowFunc.Frame_.End_.BoundType_ == plans.UNBOUNDED_FOLLOWING)
+	testingpkg.SimpleAssert(t, !windowFunc.HasUnsupportedOption_)
+
+	sqlStr = "SELECT COUNT(*) OVER () FROM scores;"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	windowFunc = queryInfo.SelectFields_[0].WindowFunc_
+	testingpkg.SimpleAssert(t, *windowFunc.FuncName_ == "count" && windowFunc.ArgColName_ == nil)
+	testingpkg.SimpleAssert(t, windowFunc.Frame_ == nil && !windowFunc.HasUnsupportedOption_)
+}
+
+func TestScalarExpressionQuery(t *testing.T) {
+	sqlStr := "SELECT id, UPPER(name) AS u, CASE WHEN price > 10 THEN 'high' ELSE 'low' END FROM items WHERE LENGTH(name) > 3 AND id = 1;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, len(queryInfo.SelectFields_) == 3)
+	testingpkg.SimpleAssert(t, queryInfo.SelectFields_[0].ExprStr_ == nil && *queryInfo.SelectFields_[0].ColName_ == "id")
+	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[1].ExprStr_ == "UPPER(`name`)" && *queryInfo.SelectFields_[1].ColName_ == "u")
+	testingpkg.SimpleAssert(t, queryInfo.SelectFields_[2].ExprStr_ != nil)
+	// WHERE clause which has function call is kept as SQL text
+	testingpkg.SimpleAssert(t, *queryInfo.WhereExprStr_ == "LENGTH(`name`)>3 AND `id`=1")
+	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.Left_ == nil)
+
+	sqlStr = "DELETE FROM items WHERE id = 1 AND name = 'a';"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, queryInfo.WhereExprStr_ == nil)
+	testingpkg.SimpleAssert(t, queryInfo.WhereExpression_.LogicalOperationType_ == expression.AND)
+}
+
+func TestGroupByHavingQuery(t *testing.T) {
+	sqlStr := "SELECT team, COUNT(*) AS cnt, my_agg(score) FROM scores GROUP BY team, UPPER(name) HAVING SUM(score) > 10;"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, len(queryInfo.SelectFields_) == 3)
+	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[0].ColName_ == "team" && queryInfo.SelectFields_[0].ExprStr_ == nil)
+	testingpkg.SimpleAssert(t, queryInfo.SelectFields_[1].IsAgg_ && queryInfo.SelectFields_[1].AggType_ == plans.COUNT_AGGREGATE)
+	testingpkg.SimpleAssert(t, *queryInfo.SelectFields_[1].Alias_ == "cnt" && *queryInfo.SelectFields_[1].ExprStr_ == "COUNT(1)")
+	testingpkg.SimpleAssert(t, !queryInfo.SelectFields_[2].IsAgg_ && *queryInfo.SelectFields_[2].ExprStr_ == "MY_AGG(`score`)")
+	testingpkg.SimpleAssert(t, len(queryInfo.GroupByExprStrs_) == 2)
+	testingpkg.SimpleAssert(t, *queryInfo.GroupByExprStrs_[0] == "`team`" && *queryInfo.GroupByExprStrs_[1] == "UPPER(`name`)")
+	testingpkg.SimpleAssert(t, *queryInfo.HavingExprStr_ == "SUM(`score`)>10")
+}
+
+func TestCreateTableWithDecimalQuery(t *testing.T) {
+	sqlStr := "CREATE TABLE prices (id INT, price DECIMAL(12, 2), rate DECIMAL, cost NUMERIC(5));"
+	queryInfo, _ := ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, *queryInfo.QueryType_ == CREATE_TABLE)
+	testingpkg.SimpleAssert(t, *queryInfo.ColDefExpressions_[1].ColType_ == types.Decimal)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[1].DecimalPrecision_ == 12 && queryInfo.ColDefExpressions_[1].DecimalScale_ == 2)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[2].DecimalPrecision_ == types.DecimalDefaultPrecision && queryInfo.ColDefExpressions_[2].DecimalScale_ == types.DecimalDefaultScale)
+	testingpkg.SimpleAssert(t, queryInfo.ColDefExpressions_[3].DecimalPrecision_ == 5 && queryInfo.ColDefExpressions_[3].DecimalScale_ == 0)
+
+	sqlStr = "INSERT INTO prices(id, price) VALUES (1, 19.99);"
+	queryInfo, _ = ProcessSQLStr(&sqlStr)
+	testingpkg.SimpleAssert(t, queryInfo.Values_[1].ValueType() == types.Decimal)
+	testingpkg.SimpleAssert(t, queryInfo.Values_[1].ToString() == "19.99")
+}
+
+func TestParseError(t *testing.T) {
+	var parseErr *samehada_errors.ParseError
+	for _, sqlStr := range []string{"SELEC a FROM t;", "DROP TABLE t;", "INSERT INTO t(a) VALUES (1) RETURNING ;"} {
+		queryInfo, err := ProcessSQLStr(&sqlStr)
+		testingpkg.SimpleAssert(t, queryInfo == nil && errors.As(err, &parseErr) && parseErr.SQL == sqlStr)
+	}
+}
@@ -1,11 +1,17 @@
 package parser
 
 import (
+	"errors"
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/mysql"
 	ptypes "github.com/pingcap/tidb/types"
 	driver "github.com/pingcap/tidb/types/parser_driver"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/types"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type QueryType int32
@@ -16,26 +22,163 @@ const (
 	INSERT
 	DELETE
 	UPDATE
+	ALTER_TABLE
+	CREATE_SEQUENCE
+	CREATE_VIEW
+	REFRESH_MATERIALIZED_VIEW
+	CREATE_INDEX
 )
 
-func ValueExprToValue(expr *driver.ValueExpr) *types.Value {
+type AlterTableType int32
+
+const (
+	ADD_COLUMN AlterTableType = iota
+	DROP_COLUMN
+	RENAME_COLUMN
+	RENAME_TABLE
+)
+
+type SequenceFuncType int32
+
+const (
+	NEXTVAL SequenceFuncType = iota
+	CURRVAL
+)
+
+// sequenceFuncOf returns type of NEXTVAL or CURRVAL call and name of the sequence passed to it.
+// isSeqFunc is false when node is call of other function
+func sequenceFuncOf(node *ast.FuncCallExpr) (funcType SequenceFuncType, seqName string, isSeqFunc bool, err error) {
+	switch node.FnName.L {
+	case "nextval":
+		funcType = NEXTVAL
+	case "currval", "lastval":
+		funcType = CURRVAL
+	default:
+		return 0, "", false, nil
+	}
+	if len(node.Args) == 0 {
+		return 0, "", true, samehada_errors.NewParseError("", "sequence name must be passed to "+node.FnName.O+".")
+	}
+	switch arg := node.Args[0].(type) {
+	case *ast.TableNameExpr:
+		seqName = arg.Name.Name.String()
+	case *ast.ColumnNameExpr:
+		seqName = arg.Name.Name.String()
+	default:
+		return 0, "", true, samehada_errors.NewParseError("", "sequence name must be passed to "+node.FnName.O+".")
+	}
+	return funcType, seqName, true, nil
+}
+
+// ValueExprToValue converts literal to a value. error is returned when the literal can't be represented
+// (ex: DECIMAL literal which has too many digits after decimal point)
+func ValueExprToValue(expr *driver.ValueExpr) (*types.Value, error) {
 	switch expr.Datum.Kind() {
 	case ptypes.KindInt64, ptypes.KindUint64:
+		if mysql.HasIsBooleanFlag(expr.Type.Flag) {
+			// TRUE and FALSE
+			ret := types.NewBoolean(expr.Datum.GetInt64() != 0)
+			return &ret, nil
+		}
 		val_str := expr.String()
 		istr := strings.Split(val_str, " ")[1]
-		ival, _ := strconv.Atoi(istr)
+		ival, err := strconv.ParseInt(istr, 10, 64)
+		if err != nil {
+			return nil, errors.New("integer literal " + istr + " is out of range.")
+		}
+		if ival > math.MaxInt32 || ival < math.MinInt32 {
+			// literal which overflows INT is BIGINT
+			ret := types.NewBigInt(ival)
+			return &ret, nil
+		}
 		ret := types.NewInteger(int32(ival))
-		return &ret
+		return &ret, nil
 	case ptypes.KindMysqlDecimal:
-		val_str := expr.String()
-		fstr := strings.Split(val_str, " ")[1]
-		fval, _ := strconv.ParseFloat(fstr, 32)
-		ret := types.NewFloat(float32(fval))
-		return &ret
+		// literal which has decimal point is DECIMAL. it is converted to Float when compared with or stored to FLOAT
+		ret, err := types.NewDecimalFromString(expr.Datum.GetMysqlDecimal().String())
+		if err != nil {
+			return nil, err
+		}
+		return &ret, nil
+	case ptypes.KindNull:
+		// type of NULL is decided by the column which the value is stored to
+		ret := types.NewNull()
+		return &ret, nil
+	case ptypes.KindString, ptypes.KindBytes:
+		ret := types.NewVarchar(expr.Datum.GetString())
+		return &ret, nil
 	default:
 		val_str := expr.String()
 		target_str := strings.Split(val_str, " ")[1]
 		ret := types.NewVarchar(target_str)
-		return &ret
+		return &ret, nil
+	}
+}
+
+// isNumericLiteral returns true when expr is integer or DECIMAL literal. TRUE and FALSE are not numeric
+func isNumericLiteral(expr *driver.ValueExpr) bool {
+	switch expr.Datum.Kind() {
+	case ptypes.KindInt64, ptypes.KindUint64:
+		return !mysql.HasIsBooleanFlag(expr.Type.Flag)
+	case ptypes.KindMysqlDecimal:
+		return true
+	}
+	return false
+}
+
+// NegatedValueExprToValue converts literal with unary minus (ex: -5, -1.25) to a value.
+// the sign is added before conversion, so minimum value of BIGINT can be written
+func NegatedValueExprToValue(expr *driver.ValueExpr) (*types.Value, error) {
+	if !isNumericLiteral(expr) {
+		return nil, samehada_errors.NewParseError("", "operand of unary minus must be numeric.")
+	}
+	if expr.Datum.Kind() != ptypes.KindMysqlDecimal {
+		istr := "-" + strings.Split(expr.String(), " ")[1]
+		ival, err := strconv.ParseInt(istr, 10, 64)
+		if err != nil {
+			return nil, errors.New("integer literal " + istr + " is out of range.")
+		}
+		if ival < math.MinInt32 {
+			ret := types.NewBigInt(ival)
+			return &ret, nil
+		}
+		ret := types.NewInteger(int32(ival))
+		return &ret, nil
+	}
+	ret, err := types.NewDecimalFromString("-" + expr.Datum.GetMysqlDecimal().String())
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// TemporalFuncToValue converts DATE '...' and TIMESTAMP '...' literals and NOW(), CURRENT_TIMESTAMP
+// and CURRENT_DATE to a value. NOW() etc. are evaluated when the SQL is parsed.
+// literal which can't be parsed is returned as Varchar. nil is returned for other functions
+func TemporalFuncToValue(node *ast.FuncCallExpr) *types.Value {
+	var ret types.Value
+	switch node.FnName.L {
+	case ast.DateLiteral, ast.TimestampLiteral:
+		valExpr, ok := node.Args[0].(*driver.ValueExpr)
+		if !ok {
+			return nil
+		}
+		str := valExpr.Datum.GetString()
+		var err error
+		if node.FnName.L == ast.DateLiteral {
+			ret, err = types.ParseDate(str)
+		} else {
+			ret, err = types.ParseTimestamp(str)
+		}
+		if err != nil {
+			ret = types.NewVarchar(str)
+		}
+	case "now", "current_timestamp", "localtime", "localtimestamp", "sysdate":
+		ret = types.NewTimestamp(time.Now())
+	case "current_date", "curdate":
+		ret = types.NewDate(time.Now())
+	default:
+		return nil
 	}
+	return &ret
 }
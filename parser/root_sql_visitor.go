@@ -3,12 +3,17 @@ package parser
 import (
 	"github.com/pingcap/parser/ast"
 	"github.com/pingcap/parser/mysql"
+	"github.com/pingcap/parser/opcode"
 	driver "github.com/pingcap/tidb/types/parser_driver"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/storage/table/column"
 	"github.com/ryogrid/SamehadaDB/types"
 )
 
 type RootSQLVisitor struct {
 	QueryInfo_ *QueryInfo
+	// first error occurred in visiting. visiting is stopped when it is set
+	err error
 }
 
 func NewRootSQLVisitor() *RootSQLVisitor {
@@ -19,6 +24,8 @@ func NewRootSQLVisitor() *RootSQLVisitor {
 	qinfo.SetExpressions_ = make([]*SetExpression, 0)
 	qinfo.ColDefExpressions_ = make([]*ColDefExpression, 0)
 	qinfo.IndexDefExpressions_ = make([]*IndexDefExpression, 0)
+	qinfo.ForeignKeyDefExpressions_ = make([]*ForeignKeyDefExpression, 0)
+	qinfo.CheckExprs_ = make([]*string, 0)
 	qinfo.TargetCols_ = make([]*string, 0)
 	qinfo.Values_ = make([]*types.Value, 0)
 	qinfo.OnExpressions_ = new(BinaryOpExpression)
@@ -27,6 +34,8 @@ func NewRootSQLVisitor() *RootSQLVisitor {
 	qinfo.LimitNum_ = -1
 	qinfo.OffsetNum_ = -1
 	qinfo.OrderByExpressions_ = make([]*OrderByExpression, 0)
+	qinfo.AlterTableExpressions_ = make([]*AlterTableExpression, 0)
+	qinfo.SequenceFuncExpressions_ = make([]*SequenceFuncExpression, 0)
 	ret.QueryInfo_ = qinfo
 
 	return ret
@@ -40,25 +49,158 @@ func (v *RootSQLVisitor) Enter(in ast.Node) (ast.Node, bool) {
 	switch node := in.(type) {
 	case *ast.SelectStmt:
 		*v.QueryInfo_.QueryType_ = SELECT
+		if node.Where, v.err = v.extractWhereExprStr(node.Where); v.err != nil {
+			return in, true
+		}
+		// GROUP BY and HAVING are planned from SQL text
+		if node.GroupBy != nil {
+			for _, item := range node.GroupBy.Items {
+				exprStr, err := ExprNodeToString(item.Expr)
+				if err != nil {
+					v.err = err
+					return in, true
+				}
+				v.QueryInfo_.GroupByExprStrs_ = append(v.QueryInfo_.GroupByExprStrs_, &exprStr)
+			}
+			node.GroupBy = nil
+		}
+		if node.Having != nil {
+			exprStr, err := ExprNodeToString(node.Having.Expr)
+			if err != nil {
+				v.err = err
+				return in, true
+			}
+			v.QueryInfo_.HavingExprStr_ = &exprStr
+			node.Having = nil
+		}
 	case *ast.CreateTableStmt:
 		*v.QueryInfo_.QueryType_ = CREATE_TABLE
 	case *ast.InsertStmt:
 		*v.QueryInfo_.QueryType_ = INSERT
+		// INSERT IGNORE skips only tuples which conflict with stored tuples on unique columns
+		v.QueryInfo_.OnConflictDoNothing_ = node.IgnoreErr
+		if v.QueryInfo_.UpsertSetExpressions_, v.err = onDuplicateToSetExpressions(node.OnDuplicate); v.err != nil {
+			return in, true
+		}
+		if node.Select != nil {
+			// INSERT ... SELECT. the SELECT statement is planned separately, so it is not visited
+			node.Table.Accept(v)
+			for _, col := range node.Columns {
+				cname := col.String()
+				v.QueryInfo_.TargetCols_ = append(v.QueryInfo_.TargetCols_, &cname)
+			}
+			selectSQL, err := nodeToString(node.Select)
+			if err != nil {
+				v.err = err
+				return in, true
+			}
+			v.QueryInfo_.InsertSelectSQL_ = &selectSQL
+			return in, true
+		}
 	case *ast.DeleteStmt:
 		*v.QueryInfo_.QueryType_ = DELETE
+		if node.Where, v.err = v.extractWhereExprStr(node.Where); v.err != nil {
+			return in, true
+		}
 	case *ast.UpdateStmt:
 		*v.QueryInfo_.QueryType_ = UPDATE
+		if node.Where, v.err = v.extractWhereExprStr(node.Where); v.err != nil {
+			return in, true
+		}
+	case *ast.AlterTableStmt:
+		*v.QueryInfo_.QueryType_ = ALTER_TABLE
+		tbname := node.Table.Name.String()
+		v.QueryInfo_.JoinTables_ = append(v.QueryInfo_.JoinTables_, &tbname)
+		for _, spec := range node.Specs {
+			ates, err := alterTableSpecToExpressions(spec)
+			if err != nil {
+				v.err = err
+				return in, true
+			}
+			v.QueryInfo_.AlterTableExpressions_ = append(v.QueryInfo_.AlterTableExpressions_, ates...)
+		}
+		return in, true
+	case *ast.CreateSequenceStmt:
+		*v.QueryInfo_.QueryType_ = CREATE_SEQUENCE
+		v.QueryInfo_.SequenceDef_ = createSequenceStmtToSequenceDefExpression(node)
+		return in, true
+	case *ast.CreateIndexStmt:
+		*v.QueryInfo_.QueryType_ = CREATE_INDEX
+		tbname := node.Table.Name.String()
+		v.QueryInfo_.JoinTables_ = append(v.QueryInfo_.JoinTables_, &tbname)
+		idf, err := indexKeysToIndexDefExpression(node.IndexName, node.IndexPartSpecifications)
+		if err != nil {
+			v.err = err
+			return in, true
+		}
+		idf.IsUnique_ = node.KeyType == ast.IndexKeyTypeUnique
+		v.QueryInfo_.IndexDefExpressions_ = append(v.QueryInfo_.IndexDefExpressions_, idf)
+		return in, true
+	case *ast.CreateViewStmt:
+		*v.QueryInfo_.QueryType_ = CREATE_VIEW
+		viewName := node.ViewName.Name.String()
+		selectSQL, err := nodeToString(node.Select)
+		if err != nil {
+			v.err = err
+			return in, true
+		}
+		v.QueryInfo_.ViewDef_ = &ViewDefExpression{&viewName, &selectSQL, false, len(node.Cols) > 0}
+		return in, true
+	case *ast.FuncCallExpr:
+		// NEXTVAL and CURRVAL in VALUES of INSERT. they are evaluated at planning
+		if *v.QueryInfo_.QueryType_ == INSERT {
+			// date/time literal and NOW() etc. are constant value
+			if val := TemporalFuncToValue(node); val != nil {
+				v.QueryInfo_.Values_ = append(v.QueryInfo_.Values_, val)
+				return in, true
+			}
+			funcType, seqName, isSeqFunc, err := sequenceFuncOf(node)
+			if err != nil {
+				v.err = err
+				return in, true
+			}
+			if !isSeqFunc {
+				v.err = samehada_errors.NewParseError("", "function "+node.FnName.O+" is not supported in VALUES.")
+				return in, true
+			}
+			sfe := &SequenceFuncExpression{FuncType_: funcType, SeqName_: &seqName}
+			// placeholder
+			nullVal := types.NewNull()
+			v.QueryInfo_.Values_ = append(v.QueryInfo_.Values_, &nullVal)
+			sfe.ValueIdx_ = len(v.QueryInfo_.Values_) - 1
+			v.QueryInfo_.SequenceFuncExpressions_ = append(v.QueryInfo_.SequenceFuncExpressions_, sfe)
+			return in, true
+		}
 	case *ast.FieldList:
 	case *ast.SelectField:
-		sv := &SelectFieldsVisitor{v.QueryInfo_}
+		sv := &SelectFieldsVisitor{v.QueryInfo_, nil}
 		node.Accept(sv)
+		v.err = sv.err
 		return in, true
 	case *ast.TableRefsClause:
 	case *ast.Assignment:
+		if *v.QueryInfo_.QueryType_ == INSERT {
+			// ON DUPLICATE KEY UPDATE is processed at InsertStmt
+			return in, true
+		}
 		// when UPDATE
 		av := new(AssignVisitor)
-		node.Accept(av)
 		setExp := new(SetExpression)
+		if _, isLiteral := node.Expr.(*driver.ValueExpr); isLiteral {
+			node.Accept(av)
+		} else {
+			node.Column.Accept(av)
+			exprStr, err := ExprNodeToString(node.Expr)
+			if err != nil {
+				v.err = err
+				return in, true
+			}
+			setExp.UpdateExprStr_ = &exprStr
+		}
+		if av.err != nil {
+			v.err = av.err
+			return in, true
+		}
 		setExp.ColName_ = av.Colname_
 		setExp.UpdateValue_ = av.Value_
 		v.QueryInfo_.SetExpressions_ = append(v.QueryInfo_.SetExpressions_, setExp)
@@ -67,6 +209,7 @@ func (v *RootSQLVisitor) Enter(in ast.Node) (ast.Node, bool) {
 		jv := new(JoinVisitor)
 		jv.QueryInfo_ = v.QueryInfo_
 		node.Accept(jv)
+		v.err = jv.err
 		return in, true
 	case *ast.OnCondition:
 	case *ast.TableSource:
@@ -79,34 +222,51 @@ func (v *RootSQLVisitor) Enter(in ast.Node) (ast.Node, bool) {
 		}
 	case *ast.ColumnDef:
 		if *v.QueryInfo_.QueryType_ == CREATE_TABLE {
-			cdef := new(ColDefExpression)
-			cname := node.Name.String()
-			cdef.ColName_ = &cname
-			col_type := node.Tp.Tp
-			switch col_type {
-			case mysql.TypeTiny, mysql.TypeLong:
-				ctype := types.Integer
-				cdef.ColType_ = &ctype
-			case mysql.TypeFloat, mysql.TypeLonglong:
-				ctype := types.Float
-				cdef.ColType_ = &ctype
-			default:
-				ctype := types.Varchar
-				cdef.ColType_ = &ctype
+			cdef, err := columnDefToColDefExpression(node)
+			if err != nil {
+				v.err = err
+				return in, true
 			}
 			v.QueryInfo_.ColDefExpressions_ = append(v.QueryInfo_.ColDefExpressions_, cdef)
 			return in, true
 		}
 	case *ast.Constraint:
 		// Index definition at CREATE TABLE
+		if *v.QueryInfo_.QueryType_ == CREATE_TABLE && node.Tp == ast.ConstraintForeignKey {
+			colnames := make([]*string, 0)
+			for _, key := range node.Keys {
+				cname := key.Column.Name.String()
+				colnames = append(colnames, &cname)
+			}
+			fkdef, err := referenceDefToForeignKeyDefExpression(colnames, node.Refer)
+			if err != nil {
+				v.err = err
+				return in, true
+			}
+			v.QueryInfo_.ForeignKeyDefExpressions_ = append(v.QueryInfo_.ForeignKeyDefExpressions_, fkdef)
+			return in, true
+		}
+		if *v.QueryInfo_.QueryType_ == CREATE_TABLE && node.Tp == ast.ConstraintCheck {
+			exprStr, err := ExprNodeToString(node.Expr)
+			if err != nil {
+				v.err = err
+				return in, true
+			}
+			v.QueryInfo_.CheckExprs_ = append(v.QueryInfo_.CheckExprs_, &exprStr)
+			return in, true
+		}
 		if *v.QueryInfo_.QueryType_ == CREATE_TABLE {
-			// get all specified column
-			cdv := &ChildDataVisitor{make([]interface{}, 0)}
-			node.Accept(cdv)
-			idf := new(IndexDefExpression)
-			idf.IndexName_ = &node.Name
-			for _, colname := range cdv.ChildDatas_ {
-				idf.Colnames_ = append(idf.Colnames_, colname.(*string))
+			idf, err := indexKeysToIndexDefExpression(node.Name, node.Keys)
+			if err != nil {
+				v.err = err
+				return in, true
+			}
+			switch node.Tp {
+			case ast.ConstraintPrimaryKey:
+				idf.IsPrimaryKey_ = true
+				idf.IsUnique_ = true
+			case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+				idf.IsUnique_ = true
 			}
 			v.QueryInfo_.IndexDefExpressions_ = append(v.QueryInfo_.IndexDefExpressions_, idf)
 			return in, true
@@ -118,24 +278,52 @@ func (v *RootSQLVisitor) Enter(in ast.Node) (ast.Node, bool) {
 			v.QueryInfo_.TargetCols_ = append(v.QueryInfo_.TargetCols_, &cname)
 			return in, true
 		}
+	case *ast.UnaryOperationExpr:
+		// when INSERT (ex: VALUES (-5))
+		if *v.QueryInfo_.QueryType_ == INSERT {
+			v.appendConstExprValue(node)
+			return in, true
+		}
 	case *ast.BinaryOperationExpr:
+		// when INSERT (ex: VALUES (0 - 5))
+		if *v.QueryInfo_.QueryType_ == INSERT {
+			v.appendConstExprValue(node)
+			return in, true
+		}
 		// for WHERE clause. other clauses handles BinaryOperationExpr with self visitor
-		new_visitor := &BinaryOpVisitor{v.QueryInfo_, new(BinaryOpExpression)}
+		new_visitor := &BinaryOpVisitor{v.QueryInfo_, new(BinaryOpExpression), nil}
 		node.Accept(new_visitor)
+		if new_visitor.err != nil {
+			v.err = new_visitor.err
+			return in, true
+		}
 		v.QueryInfo_.WhereExpression_ = new_visitor.BinaryOpExpression_
 
-		logicType, compType := GetTypesForBOperationExpr(node.Op)
+		logicType, compType, err := GetTypesForBOperationExpr(node.Op)
+		if err != nil {
+			v.err = err
+			return in, true
+		}
 		v.QueryInfo_.WhereExpression_.LogicalOperationType_ = logicType
 		v.QueryInfo_.WhereExpression_.ComparisonOperationType_ = compType
 
 		return in, true
 	case *driver.ValueExpr:
 		// when INSERT
-		v.QueryInfo_.Values_ = append(v.QueryInfo_.Values_, ValueExprToValue(node))
+		val, err := ValueExprToValue(node)
+		if err != nil {
+			v.err = err
+			return in, true
+		}
+		v.QueryInfo_.Values_ = append(v.QueryInfo_.Values_, val)
 		return in, true
 	case *ast.Limit:
-		cdv := &ChildDataVisitor{make([]interface{}, 0)}
+		cdv := &ChildDataVisitor{make([]interface{}, 0), nil}
 		node.Accept(cdv)
+		if cdv.err != nil {
+			v.err = cdv.err
+			return in, true
+		}
 		if len(cdv.ChildDatas_) == 1 {
 			v.QueryInfo_.LimitNum_ = cdv.ChildDatas_[0].(*types.Value).ToInteger()
 		} else { // 2
@@ -146,19 +334,339 @@ func (v *RootSQLVisitor) Enter(in ast.Node) (ast.Node, bool) {
 		return in, true
 	case *ast.OrderByClause:
 	case *ast.ByItem:
-		cdv := &ChildDataVisitor{make([]interface{}, 0)}
+		cdv := &ChildDataVisitor{make([]interface{}, 0), nil}
 		node.Accept(cdv)
+		if cdv.err != nil {
+			v.err = cdv.err
+			return in, true
+		}
+		var colName *string = nil
+		if len(cdv.ChildDatas_) == 1 {
+			colName, _ = cdv.ChildDatas_[0].(*string)
+		}
+		if colName == nil {
+			v.err = samehada_errors.NewParseError("", "only column name is supported in ORDER BY: "+nodeToMessageString(node))
+			return in, true
+		}
 		obe := new(OrderByExpression)
 		obe.IsDesc_ = node.Desc
-		obe.ColName_ = cdv.ChildDatas_[0].(*string)
+		obe.ColName_ = colName
 		v.QueryInfo_.OrderByExpressions_ = append(v.QueryInfo_.OrderByExpressions_, obe)
 		return in, true
 	default:
-		panic("unknown node for visitor")
+		v.err = samehada_errors.NewParseError("", "not supported syntax: "+nodeToMessageString(in))
+		return in, true
 	}
 	return in, false
 }
 
+// extractWhereExprStr stores SQL text of where to WhereExprStr_ when it can not be
+// represented with BinaryOpExpression. returned node is visited as WHERE clause
+func (v *RootSQLVisitor) extractWhereExprStr(where ast.ExprNode) (ast.ExprNode, error) {
+	// IS NULL is handled by BinaryOpVisitor only when it is operand of AND/OR
+	if _, isIsNull := where.(*ast.IsNullExpr); where == nil || (isSimplePredicate(where) && !isIsNull) {
+		return where, nil
+	}
+	exprStr, err := ExprNodeToString(where)
+	if err != nil {
+		return where, err
+	}
+	v.QueryInfo_.WhereExprStr_ = &exprStr
+	return nil, nil
+}
+
+// isSimplePredicate returns true when node is comparison of column and constant
+// or AND/OR of them
+func isSimplePredicate(node ast.ExprNode) bool {
+	switch node := node.(type) {
+	case *ast.ParenthesesExpr:
+		return isSimplePredicate(node.Expr)
+	case *ast.BinaryOperationExpr:
+		switch node.Op {
+		case opcode.LogicAnd, opcode.LogicOr:
+			return isSimplePredicate(node.L) && isSimplePredicate(node.R)
+		case opcode.EQ, opcode.NE, opcode.GT, opcode.GE, opcode.LT, opcode.LE:
+			_, isCol := node.L.(*ast.ColumnNameExpr)
+			_, isConst := node.R.(*driver.ValueExpr)
+			return isCol && isConst
+		}
+	case *ast.IsNullExpr:
+		_, isCol := node.Expr.(*ast.ColumnNameExpr)
+		return isCol
+	}
+	return false
+}
+
+// value of ON DUPLICATE KEY UPDATE is constant or VALUES(col).
+// both of UpdateValue_ and ValuesOf_ are nil when other expression is specified
+func onDuplicateToSetExpressions(assignments []*ast.Assignment) ([]*SetExpression, error) {
+	ret := make([]*SetExpression, 0)
+	for _, assignment := range assignments {
+		setExp := new(SetExpression)
+		colName := assignment.Column.Name.String()
+		setExp.ColName_ = &colName
+		switch expr := assignment.Expr.(type) {
+		case *driver.ValueExpr:
+			var err error
+			if setExp.UpdateValue_, err = ValueExprToValue(expr); err != nil {
+				return nil, err
+			}
+		case *ast.ValuesExpr:
+			srcColName := expr.Column.Name.Name.String()
+			setExp.ValuesOf_ = &srcColName
+		default:
+			var err error
+			if setExp.UpdateValue_, err = ConstExprToValue(expr); err != nil {
+				return nil, err
+			}
+		}
+		ret = append(ret, setExp)
+	}
+	return ret, nil
+}
+
+func columnDefToColDefExpression(node *ast.ColumnDef) (*ColDefExpression, error) {
+	cdef := new(ColDefExpression)
+	cname := node.Name.String()
+	cdef.ColName_ = &cname
+	col_type := node.Tp.Tp
+	switch col_type {
+	case mysql.TypeTiny:
+		// BOOL and BOOLEAN are parsed as TINYINT(1) like MySQL
+		ctype := types.Tinyint
+		if node.Tp.Flen == 1 {
+			ctype = types.Boolean
+		}
+		cdef.ColType_ = &ctype
+	case mysql.TypeShort:
+		ctype := types.Smallint
+		cdef.ColType_ = &ctype
+	case mysql.TypeInt24, mysql.TypeLong:
+		ctype := types.Integer
+		cdef.ColType_ = &ctype
+	case mysql.TypeLonglong:
+		ctype := types.BigInt
+		cdef.ColType_ = &ctype
+	case mysql.TypeFloat:
+		ctype := types.Float
+		cdef.ColType_ = &ctype
+	case mysql.TypeTimestamp, mysql.TypeDatetime:
+		ctype := types.Timestamp
+		cdef.ColType_ = &ctype
+	case mysql.TypeDate:
+		ctype := types.Date
+		cdef.ColType_ = &ctype
+	case mysql.TypeNewDecimal:
+		ctype := types.Decimal
+		cdef.ColType_ = &ctype
+		// -1 means that precision or scale is omitted
+		cdef.DecimalPrecision_ = types.DecimalDefaultPrecision
+		cdef.DecimalScale_ = types.DecimalDefaultScale
+		if node.Tp.Flen >= 0 {
+			cdef.DecimalPrecision_ = int32(node.Tp.Flen)
+		}
+		if node.Tp.Decimal >= 0 {
+			cdef.DecimalScale_ = int32(node.Tp.Decimal)
+		}
+	case mysql.TypeTinyBlob, mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		// TEXT family and BLOB family are distinguished by binary flag
+		ctype := types.Text
+		if mysql.HasBinaryFlag(node.Tp.Flag) {
+			ctype = types.Blob
+		}
+		cdef.ColType_ = &ctype
+	case mysql.TypeJSON:
+		ctype := types.JSON
+		cdef.ColType_ = &ctype
+	default:
+		ctype := types.Varchar
+		cdef.ColType_ = &ctype
+	}
+	var err error
+	for _, opt := range node.Options {
+		switch opt.Tp {
+		case ast.ColumnOptionPrimaryKey:
+			cdef.IsPrimaryKey_ = true
+			cdef.IsUnique_ = true
+		case ast.ColumnOptionUniqKey:
+			cdef.IsUnique_ = true
+		case ast.ColumnOptionNotNull:
+			cdef.IsNotNull_ = true
+		case ast.ColumnOptionDefaultValue:
+			if funcCall, ok := opt.Expr.(*ast.FuncCallExpr); ok {
+				// DEFAULT NEXTVAL(seq) is evaluated for each inserted tuple
+				if funcType, seqName, isSeqFunc, err := sequenceFuncOf(funcCall); isSeqFunc {
+					if err != nil {
+						return nil, err
+					}
+					if funcType != NEXTVAL {
+						return nil, samehada_errors.NewParseError("", "only NEXTVAL can be used as DEFAULT value of column "+cname+".")
+					}
+					cdef.DefaultSeq_ = &seqName
+					continue
+				}
+			}
+			if cdef.DefaultValue_, err = defaultExprToValue(opt.Expr); err != nil {
+				return nil, err
+			}
+		case ast.ColumnOptionReference:
+			if cdef.ForeignKey_, err = referenceDefToForeignKeyDefExpression([]*string{&cname}, opt.Refer); err != nil {
+				return nil, err
+			}
+		case ast.ColumnOptionCheck:
+			exprStr, err := ExprNodeToString(opt.Expr)
+			if err != nil {
+				return nil, err
+			}
+			cdef.CheckExpr_ = &exprStr
+		case ast.ColumnOptionAutoIncrement:
+			// SERIAL is BIGINT AUTO_INCREMENT
+			cdef.IsAutoIncrement_ = true
+		case ast.ColumnOptionGenerated:
+			// both of STORED and VIRTUAL generated column are stored
+			exprStr, err := ExprNodeToString(opt.Expr)
+			if err != nil {
+				return nil, err
+			}
+			cdef.GeneratedExpr_ = &exprStr
+		}
+	}
+	return cdef, nil
+}
+
+// indexKeysToIndexDefExpression returns definition of index which has keys.
+// key which is expression is kept as SQL text
+func indexKeysToIndexDefExpression(name string, keys []*ast.IndexPartSpecification) (*IndexDefExpression, error) {
+	idf := new(IndexDefExpression)
+	idf.IndexName_ = &name
+	for _, key := range keys {
+		if key.Expr != nil {
+			exprStr, err := ExprNodeToString(key.Expr)
+			if err != nil {
+				return nil, err
+			}
+			idf.KeyExprStrs_ = append(idf.KeyExprStrs_, &exprStr)
+		} else {
+			cname := key.Column.Name.String()
+			idf.Colnames_ = append(idf.Colnames_, &cname)
+		}
+	}
+	return idf, nil
+}
+
+func createSequenceStmtToSequenceDefExpression(node *ast.CreateSequenceStmt) *SequenceDefExpression {
+	sdef := new(SequenceDefExpression)
+	seqName := node.Name.Name.String()
+	sdef.SeqName_ = &seqName
+	sdef.IncrementBy_ = 1
+	for _, opt := range node.SeqOptions {
+		switch opt.Tp {
+		case ast.SequenceStartWith:
+			startWith := opt.IntValue
+			sdef.StartWith_ = &startWith
+		case ast.SequenceOptionIncrementBy:
+			sdef.IncrementBy_ = opt.IntValue
+		default:
+			sdef.HasUnsupportedOption_ = true
+		}
+	}
+	return sdef
+}
+
+func referenceDefToForeignKeyDefExpression(colnames []*string, refer *ast.ReferenceDef) (*ForeignKeyDefExpression, error) {
+	fkdef := new(ForeignKeyDefExpression)
+	fkdef.Colnames_ = colnames
+	refTable := refer.Table.Name.String()
+	fkdef.RefTable_ = &refTable
+	for _, spec := range refer.IndexPartSpecifications {
+		refColname := spec.Column.Name.String()
+		fkdef.RefColnames_ = append(fkdef.RefColnames_, &refColname)
+	}
+	var err error
+	fkdef.OnDelete_ = column.FK_ACTION_RESTRICT
+	if refer.OnDelete != nil {
+		if fkdef.OnDelete_, err = referOptionToReferentialAction(refer.OnDelete.ReferOpt); err != nil {
+			return nil, err
+		}
+	}
+	fkdef.OnUpdate_ = column.FK_ACTION_RESTRICT
+	if refer.OnUpdate != nil {
+		if fkdef.OnUpdate_, err = referOptionToReferentialAction(refer.OnUpdate.ReferOpt); err != nil {
+			return nil, err
+		}
+	}
+	return fkdef, nil
+}
+
+func referOptionToReferentialAction(opt ast.ReferOptionType) (column.ReferentialAction, error) {
+	switch opt {
+	case ast.ReferOptionNoOption, ast.ReferOptionRestrict, ast.ReferOptionNoAction:
+		return column.FK_ACTION_RESTRICT, nil
+	case ast.ReferOptionCascade:
+		return column.FK_ACTION_CASCADE, nil
+	case ast.ReferOptionSetNull:
+		return column.FK_ACTION_SET_NULL, nil
+	default:
+		return column.FK_ACTION_RESTRICT, samehada_errors.NewParseError("", "referential action "+opt.String()+" is not supported.")
+	}
+}
+
+// only constant value (negative number and date/time literal) is supported as DEFAULT value
+// appendConstExprValue appends value of expression in VALUES of INSERT to Values_
+func (v *RootSQLVisitor) appendConstExprValue(node ast.ExprNode) {
+	val, err := ConstExprToValue(node)
+	if err != nil {
+		v.err = err
+		return
+	}
+	v.QueryInfo_.Values_ = append(v.QueryInfo_.Values_, val)
+}
+
+func defaultExprToValue(expr ast.ExprNode) (*types.Value, error) {
+	switch node := expr.(type) {
+	case *driver.ValueExpr:
+		return ValueExprToValue(node)
+	case *ast.FuncCallExpr:
+		if node.FnName.L == ast.DateLiteral || node.FnName.L == ast.TimestampLiteral {
+			if val := TemporalFuncToValue(node); val != nil {
+				return val, nil
+			}
+		}
+	case *ast.UnaryOperationExpr:
+		if valExpr, ok := node.V.(*driver.ValueExpr); ok && node.Op == opcode.Minus {
+			return NegatedValueExprToValue(valExpr)
+		}
+	}
+	return nil, samehada_errors.NewParseError("", "not supported DEFAULT value: "+nodeToMessageString(expr))
+}
+
+func alterTableSpecToExpressions(spec *ast.AlterTableSpec) ([]*AlterTableExpression, error) {
+	ret := make([]*AlterTableExpression, 0)
+	switch spec.Tp {
+	case ast.AlterTableAddColumns:
+		for _, colDef := range spec.NewColumns {
+			cdef, err := columnDefToColDefExpression(colDef)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, &AlterTableExpression{AlterType_: ADD_COLUMN, ColDef_: cdef})
+		}
+	case ast.AlterTableDropColumn:
+		cname := spec.OldColumnName.Name.String()
+		ret = append(ret, &AlterTableExpression{AlterType_: DROP_COLUMN, ColName_: &cname})
+	case ast.AlterTableRenameColumn:
+		cname := spec.OldColumnName.Name.String()
+		newName := spec.NewColumnName.Name.String()
+		ret = append(ret, &AlterTableExpression{AlterType_: RENAME_COLUMN, ColName_: &cname, NewName_: &newName})
+	case ast.AlterTableRenameTable:
+		newName := spec.NewTable.Name.String()
+		ret = append(ret, &AlterTableExpression{AlterType_: RENAME_TABLE, NewName_: &newName})
+	default:
+		return nil, samehada_errors.NewParseError("", "not supported ALTER TABLE specification: "+nodeToMessageString(spec))
+	}
+	return ret, nil
+}
+
 func (v *RootSQLVisitor) Leave(in ast.Node) (ast.Node, bool) {
-	return in, true
+	return in, v.err == nil
 }
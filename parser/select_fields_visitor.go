@@ -2,12 +2,16 @@ package parser
 
 import (
 	"github.com/pingcap/parser/ast"
+	driver "github.com/pingcap/tidb/types/parser_driver"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
+	"github.com/ryogrid/SamehadaDB/types"
 	"strings"
 )
 
 type SelectFieldsVisitor struct {
 	QueryInfo_ *QueryInfo
+	// first error occurred in visiting. visiting is stopped when it is set
+	err error
 }
 
 func (v *SelectFieldsVisitor) Enter(in ast.Node) (ast.Node, bool) {
@@ -37,22 +41,88 @@ func (v *SelectFieldsVisitor) Enter(in ast.Node) (ast.Node, bool) {
 			v.QueryInfo_.SelectFields_ = append(v.QueryInfo_.SelectFields_, sfield)
 			return in, true
 		}
+		if windowFunc, ok := node.Expr.(*ast.WindowFuncExpr); ok {
+			sfield := new(SelectFieldExpression)
+			colname := node.AsName.O
+			if colname == "" {
+				colname = strings.TrimSpace(node.Text())
+			}
+			if colname == "" {
+				colname = windowFunc.F
+			}
+			sfield.ColName_ = &colname
+			if sfield.WindowFunc_, v.err = windowFuncToWindowFuncExpression(windowFunc); v.err != nil {
+				return in, true
+			}
+			v.QueryInfo_.SelectFields_ = append(v.QueryInfo_.SelectFields_, sfield)
+			return in, true
+		}
+		var alias *string = nil
+		if node.AsName.O != "" {
+			aliasStr := node.AsName.O
+			alias = &aliasStr
+		}
+		switch expr := node.Expr.(type) {
+		case *ast.ColumnNameExpr:
+			expr.Accept(v)
+			v.QueryInfo_.SelectFields_[len(v.QueryInfo_.SelectFields_)-1].Alias_ = alias
+			return in, true
+		case *ast.AggregateFuncExpr:
+			if isBuiltinAggregateFunc(expr.F) {
+				if _, ok := expr.Accept(v); !ok {
+					return in, true
+				}
+				sfield := v.QueryInfo_.SelectFields_[len(v.QueryInfo_.SelectFields_)-1]
+				sfield.Alias_ = alias
+				// aggregation query is planned from SQL text
+				exprStr, err := ExprNodeToString(expr)
+				if err != nil {
+					v.err = err
+					return in, true
+				}
+				sfield.ExprStr_ = &exprStr
+				return in, true
+			}
+		}
+		// function call, CASE, CAST, arithmetic operation etc.
+		sfield := new(SelectFieldExpression)
+		exprStr, err := ExprNodeToString(node.Expr)
+		if err != nil {
+			v.err = err
+			return in, true
+		}
+		sfield.ExprStr_ = &exprStr
+		sfield.Alias_ = alias
+		colname := node.AsName.O
+		if colname == "" {
+			colname = strings.TrimSpace(node.Text())
+		}
+		if colname == "" {
+			colname = exprStr
+		}
+		sfield.ColName_ = &colname
+		v.QueryInfo_.SelectFields_ = append(v.QueryInfo_.SelectFields_, sfield)
+		return in, true
 	case *ast.AggregateFuncExpr:
 		av := new(AggFuncVisitor)
 		node.Accept(av)
+		if av.err != nil {
+			v.err = av.err
+			return in, true
+		}
 		var sfield *SelectFieldExpression = nil
-		aggTypeStr := node.F
+		aggTypeStr := strings.ToLower(node.F)
 		switch aggTypeStr {
 		case "count":
-			sfield = &SelectFieldExpression{true, plans.COUNT_AGGREGATE, av.TableName_, av.ColumnName_}
+			sfield = &SelectFieldExpression{true, plans.COUNT_AGGREGATE, av.TableName_, av.ColumnName_, nil, nil, nil}
 		//case "avg":
 		//	sfield = &SelectFieldExpression{true, plans.A, av.ColumnName_}
 		case "max":
-			sfield = &SelectFieldExpression{true, plans.MAX_AGGREGATE, av.TableName_, av.ColumnName_}
+			sfield = &SelectFieldExpression{true, plans.MAX_AGGREGATE, av.TableName_, av.ColumnName_, nil, nil, nil}
 		case "min":
-			sfield = &SelectFieldExpression{true, plans.MIN_AGGREGATE, av.TableName_, av.ColumnName_}
+			sfield = &SelectFieldExpression{true, plans.MIN_AGGREGATE, av.TableName_, av.ColumnName_, nil, nil, nil}
 		case "sum":
-			sfield = &SelectFieldExpression{true, plans.SUM_AGGREGATE, av.TableName_, av.ColumnName_}
+			sfield = &SelectFieldExpression{true, plans.SUM_AGGREGATE, av.TableName_, av.ColumnName_, nil, nil, nil}
 		}
 
 		v.QueryInfo_.SelectFields_ = append(v.QueryInfo_.SelectFields_, sfield)
@@ -62,6 +132,119 @@ func (v *SelectFieldsVisitor) Enter(in ast.Node) (ast.Node, bool) {
 	return in, false
 }
 
+// aggregate functions which are represented with AggType_ of SelectFieldExpression
+func isBuiltinAggregateFunc(name string) bool {
+	switch strings.ToLower(name) {
+	case "count", "max", "min", "sum":
+		return true
+	}
+	return false
+}
+
+func windowFuncToWindowFuncExpression(node *ast.WindowFuncExpr) (*WindowFuncExpression, error) {
+	funcName := strings.ToLower(node.F)
+	ret := &WindowFuncExpression{FuncName_: &funcName, Offset_: 1}
+	ret.HasUnsupportedOption_ = node.Distinct || node.IgnoreNull || node.FromLast || node.Spec.Name.O != "" || node.Spec.Ref.O != ""
+
+	for ii, arg := range node.Args {
+		switch argNode := arg.(type) {
+		case *ast.ColumnNameExpr:
+			if ii == 0 {
+				colName := argNode.Name.String()
+				ret.ArgColName_ = &colName
+				continue
+			}
+		case *driver.ValueExpr:
+			val, err := ValueExprToValue(argNode)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case ii == 0 && funcName == "count":
+				// COUNT(*)
+				continue
+			case ii == 1 && val.ValueType() == types.Integer && !val.IsNull():
+				ret.Offset_ = val.ToInteger()
+				continue
+			case ii == 2:
+				ret.DefaultVal_ = val
+				continue
+			}
+		}
+		ret.HasUnsupportedOption_ = true
+	}
+
+	if node.Spec.PartitionBy != nil {
+		for _, item := range node.Spec.PartitionBy.Items {
+			colName, ok := byItemToColName(item)
+			if !ok {
+				ret.HasUnsupportedOption_ = true
+				continue
+			}
+			ret.PartitionBy_ = append(ret.PartitionBy_, colName)
+		}
+	}
+	if node.Spec.OrderBy != nil {
+		for _, item := range node.Spec.OrderBy.Items {
+			colName, ok := byItemToColName(item)
+			if !ok {
+				ret.HasUnsupportedOption_ = true
+				continue
+			}
+			ret.OrderBy_ = append(ret.OrderBy_, &OrderByExpression{item.Desc, colName})
+		}
+	}
+	if frame := node.Spec.Frame; frame != nil {
+		ret.Frame_ = &WindowFrameExpression{frame.Type == ast.Rows, frameBoundToExpression(&frame.Extent.Start), frameBoundToExpression(&frame.Extent.End)}
+		if frame.Type != ast.Rows && frame.Type != ast.Ranges {
+			ret.HasUnsupportedOption_ = true
+		}
+		if ret.Frame_.Start_ == nil || ret.Frame_.End_ == nil {
+			ret.HasUnsupportedOption_ = true
+		}
+	}
+	return ret, nil
+}
+
+func byItemToColName(item *ast.ByItem) (*string, bool) {
+	colNameExpr, ok := item.Expr.(*ast.ColumnNameExpr)
+	if !ok {
+		return nil, false
+	}
+	colName := colNameExpr.Name.String()
+	return &colName, true
+}
+
+// nil is returned when offset of the bound is not a constant integer
+func frameBoundToExpression(bound *ast.FrameBound) *WindowFrameBoundExpression {
+	ret := new(WindowFrameBoundExpression)
+	switch {
+	case bound.Type == ast.CurrentRow:
+		ret.BoundType_ = plans.CURRENT_ROW
+		return ret
+	case bound.UnBounded && bound.Type == ast.Preceding:
+		ret.BoundType_ = plans.UNBOUNDED_PRECEDING
+		return ret
+	case bound.UnBounded:
+		ret.BoundType_ = plans.UNBOUNDED_FOLLOWING
+		return ret
+	case bound.Type == ast.Preceding:
+		ret.BoundType_ = plans.PRECEDING
+	default:
+		ret.BoundType_ = plans.FOLLOWING
+	}
+	valExpr, ok := bound.Expr.(*driver.ValueExpr)
+	if !ok {
+		return nil
+	}
+	val, err := ValueExprToValue(valExpr)
+	if err != nil || val.ValueType() != types.Integer || val.IsNull() {
+		return nil
+	}
+	ret.Offset_ = val.ToInteger()
+	return ret
+}
+
 func (v *SelectFieldsVisitor) Leave(in ast.Node) (ast.Node, bool) {
-	return in, true
+	return in, v.err == nil
 }
@@ -2,12 +2,13 @@ package planner
 
 import (
 	"errors"
-	"fmt"
 	"github.com/ryogrid/SamehadaDB/catalog"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/execution/executors"
 	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
 	"github.com/ryogrid/SamehadaDB/parser"
+	"github.com/ryogrid/SamehadaDB/samehada/samehada_util"
 	"github.com/ryogrid/SamehadaDB/storage/access"
 	"github.com/ryogrid/SamehadaDB/storage/buffer"
 	"github.com/ryogrid/SamehadaDB/storage/index/index_constants"
@@ -15,6 +16,7 @@ import (
 	"github.com/ryogrid/SamehadaDB/storage/table/schema"
 	"github.com/ryogrid/SamehadaDB/types"
 	"math"
+	"strings"
 )
 
 type SimplePlanner struct {
@@ -22,15 +24,21 @@ type SimplePlanner struct {
 	catalog_ *catalog.Catalog
 	bpm      *buffer.BufferPoolManager
 	txn      *access.Transaction
+	// CTEs which can be referenced from the query being planned. key is name of CTE
+	ctes map[string]*cteInfo
 }
 
 func NewSimplePlanner(c *catalog.Catalog, bpm *buffer.BufferPoolManager) *SimplePlanner {
-	return &SimplePlanner{nil, c, bpm, nil}
+	return &SimplePlanner{nil, c, bpm, nil, nil}
 }
 
 func (pner *SimplePlanner) MakePlan(qi *parser.QueryInfo, txn *access.Transaction) (error, plans.Plan) {
+	if qi == nil {
+		return createError("query info is not passed.")
+	}
 	pner.qi = qi
 	pner.txn = txn
+	pner.ctes = nil
 
 	switch *pner.qi.QueryType_ {
 	case parser.SELECT:
@@ -38,41 +46,89 @@ func (pner *SimplePlanner) MakePlan(qi *parser.QueryInfo, txn *access.Transactio
 	case parser.CREATE_TABLE:
 		return pner.MakeCreateTablePlan()
 	case parser.INSERT:
-		return pner.MakeInsertPlan()
+		return pner.makeReturningPlan(pner.MakeInsertPlan())
 	case parser.DELETE:
-		return pner.MakeDeletePlan()
+		return pner.makeReturningPlan(pner.MakeDeletePlan())
 	case parser.UPDATE:
-		return pner.MakeUpdatePlan()
+		return pner.makeReturningPlan(pner.MakeUpdatePlan())
+	case parser.ALTER_TABLE:
+		return pner.MakeAlterTablePlan()
+	case parser.CREATE_SEQUENCE:
+		return pner.MakeCreateSequencePlan()
+	case parser.CREATE_VIEW:
+		return pner.MakeCreateViewPlan()
+	case parser.REFRESH_MATERIALIZED_VIEW:
+		return pner.MakeRefreshMaterializedViewPlan()
+	case parser.CREATE_INDEX:
+		return pner.MakeCreateIndexPlan()
 	default:
-		panic("unknown query type")
+		return returnError(samehada_errors.NewParseError("", "not supported statement."))
+	}
+}
+
+// makeReturningPlan wraps plan of INSERT, UPDATE or DELETE with ProjectionPlanNode
+// which evaluates expressions of RETURNING clause for each affected tuple
+func (pner *SimplePlanner) makeReturningPlan(err error, plan plans.Plan) (error, plans.Plan) {
+	if err != nil || len(pner.qi.ReturningExprs_) == 0 {
+		return err, plan
+	}
+
+	tableSchema := pner.catalog_.GetTableByName(*pner.qi.JoinTables_[0]).Schema()
+	outColumns := make([]*column.Column, 0)
+	for _, retExpr := range pner.qi.ReturningExprs_ {
+		if *retExpr.ExprStr_ == "*" {
+			for colIdx, col := range tableSchema.GetColumns() {
+				colVal := expression.NewColumnValue(0, uint32(colIdx), col.GetType())
+				outColumns = append(outColumns, column.NewColumn(col.GetColumnName(), col.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), colVal))
+			}
+			continue
+		}
+		expr, exprType, err := pner.exprStrToExpression(*retExpr.ExprStr_, tableSchema)
+		if err != nil {
+			return returnError(samehada_errors.NewParseError("", "invalid expression in RETURNING clause: "+*retExpr.ExprStr_))
+		}
+		outColumns = append(outColumns, column.NewColumn(*retExpr.Alias_, exprType, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), expr))
 	}
+
+	return nil, plans.NewProjectionPlanNode(plan, schema.NewSchema(outColumns))
 }
 
 func (pner *SimplePlanner) MakeSelectPlanWithoutJoin() (error, plans.Plan) {
 	tblName := *pner.qi.JoinTables_[0]
+	if pner.ctes[tblName] != nil {
+		err, ctePlan := pner.makeCTEPlan(tblName)
+		if err != nil {
+			return err, nil
+		}
+		return pner.makeSelectPlanOnSubquery(tblName, ctePlan)
+	}
+	if view := pner.catalog_.GetView(tblName); view != nil && !view.IsMaterialized() {
+		err, viewPlan := pner.makeViewQueryPlan(view)
+		if err != nil {
+			return err, nil
+		}
+		return pner.makeSelectPlanOnSubquery("view "+view.GetName(), viewPlan)
+	}
 	tableMetadata := pner.catalog_.GetTableByName(tblName)
+	if tableMetadata == nil {
+		return returnError(samehada_errors.NewUnknownTableError(tblName))
+	}
 
 	tgtTblSchema := tableMetadata.Schema()
 	tgtTblColumns := tgtTblSchema.GetColumns()
-	hasWhere := pner.qi.WhereExpression_.Left_ != nil && pner.qi.WhereExpression_.Right_ != nil
+	hasWhere := pner.hasWhere()
 
 	outColDefs := make([]*column.Column, 0)
 	var outSchema *schema.Schema = nil
 	if !(len(pner.qi.SelectFields_) == 1 && *pner.qi.SelectFields_[0].ColName_ == "*") {
 		// column existance check
 		for _, sfield := range pner.qi.SelectFields_ {
-			colName := sfield.ColName_
-			isOk := false
-			for _, existCol := range tgtTblColumns {
-				if existCol.GetColumnName() == *colName {
-					isOk = true
-					outColDefs = append(outColDefs, column.NewColumn(*colName, existCol.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), existCol.GetExpr()))
-					break
-				}
-			}
-			if !isOk {
-				return PrintAndCreateError("column " + *colName + " does not exist.")
+			colIdx := getSelectFieldColIndex(tgtTblSchema, sfield)
+			if colIdx == math.MaxUint32 {
+				return returnError(samehada_errors.NewUnknownColumnError("", *sfield.ColName_))
 			}
+			existCol := tgtTblColumns[colIdx]
+			outColDefs = append(outColDefs, column.NewColumn(existCol.GetColumnName(), existCol.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), existCol.GetExpr()))
 		}
 		// Attention: this method call modifies passed Column objects
 		outSchema = schema.NewSchema(outColDefs)
@@ -82,46 +138,84 @@ func (pner *SimplePlanner) MakeSelectPlanWithoutJoin() (error, plans.Plan) {
 
 	var predicate expression.Expression = nil
 	if hasWhere {
-		predicate = pner.ConstructPredicate([]*schema.Schema{tgtTblSchema})
+		var err error
+		if predicate, err = pner.constructPredicateOnGeneratedColumn(tgtTblSchema); err != nil {
+			return returnError(err)
+		}
+		if predicate == nil {
+			if predicate, err = pner.ConstructPredicate([]*schema.Schema{tgtTblSchema}); err != nil {
+				return returnError(err)
+			}
+		}
 	}
 
+	if comparison := getIndexPointScanPredicate(tgtTblSchema, predicate); comparison != nil {
+		return nil, plans.NewPointScanWithIndexPlanNode(outSchema, comparison, tableMetadata.OID())
+	}
 	return nil, plans.NewSeqScanPlanNode(outSchema, predicate, tableMetadata.OID())
 }
 
+// constructPredicateOnGeneratedColumn returns predicate which compares indexed generated column
+// when WHERE clause is "expr = value" and expr is same as generation expression of the column.
+// nil is returned when WHERE clause is not the form
+func (pner *SimplePlanner) constructPredicateOnGeneratedColumn(schema_ *schema.Schema) (expression.Expression, error) {
+	if pner.qi.WhereExprStr_ == nil {
+		return nil, nil
+	}
+	lhsStr, rhsStr, ok := parser.EqualityOperandStrs(*pner.qi.WhereExprStr_)
+	if !ok {
+		return nil, nil
+	}
+	for _, col := range schema_.GetColumns() {
+		if col.IsGenerated() && col.IndexKind() == index_constants.INDEX_KIND_NON_UNIQUE_SKIP_LIST && col.GeneratedExprStr() == lhsStr {
+			predicate, _, err := pner.exprStrToExpression("`"+col.GetColumnName()+"` = "+rhsStr, schema_)
+			return predicate, err
+		}
+	}
+	return nil, nil
+}
+
+// getIndexPointScanPredicate returns predicate when it is "column = constant" and the column has
+// index which allows duplicated keys. otherwise nil is returned
+func getIndexPointScanPredicate(schema_ *schema.Schema, predicate expression.Expression) *expression.Comparison {
+	comparison, ok := predicate.(*expression.Comparison)
+	if !ok || comparison.GetComparisonType() != expression.Equal {
+		return nil
+	}
+	colVal, ok := comparison.GetChildAt(0).(*expression.ColumnValue)
+	if !ok {
+		return nil
+	}
+	constVal, ok := comparison.GetChildAt(1).(*expression.ConstantValue)
+	if !ok {
+		return nil
+	}
+	col := schema_.GetColumn(colVal.GetColIndex())
+	val := constVal.Evaluate(nil, schema_)
+	if col.IndexKind() != index_constants.INDEX_KIND_NON_UNIQUE_SKIP_LIST || val.IsNull() || val.ValueType() != col.GetType() {
+		return nil
+	}
+	return comparison
+}
+
 func (pner *SimplePlanner) MakeSelectPlanWithJoin() (error, plans.Plan) {
 	tblNameL := *pner.qi.JoinTables_[0]
-	tableMetadataL := pner.catalog_.GetTableByName(tblNameL)
-	tgtTblSchemaL := tableMetadataL.Schema()
-	tgtTblColumnsL := tgtTblSchemaL.GetColumns()
+	err, scanPlanL, srcSchemaL := pner.makeJoinSourcePlan(tblNameL)
+	if err != nil {
+		return err, nil
+	}
+	outSchemaL := scanPlanL.OutputSchema()
+	tgtTblColumnsL := srcSchemaL.GetColumns()
 
 	tblNameR := *pner.qi.JoinTables_[1]
-	tableMetadataR := pner.catalog_.GetTableByName(tblNameR)
-	tgtTblSchemaR := tableMetadataR.Schema()
-	tgtTblColumnsR := tgtTblSchemaR.GetColumns()
-
-	hasWhere := pner.qi.WhereExpression_.Left_ != nil && pner.qi.WhereExpression_.Right_ != nil
-
-	var outSchemaL *schema.Schema
-	var scanPlanL plans.Plan
-	{
-		var columns []*column.Column = make([]*column.Column, 0)
-		for _, col := range tgtTblColumnsL {
-			columns = append(columns, column.NewColumn(tblNameL+"."+col.GetColumnName(), col.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), col.GetExpr()))
-		}
-		outSchemaL = schema.NewSchema(columns)
-		scanPlanL = plans.NewSeqScanPlanNode(outSchemaL, nil, tableMetadataL.OID())
+	err, scanPlanR, srcSchemaR := pner.makeJoinSourcePlan(tblNameR)
+	if err != nil {
+		return err, nil
 	}
+	outSchemaR := scanPlanR.OutputSchema()
+	tgtTblColumnsR := srcSchemaR.GetColumns()
 
-	var outSchemaR *schema.Schema
-	var scanPlanR plans.Plan
-	{
-		var columns []*column.Column = make([]*column.Column, 0)
-		for _, col := range tgtTblColumnsR {
-			columns = append(columns, column.NewColumn(tblNameR+"."+col.GetColumnName(), col.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), col.GetExpr()))
-		}
-		outSchemaR = schema.NewSchema(columns)
-		scanPlanR = plans.NewSeqScanPlanNode(outSchemaR, nil, tableMetadataR.OID())
-	}
+	hasWhere := pner.hasWhere()
 
 	var joinPlan *plans.HashJoinPlanNode
 	var outFinal *schema.Schema
@@ -129,10 +223,15 @@ func (pner *SimplePlanner) MakeSelectPlanWithJoin() (error, plans.Plan) {
 	{
 		finalOutCols := make([]*column.Column, 0)
 
+		onColNameL := *pner.qi.OnExpressions_.Left_.(*string)
+		onColNameR := *pner.qi.OnExpressions_.Right_.(*string)
+		if outSchemaL.GetColIndex(onColNameL) == math.MaxUint32 || outSchemaR.GetColIndex(onColNameR) == math.MaxUint32 {
+			return createError("join condition " + onColNameL + " = " + onColNameR + " is invalid.")
+		}
 		// new columns have tuple index of 0 because they are the left side of the join
-		colValL := executors.MakeColumnValueExpression(outSchemaL, 0, *pner.qi.OnExpressions_.Left_.(*string))
+		colValL := executors.MakeColumnValueExpression(outSchemaL, 0, onColNameL)
 		// new columns have tuple index of 1 because they are the right side of the join
-		colValR := executors.MakeColumnValueExpression(outSchemaR, 1, *pner.qi.OnExpressions_.Right_.(*string))
+		colValR := executors.MakeColumnValueExpression(outSchemaR, 1, onColNameR)
 
 		for _, colDef := range tgtTblColumnsL {
 			col := column.NewColumn(tblNameL+"."+colDef.GetColumnName(), colDef.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), colDef.GetExpr())
@@ -147,44 +246,40 @@ func (pner *SimplePlanner) MakeSelectPlanWithJoin() (error, plans.Plan) {
 		// output schema of HashJoinExecutor
 		outFinal = schema.NewSchema(finalOutCols)
 
-		if hasWhere {
-			// query has WHERE clause
-			if len(pner.qi.SelectFields_) == 1 && *pner.qi.SelectFields_[0].ColName_ == "*" {
-				// both schema includes all columns
-				filterOut = outFinal
-			} else {
-				filterOutCols := make([]*column.Column, 0)
-				// column existance check
-				for _, sfield := range pner.qi.SelectFields_ {
-					colName := sfield.ColName_
-					tblName := sfield.TableName_
-
-					if *tblName != tblNameL && *tblName != tblNameR {
-						return PrintAndCreateError("specified selection " + *tblName + "." + *colName + " is invalid.")
-					}
+		if len(pner.qi.SelectFields_) == 1 && *pner.qi.SelectFields_[0].ColName_ == "*" {
+			// both schema includes all columns
+			filterOut = outFinal
+		} else {
+			filterOutCols := make([]*column.Column, 0)
+			// column existance check
+			for _, sfield := range pner.qi.SelectFields_ {
+				colName := sfield.ColName_
+				tblName := sfield.TableName_
 
-					if pner.catalog_.GetTableByName(*tblName) == nil {
-						return PrintAndCreateError("table " + *tblName + " does not exist.")
-					}
+				if tblName == nil || (*tblName != tblNameL && *tblName != tblNameR) {
+					return createError("specified selection " + *colName + " is invalid. table name is needed.")
+				}
 
-					tmpSchema := pner.catalog_.GetTableByName(*tblName).Schema()
-					colIdx := tmpSchema.GetColIndex(*colName)
-					if colIdx == math.MaxUint32 {
-						return PrintAndCreateError("column " + *colName + " does not exist on " + *tblName + ".")
-					}
+				tmpSchema := srcSchemaL
+				if *tblName == tblNameR {
+					tmpSchema = srcSchemaR
+				}
+				colIdx := tmpSchema.GetColIndex(*colName)
+				if colIdx == math.MaxUint32 {
+					return returnError(samehada_errors.NewUnknownColumnError(*tblName, *colName))
+				}
 
-					colDef := tmpSchema.GetColumn(colIdx)
-					col := column.NewColumn(*tblName+"."+*colName, colDef.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), colDef.GetExpr())
-					if *tblName == tblNameL {
-						col.SetIsLeft(true)
-					} else { // Right
-						col.SetIsLeft(false)
-					}
-					// Attention: this method call modifies passed Column objects
-					filterOutCols = append(filterOutCols, col)
+				colDef := tmpSchema.GetColumn(colIdx)
+				col := column.NewColumn(*tblName+"."+*colName, colDef.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), colDef.GetExpr())
+				if *tblName == tblNameL {
+					col.SetIsLeft(true)
+				} else { // Right
+					col.SetIsLeft(false)
 				}
-				filterOut = schema.NewSchema(finalOutCols)
+				// Attention: this method call modifies passed Column objects
+				filterOutCols = append(filterOutCols, col)
 			}
+			filterOut = schema.NewSchema(filterOutCols)
 		}
 
 		onPredicate := executors.MakeComparisonExpression(colValL, colValR, expression.Equal)
@@ -200,190 +295,1624 @@ func (pner *SimplePlanner) MakeSelectPlanWithJoin() (error, plans.Plan) {
 	}
 
 	if hasWhere {
-		whereExp := pner.ConstructPredicate([]*schema.Schema{outFinal})
+		whereExp, err := pner.ConstructPredicate([]*schema.Schema{outFinal})
+		if err != nil {
+			return returnError(err)
+		}
 		// filter joined recoreds with predicate which is specified on WHERE clause if needed
 		filterPlan := plans.NewFilterPlanNode(joinPlan, filterOut, whereExp)
 		return nil, filterPlan
+	} else if filterOut != outFinal {
+		// has no WHERE clause. joined records are only projected to selected columns
+		return nil, plans.NewFilterPlanNode(joinPlan, filterOut, nil)
 	} else {
 		// has no WHERE clause
 		return nil, joinPlan
 	}
 }
 
-func (pner *SimplePlanner) MakeSelectPlan() (error, plans.Plan) {
-	if len(pner.qi.JoinTables_) == 1 {
-		return pner.MakeSelectPlanWithoutJoin()
-	} else {
-		return pner.MakeSelectPlanWithJoin()
+// makeJoinSourcePlan returns plan which reads a table, a CTE or a view to be joined and schema of the source.
+// query of CTE or view is inlined. columns of tuples returned from the plan are named as "table.column"
+func (pner *SimplePlanner) makeJoinSourcePlan(tblName string) (error, plans.Plan, *schema.Schema) {
+	var subPlan plans.Plan = nil
+	if pner.ctes[tblName] != nil {
+		var err error
+		if err, subPlan = pner.makeCTEPlan(tblName); err != nil {
+			return err, nil, nil
+		}
+	} else if view := pner.catalog_.GetView(tblName); view != nil && !view.IsMaterialized() {
+		var err error
+		if err, subPlan = pner.makeViewQueryPlan(view); err != nil {
+			return err, nil, nil
+		}
+	}
+	if subPlan != nil {
+		srcSchema := subPlan.OutputSchema()
+		columns := make([]*column.Column, 0)
+		for colIdx, col := range srcSchema.GetColumns() {
+			colVal := expression.NewColumnValue(0, uint32(colIdx), col.GetType())
+			columns = append(columns, column.NewColumn(tblName+"."+col.GetColumnName(), col.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), colVal))
+		}
+		return nil, plans.NewProjectionPlanNode(subPlan, schema.NewSchema(columns)), srcSchema
+	}
+	tableMetadata := pner.catalog_.GetTableByName(tblName)
+	if tableMetadata == nil {
+		return samehada_errors.NewUnknownTableError(tblName), nil, nil
 	}
-}
-
-func processPredicateTreeNode(node *parser.BinaryOpExpression, tgtTblSchemas []*schema.Schema) expression.Expression {
-	if node.LogicalOperationType_ != -1 { // node of logical operation
-		left_side_pred := processPredicateTreeNode(node.Left_.(*parser.BinaryOpExpression), tgtTblSchemas)
-		right_side_pred := processPredicateTreeNode(node.Right_.(*parser.BinaryOpExpression), tgtTblSchemas)
-		return expression.NewLogicalOp(left_side_pred, right_side_pred, node.LogicalOperationType_, types.Boolean)
-	} else { // node of conpare operation
-		colName := *node.Left_.(*string)
-		specfiedVal := node.Right_.(*types.Value)
 
-		// TODO: (SDB) need to validate specified table name prefix, column name and literal (processPredicateTreeNode of SimplePlanner)
-		//             without use of panic function
+	columns := make([]*column.Column, 0)
+	for _, col := range tableMetadata.Schema().GetColumns() {
+		columns = append(columns, column.NewColumn(tblName+"."+col.GetColumnName(), col.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), col.GetExpr()))
+	}
+	return nil, plans.NewSeqScanPlanNode(schema.NewSchema(columns), nil, tableMetadata.OID()), tableMetadata.Schema()
+}
 
-		//splited := strings.Split(colName, ".")
-		//
-		//var colNamePart *string = nil
-		//if len(splited) > 1 {
-		//	colNamePart = &splited[1]
-		//} else {
-		//	// has no table name prefix
-		//	colNamePart = &colName
-		//}
-		//
-		//var tmpColIdx uint32 = math.MaxUint32
-		//for _, schema_ := range tgtTblSchemas {
-		//	idx := schema_.GetColIndex(*colNamePart)
-		//	if idx != math.MaxUint32 {
-		//		tmpColIdx = idx
-		//		break
-		//	}
-		//}
-		//if tmpColIdx == math.MaxUint32 {
-		//	panic("in WHERE clause, column name part of " + colName + " is invalid.")
-		//}
-		//
+// query of view or CTE is inlined. the query of outer SELECT is applied to tuples returned from it
+func (pner *SimplePlanner) makeSelectPlanOnSubquery(name string, subPlan plans.Plan) (error, plans.Plan) {
+	subSchema := subPlan.OutputSchema()
 
-		//var tmpColIdx uint32
-		//if len(tgtTblSchemas) > 1 {
-		//	// with JOIN case
-		//	// because FilterExecutor is used, refer outSchema
-		//	tmpColIdx = outSchema.GetColIndex(colName)
-		//} else {
-		//	// without JOIN case
-		//	// because SeqScanExecute is used, refer schema of data source table
-		//	tmpColIdx = tgtTblSchemas[0].GetColIndex(colName)
-		//}
+	outSchema := subSchema
+	if !(len(pner.qi.SelectFields_) == 1 && *pner.qi.SelectFields_[0].ColName_ == "*") {
+		outColDefs := make([]*column.Column, 0)
+		for _, sfield := range pner.qi.SelectFields_ {
+			colIdx := getSelectFieldColIndex(subSchema, sfield)
+			if colIdx == math.MaxUint32 {
+				return returnError(samehada_errors.NewUnknownColumnError(name, *sfield.ColName_))
+			}
+			subCol := subSchema.GetColumn(colIdx)
+			outColDefs = append(outColDefs, column.NewColumn(subCol.GetColumnName(), subCol.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), subCol.GetExpr()))
+		}
+		// Attention: this method call modifies passed Column objects
+		outSchema = schema.NewSchema(outColDefs)
+	}
 
-		tmpColIdx := tgtTblSchemas[0].GetColIndex(colName)
+	var predicate expression.Expression = nil
+	if pner.hasWhere() {
+		var err error
+		if predicate, err = pner.ConstructPredicate([]*schema.Schema{subSchema}); err != nil {
+			return returnError(err)
+		}
+	}
+	return nil, plans.NewFilterPlanNode(subPlan, outSchema, predicate)
+}
 
-		tmpColVal := expression.NewColumnValue(0, tmpColIdx, specfiedVal.ValueType())
-		constVal := expression.NewConstantValue(*specfiedVal, specfiedVal.ValueType())
+// makeViewQueryPlan makes plan of SELECT statement which defines view
+func (pner *SimplePlanner) makeViewQueryPlan(view *catalog.View) (error, plans.Plan) {
+	// CTEs of outer query can't be referenced from definition of view
+	outerCTEs := pner.ctes
+	pner.ctes = nil
+	defer func() { pner.ctes = outerCTEs }()
+	return pner.makeSelectPlanFromSQL(view.GetSelectSQL())
+}
 
-		return expression.NewComparison(tmpColVal, constVal, node.ComparisonOperationType_, types.Boolean)
+func (pner *SimplePlanner) makeSelectPlanFromSQL(selectSQL string) (error, plans.Plan) {
+	qi, err := parser.ProcessSQLStr(&selectSQL)
+	if err != nil {
+		return returnError(err)
 	}
+	if *qi.QueryType_ != parser.SELECT {
+		return returnError(samehada_errors.NewParseError("", "SELECT statement is expected: "+selectSQL))
+	}
+	outerQi := pner.qi
+	pner.qi = qi
+	err, plan := pner.MakeSelectPlan()
+	pner.qi = outerQi
+	return err, plan
 }
 
-func (pner *SimplePlanner) ConstructPredicate(tgtTblSchemas []*schema.Schema) expression.Expression {
-	return processPredicateTreeNode(pner.qi.WhereExpression_, tgtTblSchemas)
+// returns index of column specified as sfield. column of joined tuples is named as "table.column"
+// and it is also matched. math.MaxUint32 is returned when the column is not found
+func getSelectFieldColIndex(schema_ *schema.Schema, sfield *parser.SelectFieldExpression) uint32 {
+	colIdx := schema_.GetColIndex(*sfield.ColName_)
+	if colIdx == math.MaxUint32 && sfield.TableName_ != nil {
+		colIdx = schema_.GetColIndex(*sfield.TableName_ + "." + *sfield.ColName_)
+	}
+	return colIdx
 }
 
-func (pner *SimplePlanner) MakeCreateTablePlan() (error, plans.Plan) {
-	if pner.catalog_.GetTableByName(*pner.qi.NewTable_) != nil {
-		return PrintAndCreateError("already " + *pner.qi.NewTable_ + " exists.")
+func (pner *SimplePlanner) MakeSelectPlan() (error, plans.Plan) {
+	if len(pner.qi.CTEs_) > 0 {
+		// CTEs are visible only in this query
+		outerCTEs := pner.ctes
+		defer func() { pner.ctes = outerCTEs }()
+		if err := pner.registerCTEs(); err != nil {
+			return returnError(err)
+		}
 	}
 
-	columns := make([]*column.Column, 0)
-	for _, cdefExp := range pner.qi.ColDefExpressions_ {
-		columns = append(columns, column.NewColumn(*cdefExp.ColName_, *cdefExp.ColType_, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil))
+	if pner.qi.SetOperation_ != nil {
+		return pner.makeSetOperationPlan()
+	}
+	if pner.isAggregationQuery() {
+		if len(pner.qi.JoinTables_) == 0 {
+			return createError("aggregation can't be used without FROM clause.")
+		}
+		return pner.makeAggregationPlan()
+	}
+	for _, sfield := range pner.qi.SelectFields_ {
+		if sfield.WindowFunc_ != nil || sfield.ExprStr_ != nil {
+			return pner.makeSelectPlanWithExpressions()
+		}
+	}
+	if len(pner.qi.JoinTables_) == 0 {
+		return createError("columns can't be selected without FROM clause.")
 	}
-	schema_ := schema.NewSchema(columns)
 
-	pner.catalog_.CreateTable(*pner.qi.NewTable_, schema_, pner.txn)
+	err, plan := pner.makeSelectPlanOnTables()
+	if err != nil {
+		return err, nil
+	}
+	return pner.makeOrderByAndLimitPlan(plan)
+}
 
-	return nil, nil
+func (pner *SimplePlanner) makeSelectPlanOnTables() (error, plans.Plan) {
+	if len(pner.qi.JoinTables_) == 0 {
+		// SELECT without FROM clause. expressions of select fields are evaluated on the one tuple
+		return nil, plans.NewOneRowPlanNode()
+	} else if len(pner.qi.JoinTables_) == 1 {
+		return pner.MakeSelectPlanWithoutJoin()
+	} else {
+		return pner.MakeSelectPlanWithJoin()
+	}
 }
 
-func PrintAndCreateError(msg string) (error, plans.Plan) {
-	fmt.Println(msg)
-	return errors.New(msg), nil
+func (pner *SimplePlanner) isAggregationQuery() bool {
+	if len(pner.qi.GroupByExprStrs_) > 0 || pner.qi.HavingExprStr_ != nil {
+		return true
+	}
+	for _, sfield := range pner.qi.SelectFields_ {
+		if sfield.IsAgg_ || (sfield.ExprStr_ != nil && parser.HasAggregateFunction(*sfield.ExprStr_)) {
+			return true
+		}
+	}
+	return false
 }
 
-func (pner *SimplePlanner) MakeInsertPlan() (error, plans.Plan) {
-	tableMetadata := pner.catalog_.GetTableByName(*pner.qi.JoinTables_[0])
-	if tableMetadata == nil {
-		return PrintAndCreateError("table " + *pner.qi.JoinTables_[0] + " not found.")
+// makeAggregationPlan makes plan of SELECT statement which has aggregate functions, GROUP BY or HAVING.
+// all columns of tables are selected and grouped. ORDER BY and LIMIT are applied to the result
+func (pner *SimplePlanner) makeAggregationPlan() (error, plans.Plan) {
+	selectFields := pner.qi.SelectFields_
+	wildcard := "*"
+	pner.qi.SelectFields_ = []*parser.SelectFieldExpression{{ColName_: &wildcard}}
+	err, srcPlan := pner.makeSelectPlanOnTables()
+	pner.qi.SelectFields_ = selectFields
+	if err != nil {
+		return err, nil
 	}
 
-	schema_ := tableMetadata.Schema()
-	tgtColNum := len(pner.qi.TargetCols_)
-	insRows := make([][]types.Value, 0)
-	insertRowCnt := 0
-	valCnt := 0
-	row := make([]types.Value, 0)
-	for idx, colName := range pner.qi.TargetCols_ {
-		val := pner.qi.Values_[idx-(tgtColNum*insertRowCnt)]
-		if schema_.GetColIndex(*colName) == math.MaxUint32 {
-			return PrintAndCreateError("specified column name " + *colName + " does not exist on table " + *pner.qi.JoinTables_[0] + ".")
+	aggCtx, err := parser.NewAggregationContext(pner.qi.GroupByExprStrs_, srcPlan.OutputSchema())
+	if err != nil {
+		return returnError(err)
+	}
+	outColDefs := make([]*column.Column, 0)
+	for _, sfield := range selectFields {
+		if sfield.WindowFunc_ != nil {
+			return createError("window function can not be used with aggregation.")
+		}
+		exprStr := sfield.ExprStr_
+		outName := *sfield.ColName_
+		if exprStr == nil {
+			if outName == "*" {
+				return createError("* can not be selected with aggregation.")
+			}
+			colName := "`" + outName + "`"
+			if sfield.TableName_ != nil {
+				colName = "`" + *sfield.TableName_ + "`." + colName
+			}
+			exprStr = &colName
+		} else if sfield.IsAgg_ {
+			// ColName_ of aggregate function is name of its argument
+			outName = *exprStr
+		}
+		if sfield.Alias_ != nil {
+			outName = *sfield.Alias_
 		}
-		valType := schema_.GetColumn(schema_.GetColIndex(*colName)).GetType()
-		if val.ValueType() != valType {
-			return PrintAndCreateError("data type of " + *colName + " is wrong.")
+		expr, exprType, err := aggCtx.ExprStrToExpression(*exprStr)
+		if err != nil {
+			return returnError(err)
 		}
-		row = append(row, *val)
-		valCnt++
-		if valCnt == tgtColNum {
-			// to next record
-			insRows = append(insRows, row)
-			valCnt = 0
-			insertRowCnt++
-			row = make([]types.Value, 0)
+		if exprType == types.Invalid {
+			return createError("type of " + outName + " can not be determined.")
 		}
+		outColDefs = append(outColDefs, column.NewColumn(outName, exprType, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), expr))
 	}
 
-	return nil, plans.NewInsertPlanNode(insRows, tableMetadata.OID())
+	var having expression.Expression = nil
+	if pner.qi.HavingExprStr_ != nil {
+		var havingType types.TypeID
+		if having, havingType, err = aggCtx.ExprStrToExpression(*pner.qi.HavingExprStr_); err != nil {
+			return returnError(err)
+		}
+		if havingType != types.Boolean {
+			return returnError(samehada_errors.NewTypeMismatchError("HAVING clause must be boolean expression."))
+		}
+	}
+
+	aggPlan := plans.NewAggregationPlanNode(schema.NewSchema(outColDefs), srcPlan, having, aggCtx.GroupBys_, aggCtx.Aggregates_, aggCtx.AggregateTypes_)
+	return pner.makeOrderByAndLimitPlan(aggPlan)
 }
 
-func (pner *SimplePlanner) MakeDeletePlan() (error, plans.Plan) {
-	tableMetadata := pner.catalog_.GetTableByName(*pner.qi.JoinTables_[0])
-	if tableMetadata == nil {
-		return PrintAndCreateError("table " + *pner.qi.JoinTables_[0] + " not found.")
+// makeSelectPlanWithExpressions makes plan of SELECT statement which has window functions or expressions
+// in select fields. all columns of tables are selected and window functions and expressions are evaluated
+// over them. ORDER BY and LIMIT are applied to the result
+func (pner *SimplePlanner) makeSelectPlanWithExpressions() (error, plans.Plan) {
+	selectFields := pner.qi.SelectFields_
+	wildcard := "*"
+	pner.qi.SelectFields_ = []*parser.SelectFieldExpression{{ColName_: &wildcard}}
+	err, srcPlan := pner.makeSelectPlanOnTables()
+	pner.qi.SelectFields_ = selectFields
+	if err != nil {
+		return err, nil
 	}
 
-	tgtTblSchema := tableMetadata.Schema()
+	srcSchema := srcPlan.OutputSchema()
+	windowColDefs := make([]*column.Column, 0)
+	for _, col := range srcSchema.GetColumns() {
+		windowColDefs = append(windowColDefs, column.NewColumn(col.GetColumnName(), col.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil))
+	}
+	windowFuncs := make([]*plans.WindowFunc, 0)
+	for _, sfield := range selectFields {
+		if sfield.WindowFunc_ == nil {
+			continue
+		}
+		windowFunc, colType, err := makeWindowFunc(srcSchema, sfield.WindowFunc_)
+		if err != nil {
+			return returnError(err)
+		}
+		windowFuncs = append(windowFuncs, windowFunc)
+		windowColDefs = append(windowColDefs, column.NewColumn(*sfield.ColName_, colType, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil))
+	}
+	windowSchema := srcSchema
+	windowPlan := srcPlan
+	if len(windowFuncs) > 0 {
+		windowSchema = schema.NewSchema(windowColDefs)
+		windowPlan = plans.NewWindowPlanNode(srcPlan, windowSchema, windowFuncs)
+	}
 
-	expression_ := pner.ConstructPredicate([]*schema.Schema{tgtTblSchema})
-	//deletePlan := plans.NewDeletePlanNode(expression_, tableMetadata.OID())
-	seqScanPlanP := plans.NewSeqScanPlanNode(tgtTblSchema, expression_, tableMetadata.OID())
-	deletePlan := plans.NewDeletePlanNode(seqScanPlanP)
+	// window functions and selected columns are projected
+	outColDefs := make([]*column.Column, 0)
+	appendOutCol := func(colIdx uint32, name string) {
+		colType := windowSchema.GetColumn(colIdx).GetType()
+		colVal := expression.NewColumnValue(0, colIdx, colType)
+		outColDefs = append(outColDefs, column.NewColumn(name, colType, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), colVal))
+	}
+	windowFuncIdx := srcSchema.GetColumnCount()
+	for _, sfield := range selectFields {
+		switch {
+		case sfield.WindowFunc_ != nil:
+			appendOutCol(windowFuncIdx, *sfield.ColName_)
+			windowFuncIdx++
+		case sfield.ExprStr_ != nil:
+			expr, exprType, err := pner.exprStrToExpression(*sfield.ExprStr_, windowSchema)
+			if err != nil {
+				return returnError(err)
+			}
+			if exprType == types.Invalid {
+				return createError("type of " + *sfield.ColName_ + " can not be determined.")
+			}
+			outColDefs = append(outColDefs, column.NewColumn(*sfield.ColName_, exprType, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), expr))
+		case *sfield.ColName_ == "*":
+			for ii, col := range srcSchema.GetColumns() {
+				appendOutCol(uint32(ii), col.GetColumnName())
+			}
+		case sfield.IsAgg_:
+			return createError("aggregate function can not be used with window functions or expressions.")
+		default:
+			colIdx := getSelectFieldColIndex(srcSchema, sfield)
+			if colIdx == math.MaxUint32 {
+				colIdx = findColIndex(srcSchema, *sfield.ColName_)
+			}
+			if colIdx == math.MaxUint32 {
+				return returnError(samehada_errors.NewUnknownColumnError("", *sfield.ColName_))
+			}
+			appendOutCol(colIdx, srcSchema.GetColumn(colIdx).GetColumnName())
+		}
+	}
 
-	return nil, deletePlan
+	return pner.makeOrderByAndLimitPlan(plans.NewProjectionPlanNode(windowPlan, schema.NewSchema(outColDefs)))
 }
 
-func (pner *SimplePlanner) MakeUpdatePlan() (error, plans.Plan) {
-	tableMetadata := pner.catalog_.GetTableByName(*pner.qi.JoinTables_[0])
-	if tableMetadata == nil {
-		return PrintAndCreateError("table " + *pner.qi.JoinTables_[0] + " not found.")
+// makeWindowFunc resolves columns of window function and returns it with type of its value
+func makeWindowFunc(srcSchema *schema.Schema, expr *parser.WindowFuncExpression) (*plans.WindowFunc, types.TypeID, error) {
+	funcName := *expr.FuncName_
+	if expr.HasUnsupportedOption_ {
+		return nil, types.Invalid, samehada_errors.NewParseError("", "unsupported option is specified to window function "+funcName+".")
 	}
-	tgtTblSchema := tableMetadata.Schema()
-	hasWhere := pner.qi.WhereExpression_.Left_ != nil && pner.qi.WhereExpression_.Right_ != nil
 
-	updateColIdxs := make([]int, 0)
+	windowFunc := &plans.WindowFunc{ArgColIdx: -1, Offset: int(expr.Offset_)}
+	var argCol *column.Column = nil
+	if expr.ArgColName_ != nil {
+		colIdx := findColIndex(srcSchema, *expr.ArgColName_)
+		if colIdx == math.MaxUint32 {
+			return nil, types.Invalid, samehada_errors.NewUnknownColumnError("", *expr.ArgColName_)
+		}
+		windowFunc.ArgColIdx = int(colIdx)
+		argCol = srcSchema.GetColumn(colIdx)
+	}
 
-	// first, create update column idx list
-	for _, setExp := range pner.qi.SetExpressions_ {
-		colIdx := tgtTblSchema.GetColIndex(*setExp.ColName_)
+	var colType types.TypeID
+	needsArg := true
+	switch funcName {
+	case "row_number":
+		windowFunc.FuncType, colType, needsArg = plans.ROW_NUMBER_WINDOW_FUNC, types.Integer, false
+	case "rank":
+		windowFunc.FuncType, colType, needsArg = plans.RANK_WINDOW_FUNC, types.Integer, false
+	case "dense_rank":
+		windowFunc.FuncType, colType, needsArg = plans.DENSE_RANK_WINDOW_FUNC, types.Integer, false
+	case "count":
+		windowFunc.FuncType, colType, needsArg = plans.COUNT_WINDOW_FUNC, types.Integer, false
+	case "lag", "lead":
+		windowFunc.FuncType = plans.LAG_WINDOW_FUNC
+		if funcName == "lead" {
+			windowFunc.FuncType = plans.LEAD_WINDOW_FUNC
+		}
+		if windowFunc.Offset < 0 {
+			return nil, types.Invalid, errors.New("offset of " + funcName + " must not be negative.")
+		}
+		if argCol != nil {
+			colType = argCol.GetType()
+			if expr.DefaultVal_ != nil {
+				defaultVal, err := expression.AdjustValueForColumn(*expr.DefaultVal_, argCol)
+				if err != nil {
+					return nil, types.Invalid, err
+				}
+				windowFunc.DefaultValue = &defaultVal
+			}
+		}
+	case "first_value":
+		windowFunc.FuncType = plans.FIRST_VALUE_WINDOW_FUNC
+		if argCol != nil {
+			colType = argCol.GetType()
+		}
+	case "sum", "avg":
+		windowFunc.FuncType = plans.SUM_WINDOW_FUNC
+		if funcName == "avg" {
+			windowFunc.FuncType = plans.AVG_WINDOW_FUNC
+		}
+		if argCol != nil {
+			if !argCol.GetType().IsNumeric() {
+				return nil, types.Invalid, errors.New(funcName + " can not be applied to column " + argCol.GetColumnName() + ".")
+			}
+			colType = argCol.GetType()
+			if funcName == "avg" && colType != types.Decimal {
+				colType = types.Float
+			}
+		}
+	default:
+		return nil, types.Invalid, samehada_errors.NewParseError("", "window function "+funcName+" is not supported.")
+	}
+	if needsArg && argCol == nil {
+		return nil, types.Invalid, errors.New("column must be passed to window function " + funcName + ".")
+	}
+
+	for _, colName := range expr.PartitionBy_ {
+		colIdx := findColIndex(srcSchema, *colName)
 		if colIdx == math.MaxUint32 {
-			return PrintAndCreateError("column " + *setExp.ColName_ + " does not exist on table " + *pner.qi.JoinTables_[0] + ".")
+			return nil, types.Invalid, samehada_errors.NewUnknownColumnError("", *colName)
+		}
+		windowFunc.PartitionColIdxs = append(windowFunc.PartitionColIdxs, int(colIdx))
+	}
+	for _, orderBy := range expr.OrderBy_ {
+		colIdx := findColIndex(srcSchema, *orderBy.ColName_)
+		if colIdx == math.MaxUint32 {
+			return nil, types.Invalid, samehada_errors.NewUnknownColumnError("", *orderBy.ColName_)
+		}
+		windowFunc.OrderColIdxs = append(windowFunc.OrderColIdxs, int(colIdx))
+		if orderBy.IsDesc_ {
+			windowFunc.OrderTypes = append(windowFunc.OrderTypes, plans.DESC)
+		} else {
+			windowFunc.OrderTypes = append(windowFunc.OrderTypes, plans.ASC)
 		}
-		updateColIdxs = append(updateColIdxs, int(colIdx))
 	}
 
-	// second, construc values list includes dummy value (not update target)
-	updateVals := make([]types.Value, tgtTblSchema.GetColumnCount())
-	// fill all elems with dummy
-	for idx, _ := range tgtTblSchema.GetColumns() {
-		updateVals[idx] = types.NewNull()
+	if frame := expr.Frame_; frame != nil {
+		start := plans.WindowFrameBound{Type: frame.Start_.BoundType_, Offset: int(frame.Start_.Offset_)}
+		end := plans.WindowFrameBound{Type: frame.End_.BoundType_, Offset: int(frame.End_.Offset_)}
+		if start.Type == plans.UNBOUNDED_FOLLOWING || end.Type == plans.UNBOUNDED_PRECEDING || start.Type > end.Type {
+			return nil, types.Invalid, errors.New("frame of window function " + funcName + " is invalid.")
+		}
+		for _, bound := range []plans.WindowFrameBound{start, end} {
+			if (bound.Type == plans.PRECEDING || bound.Type == plans.FOLLOWING) && (!frame.IsRows_ || bound.Offset < 0) {
+				return nil, types.Invalid, samehada_errors.NewParseError("", "offset of frame is supported with non-negative integer on ROWS frame only.")
+			}
+		}
+		windowFunc.Frame = &plans.WindowFrame{IsRows: frame.IsRows_, Start: start, End: end}
 	}
-	// overwrite elem which is update target
-	for idx, colIdx := range updateColIdxs {
-		updateVals[colIdx] = *pner.qi.SetExpressions_[idx].UpdateValue_
+
+	return windowFunc, colType, nil
+}
+
+// makeSetOperationPlan makes plan of SELECT statements combined with UNION, INTERSECT or EXCEPT.
+// INTERSECT binds tighter than UNION and EXCEPT. ORDER BY and LIMIT are applied to combined result
+func (pner *SimplePlanner) makeSetOperationPlan() (error, plans.Plan) {
+	setOp := pner.qi.SetOperation_
+	operandPlans := make([]plans.Plan, 0)
+	for _, selectSQL := range setOp.SelectSQLs_ {
+		err, plan := pner.makeSelectPlanFromSQL(*selectSQL)
+		if err != nil {
+			return err, nil
+		}
+		operandPlans = append(operandPlans, plan)
 	}
 
-	var predicate expression.Expression = nil
-	if hasWhere {
-		predicate = pner.ConstructPredicate([]*schema.Schema{tgtTblSchema})
+	// columns of combined result are named after columns of first SELECT statement
+	firstSchema := operandPlans[0].OutputSchema()
+	outColDefs := make([]*column.Column, 0)
+	for _, col := range firstSchema.GetColumns() {
+		outColDefs = append(outColDefs, column.NewColumn(col.GetColumnName(), col.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil))
+	}
+	outSchema := schema.NewSchema(outColDefs)
+	for _, plan := range operandPlans[1:] {
+		operandSchema := plan.OutputSchema()
+		if operandSchema.GetColumnCount() != firstSchema.GetColumnCount() {
+			return createError("each SELECT statement of set operation must have same number of columns.")
+		}
+		for ii, col := range operandSchema.GetColumns() {
+			if col.GetType() != firstSchema.GetColumn(uint32(ii)).GetType() {
+				return returnError(samehada_errors.NewTypeMismatchError("type of column " + col.GetColumnName() + " does not match with column " + firstSchema.GetColumn(uint32(ii)).GetColumnName() + " on set operation."))
+			}
+		}
+	}
+
+	combine := func(left plans.Plan, right plans.Plan, opType plans.SetOperationType, isAll bool) plans.Plan {
+		if opType == plans.UNION_OPERATION && isAll {
+			return plans.NewUnionAllPlanNode(left, right, outSchema)
+		}
+		return plans.NewSetOperationPlanNode(left, right, outSchema, opType, isAll)
+	}
+
+	// INTERSECTs are applied first
+	terms := []plans.Plan{operandPlans[0]}
+	termOps := make([]int, 0)
+	for ii, opType := range setOp.Operations_ {
+		if opType == plans.INTERSECT_OPERATION {
+			terms[len(terms)-1] = combine(terms[len(terms)-1], operandPlans[ii+1], opType, setOp.IsAll_[ii])
+		} else {
+			terms = append(terms, operandPlans[ii+1])
+			termOps = append(termOps, ii)
+		}
+	}
+	plan := terms[0]
+	for ii, opIdx := range termOps {
+		plan = combine(plan, terms[ii+1], setOp.Operations_[opIdx], setOp.IsAll_[opIdx])
+	}
+
+	return pner.makeOrderByAndLimitPlan(plan)
+}
+
+// makeOrderByAndLimitPlan wraps plan with plans which apply ORDER BY and LIMIT of current query to its result
+func (pner *SimplePlanner) makeOrderByAndLimitPlan(plan plans.Plan) (error, plans.Plan) {
+	outSchema := plan.OutputSchema()
+	if len(pner.qi.OrderByExpressions_) > 0 {
+		colIdxs := make([]int, 0)
+		orderTypes := make([]plans.OrderbyType, 0)
+		for _, orderBy := range pner.qi.OrderByExpressions_ {
+			colIdx := findColIndex(outSchema, *orderBy.ColName_)
+			if colIdx == math.MaxUint32 {
+				return returnError(samehada_errors.NewUnknownColumnError("", *orderBy.ColName_))
+			}
+			colIdxs = append(colIdxs, int(colIdx))
+			if orderBy.IsDesc_ {
+				orderTypes = append(orderTypes, plans.DESC)
+			} else {
+				orderTypes = append(orderTypes, plans.ASC)
+			}
+		}
+		plan = plans.NewOrderbyPlanNode(outSchema, plan, colIdxs, orderTypes)
+	}
+
+	if pner.qi.LimitNum_ != -1 || pner.qi.OffsetNum_ != -1 {
+		limit := uint32(math.MaxUint32)
+		if pner.qi.LimitNum_ != -1 {
+			limit = uint32(pner.qi.LimitNum_)
+		}
+		offset := uint32(0)
+		if pner.qi.OffsetNum_ != -1 {
+			offset = uint32(pner.qi.OffsetNum_)
+		}
+		plan = plans.NewLimitPlanNode(plan, limit, offset)
+	}
+
+	return nil, plan
+}
+
+// returns index of column named colName. column named as "table.column" is also matched with
+// column name only and vice versa. math.MaxUint32 is returned when the column is not found
+func findColIndex(schema_ *schema.Schema, colName string) uint32 {
+	if colIdx := schema_.GetColIndex(colName); colIdx != math.MaxUint32 {
+		return colIdx
+	}
+	for ii, col := range schema_.GetColumns() {
+		if strings.HasSuffix(col.GetColumnName(), "."+colName) {
+			return uint32(ii)
+		}
+	}
+	if dotIdx := strings.LastIndex(colName, "."); dotIdx != -1 {
+		return schema_.GetColIndex(colName[dotIdx+1:])
+	}
+	return math.MaxUint32
+}
+
+// cteInfo is a common table expression which can be referenced from the query being planned
+type cteInfo struct {
+	def *parser.CTEExpression
+	// number of references in the query. CTE referenced more than once is materialized
+	refCount int
+	// CTEs which can be referenced from the CTE
+	visibleCTEs map[string]*cteInfo
+	// output schema of recursive CTE while its recursive part is planned.
+	// references to the CTE in recursive part are scans of working table
+	workTableSchema *schema.Schema
+	isPlanning      bool
+}
+
+// registerCTEs makes CTEs in WITH clause of current query visible. a CTE can reference CTEs defined
+// before it and recursive CTE can reference itself in recursive part
+func (pner *SimplePlanner) registerCTEs() error {
+	ctes := make(map[string]*cteInfo)
+	for name, cte := range pner.ctes {
+		ctes[name] = cte
+	}
+
+	for _, def := range pner.qi.CTEs_ {
+		name := *def.Name_
+		for _, registered := range pner.qi.CTEs_ {
+			if registered == def {
+				break
+			}
+			if *registered.Name_ == name {
+				return errors.New("CTE " + name + " is defined more than once.")
+			}
+		}
+		visibleCTEs := make(map[string]*cteInfo)
+		for visibleName, visible := range ctes {
+			visibleCTEs[visibleName] = visible
+		}
+		cte := &cteInfo{def, 0, visibleCTEs, nil, false}
+		if def.RecursiveSQL_ != nil {
+			visibleCTEs[name] = cte
+		}
+		ctes[name] = cte
+	}
+
+	// references in main query and bodies of CTEs are counted
+	var countRefs func(qi *parser.QueryInfo, self string)
+	countRefs = func(qi *parser.QueryInfo, self string) {
+		for _, tblName := range qi.JoinTables_ {
+			if cte, ok := ctes[*tblName]; ok && *tblName != self {
+				cte.refCount++
+			}
+		}
+		if qi.SetOperation_ != nil {
+			for _, selectSQL := range qi.SetOperation_.SelectSQLs_ {
+				operand := *selectSQL
+				if operandQi, err := parser.ProcessSQLStr(&operand); err == nil {
+					countRefs(operandQi, self)
+				}
+			}
+		}
+	}
+	countRefs(pner.qi, "")
+	for _, def := range pner.qi.CTEs_ {
+		for _, sqlStr := range []*string{def.SelectSQL_, def.RecursiveSQL_} {
+			if sqlStr == nil {
+				continue
+			}
+			body := *sqlStr
+			if qi, err := parser.ProcessSQLStr(&body); err == nil {
+				countRefs(qi, *def.Name_)
+			}
+		}
+	}
+
+	pner.ctes = ctes
+	return nil
+}
+
+// makeCTEPlan returns plan which returns tuples of the CTE. the CTE is inlined when it is referenced once.
+// otherwise it is materialized at first scan. recursive CTE is evaluated with working table
+func (pner *SimplePlanner) makeCTEPlan(name string) (error, plans.Plan) {
+	cte := pner.ctes[name]
+	if cte.workTableSchema != nil {
+		// reference in recursive part of itself
+		return nil, plans.NewWorkTableScanPlanNode(cte.workTableSchema, name)
+	}
+	if cte.isPlanning {
+		return createError("CTE " + name + " can be referenced only from recursive part of itself.")
+	}
+	cte.isPlanning = true
+	defer func() { cte.isPlanning = false }()
+
+	outerCTEs := pner.ctes
+	pner.ctes = cte.visibleCTEs
+	defer func() { pner.ctes = outerCTEs }()
+
+	err, anchorPlan := pner.makeSelectPlanFromSQL(*cte.def.SelectSQL_)
+	if err != nil {
+		return err, nil
+	}
+	anchorSchema := anchorPlan.OutputSchema()
+	if len(cte.def.ColNames_) > 0 && len(cte.def.ColNames_) != int(anchorSchema.GetColumnCount()) {
+		return createError("number of columns of CTE " + name + " does not match with its query.")
+	}
+	// columns of CTE are named with column list or names of selected columns without table name
+	outColumns := make([]*column.Column, 0)
+	for colIdx, col := range anchorSchema.GetColumns() {
+		colName := col.GetColumnName()
+		if len(cte.def.ColNames_) > 0 {
+			colName = *cte.def.ColNames_[colIdx]
+		} else if dotIdx := strings.LastIndex(colName, "."); dotIdx != -1 {
+			colName = colName[dotIdx+1:]
+		}
+		colVal := expression.NewColumnValue(0, uint32(colIdx), col.GetType())
+		outColumns = append(outColumns, column.NewColumn(colName, col.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), colVal))
+	}
+	outSchema := schema.NewSchema(outColumns)
+	var ctePlan plans.Plan = plans.NewProjectionPlanNode(anchorPlan, outSchema)
+
+	if cte.def.RecursiveSQL_ != nil {
+		cte.workTableSchema = outSchema
+		err, recursivePlan := pner.makeSelectPlanFromSQL(*cte.def.RecursiveSQL_)
+		cte.workTableSchema = nil
+		if err != nil {
+			return err, nil
+		}
+		recursiveSchema := recursivePlan.OutputSchema()
+		if recursiveSchema.GetColumnCount() != outSchema.GetColumnCount() {
+			return createError("number of columns of recursive part of CTE " + name + " does not match with anchor part.")
+		}
+		for colIdx, col := range outSchema.GetColumns() {
+			if recursiveSchema.GetColumn(uint32(colIdx)).GetType() != col.GetType() {
+				return returnError(samehada_errors.NewTypeMismatchError("type of column " + col.GetColumnName() + " of recursive part of CTE " + name + " does not match with anchor part."))
+			}
+		}
+		ctePlan = plans.NewRecursiveCTEPlanNode(ctePlan, recursivePlan, outSchema, name, cte.def.IsUnionAll_)
+	}
+
+	if cte.refCount > 1 {
+		return nil, plans.NewCTEScanPlanNode(ctePlan, outSchema, name)
+	}
+	return nil, ctePlan
+}
+
+func processPredicateTreeNode(node *parser.BinaryOpExpression, tgtTblSchemas []*schema.Schema) (expression.Expression, error) {
+	if node.LogicalOperationType_ != -1 { // node of logical operation
+		left_side_pred, err := processPredicateTreeNode(node.Left_.(*parser.BinaryOpExpression), tgtTblSchemas)
+		if err != nil {
+			return nil, err
+		}
+		right_side_pred, err := processPredicateTreeNode(node.Right_.(*parser.BinaryOpExpression), tgtTblSchemas)
+		if err != nil {
+			return nil, err
+		}
+		return expression.NewLogicalOp(left_side_pred, right_side_pred, node.LogicalOperationType_, types.Boolean), nil
+	} else { // node of conpare operation
+		colName := *node.Left_.(*string)
+		specfiedVal := node.Right_.(*types.Value)
+
+		// TODO: (SDB) need to validate specified table name prefix, column name and literal (processPredicateTreeNode of SimplePlanner)
+		//             without use of panic function
+
+		//splited := strings.Split(colName, ".")
+		//
+		//var colNamePart *string = nil
+		//if len(splited) > 1 {
+		//	colNamePart = &splited[1]
+		//} else {
+		//	// has no table name prefix
+		//	colNamePart = &colName
+		//}
+		//
+		//var tmpColIdx uint32 = math.MaxUint32
+		//for _, schema_ := range tgtTblSchemas {
+		//	idx := schema_.GetColIndex(*colNamePart)
+		//	if idx != math.MaxUint32 {
+		//		tmpColIdx = idx
+		//		break
+		//	}
+		//}
+		//if tmpColIdx == math.MaxUint32 {
+		//	panic("in WHERE clause, column name part of " + colName + " is invalid.")
+		//}
+		//
+
+		//var tmpColIdx uint32
+		//if len(tgtTblSchemas) > 1 {
+		//	// with JOIN case
+		//	// because FilterExecutor is used, refer outSchema
+		//	tmpColIdx = outSchema.GetColIndex(colName)
+		//} else {
+		//	// without JOIN case
+		//	// because SeqScanExecute is used, refer schema of data source table
+		//	tmpColIdx = tgtTblSchemas[0].GetColIndex(colName)
+		//}
+
+		tmpColIdx := tgtTblSchemas[0].GetColIndex(colName)
+		if tmpColIdx != math.MaxUint32 {
+			// literal is converted to type of the column. ex: string literal is compared as number or date/time value
+			// and integer literal is compared as boolean
+			colType := tgtTblSchemas[0].GetColumn(tmpColIdx).GetType()
+			converted, err := expression.CoerceLiteralForComparison(*specfiedVal, colType)
+			if err != nil {
+				return nil, samehada_errors.NewTypeMismatchError("invalid comparison with column " + colName + ": " + err.Error())
+			}
+			specfiedVal = &converted
+		}
+
+		tmpColVal := expression.NewColumnValue(0, tmpColIdx, specfiedVal.ValueType())
+		constVal := expression.NewConstantValue(*specfiedVal, specfiedVal.ValueType())
+
+		return expression.NewComparison(tmpColVal, constVal, node.ComparisonOperationType_, types.Boolean), nil
+	}
+}
+
+// exprStrToExpression converts SQL text of an expression in the query. NEXTVAL and CURRVAL can be used in it
+func (pner *SimplePlanner) exprStrToExpression(exprStr string, schema_ *schema.Schema) (expression.Expression, types.TypeID, error) {
+	return parser.ExprStrToExpressionWithSequences(exprStr, schema_, pner.catalog_)
+}
+
+func (pner *SimplePlanner) hasWhere() bool {
+	return pner.qi.WhereExprStr_ != nil || (pner.qi.WhereExpression_.Left_ != nil && pner.qi.WhereExpression_.Right_ != nil)
+}
+
+// ConstructPredicate returns nil when the query has no WHERE clause
+func (pner *SimplePlanner) ConstructPredicate(tgtTblSchemas []*schema.Schema) (expression.Expression, error) {
+	if pner.qi.WhereExprStr_ != nil {
+		// WHERE clause which has function calls etc.
+		predicate, predicateType, err := pner.exprStrToExpression(*pner.qi.WhereExprStr_, tgtTblSchemas[0])
+		if err != nil {
+			return nil, err
+		}
+		if predicateType != types.Boolean {
+			return nil, samehada_errors.NewTypeMismatchError("WHERE clause must be boolean expression.")
+		}
+		return predicate, nil
+	}
+	if !pner.hasWhere() {
+		return nil, nil
+	}
+	return processPredicateTreeNode(pner.qi.WhereExpression_, tgtTblSchemas)
+}
+
+func (pner *SimplePlanner) MakeCreateTablePlan() (error, plans.Plan) {
+	if pner.catalog_.GetTableByName(*pner.qi.NewTable_) != nil || pner.catalog_.GetView(*pner.qi.NewTable_) != nil {
+		return createError("already " + *pner.qi.NewTable_ + " exists.")
+	}
+
+	columns := make([]*column.Column, 0)
+	for _, cdefExp := range pner.qi.ColDefExpressions_ {
+		col, err := newColumnFromColDef(cdefExp)
+		if err != nil {
+			return returnError(err)
+		}
+		columns = append(columns, col)
+	}
+
+	// PRIMARY KEY and UNIQUE constraints and INDEX specified as table constraint
+	for _, idxDef := range pner.qi.IndexDefExpressions_ {
+		if len(idxDef.KeyExprStrs_) > 0 {
+			return returnError(samehada_errors.NewParseError("", "index on expression is not supported. create index on generated column of the expression instead."))
+		}
+		// composite keys are not supported. key of index is always value of one column
+		if len(idxDef.Colnames_) != 1 {
+			if idxDef.IsUnique_ {
+				return returnError(samehada_errors.NewParseError("", "PRIMARY KEY or UNIQUE constraint on multiple columns is not supported."))
+			}
+			return returnError(samehada_errors.NewParseError("", "index on multiple columns is not supported."))
+		}
+		isFound := false
+		for _, col := range columns {
+			if col.GetColumnName() == *idxDef.Colnames_[0] {
+				if idxDef.IsUnique_ {
+					setUniqueConstraint(col, idxDef.IsPrimaryKey_)
+				} else if !col.HasIndex() {
+					// INDEX allows duplicated keys. it is used for point scan of equality predicate
+					col.SetHasIndex(true)
+					col.SetIndexKind(index_constants.INDEX_KIND_NON_UNIQUE_SKIP_LIST)
+				}
+				isFound = true
+			}
+		}
+		if !isFound {
+			return returnError(samehada_errors.NewUnknownColumnError(*pner.qi.NewTable_, *idxDef.Colnames_[0]))
+		}
+	}
+
+	// FOREIGN KEY constraints specified as column option and table constraint
+	fkDefs := make([]*parser.ForeignKeyDefExpression, 0)
+	for _, cdefExp := range pner.qi.ColDefExpressions_ {
+		if cdefExp.ForeignKey_ != nil {
+			fkDefs = append(fkDefs, cdefExp.ForeignKey_)
+		}
+	}
+	fkDefs = append(fkDefs, pner.qi.ForeignKeyDefExpressions_...)
+	for _, fkDef := range fkDefs {
+		if len(fkDef.Colnames_) != 1 {
+			return returnError(samehada_errors.NewParseError("", "FOREIGN KEY constraint on multiple columns is not supported."))
+		}
+		var fkCol *column.Column = nil
+		for _, col := range columns {
+			if col.GetColumnName() == *fkDef.Colnames_[0] {
+				fkCol = col
+			}
+		}
+		if fkCol == nil {
+			return returnError(samehada_errors.NewUnknownColumnError(*pner.qi.NewTable_, *fkDef.Colnames_[0]))
+		}
+		if *fkDef.RefTable_ == *pner.qi.NewTable_ {
+			return returnError(samehada_errors.NewParseError("", "self referencing FOREIGN KEY constraint is not supported."))
+		}
+		if err := pner.setForeignKeyConstraint(fkCol, fkDef); err != nil {
+			return returnError(err)
+		}
+	}
+
+	pkCnt := 0
+	for _, col := range columns {
+		if col.IsPrimaryKey() {
+			pkCnt++
+		}
+	}
+	if pkCnt > 1 {
+		return createError("multiple PRIMARY KEY are defined on " + *pner.qi.NewTable_ + ".")
+	}
+	schema_ := schema.NewSchema(columns)
+
+	// CHECK constraints specified as table constraint are attached to first column referenced by them
+	for _, checkExpr := range pner.qi.CheckExprs_ {
+		expr, _, err := parser.ExprStrToExpression(*checkExpr, schema_)
+		if err != nil {
+			return returnError(err)
+		}
+		var col *column.Column = columns[0]
+		if colIdxs := expression.GetColIndexesOfExpression(expr); len(colIdxs) > 0 {
+			col = columns[colIdxs[0]]
+		}
+		if col.CheckExprStr() == "" {
+			col.SetCheckExprStr(*checkExpr)
+		} else {
+			col.SetCheckExprStr("(" + col.CheckExprStr() + ") AND (" + *checkExpr + ")")
+		}
+	}
+	if err := catalog.CompileColumnExpressions(schema_); err != nil {
+		return returnError(err)
+	}
+
+	for _, col := range columns {
+		if col.HasDefaultSeq() && !pner.catalog_.SequenceExists(col.DefaultSeqName()) {
+			return createError("sequence " + col.DefaultSeqName() + " does not exist.")
+		}
+	}
+
+	// values of AUTO_INCREMENT column are generated with a sequence which is created implicitly
+	for idx, col := range columns {
+		if !pner.qi.ColDefExpressions_[idx].IsAutoIncrement_ {
+			continue
+		}
+		seqName := *pner.qi.NewTable_ + "_" + col.GetColumnName() + "_seq"
+		if err := pner.catalog_.CreateSequence(seqName, 1, 1, pner.txn); err != nil {
+			return returnError(err)
+		}
+		col.SetAutoIncrementSeqName(seqName)
+	}
+
+	pner.catalog_.CreateTable(*pner.qi.NewTable_, schema_, pner.txn)
+
+	return nil, nil
+}
+
+func newColumnFromColDef(cdefExp *parser.ColDefExpression) (*column.Column, error) {
+	col := column.NewColumn(*cdefExp.ColName_, *cdefExp.ColType_, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	if *cdefExp.ColType_ == types.Decimal {
+		if err := types.ValidateDecimalPrecisionAndScale(cdefExp.DecimalPrecision_, cdefExp.DecimalScale_); err != nil {
+			return nil, errors.New("column " + col.GetColumnName() + ": " + err.Error())
+		}
+		col.SetDecimalPrecisionAndScale(cdefExp.DecimalPrecision_, cdefExp.DecimalScale_)
+	}
+	if cdefExp.IsUnique_ {
+		setUniqueConstraint(col, cdefExp.IsPrimaryKey_)
+	}
+	if cdefExp.IsNotNull_ {
+		col.SetIsNotNull(true)
+	}
+	if cdefExp.DefaultValue_ != nil {
+		defaultVal, err := expression.AdjustValueForColumn(*cdefExp.DefaultValue_, col)
+		if err != nil {
+			return nil, errors.New("DEFAULT value of column " + col.GetColumnName() + " is invalid: " + err.Error())
+		}
+		if defaultVal.IsNull() && col.IsNotNull() {
+			return nil, errors.New("DEFAULT value of NOT NULL column " + col.GetColumnName() + " can't be NULL.")
+		}
+		col.SetDefaultValue(&defaultVal)
+	}
+	if cdefExp.DefaultSeq_ != nil {
+		if !col.GetType().IsIntegerFamily() {
+			return nil, errors.New("column " + col.GetColumnName() + " whose DEFAULT is NEXTVAL must be integer type.")
+		}
+		col.SetDefaultSeqName(*cdefExp.DefaultSeq_)
+	}
+	if cdefExp.GeneratedExpr_ != nil {
+		if cdefExp.DefaultValue_ != nil || cdefExp.DefaultSeq_ != nil {
+			return nil, errors.New("DEFAULT value can't be specified for generated column " + col.GetColumnName() + ".")
+		}
+		col.SetGeneratedExprStr(*cdefExp.GeneratedExpr_)
+	}
+	if cdefExp.CheckExpr_ != nil {
+		col.SetCheckExprStr(*cdefExp.CheckExpr_)
+	}
+	if cdefExp.IsAutoIncrement_ {
+		if !col.GetType().IsIntegerFamily() {
+			return nil, errors.New("AUTO_INCREMENT column " + col.GetColumnName() + " must be integer type.")
+		}
+		if cdefExp.DefaultValue_ != nil || cdefExp.DefaultSeq_ != nil || cdefExp.GeneratedExpr_ != nil {
+			return nil, errors.New("DEFAULT value or generation expression can't be specified for AUTO_INCREMENT column " + col.GetColumnName() + ".")
+		}
+	}
+	return col, nil
+}
+
+// PRIMARY KEY and UNIQUE constraint are checked with hash index which is created automatically
+func setUniqueConstraint(col *column.Column, isPrimaryKey bool) {
+	col.SetIsUnique(true)
+	if isPrimaryKey {
+		col.SetIsPrimaryKey(true)
+		col.SetIsNotNull(true)
+	}
+	if !col.HasIndex() {
+		col.SetHasIndex(true)
+		col.SetIndexKind(index_constants.INDEX_KIND_HASH)
+	}
+}
+
+// FOREIGN KEY constraint is checked with unique index of referenced column and
+// hash index of referencing column which is created automatically
+func (pner *SimplePlanner) setForeignKeyConstraint(col *column.Column, fkDef *parser.ForeignKeyDefExpression) error {
+	if len(fkDef.RefColnames_) != 1 {
+		return samehada_errors.NewParseError("", "FOREIGN KEY constraint on multiple columns is not supported.")
+	}
+	refTable := pner.catalog_.GetTableByName(*fkDef.RefTable_)
+	if refTable == nil {
+		return samehada_errors.NewUnknownTableError(*fkDef.RefTable_)
+	}
+	refColName := *fkDef.RefColnames_[0]
+	refColIdx := refTable.Schema().GetColIndex(refColName)
+	if refColIdx == math.MaxUint32 {
+		return samehada_errors.NewUnknownColumnError(*fkDef.RefTable_, refColName)
+	}
+	refCol := refTable.Schema().GetColumn(refColIdx)
+	if !refCol.IsUnique() {
+		return errors.New("column " + refColName + " referenced by " + col.GetColumnName() + " must be PRIMARY KEY or UNIQUE.")
+	}
+	if refCol.GetType() != col.GetType() {
+		return samehada_errors.NewTypeMismatchError("column " + col.GetColumnName() + " is " + col.GetType().String() + " but referenced column " + refColName + " is " + refCol.GetType().String() + ".")
+	}
+	if col.IsNotNull() && (fkDef.OnDelete_ == column.FK_ACTION_SET_NULL || fkDef.OnUpdate_ == column.FK_ACTION_SET_NULL) {
+		return errors.New("SET NULL action can't be specified for NOT NULL column " + col.GetColumnName() + ".")
+	}
+
+	col.SetForeignKey(column.NewForeignKey(refTable.OID(), refColName, fkDef.OnDelete_, fkDef.OnUpdate_))
+	if !col.HasIndex() {
+		col.SetHasIndex(true)
+		col.SetIndexKind(index_constants.INDEX_KIND_HASH)
+	}
+	return nil
+}
+
+// returns name of table which has FOREIGN KEY constraint referencing colName of tableMetadata.
+// empty string is returned when the column is not referenced
+func (pner *SimplePlanner) getReferencingTableName(tableMetadata *catalog.TableMetadata, colName string) string {
+	for _, ref := range pner.catalog_.GetReferencingForeignKeys(tableMetadata) {
+		if ref.ForeignKey.RefColumnName == colName {
+			return ref.ChildTable.GetTableName()
+		}
+	}
+	return ""
+}
+
+// returns name of column whose generation expression or CHECK constraint refers column at colIdx.
+// empty string is returned when the column is not referenced. when exceptSelf is true, expressions of
+// the column itself are not checked
+func getReferencingColumnName(schema_ *schema.Schema, colIdx uint32, exceptSelf bool) string {
+	for idx, col := range schema_.GetColumns() {
+		if exceptSelf && uint32(idx) == colIdx {
+			continue
+		}
+		for _, expr := range []interface{}{col.GetExpr(), col.GetCheckExpr()} {
+			if expr == nil {
+				continue
+			}
+			if samehada_util.IsContainList[uint32](expression.GetColIndexesOfExpression(expr.(expression.Expression)), colIdx) {
+				return col.GetColumnName()
+			}
+		}
+	}
+	return ""
+}
+
+// ALTER TABLE is processed at planning like CREATE TABLE. so returned plan is always nil
+func (pner *SimplePlanner) MakeAlterTablePlan() (error, plans.Plan) {
+	tblName := *pner.qi.JoinTables_[0]
+	tableMetadata := pner.catalog_.GetTableByName(tblName)
+	if tableMetadata == nil {
+		return returnError(samehada_errors.NewUnknownTableError(tblName))
+	}
+	if err := pner.checkNotMaterializedView(tblName); err != nil {
+		return returnError(err)
+	}
+
+	for _, alterExp := range pner.qi.AlterTableExpressions_ {
+		if alterExp.AlterType_ == parser.RENAME_TABLE {
+			if err := pner.catalog_.RenameTable(tableMetadata, *alterExp.NewName_, pner.txn); err != nil {
+				return returnError(err)
+			}
+			tblName = *alterExp.NewName_
+			continue
+		}
+
+		curColumns := tableMetadata.Schema().GetColumns()
+		newColumns := make([]*column.Column, 0)
+		srcColIdxs := make([]int, 0)
+		fillVals := make([]types.Value, 0)
+		addColumn := func(srcIdx int, name string, base *column.Column, fillVal types.Value) {
+			newColumns = append(newColumns, copyColumnDef(name, base))
+			srcColIdxs = append(srcColIdxs, srcIdx)
+			fillVals = append(fillVals, fillVal)
+		}
+
+		switch alterExp.AlterType_ {
+		case parser.ADD_COLUMN:
+			colName := *alterExp.ColDef_.ColName_
+			if tableMetadata.Schema().GetColIndex(colName) != math.MaxUint32 {
+				return createError("column " + colName + " already exists on table " + tblName + ".")
+			}
+			if alterExp.ColDef_.IsAutoIncrement_ {
+				return createError("AUTO_INCREMENT column can't be added to existing table " + tblName + ".")
+			}
+			if alterExp.ColDef_.DefaultSeq_ != nil {
+				return createError("column whose DEFAULT is NEXTVAL can't be added to existing table " + tblName + ".")
+			}
+			if alterExp.ColDef_.IsPrimaryKey_ {
+				for _, col := range curColumns {
+					if col.IsPrimaryKey() {
+						return createError("PRIMARY KEY is already defined on " + tblName + ".")
+					}
+				}
+			}
+			newCol, err := newColumnFromColDef(alterExp.ColDef_)
+			if err != nil {
+				return returnError(err)
+			}
+			// existing tuples are filled with DEFAULT value.
+			// NOT NULL column without DEFAULT value can be added only to empty table
+			fillVal := types.NewNullOfType(newCol.GetType())
+			if newCol.DefaultValue() != nil {
+				fillVal = *newCol.DefaultValue()
+			}
+			if alterExp.ColDef_.ForeignKey_ != nil {
+				if *alterExp.ColDef_.ForeignKey_.RefTable_ == tblName {
+					return createError("self referencing FOREIGN KEY constraint is not supported.")
+				}
+				if err := pner.setForeignKeyConstraint(newCol, alterExp.ColDef_.ForeignKey_); err != nil {
+					return returnError(err)
+				}
+				// existing tuples are filled with DEFAULT value. it must be referenceable
+				if !fillVal.IsNull() && tableMetadata.Table().GetFirstTuple(pner.txn) != nil {
+					refTable := pner.catalog_.GetTableByOID(newCol.ForeignKey().RefTableOID)
+					found, err := refTable.FindTuplesByValue(int(refTable.Schema().GetColIndex(newCol.ForeignKey().RefColumnName)), fillVal, pner.txn)
+					if err != nil {
+						return returnError(err)
+					}
+					if len(found) == 0 {
+						return returnError(samehada_errors.NewConstraintViolationError(samehada_errors.CONSTRAINT_FOREIGN_KEY, tblName, colName))
+					}
+				}
+			}
+			for idx, col := range curColumns {
+				addColumn(idx, col.GetColumnName(), col, types.Value{})
+			}
+			addColumn(-1, colName, newCol, fillVal)
+		case parser.DROP_COLUMN:
+			if tableMetadata.Schema().GetColIndex(*alterExp.ColName_) == math.MaxUint32 {
+				return returnError(samehada_errors.NewUnknownColumnError(tblName, *alterExp.ColName_))
+			}
+			if len(curColumns) == 1 {
+				return createError("can't drop the only column of table " + tblName + ".")
+			}
+			if refTblName := pner.getReferencingTableName(tableMetadata, *alterExp.ColName_); refTblName != "" {
+				return createError("column " + *alterExp.ColName_ + " is referenced by FOREIGN KEY constraint of " + refTblName + ".")
+			}
+			if refColName := getReferencingColumnName(tableMetadata.Schema(), tableMetadata.Schema().GetColIndex(*alterExp.ColName_), true); refColName != "" {
+				return createError("column " + *alterExp.ColName_ + " is referenced by expression of column " + refColName + ".")
+			}
+			for idx, col := range curColumns {
+				if col.GetColumnName() != *alterExp.ColName_ {
+					addColumn(idx, col.GetColumnName(), col, types.Value{})
+				}
+			}
+		case parser.RENAME_COLUMN:
+			if tableMetadata.Schema().GetColIndex(*alterExp.ColName_) == math.MaxUint32 {
+				return returnError(samehada_errors.NewUnknownColumnError(tblName, *alterExp.ColName_))
+			}
+			if tableMetadata.Schema().GetColIndex(*alterExp.NewName_) != math.MaxUint32 {
+				return createError("column " + *alterExp.NewName_ + " already exists on table " + tblName + ".")
+			}
+			if refTblName := pner.getReferencingTableName(tableMetadata, *alterExp.ColName_); refTblName != "" {
+				return createError("column " + *alterExp.ColName_ + " is referenced by FOREIGN KEY constraint of " + refTblName + ".")
+			}
+			// SQL text of expressions is not rewritten
+			if refColName := getReferencingColumnName(tableMetadata.Schema(), tableMetadata.Schema().GetColIndex(*alterExp.ColName_), false); refColName != "" {
+				return createError("column " + *alterExp.ColName_ + " is referenced by expression of column " + refColName + ".")
+			}
+			for idx, col := range curColumns {
+				colName := col.GetColumnName()
+				if colName == *alterExp.ColName_ {
+					colName = *alterExp.NewName_
+				}
+				addColumn(idx, colName, col, types.Value{})
+			}
+		}
+
+		var err error
+		tableMetadata, err = pner.catalog_.AlterTableSchema(tableMetadata, schema.NewSchema(newColumns), srcColIdxs, fillVals, pner.txn)
+		if err != nil {
+			// error type is kept (ex: constraint violation by existing tuples)
+			return returnError(err)
+		}
+	}
+
+	return nil, nil
+}
+
+// column definition except name is copied from base
+func copyColumnDef(name string, base *column.Column) *column.Column {
+	newCol := column.NewColumn(name, base.GetType(), base.HasIndex(), base.IndexKind(), types.PageID(-1), nil)
+	newCol.SetIsPrimaryKey(base.IsPrimaryKey())
+	newCol.SetIsUnique(base.IsUnique())
+	newCol.SetIsNotNull(base.IsNotNull())
+	newCol.SetDefaultValue(base.DefaultValue())
+	newCol.SetForeignKey(base.ForeignKey())
+	newCol.SetGeneratedExprStr(base.GeneratedExprStr())
+	newCol.SetCheckExprStr(base.CheckExprStr())
+	newCol.SetAutoIncrementSeqName(base.AutoIncrementSeqName())
+	newCol.SetDefaultSeqName(base.DefaultSeqName())
+	newCol.SetDecimalPrecisionAndScale(base.DecimalPrecision(), base.DecimalScale())
+	return newCol
+}
+
+// CREATE INDEX is processed at planning like ALTER TABLE. so returned plan is always nil.
+// index can be created also on materialized view because it is stored as a table
+func (pner *SimplePlanner) MakeCreateIndexPlan() (error, plans.Plan) {
+	tblName := *pner.qi.JoinTables_[0]
+	if view := pner.catalog_.GetView(tblName); view != nil && !view.IsMaterialized() {
+		return createError("index can't be created on view " + tblName + ".")
+	}
+	tableMetadata := pner.catalog_.GetTableByName(tblName)
+	if tableMetadata == nil {
+		return returnError(samehada_errors.NewUnknownTableError(tblName))
+	}
+	idxDef := pner.qi.IndexDefExpressions_[0]
+	if len(idxDef.KeyExprStrs_) > 0 {
+		return returnError(samehada_errors.NewParseError("", "index on expression is not supported. create index on generated column of the expression instead."))
+	}
+	if len(idxDef.Colnames_) != 1 {
+		return returnError(samehada_errors.NewParseError("", "index on multiple columns is not supported."))
+	}
+	colIdx := tableMetadata.Schema().GetColIndex(*idxDef.Colnames_[0])
+	if colIdx == math.MaxUint32 {
+		return returnError(samehada_errors.NewUnknownColumnError(tblName, *idxDef.Colnames_[0]))
+	}
+
+	newColumns := make([]*column.Column, 0)
+	srcColIdxs := make([]int, 0)
+	fillVals := make([]types.Value, 0)
+	for idx, col := range tableMetadata.Schema().GetColumns() {
+		newCol := copyColumnDef(col.GetColumnName(), col)
+		if idx == int(colIdx) {
+			if col.HasIndex() && (!idxDef.IsUnique_ || col.IsUnique()) {
+				return createError("index on column " + col.GetColumnName() + " of " + tblName + " already exists.")
+			}
+			if idxDef.IsUnique_ {
+				setUniqueConstraint(newCol, false)
+			} else {
+				// INDEX allows duplicated keys. it is used for point scan of equality predicate
+				newCol.SetHasIndex(true)
+				newCol.SetIndexKind(index_constants.INDEX_KIND_NON_UNIQUE_SKIP_LIST)
+			}
+		}
+		newColumns = append(newColumns, newCol)
+		srcColIdxs = append(srcColIdxs, idx)
+		fillVals = append(fillVals, types.Value{})
+	}
+	// tuples are not rewritten because layout is not changed. index entries of existing tuples are inserted
+	if _, err := pner.catalog_.AlterTableSchema(tableMetadata, schema.NewSchema(newColumns), srcColIdxs, fillVals, pner.txn); err != nil {
+		// error type is kept (ex: existing tuples violate UNIQUE constraint)
+		return returnError(err)
+	}
+	return nil, nil
+}
+
+// CREATE SEQUENCE is processed at planning like CREATE TABLE. so returned plan is always nil
+func (pner *SimplePlanner) MakeCreateSequencePlan() (error, plans.Plan) {
+	seqDef := pner.qi.SequenceDef_
+	if seqDef.HasUnsupportedOption_ {
+		return returnError(samehada_errors.NewParseError("", "only START WITH and INCREMENT BY are supported as option of sequence."))
+	}
+	// descending sequence starts from -1 by default
+	start := int64(1)
+	if seqDef.IncrementBy_ < 0 {
+		start = -1
+	}
+	if seqDef.StartWith_ != nil {
+		start = *seqDef.StartWith_
+	}
+	if err := pner.catalog_.CreateSequence(*seqDef.SeqName_, start, seqDef.IncrementBy_, pner.txn); err != nil {
+		return returnError(err)
+	}
+	return nil, nil
+}
+
+// CREATE VIEW is processed at planning like CREATE TABLE. so returned plan is always nil.
+// result of materialized view is stored to the table which has same name with the view
+func (pner *SimplePlanner) MakeCreateViewPlan() (error, plans.Plan) {
+	viewDef := pner.qi.ViewDef_
+	viewName := *viewDef.ViewName_
+	if viewDef.HasColumnList_ {
+		return returnError(samehada_errors.NewParseError("", "column list of view is not supported."))
+	}
+	if pner.catalog_.GetTableByName(viewName) != nil || pner.catalog_.GetView(viewName) != nil {
+		return createError("already " + viewName + " exists.")
+	}
+	// the query is validated by planning
+	err, plan := pner.makeSelectPlanFromSQL(*viewDef.SelectSQL_)
+	if err != nil {
+		return err, nil
+	}
+
+	if viewDef.IsMaterialized_ {
+		columns := make([]*column.Column, 0)
+		for _, col := range plan.OutputSchema().GetColumns() {
+			columns = append(columns, column.NewColumn(col.GetColumnName(), col.GetType(), false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil))
+		}
+		tableMetadata := pner.catalog_.CreateTable(viewName, schema.NewSchema(columns), pner.txn)
+		if err := pner.materializeView(tableMetadata, plan); err != nil {
+			return returnError(err)
+		}
+	}
+	if err := pner.catalog_.CreateView(viewName, *viewDef.SelectSQL_, viewDef.IsMaterialized_, pner.txn); err != nil {
+		return returnError(err)
+	}
+	return nil, nil
+}
+
+// REFRESH MATERIALIZED VIEW is processed at planning. so returned plan is always nil
+func (pner *SimplePlanner) MakeRefreshMaterializedViewPlan() (error, plans.Plan) {
+	viewName := *pner.qi.ViewDef_.ViewName_
+	view := pner.catalog_.GetView(viewName)
+	if view == nil || !view.IsMaterialized() {
+		return createError("materialized view " + viewName + " not found.")
+	}
+	tableMetadata := pner.catalog_.GetTableByName(viewName)
+	err, plan := pner.makeViewQueryPlan(view)
+	if err != nil {
+		return err, nil
+	}
+	// tables referenced by the view may be altered
+	viewColumns := plan.OutputSchema().GetColumns()
+	if len(viewColumns) != len(tableMetadata.Schema().GetColumns()) {
+		return createError("columns of materialized view " + viewName + " don't match its query.")
+	}
+	for idx, col := range tableMetadata.Schema().GetColumns() {
+		if col.GetType() != viewColumns[idx].GetType() {
+			return createError("columns of materialized view " + viewName + " don't match its query.")
+		}
+	}
+
+	// all tuples are replaced in the transaction. so readers see old or new result
+	context := executors.NewExecutorContext(pner.catalog_, pner.bpm, pner.txn)
+	seqScanPlan := plans.NewSeqScanPlanNode(tableMetadata.Schema(), nil, tableMetadata.OID())
+	if _, err := (&executors.ExecutionEngine{}).ExecuteWithoutResult(plans.NewDeletePlanNode(seqScanPlan), context); err != nil {
+		return returnError(err)
+	}
+	if err := pner.materializeView(tableMetadata, plan); err != nil {
+		return returnError(err)
+	}
+	return nil, nil
+}
+
+// materializeView executes plan and inserts the result to table of materialized view
+func (pner *SimplePlanner) materializeView(tableMetadata *catalog.TableMetadata, plan plans.Plan) error {
+	tgtColIdxs := make([]int, 0)
+	for colIdx := range tableMetadata.Schema().GetColumns() {
+		tgtColIdxs = append(tgtColIdxs, colIdx)
+	}
+	context := executors.NewExecutorContext(pner.catalog_, pner.bpm, pner.txn)
+	_, err := (&executors.ExecutionEngine{}).ExecuteWithoutResult(plans.NewInsertSelectPlanNode(plan, tgtColIdxs, tableMetadata.OID()), context)
+	return err
+}
+
+// tuples of materialized view are modified only by REFRESH MATERIALIZED VIEW
+func (pner *SimplePlanner) checkNotMaterializedView(tblName string) error {
+	if view := pner.catalog_.GetView(tblName); view != nil && view.IsMaterialized() {
+		return errors.New("materialized view " + tblName + " can't be modified directly.")
+	}
+	return nil
+}
+
+func createError(msg string) (error, plans.Plan) {
+	return errors.New(msg), nil
+}
+
+func returnError(err error) (error, plans.Plan) {
+	return err, nil
+}
+
+func (pner *SimplePlanner) MakeInsertPlan() (error, plans.Plan) {
+	tblName := *pner.qi.JoinTables_[0]
+	tableMetadata := pner.catalog_.GetTableByName(tblName)
+	if tableMetadata == nil {
+		return returnError(samehada_errors.NewUnknownTableError(tblName))
+	}
+	if err := pner.checkNotMaterializedView(tblName); err != nil {
+		return returnError(err)
+	}
+
+	schema_ := tableMetadata.Schema()
+	columns := schema_.GetColumns()
+
+	// when column list is omitted, values of all columns except generated columns are passed
+	tgtColIdxs := make([]int, 0)
+	if len(pner.qi.TargetCols_) == 0 {
+		for idx, col := range columns {
+			if !col.IsGenerated() {
+				tgtColIdxs = append(tgtColIdxs, idx)
+			}
+		}
+	} else {
+		for _, colName := range pner.qi.TargetCols_ {
+			colIdx := schema_.GetColIndex(*colName)
+			if colIdx == math.MaxUint32 {
+				return returnError(samehada_errors.NewUnknownColumnError(tblName, *colName))
+			}
+			if samehada_util.IsContainList[int](tgtColIdxs, int(colIdx)) {
+				return createError("column " + *colName + " is specified more than once.")
+			}
+			if columns[colIdx].IsGenerated() {
+				return createError("value of generated column " + *colName + " can't be specified.")
+			}
+			tgtColIdxs = append(tgtColIdxs, int(colIdx))
+		}
+	}
+	onConflict, err := pner.makeOnConflictAction(tableMetadata)
+	if err != nil {
+		return returnError(err)
+	}
+	if pner.qi.InsertSelectSQL_ != nil {
+		err, plan := pner.makeInsertSelectPlan(tableMetadata, tgtColIdxs)
+		if err != nil {
+			return err, nil
+		}
+		plan.(*plans.InsertPlanNode).SetOnConflictAction(onConflict)
+		return nil, plan
+	}
+	tgtColNum := len(tgtColIdxs)
+	if len(pner.qi.Values_)%tgtColNum != 0 {
+		return createError("number of values does not match number of columns.")
+	}
+
+	// NEXTVAL and CURRVAL are evaluated in order of appearance
+	for _, seqFunc := range pner.qi.SequenceFuncExpressions_ {
+		var seqVal int64
+		var err error
+		if seqFunc.FuncType_ == parser.NEXTVAL {
+			seqVal, err = pner.catalog_.NextVal(*seqFunc.SeqName_)
+		} else {
+			seqVal, err = pner.catalog_.CurrVal(*seqFunc.SeqName_)
+		}
+		if err != nil {
+			return returnError(err)
+		}
+		// typed same as integer literal. it is converted to type of the column
+		val := types.NewBigInt(seqVal)
+		if seqVal >= math.MinInt32 && seqVal <= math.MaxInt32 {
+			val = types.NewInteger(int32(seqVal))
+		}
+		pner.qi.Values_[seqFunc.ValueIdx_] = &val
+	}
+
+	insRows := make([][]types.Value, 0)
+	for rowHead := 0; rowHead < len(pner.qi.Values_); rowHead += tgtColNum {
+		row := make([]types.Value, len(columns))
+		isSpecified := make([]bool, len(columns))
+		for ii, colIdx := range tgtColIdxs {
+			val, err := expression.AdjustValueForColumn(*pner.qi.Values_[rowHead+ii], columns[colIdx])
+			if err != nil {
+				return returnError(err)
+			}
+			row[colIdx] = val
+			isSpecified[colIdx] = true
+		}
+		// columns which are not specified are filled with DEFAULT value or NULL.
+		// NOT NULL constraint is checked and generated columns are computed at InsertExecutor
+		if err := pner.catalog_.FillUnspecifiedValues(tableMetadata, row, isSpecified); err != nil {
+			return returnError(err)
+		}
+		insRows = append(insRows, row)
+	}
+
+	plan := plans.NewInsertPlanNode(insRows, tableMetadata.OID())
+	plan.(*plans.InsertPlanNode).SetOnConflictAction(onConflict)
+	return nil, plan
+}
+
+// makeOnConflictAction returns nil when neither ON DUPLICATE KEY UPDATE nor ON CONFLICT DO NOTHING is specified
+func (pner *SimplePlanner) makeOnConflictAction(tableMetadata *catalog.TableMetadata) (*plans.OnConflictAction, error) {
+	if pner.qi.OnConflictDoNothing_ {
+		return &plans.OnConflictAction{DoNothing: true}, nil
+	}
+	if len(pner.qi.UpsertSetExpressions_) == 0 {
+		return nil, nil
+	}
+
+	schema_ := tableMetadata.Schema()
+	setExps := make(map[uint32]*parser.SetExpression)
+	for _, setExp := range pner.qi.UpsertSetExpressions_ {
+		colIdx := schema_.GetColIndex(*setExp.ColName_)
+		if colIdx == math.MaxUint32 {
+			return nil, samehada_errors.NewUnknownColumnError(tableMetadata.GetTableName(), *setExp.ColName_)
+		}
+		if schema_.GetColumn(colIdx).IsGenerated() {
+			return nil, errors.New("value of generated column " + *setExp.ColName_ + " can't be specified.")
+		}
+		if _, ok := setExps[colIdx]; ok {
+			return nil, errors.New("column " + *setExp.ColName_ + " is specified more than once.")
+		}
+		setExps[colIdx] = setExp
+	}
+
+	// updated columns should be ordered by index
+	action := &plans.OnConflictAction{UpdateColIdxs: make([]int, 0), UpdateValues: make([]types.Value, 0), UpdateSrcColIdxs: make([]int, 0)}
+	for colIdx := uint32(0); colIdx < schema_.GetColumnCount(); colIdx++ {
+		setExp, ok := setExps[colIdx]
+		if !ok {
+			continue
+		}
+		col := schema_.GetColumn(colIdx)
+		srcColIdx := -1
+		val := types.NewNullOfType(col.GetType())
+		switch {
+		case setExp.ValuesOf_ != nil:
+			srcIdx := schema_.GetColIndex(*setExp.ValuesOf_)
+			if srcIdx == math.MaxUint32 {
+				return nil, samehada_errors.NewUnknownColumnError(tableMetadata.GetTableName(), *setExp.ValuesOf_)
+			}
+			if schema_.GetColumn(srcIdx).GetType() != col.GetType() {
+				return nil, samehada_errors.NewTypeMismatchError("column " + col.GetColumnName() + " is " + col.GetType().String() + " but " + schema_.GetColumn(srcIdx).GetType().String() + " value is passed.")
+			}
+			srcColIdx = int(srcIdx)
+		case setExp.UpdateValue_ != nil:
+			adjusted, err := expression.AdjustValueForColumn(*setExp.UpdateValue_, col)
+			if err != nil {
+				return nil, err
+			}
+			val = adjusted
+		default:
+			return nil, samehada_errors.NewParseError("", "only constant and VALUES(column) are supported on ON DUPLICATE KEY UPDATE.")
+		}
+		action.UpdateColIdxs = append(action.UpdateColIdxs, int(colIdx))
+		action.UpdateValues = append(action.UpdateValues, val)
+		action.UpdateSrcColIdxs = append(action.UpdateSrcColIdxs, srcColIdx)
+	}
+	return action, nil
+}
+
+// values of unspecified columns are filled at InsertExecutor
+func (pner *SimplePlanner) makeInsertSelectPlan(tableMetadata *catalog.TableMetadata, tgtColIdxs []int) (error, plans.Plan) {
+	err, childPlan := pner.makeSelectPlanFromSQL(*pner.qi.InsertSelectSQL_)
+	if err != nil {
+		return err, nil
+	}
+	columns := tableMetadata.Schema().GetColumns()
+	selectedCols := childPlan.OutputSchema().GetColumns()
+	if len(selectedCols) != len(tgtColIdxs) {
+		return createError("number of selected columns does not match number of columns.")
+	}
+	for ii, colIdx := range tgtColIdxs {
+		if selectedCols[ii].GetType() != columns[colIdx].GetType() {
+			return returnError(samehada_errors.NewTypeMismatchError("column " + columns[colIdx].GetColumnName() + " is " + columns[colIdx].GetType().String() + " but " + selectedCols[ii].GetType().String() + " value is selected."))
+		}
+	}
+	return nil, plans.NewInsertSelectPlanNode(childPlan, tgtColIdxs, tableMetadata.OID())
+}
+
+func (pner *SimplePlanner) MakeDeletePlan() (error, plans.Plan) {
+	tableMetadata := pner.catalog_.GetTableByName(*pner.qi.JoinTables_[0])
+	if tableMetadata == nil {
+		return returnError(samehada_errors.NewUnknownTableError(*pner.qi.JoinTables_[0]))
+	}
+	if err := pner.checkNotMaterializedView(*pner.qi.JoinTables_[0]); err != nil {
+		return returnError(err)
+	}
+
+	tgtTblSchema := tableMetadata.Schema()
+
+	expression_, err := pner.ConstructPredicate([]*schema.Schema{tgtTblSchema})
+	if err != nil {
+		return returnError(err)
+	}
+	//deletePlan := plans.NewDeletePlanNode(expression_, tableMetadata.OID())
+	seqScanPlanP := plans.NewSeqScanPlanNode(tgtTblSchema, expression_, tableMetadata.OID())
+	deletePlan := plans.NewDeletePlanNode(seqScanPlanP)
+
+	return nil, deletePlan
+}
+
+func (pner *SimplePlanner) MakeUpdatePlan() (error, plans.Plan) {
+	tableMetadata := pner.catalog_.GetTableByName(*pner.qi.JoinTables_[0])
+	if tableMetadata == nil {
+		return returnError(samehada_errors.NewUnknownTableError(*pner.qi.JoinTables_[0]))
+	}
+	if err := pner.checkNotMaterializedView(*pner.qi.JoinTables_[0]); err != nil {
+		return returnError(err)
+	}
+	tgtTblSchema := tableMetadata.Schema()
+	hasWhere := pner.hasWhere()
+
+	updateColIdxs := make([]int, 0)
+
+	// first, create update column idx list
+	for _, setExp := range pner.qi.SetExpressions_ {
+		colIdx := tgtTblSchema.GetColIndex(*setExp.ColName_)
+		if colIdx == math.MaxUint32 {
+			return returnError(samehada_errors.NewUnknownColumnError(*pner.qi.JoinTables_[0], *setExp.ColName_))
+		}
+		if tgtTblSchema.GetColumn(colIdx).IsGenerated() {
+			return createError("value of generated column " + *setExp.ColName_ + " can't be specified.")
+		}
+		updateColIdxs = append(updateColIdxs, int(colIdx))
+	}
+
+	// second, construc values list includes dummy value (not update target)
+	updateVals := make([]types.Value, tgtTblSchema.GetColumnCount())
+	// fill all elems with dummy
+	for idx, _ := range tgtTblSchema.GetColumns() {
+		updateVals[idx] = types.NewNull()
+	}
+	// overwrite elem which is update target
+	var updateExprs []expression.Expression = nil
+	for idx, colIdx := range updateColIdxs {
+		col := tgtTblSchema.GetColumn(uint32(colIdx))
+		if exprStr := pner.qi.SetExpressions_[idx].UpdateExprStr_; exprStr != nil {
+			// evaluated for each tuple
+			expr, exprType, err := pner.exprStrToExpression(*exprStr, tgtTblSchema)
+			if err != nil {
+				return returnError(err)
+			}
+			if exprType != types.Invalid && !expression.IsImplicitlyConvertible(exprType, col.GetType()) {
+				return returnError(samehada_errors.NewTypeMismatchError("column " + col.GetColumnName() + " is " + col.GetType().String() + " but " + exprType.String() + " value is passed."))
+			}
+			if updateExprs == nil {
+				updateExprs = make([]expression.Expression, len(updateColIdxs))
+			}
+			updateExprs[idx] = expr
+			continue
+		}
+		val, err := expression.AdjustValueForColumn(*pner.qi.SetExpressions_[idx].UpdateValue_, col)
+		if err != nil {
+			return returnError(err)
+		}
+		updateVals[colIdx] = val
+	}
+
+	var predicate expression.Expression = nil
+	if hasWhere {
+		var err error
+		if predicate, err = pner.ConstructPredicate([]*schema.Schema{tgtTblSchema}); err != nil {
+			return returnError(err)
+		}
 	}
 
 	seqScanPlan := plans.NewSeqScanPlanNode(tgtTblSchema, predicate, tableMetadata.OID())
-	return nil, plans.NewUpdatePlanNode(updateVals, updateColIdxs, seqScanPlan)
+	updatePlan := plans.NewUpdatePlanNode(updateVals, updateColIdxs, seqScanPlan)
+	updatePlan.(*plans.UpdatePlanNode).SetUpdateExprs(updateExprs)
+	return nil, updatePlan
 }
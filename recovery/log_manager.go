@@ -182,6 +182,13 @@ func (log_manager *LogManager) AppendLogRecord(log_record *LogRecord) types.LSN
 		binary.Write(buf, binary.LittleEndian, log_record.Prev_page_id)
 		pageIdInBytes := buf.Bytes()
 		copy(log_manager.log_buffer[pos:], pageIdInBytes)
+	} else if log_record.Log_record_type == OVERFLOWPAGE {
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.LittleEndian, log_record.Overflow_page_id)
+		binary.Write(buf, binary.LittleEndian, log_record.Overflow_next_page_id)
+		binary.Write(buf, binary.LittleEndian, uint32(len(log_record.Overflow_data)))
+		buf.Write(log_record.Overflow_data)
+		copy(log_manager.log_buffer[pos:], buf.Bytes())
 	}
 
 	log_manager.latch.WUnlock()
@@ -27,6 +27,8 @@ const (
 	ABORT
 	/** Creating a new page in the table heap. */
 	NEWPAGE
+	/** Writing a page which holds a part of large tuple. */
+	OVERFLOWPAGE
 )
 
 /**
@@ -52,6 +54,10 @@ const (
  *--------------------------
  * | HEADER | prev_page_id |
  *--------------------------
+ * For overflow page type log record
+ *--------------------------------------------------------------
+ * | HEADER | page_id | next_page_id | data_size | data_bytes |
+ *--------------------------------------------------------------
  */
 
 type LogRecord struct {
@@ -78,6 +84,11 @@ type LogRecord struct {
 
 	// case4: for new page opeartion
 	Prev_page_id types.PageID //INVALID_PAGE_ID
+
+	// case5: for overflow page opeartion
+	Overflow_page_id      types.PageID
+	Overflow_next_page_id types.PageID
+	Overflow_data         []byte
 }
 
 // friend class LogManager;
@@ -143,6 +154,21 @@ func NewLogRecordNewPage(txn_id types.TxnID, prev_lsn types.LSN, log_record_type
 	return ret
 }
 
+// constructor for OVERFLOWPAGE type
+func NewLogRecordOverflowPage(txn_id types.TxnID, prev_lsn types.LSN, log_record_type LogRecordType, page_id types.PageID,
+	next_page_id types.PageID, data []byte) *LogRecord {
+	ret := new(LogRecord)
+	ret.Txn_id = txn_id
+	ret.Prev_lsn = prev_lsn
+	ret.Log_record_type = log_record_type
+	ret.Overflow_page_id = page_id
+	ret.Overflow_next_page_id = next_page_id
+	ret.Overflow_data = data
+	// calculate log record size
+	ret.Size = HEADER_SIZE + 2*uint32(unsafe.Sizeof(page_id)) + uint32(unsafe.Sizeof(uint32(0))) + uint32(len(data))
+	return ret
+}
+
 func (log_record *LogRecord) GetDeleteRID() page.RID          { return log_record.Delete_rid }
 func (log_record *LogRecord) GetInserteTuple() tuple.Tuple    { return log_record.Insert_tuple }
 func (log_record *LogRecord) GetInsertRID() page.RID          { return log_record.Insert_rid }
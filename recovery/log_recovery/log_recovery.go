@@ -87,6 +87,19 @@ func (log_recovery *LogRecovery) DeserializeLogRecord(data []byte, log_record *r
 		log_record.New_tuple.DeserializeFrom(data[pos:])
 	} else if log_record.Log_record_type == recovery.NEWPAGE {
 		binary.Read(bytes.NewBuffer(data[pos:]), binary.LittleEndian, &log_record.Prev_page_id)
+	} else if log_record.Log_record_type == recovery.OVERFLOWPAGE {
+		if uint32(len(data)) < log_record.Size {
+			// incomplete log record
+			return false
+		}
+		buf := bytes.NewBuffer(data[pos:])
+		binary.Read(buf, binary.LittleEndian, &log_record.Overflow_page_id)
+		binary.Read(buf, binary.LittleEndian, &log_record.Overflow_next_page_id)
+		dataSize := new(uint32)
+		binary.Read(buf, binary.LittleEndian, dataSize)
+		pos += 2*uint32(unsafe.Sizeof(log_record.Overflow_page_id)) + uint32(unsafe.Sizeof(*dataSize))
+		log_record.Overflow_data = make([]byte, *dataSize)
+		copy(log_record.Overflow_data, data[pos:pos+*dataSize])
 	}
 
 	//fmt.Println(log_record)
@@ -150,7 +163,7 @@ func (log_recovery *LogRecovery) Redo() (types.LSN, bool) {
 				page_ :=
 					access.CastPageAsTablePage(log_recovery.buffer_pool_manager.FetchPage(log_record.Delete_rid.GetPageId()))
 				if page_.GetLSN() < log_record.GetLSN() {
-					page_.ApplyDelete(&log_record.Delete_rid, nil, nil)
+					page_.ApplyDelete(&log_record.Delete_rid, nil, log_recovery.log_manager)
 					page_.SetLSN(log_record.GetLSN())
 					isRedoOccured = true
 				}
@@ -187,8 +200,9 @@ func (log_recovery *LogRecovery) Redo() (types.LSN, bool) {
 			} else if log_record.Log_record_type == recovery.BEGIN {
 				// fmt.Println("found BEGIN log record")
 				log_recovery.active_txn[log_record.Txn_id] = log_record.Lsn
-			} else if log_record.Log_record_type == recovery.COMMIT {
+			} else if log_record.Log_record_type == recovery.COMMIT || log_record.Log_record_type == recovery.ABORT {
 				// fmt.Println("found COMMIT log record")
+				// rollback of aborted txn is logged and redone above, so it must not be undone again
 				delete(log_recovery.active_txn, log_record.Txn_id)
 			} else if log_record.Log_record_type == recovery.NEWPAGE {
 				var page_id types.PageID
@@ -199,6 +213,16 @@ func (log_recovery *LogRecovery) Redo() (types.LSN, bool) {
 				new_page.Init(page_id, log_record.Prev_page_id, log_recovery.log_manager, nil, nil)
 				//log_recovery.buffer_pool_manager.FlushPage(page_id)
 				log_recovery.buffer_pool_manager.UnpinPage(page_id, true)
+			} else if log_record.Log_record_type == recovery.OVERFLOWPAGE {
+				// overflow page is flushed at creation. so it exists on disk
+				page_ :=
+					access.CastPageAsOverflowPage(log_recovery.buffer_pool_manager.FetchPage(log_record.Overflow_page_id))
+				if page_.GetLSN() < log_record.GetLSN() {
+					page_.SetContent(log_record.Overflow_page_id, log_record.Overflow_next_page_id, log_record.Overflow_data)
+					page_.SetLSN(log_record.GetLSN())
+					isRedoOccured = true
+				}
+				log_recovery.buffer_pool_manager.UnpinPage(log_record.Overflow_page_id, true)
 			}
 			buffer_offset += log_record.Size
 		}
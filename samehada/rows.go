@@ -0,0 +1,299 @@
+package samehada
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/execution/executors"
+	"github.com/ryogrid/SamehadaDB/parser"
+	"github.com/ryogrid/SamehadaDB/storage/access"
+	"github.com/ryogrid/SamehadaDB/storage/table/schema"
+	"github.com/ryogrid/SamehadaDB/types"
+	"strconv"
+	"time"
+)
+
+// Rows is a cursor on result of a statement executed with SamehadaDB.Query.
+// result rows are pulled from executors one by one on each Next call.
+// the transaction of the statement is kept open and locks acquired by it are held
+// until all rows are read or Close is called
+type Rows struct {
+	sdb     *SamehadaDB
+	txn     *access.Transaction
+	cursor  *executors.Cursor
+	cancel  context.CancelFunc
+	schema_ *schema.Schema
+	curVals []*types.Value
+	// rows of RETURNING clause of DML. they are read on Query. nil for other statements
+	returnedVals [][]*types.Value
+	affectedRows int64
+	err          error
+	closed       bool
+}
+
+// Query executes sqlStr and returns Rows which reads result rows lazily.
+// unlike ExecuteSQL, all result rows are not materialized on memory at once.
+// Close of the returned Rows must be called after use to finish the transaction.
+// INSERT, UPDATE, DELETE and DDL statements are executed completely before Query returns.
+// rows of RETURNING clause of DML are kept on memory and can be read with Next
+func (sdb *SamehadaDB) Query(sqlStr string) (*Rows, error) {
+	return sdb.QueryContext(context.Background(), sqlStr)
+}
+
+// QueryContext is same as Query except that execution is canceled when ctx is done
+// before all rows are read. statement timeout includes time to read rows
+func (sdb *SamehadaDB) QueryContext(ctx context.Context, sqlStr string) (rows *Rows, retErr error) {
+	qi, err := parser.ProcessSQLStr(&sqlStr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := sdb.withStatementTimeout(ctx)
+	if ctx.Err() != nil {
+		cancel()
+		return nil, samehada_errors.NewQueryCanceledError(ctx.Err())
+	}
+	r := &Rows{sdb: sdb, txn: sdb.shi_.GetTransactionManager().Begin(nil), cancel: cancel}
+	defer func() {
+		if p := recover(); p != nil {
+			r.finish(panicToError(p))
+			rows, retErr = nil, r.err
+		}
+	}()
+	err, plan := sdb.planner_.MakePlan(qi, r.txn)
+	if err != nil || plan == nil {
+		// DDL is scceeded or planning is failed.
+		// changes made on planning (ex: ALTER_TABLE) are rollbacked when it is failed
+		r.finish(withSQLText(err, sqlStr))
+		if r.err != nil {
+			return nil, r.err
+		}
+		return r, nil
+	}
+
+	execCtx := executors.NewExecutorContext(sdb.catalog_, sdb.shi_.GetBufferPoolManager(), r.txn)
+	execCtx.SetContext(ctx)
+	r.cursor = sdb.exec_engine_.Open(plan, execCtx)
+	switch *qi.QueryType_ {
+	case parser.INSERT, parser.UPDATE, parser.DELETE:
+		// changes should not depend on how many rows caller reads
+		if len(qi.ReturningExprs_) > 0 {
+			r.schema_ = plan.OutputSchema()
+		}
+		r.readAllAffectedRows()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return r, nil
+	}
+	r.schema_ = plan.OutputSchema()
+	return r, nil
+}
+
+// Next prepares next result row for Scan and Values. it returns false when there is no more row
+// or an error occurred. in both cases the transaction is finished and Err should be checked
+func (rows *Rows) Next() (ret bool) {
+	if rows.returnedVals != nil {
+		if len(rows.returnedVals) == 0 {
+			rows.curVals = nil
+			return false
+		}
+		rows.curVals = rows.returnedVals[0]
+		rows.returnedVals = rows.returnedVals[1:]
+		return true
+	}
+	if rows.closed {
+		return false
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			rows.finish(panicToError(p))
+			ret = false
+		}
+	}()
+	for {
+		tuple_, done, err := rows.cursor.Next()
+		if err != nil {
+			rows.finish(err)
+			return false
+		}
+		if done || rows.txn.GetState() == access.ABORTED {
+			rows.finish(nil)
+			return false
+		}
+		if rows.schema_ != nil {
+			rows.curVals = convTupleToValues(rows.schema_, tuple_)
+			return true
+		}
+	}
+}
+
+// readAllAffectedRows executes DML completely and finishes the transaction.
+// rows of RETURNING clause are kept when schema_ is set
+func (rows *Rows) readAllAffectedRows() {
+	returnedVals := make([][]*types.Value, 0)
+	for {
+		tuple_, done, err := rows.cursor.Next()
+		if err != nil {
+			rows.finish(err)
+			return
+		}
+		if done || rows.txn.GetState() == access.ABORTED {
+			break
+		}
+		// DML executors return affected tuples
+		rows.affectedRows++
+		if rows.schema_ != nil {
+			returnedVals = append(returnedVals, convTupleToValues(rows.schema_, tuple_))
+		}
+	}
+	rows.finish(nil)
+	if rows.err == nil && rows.schema_ != nil {
+		rows.returnedVals = returnedVals
+	}
+}
+
+// RowsAffected returns number of rows inserted, updated or deleted by DML.
+// it is 0 for other statements
+func (rows *Rows) RowsAffected() int64 {
+	return rows.affectedRows
+}
+
+// Columns returns names of columns of result rows
+func (rows *Rows) Columns() []string {
+	if rows.schema_ == nil {
+		return []string{}
+	}
+	ret := make([]string, 0)
+	for _, col := range rows.schema_.GetColumns() {
+		ret = append(ret, col.GetColumnName())
+	}
+	return ret
+}
+
+// Values returns values of current row
+func (rows *Rows) Values() []*types.Value {
+	return rows.curVals
+}
+
+// Scan copies values of current row to dest.
+// supported types of dest are *types.Value, *interface{} (converted same as ExecuteSQL),
+// *int, *int32, *int64, *float32, *float64, *string, *bool, *time.Time and *[]byte
+func (rows *Rows) Scan(dest ...interface{}) error {
+	if rows.curVals == nil {
+		return errors.New("Scan is called without successful Next.")
+	}
+	if len(dest) != len(rows.curVals) {
+		return errors.New("expected " + strconv.Itoa(len(rows.curVals)) + " destinations, but got " + strconv.Itoa(len(dest)) + ".")
+	}
+	for idx, val := range rows.curVals {
+		if err := scanValue(val, dest[idx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Err returns error occurred during iteration
+func (rows *Rows) Err() error {
+	return rows.err
+}
+
+// Close finishes the transaction if it is not finished yet and releases resources used by the execution.
+// returned error is same as Err
+func (rows *Rows) Close() error {
+	rows.returnedVals = nil
+	rows.curVals = nil
+	rows.finish(nil)
+	return rows.err
+}
+
+// finish ends the transaction. it is aborted when err is not nil or it is already set to aborted state
+func (rows *Rows) finish(err error) {
+	if rows.closed {
+		return
+	}
+	rows.curVals = nil
+	if rows.cursor != nil {
+		rows.cursor.Close()
+	}
+	rows.closed = true
+	rows.cancel()
+	txnMgr := rows.sdb.shi_.GetTransactionManager()
+	if err == nil && rows.txn.GetState() != access.ABORTED {
+		// changes are committed even if removal of deleted tuples fails
+		rows.err = txnMgr.Commit(rows.txn)
+		return
+	}
+	// TODO: (SDB) when concurrent execution of transaction is activated, appropriate handling of aborted request is needed
+	txnMgr.Abort(rows.sdb.catalog_, rows.txn)
+	if err == nil {
+		err = abortedTxnError(rows.txn)
+	}
+	rows.err = err
+}
+
+func scanValue(val *types.Value, dest interface{}) error {
+	switch d := dest.(type) {
+	case *types.Value:
+		*d = *val
+		return nil
+	case *interface{}:
+		*d = convValueToIF(val)
+		return nil
+	}
+
+	if val.IsNull() {
+		return samehada_errors.NewTypeMismatchError(fmt.Sprintf("NULL value can not be stored to %T.", dest))
+	}
+	typ := val.ValueType()
+	switch d := dest.(type) {
+	case *int:
+		if typ.IsIntegerFamily() {
+			*d = int(val.ToInt64())
+			return nil
+		}
+	case *int64:
+		if typ.IsIntegerFamily() {
+			*d = val.ToInt64()
+			return nil
+		}
+	case *int32:
+		if typ == types.Tinyint || typ == types.Smallint || typ == types.Integer {
+			*d = int32(val.ToInt64())
+			return nil
+		}
+	case *float32:
+		if typ == types.Float {
+			*d = val.ToFloat()
+			return nil
+		}
+	case *float64:
+		if typ == types.Float {
+			*d = float64(val.ToFloat())
+			return nil
+		} else if typ.IsIntegerFamily() {
+			*d = float64(val.ToInt64())
+			return nil
+		}
+	case *string:
+		*d = val.ToString()
+		return nil
+	case *bool:
+		if typ == types.Boolean {
+			*d = val.ToBoolean()
+			return nil
+		}
+	case *time.Time:
+		if typ.IsTemporal() {
+			*d = val.ToTime()
+			return nil
+		}
+	case *[]byte:
+		if typ.IsString() {
+			*d = val.ToBlob()
+			return nil
+		}
+	}
+	return samehada_errors.NewTypeMismatchError(fmt.Sprintf("%s value can not be stored to %T.", typ.String(), dest))
+}
@@ -1,11 +1,15 @@
 package samehada
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/ryogrid/SamehadaDB/catalog"
 	"github.com/ryogrid/SamehadaDB/common"
 	"github.com/ryogrid/SamehadaDB/concurrency"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/execution/executors"
+	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/execution/plans"
 	"github.com/ryogrid/SamehadaDB/parser"
 	"github.com/ryogrid/SamehadaDB/planner"
@@ -19,6 +23,8 @@ import (
 	"github.com/ryogrid/SamehadaDB/storage/tuple"
 	"github.com/ryogrid/SamehadaDB/types"
 	"math"
+	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -28,12 +34,12 @@ type SamehadaDB struct {
 	exec_engine_ *executors.ExecutionEngine
 	chkpntMgr    *concurrency.CheckpointManager
 	planner_     planner.Planner
+	// execution of a statement is canceled when it exceeds this. 0 means no timeout.
+	// value is time.Duration and it must be accessed atomically because statements run on other goroutines
+	stmtTimeout int64
 }
 
 func reconstructIndexDataOfATbl(t *catalog.TableMetadata, c *catalog.Catalog, dman disk.DiskManager, txn *access.Transaction) {
-	executionEngine := &executors.ExecutionEngine{}
-	executorContext := executors.NewExecutorContext(c, t.Table().GetBufferPoolManager(), txn)
-
 	zeroClearedBuf := make([]byte, common.PageSize)
 	bpm := t.Table().GetBufferPoolManager()
 
@@ -48,14 +54,19 @@ func reconstructIndexDataOfATbl(t *catalog.TableMetadata, c *catalog.Catalog, dm
 
 				indexHeaderPageId := column_.IndexHeaderPageId()
 
-				hPageData := bpm.FetchPage(indexHeaderPageId).Data()
-				headerPage := (*page.HashTableHeaderPage)(unsafe.Pointer(hPageData))
+				hPage, err := bpm.FetchPageWithError(indexHeaderPageId)
+				if hPage == nil {
+					// db can't be used without the index
+					panic(fmt.Sprintf("header page of index on %s.%s can't be fetched: %v", t.GetTableName(), column_.GetColumnName(), err))
+				}
+				headerPage := (*page.HashTableHeaderPage)(unsafe.Pointer(hPage.Data()))
 				for ii := uint32(0); ii < headerPage.NumBlocks(); ii++ {
 					blockPageId := headerPage.GetBlockPageId(ii)
 					// zero clear specifed space of db file
 					dman.WritePage(blockPageId, zeroClearedBuf)
 				}
-			case index_constants.INDEX_KIND_SKIP_LIST:
+				bpm.UnpinPage(indexHeaderPageId, false)
+			case index_constants.INDEX_KIND_SKIP_LIST, index_constants.INDEX_KIND_NON_UNIQUE_SKIP_LIST:
 				// do nothing here
 				// (Since SkipList index can't reuse past allocated pages, data clear of allocated pages
 				//  are not needed...)
@@ -65,11 +76,19 @@ func reconstructIndexDataOfATbl(t *catalog.TableMetadata, c *catalog.Catalog, dm
 		}
 	}
 
+	insertAllIndexEntries(t, c, txn, func(index_constants.IndexKind) bool { return true })
+}
+
+// insertAllIndexEntries inserts entries of all tuples to indexes whose kind is accepted by isTarget
+func insertAllIndexEntries(t *catalog.TableMetadata, c *catalog.Catalog, txn *access.Transaction, isTarget func(index_constants.IndexKind) bool) {
+	executionEngine := &executors.ExecutionEngine{}
+	executorContext := executors.NewExecutorContext(c, t.Table().GetBufferPoolManager(), txn)
+
 	var allTuples []*tuple.Tuple = nil
 
 	// insert index entries correspond to each tuple and column to each index objects
-	for _, index_ := range t.Indexes() {
-		if index_ != nil {
+	for colIdx, index_ := range t.Indexes() {
+		if index_ != nil && isTarget(t.Schema().GetColumn(uint32(colIdx)).IndexKind()) {
 			if allTuples == nil {
 				// get all tuples once
 				outSchema := t.Schema()
@@ -91,6 +110,17 @@ func ReconstructAllIndexData(c *catalog.Catalog, dman disk.DiskManager, txn *acc
 	}
 }
 
+// ReconstructSkipListIndexData inserts entries of SkipList indexes which are empty at launch
+// because SkipList index always use new pages
+func ReconstructSkipListIndexData(c *catalog.Catalog, txn *access.Transaction) {
+	isSkipList := func(kind index_constants.IndexKind) bool {
+		return kind == index_constants.INDEX_KIND_SKIP_LIST || kind == index_constants.INDEX_KIND_NON_UNIQUE_SKIP_LIST
+	}
+	for _, t := range c.GetAllTables() {
+		insertAllIndexEntries(t, c, txn, isSkipList)
+	}
+}
+
 func NewSamehadaDB(dbName string, memKBytes int) *SamehadaDB {
 	isExistingDB := false
 
@@ -124,11 +154,16 @@ func NewSamehadaDB(dbName string, memKBytes int) *SamehadaDB {
 			// so when db did not exit graceful, all index data should be recounstruct
 			// (hash index uses already allocated pages but skip list index deserts these...)
 			ReconstructAllIndexData(c, shi.GetDiskManager(), txn)
+		} else {
+			ReconstructSkipListIndexData(c, txn)
 		}
 	} else {
 		c = catalog.BootstrapCatalog(shi.GetBufferPoolManager(), shi.GetLogManager(), shi.GetLockManager(), txn)
 	}
 
+	// counters of sequences are persisted with transactions which are independent of callers
+	c.SetTransactionManager(shi.GetTransactionManager())
+
 	shi.bpm.FlushAllPages()
 	shi.transaction_manager.Commit(txn)
 
@@ -140,46 +175,184 @@ func NewSamehadaDB(dbName string, memKBytes int) *SamehadaDB {
 	chkpntMgr := concurrency.NewCheckpointManager(shi.GetTransactionManager(), shi.GetLogManager(), shi.GetBufferPoolManager())
 	chkpntMgr.StartCheckpointTh()
 
-	return &SamehadaDB{shi, c, exec_engine, chkpntMgr, pnner}
+	return &SamehadaDB{shi, c, exec_engine, chkpntMgr, pnner, 0}
 }
 
 func (sdb *SamehadaDB) ExecuteSQL(sqlStr string) (error, [][]interface{}) {
-	err, results := sdb.ExecuteSQLRetValues(sqlStr)
+	return sdb.ExecuteSQLContext(context.Background(), sqlStr)
+}
+
+// ExecuteSQLContext is same as ExecuteSQL except that execution is canceled when ctx is done.
+// the transaction is aborted and QueryCanceledError is returned in that case
+func (sdb *SamehadaDB) ExecuteSQLContext(ctx context.Context, sqlStr string) (error, [][]interface{}) {
+	err, results, _ := sdb.executeSQL(ctx, sqlStr)
 	return err, ConvValueListToIFs(results)
 }
 
 func (sdb *SamehadaDB) ExecuteSQLRetValues(sqlStr string) (error, [][]*types.Value) {
-	qi := parser.ProcessSQLStr(&sqlStr)
+	err, retVals, _ := sdb.executeSQL(context.Background(), sqlStr)
+	return err, retVals
+}
+
+// ExecuteSQLRetAffectedRows returns count of rows inserted, updated or deleted by sqlStr.
+// the count is 0 for other statements
+func (sdb *SamehadaDB) ExecuteSQLRetAffectedRows(sqlStr string) (error, int64) {
+	err, _, affectedRows := sdb.executeSQL(context.Background(), sqlStr)
+	return err, affectedRows
+}
+
+// SetStatementTimeout sets timeout which is applied to each statement executed after the call.
+// 0 disables the timeout (default). when both of the timeout and deadline of the context passed
+// to ExecuteSQLContext are set, earlier one is applied
+func (sdb *SamehadaDB) SetStatementTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&sdb.stmtTimeout, int64(timeout))
+}
+
+// withStatementTimeout returns ctx which applies statement timeout if it is set
+func (sdb *SamehadaDB) withStatementTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := time.Duration(atomic.LoadInt64(&sdb.stmtTimeout)); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// executeSQL returns errors defined in samehada_errors package when the statement is invalid or failed.
+// panic in lower layers is also returned as error so that the process which embeds the DB is not terminated
+func (sdb *SamehadaDB) executeSQL(ctx context.Context, sqlStr string) (retErr error, retVals [][]*types.Value, affectedRows int64) {
+	qi, err := parser.ProcessSQLStr(&sqlStr)
+	if err != nil {
+		return err, nil, 0
+	}
+	ctx, cancel := sdb.withStatementTimeout(ctx)
+	defer cancel()
+	if ctx.Err() != nil {
+		return samehada_errors.NewQueryCanceledError(ctx.Err()), nil, 0
+	}
 	txn := sdb.shi_.transaction_manager.Begin(nil)
+	isTxnFinished := false
+	defer func() {
+		if r := recover(); r != nil {
+			if !isTxnFinished {
+				sdb.shi_.GetTransactionManager().Abort(sdb.catalog_, txn)
+			}
+			retErr, retVals, affectedRows = panicToError(r), nil, 0
+		}
+	}()
 	err, plan := sdb.planner_.MakePlan(qi, txn)
 
 	if err == nil && plan == nil {
-		// CREATE_TABLE is scceeded
+		// CREATE_TABLE, ALTER_TABLE or CREATE_SEQUENCE is scceeded
+		isTxnFinished = true
 		sdb.shi_.GetTransactionManager().Commit(txn)
-		return nil, nil
+		return nil, nil, 0
 	} else if err != nil {
-		return err, nil
+		// changes made on planning (ex: ALTER_TABLE) should be rollbacked
+		isTxnFinished = true
+		sdb.shi_.GetTransactionManager().Abort(sdb.catalog_, txn)
+		return withSQLText(err, sqlStr), nil, 0
 	}
 
-	context := executors.NewExecutorContext(sdb.catalog_, sdb.shi_.GetBufferPoolManager(), txn)
-	result := sdb.exec_engine_.Execute(plan, context)
+	execCtx := executors.NewExecutorContext(sdb.catalog_, sdb.shi_.GetBufferPoolManager(), txn)
+	execCtx.SetContext(ctx)
+	outSchema := plan.OutputSchema()
+	var result []*tuple.Tuple
+	var resultNum int64
+	if outSchema == nil {
+		// DML without RETURNING clause. affected tuples are only counted
+		resultNum, err = sdb.exec_engine_.ExecuteWithoutResult(plan, execCtx)
+	} else {
+		result, err = sdb.exec_engine_.ExecuteWithError(plan, execCtx)
+		resultNum = int64(len(result))
+	}
 
+	isTxnFinished = true
 	if txn.GetState() == access.ABORTED {
 		// TODO: (SDB) when concurrent execution of transaction is activated, appropriate handling of aborted request is needed
 		sdb.shi_.GetTransactionManager().Abort(sdb.catalog_, txn)
-	} else {
-		sdb.shi_.GetTransactionManager().Commit(txn)
+		if err != nil {
+			// ex: constraint violation
+			return withSQLText(err, sqlStr), nil, 0
+		}
+		return abortedTxnError(txn), nil, 0
+	} else if err := sdb.shi_.GetTransactionManager().Commit(txn); err != nil {
+		// changes are committed, but removal of deleted tuples failed
+		return err, nil, 0
+	}
+
+	switch *qi.QueryType_ {
+	case parser.INSERT, parser.UPDATE, parser.DELETE:
+		// DML executors return affected tuples
+		affectedRows = resultNum
 	}
 
-	outSchema := plan.OutputSchema()
 	if outSchema == nil { // when DELETE etc...
-		return nil, nil
+		return nil, nil, affectedRows
 	}
 
 	//fmt.Println(result, outSchema)
-	retVals := ConvTupleListToValues(outSchema, result)
+	retVals = ConvTupleListToValues(outSchema, result)
 
-	return nil, retVals
+	return nil, retVals, affectedRows
+}
+
+// abortedTxnError returns error which is reported when txn is aborted without error from executors
+func abortedTxnError(txn *access.Transaction) error {
+	if cause := txn.GetAbortCause(); cause != nil {
+		var ioErr *samehada_errors.IOError
+		if errors.As(cause, &ioErr) {
+			return ioErr
+		}
+		return samehada_errors.NewTxnAbortedError(cause)
+	}
+	// ex: lock conflict
+	return samehada_errors.NewTxnAbortedError(nil)
+}
+
+// panicToError converts value recovered from panic to error which is returned to the caller.
+// errors defined in samehada_errors package are returned as is and others are reported as InternalError.
+// error of expression evaluation (ex: NEXTVAL of exhausted sequence) is returned as is
+func panicToError(r interface{}) error {
+	if evalErr, ok := r.(*expression.EvaluationError); ok {
+		// failure of evaluation is not a bug
+		return evalErr.Err
+	}
+	err, ok := r.(error)
+	if !ok {
+		return samehada_errors.NewInternalError(fmt.Errorf("%v", r))
+	}
+	var ioErr *samehada_errors.IOError
+	var parseErr *samehada_errors.ParseError
+	var typeErr *samehada_errors.TypeMismatchError
+	var cvErr *samehada_errors.ConstraintViolationError
+	var canceledErr *samehada_errors.QueryCanceledError
+	var tableErr *samehada_errors.UnknownTableError
+	var columnErr *samehada_errors.UnknownColumnError
+	switch {
+	case errors.As(err, &ioErr):
+		return ioErr
+	case errors.As(err, &parseErr):
+		return parseErr
+	case errors.As(err, &typeErr):
+		return typeErr
+	case errors.As(err, &cvErr):
+		return cvErr
+	case errors.As(err, &canceledErr):
+		return canceledErr
+	case errors.As(err, &tableErr):
+		return tableErr
+	case errors.As(err, &columnErr):
+		return columnErr
+	}
+	return samehada_errors.NewInternalError(err)
+}
+
+// withSQLText sets sqlStr to ParseError which is reported by planner or executors without SQL text
+func withSQLText(err error, sqlStr string) error {
+	var parseErr *samehada_errors.ParseError
+	if errors.As(err, &parseErr) && parseErr.SQL == "" {
+		parseErr.SQL = sqlStr
+	}
+	return err
 }
 
 func (sdb *SamehadaDB) Shutdown() {
@@ -189,45 +362,75 @@ func (sdb *SamehadaDB) Shutdown() {
 	sdb.shi_.Shutdown(false)
 }
 
+// for testing. this method closes files without flushing dirty pages to simulate crash of the process
+func (sdb *SamehadaDB) CrashForTesting() {
+	sdb.chkpntMgr.StopCheckpointTh()
+	sdb.shi_.CloseFilesForTesting()
+}
+
 func ConvTupleListToValues(schema_ *schema.Schema, result []*tuple.Tuple) [][]*types.Value {
 	retVals := make([][]*types.Value, 0)
 	for _, tuple_ := range result {
-		rowVals := make([]*types.Value, 0)
-		colNum := int(schema_.GetColumnCount())
-		for idx := 0; idx < colNum; idx++ {
-			val := tuple_.GetValue(schema_, uint32(idx))
-			rowVals = append(rowVals, &val)
-		}
-		retVals = append(retVals, rowVals)
+		retVals = append(retVals, convTupleToValues(schema_, tuple_))
 	}
 	return retVals
 }
 
+func convTupleToValues(schema_ *schema.Schema, tuple_ *tuple.Tuple) []*types.Value {
+	rowVals := make([]*types.Value, 0)
+	colNum := int(schema_.GetColumnCount())
+	for idx := 0; idx < colNum; idx++ {
+		val := tuple_.GetValue(schema_, uint32(idx))
+		rowVals = append(rowVals, &val)
+	}
+	return rowVals
+}
+
 func ConvValueListToIFs(vals [][]*types.Value) [][]interface{} {
 	retVals := make([][]interface{}, 0)
 	for _, valsRow := range vals {
 		ifsList := make([]interface{}, 0)
 		for _, val := range valsRow {
-			if val.IsNull() {
-				ifsList = append(ifsList, nil)
-			} else {
-				switch val.ValueType() {
-				case types.Integer:
-					ifsList = append(ifsList, val.ToInteger())
-				case types.Float:
-					ifsList = append(ifsList, val.ToFloat())
-				case types.Varchar:
-					ifsList = append(ifsList, val.ToString())
-				default:
-					panic("not supported Value object")
-				}
-			}
+			ifsList = append(ifsList, convValueToIF(val))
 		}
 		retVals = append(retVals, ifsList)
 	}
 	return retVals
 }
 
+func convValueToIF(val *types.Value) interface{} {
+	if val.IsNull() {
+		return nil
+	}
+	switch val.ValueType() {
+	case types.Tinyint:
+		return val.ToTinyint()
+	case types.Smallint:
+		return val.ToSmallint()
+	case types.Integer:
+		return val.ToInteger()
+	case types.BigInt:
+		return val.ToBigInt()
+	case types.Float:
+		return val.ToFloat()
+	case types.Decimal:
+		// exact value is passed as string like "123.45"
+		return val.ToString()
+	case types.Timestamp, types.Date:
+		// time.Time in UTC
+		return val.ToTime()
+	case types.Varchar, types.Text, types.JSON:
+		return val.ToString()
+	case types.Blob:
+		return val.ToBlob()
+	case types.Boolean:
+		return val.ToBoolean()
+	default:
+		// Invalid type is not stored to tables
+		return nil
+	}
+}
+
 func PrintExecuteResults(results [][]*types.Value) {
 	fmt.Println("----")
 	for _, valList := range results {
@@ -1,12 +1,21 @@
 package samehada_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/ryogrid/SamehadaDB/common"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
+	"github.com/ryogrid/SamehadaDB/execution/expression"
 	"github.com/ryogrid/SamehadaDB/samehada"
 	testingpkg "github.com/ryogrid/SamehadaDB/testing"
+	"github.com/ryogrid/SamehadaDB/types"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TODO: (SDB) need to check query result (TestInsertAndMultiItemPredicateSelect)
@@ -182,6 +191,40 @@ func TestRebootWithLoadAndRecovery(t *testing.T) {
 	common.TempSuppressOnMemStorageMutex.Unlock()
 }
 
+// rollback of aborted txn must not be undone again at relaunch
+func TestRebootAfterAbort(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE accounts(id INT PRIMARY KEY, balance INT UNIQUE);")
+	db.ExecuteSQL("INSERT INTO accounts(id, balance) VALUES (1, 10);")
+	db.ExecuteSQL("INSERT INTO accounts(id, balance) VALUES (2, 20);")
+
+	// first row is updated and second one breaks UNIQUE constraint. so the txn is aborted
+	err, _ := db.ExecuteSQL("UPDATE accounts SET balance = 30;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("UPDATE accounts SET balance = 11 WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	db.Shutdown()
+
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	_, results := db2.ExecuteSQL("SELECT id, balance FROM accounts ORDER BY id;")
+	testingpkg.SimpleAssert(t, len(results) == 2)
+	testingpkg.SimpleAssert(t, results[0][1].(int32) == 11 && results[1][1].(int32) == 20)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
 func TestRebootAndReturnIFValues(t *testing.T) {
 	common.TempSuppressOnMemStorageMutex.Lock()
 	common.TempSuppressOnMemStorage = true
@@ -242,3 +285,2218 @@ func TestRebootAndReturnIFValues(t *testing.T) {
 	db3.Shutdown()
 	common.TempSuppressOnMemStorageMutex.Unlock()
 }
+
+func TestAlterTable(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE name_age_list(name VARCHAR(256), age INT);")
+	db.ExecuteSQL("INSERT INTO name_age_list(name, age) VALUES ('鈴木', 20);")
+	db.ExecuteSQL("INSERT INTO name_age_list(name, age) VALUES ('青木', 22);")
+	db.ExecuteSQL("INSERT INTO name_age_list(name, age) VALUES ('山田', 25);")
+
+	err, _ := db.ExecuteSQL("ALTER TABLE name_age_list ADD COLUMN city VARCHAR(256) DEFAULT 'Tokyo';")
+	testingpkg.SimpleAssert(t, err == nil)
+	db.ExecuteSQL("INSERT INTO name_age_list(name, age, city) VALUES ('加藤', 18, 'Osaka');")
+	_, results1 := db.ExecuteSQL("SELECT * FROM name_age_list WHERE city = 'Tokyo';")
+	testingpkg.SimpleAssert(t, len(results1) == 3)
+	testingpkg.SimpleAssert(t, len(results1[0]) == 3)
+
+	err, _ = db.ExecuteSQL("ALTER TABLE name_age_list ADD COLUMN score INT;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results2 := db.ExecuteSQL("SELECT name, score FROM name_age_list WHERE age = 20;")
+	testingpkg.SimpleAssert(t, len(results2) == 1)
+	testingpkg.SimpleAssert(t, results2[0][1] == nil)
+
+	err, _ = db.ExecuteSQL("ALTER TABLE name_age_list DROP COLUMN age;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("ALTER TABLE name_age_list RENAME COLUMN name TO fullname;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("ALTER TABLE name_age_list RENAME TO members;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	err, _ = db.ExecuteSQL("ALTER TABLE name_age_list DROP COLUMN city;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("ALTER TABLE members DROP COLUMN age;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	_, results3 := db.ExecuteSQL("SELECT fullname, city FROM members WHERE city = 'Osaka';")
+	testingpkg.SimpleAssert(t, len(results3) == 1)
+	testingpkg.SimpleAssert(t, results3[0][0].(string) == "加藤")
+
+	db.Shutdown()
+
+	// relaunch and check that changed schema is persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	_, results4 := db2.ExecuteSQL("SELECT * FROM members;")
+	testingpkg.SimpleAssert(t, len(results4) == 4)
+	testingpkg.SimpleAssert(t, len(results4[0]) == 3)
+	_, results5 := db2.ExecuteSQL("SELECT fullname FROM members WHERE city = 'Tokyo';")
+	testingpkg.SimpleAssert(t, len(results5) == 3)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+// table larger than buffer pool is rewritten page by page. tuples grow and some of them are moved to other pages
+func TestAlterTableOnManyPages(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 30)
+	err, _ := db.ExecuteSQL("CREATE TABLE items(id INT PRIMARY KEY, name VARCHAR(256));")
+	testingpkg.SimpleAssert(t, err == nil)
+	const rowNum = 3000
+	for ii := 0; ii < rowNum; ii += 100 {
+		vals := make([]string, 0)
+		for jj := ii; jj < ii+100; jj++ {
+			vals = append(vals, fmt.Sprintf("(%d, 'item%d')", jj, jj))
+		}
+		err, _ = db.ExecuteSQL("INSERT INTO items(id, name) VALUES " + strings.Join(vals, ", ") + ";")
+		testingpkg.SimpleAssert(t, err == nil)
+	}
+
+	err, _ = db.ExecuteSQL("ALTER TABLE items ADD COLUMN memo VARCHAR(256) DEFAULT '" + strings.Repeat("x", 50) + "';")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results1 := db.ExecuteSQL("SELECT COUNT(id), SUM(id) FROM items WHERE memo = '" + strings.Repeat("x", 50) + "';")
+	testingpkg.SimpleAssert(t, err == nil && len(results1) == 1)
+	testingpkg.SimpleAssert(t, results1[0][0].(int32) == rowNum && results1[0][1].(int32) == rowNum*(rowNum-1)/2)
+	err, results2 := db.ExecuteSQL("SELECT name, memo FROM items WHERE id = 2999;")
+	testingpkg.SimpleAssert(t, err == nil && len(results2) == 1 && results2[0][0].(string) == "item2999")
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name) VALUES (0, 'dup');")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// all tuples are checked against constraint of added column
+	err, _ = db.ExecuteSQL("ALTER TABLE items ADD COLUMN code INT UNIQUE DEFAULT 1;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, results3 := db.ExecuteSQL("SELECT COUNT(id) FROM items;")
+	testingpkg.SimpleAssert(t, err == nil && results3[0][0].(int32) == rowNum)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestAlterTableAbort(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE items(id INT PRIMARY KEY, name VARCHAR(256));")
+	for ii := 0; ii < 100; ii++ {
+		db.ExecuteSQL(fmt.Sprintf("INSERT INTO items(id, name) VALUES (%d, 'item%d');", ii, ii))
+	}
+
+	// last spec fails. changes of preceding specs (including rewriting of tuples) must be rolled back
+	err, _ := db.ExecuteSQL("ALTER TABLE items ADD COLUMN memo VARCHAR(256) DEFAULT '" + strings.Repeat("x", 100) + "', RENAME TO goods, ADD COLUMN memo INT;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	err, _ = db.ExecuteSQL("SELECT * FROM goods;")
+	testingpkg.SimpleAssert(t, err != nil)
+	_, results1 := db.ExecuteSQL("SELECT * FROM items;")
+	testingpkg.SimpleAssert(t, len(results1) == 100)
+	testingpkg.SimpleAssert(t, len(results1[0]) == 2)
+	_, results2 := db.ExecuteSQL("SELECT name FROM items WHERE id = 77;")
+	testingpkg.SimpleAssert(t, len(results2) == 1)
+	testingpkg.SimpleAssert(t, results2[0][0].(string) == "item77")
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name) VALUES (77, 'dup');")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// table is still alterable after abort
+	err, _ = db.ExecuteSQL("ALTER TABLE items ADD COLUMN memo INT DEFAULT 1, RENAME TO goods;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results3 := db.ExecuteSQL("SELECT name, memo FROM goods WHERE id = 77;")
+	testingpkg.SimpleAssert(t, len(results3) == 1)
+	testingpkg.SimpleAssert(t, results3[0][0].(string) == "item77")
+	testingpkg.SimpleAssert(t, results3[0][1].(int32) == 1)
+
+	db.Shutdown()
+
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	_, results4 := db2.ExecuteSQL("SELECT * FROM goods;")
+	testingpkg.SimpleAssert(t, len(results4) == 100)
+	testingpkg.SimpleAssert(t, len(results4[0]) == 3)
+	err, _ = db2.ExecuteSQL("SELECT * FROM items;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestPrimaryKeyAndUniqueConstraint(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE users(id INT PRIMARY KEY, email VARCHAR(256), name VARCHAR(256), UNIQUE (email));")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO users(id, email, name) VALUES (1, 'suzuki@example.com', '鈴木');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO users(id, email, name) VALUES (2, 'aoki@example.com', '青木');")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	var cvErr *samehada_errors.ConstraintViolationError
+	err, _ = db.ExecuteSQL("INSERT INTO users(id, email, name) VALUES (1, 'yamada@example.com', '山田');")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.Kind == samehada_errors.CONSTRAINT_PRIMARY_KEY && cvErr.ColumnName == "id")
+	err, _ = db.ExecuteSQL("INSERT INTO users(id, email, name) VALUES (3, 'aoki@example.com', '山田');")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.Kind == samehada_errors.CONSTRAINT_UNIQUE && cvErr.ColumnName == "email")
+
+	_, results1 := db.ExecuteSQL("SELECT * FROM users;")
+	testingpkg.SimpleAssert(t, len(results1) == 2)
+
+	err, _ = db.ExecuteSQL("UPDATE users SET email = 'suzuki@example.com' WHERE id = 2;")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.Kind == samehada_errors.CONSTRAINT_UNIQUE)
+	err, _ = db.ExecuteSQL("UPDATE users SET email = 'aoki@example.jp' WHERE id = 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	// updating to same value is not violation
+	err, _ = db.ExecuteSQL("UPDATE users SET id = 2 WHERE id = 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	// deleted key can be reused
+	db.ExecuteSQL("DELETE FROM users WHERE id = 1;")
+	err, _ = db.ExecuteSQL("INSERT INTO users(id, email, name) VALUES (1, 'suzuki@example.com', '鈴木');")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	// composite keys are not supported and the table is not created
+	var parseErr *samehada_errors.ParseError
+	err, _ = db.ExecuteSQL("CREATE TABLE pairs(a INT, b INT, PRIMARY KEY (a, b));")
+	testingpkg.SimpleAssert(t, errors.As(err, &parseErr))
+	err, _ = db.ExecuteSQL("CREATE TABLE pairs(a INT, b INT, UNIQUE (a, b));")
+	testingpkg.SimpleAssert(t, errors.As(err, &parseErr))
+	err, _ = db.ExecuteSQL("INSERT INTO pairs(a, b) VALUES (1, 2);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE UNIQUE INDEX users_id_email ON users (id, email);")
+	testingpkg.SimpleAssert(t, errors.As(err, &parseErr))
+	err, _ = db.ExecuteSQL("CREATE TABLE pairs(a INT, b INT, PRIMARY KEY (a));")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	db.Shutdown()
+
+	// relaunch and check that constraints are persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ = db2.ExecuteSQL("INSERT INTO users(id, email, name) VALUES (2, 'kato@example.com', '加藤');")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.Kind == samehada_errors.CONSTRAINT_PRIMARY_KEY)
+	err, _ = db2.ExecuteSQL("INSERT INTO users(id, email, name) VALUES (3, 'kato@example.com', '加藤');")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results2 := db2.ExecuteSQL("SELECT * FROM users;")
+	testingpkg.SimpleAssert(t, len(results2) == 3)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestNotNullAndDefault(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE items(id INT PRIMARY KEY, name VARCHAR(256) NOT NULL, city VARCHAR(256) DEFAULT 'Tokyo', price INT NOT NULL DEFAULT 100, memo VARCHAR(256));")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_default(id INT NOT NULL DEFAULT NULL);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_default(id INT DEFAULT 'abc');")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// columns which are not specified are filled with DEFAULT value or NULL
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name) VALUES (1, 'apple');")
+	testingpkg.SimpleAssert(t, err == nil)
+	// order of column list can differ from table definition
+	err, _ = db.ExecuteSQL("INSERT INTO items(price, name, id) VALUES (300, 'banana', 2), (50, 'cherry', 3);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO items VALUES (4, 'durian', 'Osaka', 1000, 'smelly');")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	_, results1 := db.ExecuteSQL("SELECT * FROM items WHERE id = 1;")
+	testingpkg.SimpleAssert(t, len(results1) == 1)
+	testingpkg.SimpleAssert(t, results1[0][2].(string) == "Tokyo")
+	testingpkg.SimpleAssert(t, results1[0][3].(int32) == 100)
+	testingpkg.SimpleAssert(t, results1[0][4] == nil)
+	_, results2 := db.ExecuteSQL("SELECT name, price FROM items WHERE id = 3;")
+	testingpkg.SimpleAssert(t, results2[0][0].(string) == "cherry")
+	testingpkg.SimpleAssert(t, results2[0][1].(int32) == 50)
+
+	var cvErr *samehada_errors.ConstraintViolationError
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, city) VALUES (5, 'Nagoya');")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.Kind == samehada_errors.CONSTRAINT_NOT_NULL && cvErr.ColumnName == "name")
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name, price) VALUES (5, 'egg', NULL);")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.Kind == samehada_errors.CONSTRAINT_NOT_NULL && cvErr.ColumnName == "price")
+	err, _ = db.ExecuteSQL("UPDATE items SET name = NULL WHERE id = 1;")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.Kind == samehada_errors.CONSTRAINT_NOT_NULL)
+	err, _ = db.ExecuteSQL("UPDATE items SET memo = NULL WHERE id = 4;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	// type mismatch is reported with column name and types
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name, price) VALUES (5, 'egg', TRUE);")
+	testingpkg.SimpleAssert(t, err != nil && err.Error() == "column price is INTEGER but BOOLEAN value is passed.")
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name, price) VALUES (5, 'egg', 'free');")
+	testingpkg.SimpleAssert(t, err != nil && err.Error() == "can not store to column price: value 'free' is invalid as INTEGER.")
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name) VALUES (5, 'egg', 'free');")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// NOT NULL column without DEFAULT can't be added to non-empty table
+	err, _ = db.ExecuteSQL("ALTER TABLE items ADD COLUMN stock INT NOT NULL;")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	err, _ = db.ExecuteSQL("ALTER TABLE items ADD COLUMN stock INT NOT NULL DEFAULT 10;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	db.Shutdown()
+
+	// relaunch and check that constraints and DEFAULT values are persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ = db2.ExecuteSQL("INSERT INTO items(id, name) VALUES (5, 'egg');")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results3 := db2.ExecuteSQL("SELECT * FROM items WHERE id = 5;")
+	testingpkg.SimpleAssert(t, len(results3) == 1)
+	testingpkg.SimpleAssert(t, results3[0][2].(string) == "Tokyo")
+	testingpkg.SimpleAssert(t, results3[0][3].(int32) == 100)
+	testingpkg.SimpleAssert(t, results3[0][5].(int32) == 10)
+	err, _ = db2.ExecuteSQL("INSERT INTO items(id) VALUES (6);")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	_, results4 := db2.ExecuteSQL("SELECT * FROM items;")
+	testingpkg.SimpleAssert(t, len(results4) == 5)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestForeignKey(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE users(id INT PRIMARY KEY, name VARCHAR(256));")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE orders(id INT PRIMARY KEY, user_id INT REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE reviews(id INT, user_id INT, FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE payments(id INT, user_id INT REFERENCES users(id));")
+	testingpkg.SimpleAssert(t, err == nil)
+	// referenced column must be PRIMARY KEY or UNIQUE
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_fk(id INT, user_name VARCHAR(256) REFERENCES users(name));")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_fk(id INT, user_id INT REFERENCES no_such_table(id));")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	err, _ = db.ExecuteSQL("INSERT INTO users VALUES (1, 'alice'), (2, 'bob'), (3, 'carol');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO orders VALUES (10, 1), (11, 2), (12, 2), (13, 3), (14, NULL);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO reviews VALUES (20, 2), (21, 1);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO payments VALUES (30, 1);")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	// referenced tuple must exist on INSERT and UPDATE of child
+	var cvErr *samehada_errors.ConstraintViolationError
+	err, _ = db.ExecuteSQL("INSERT INTO orders VALUES (15, 4);")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.Kind == samehada_errors.CONSTRAINT_FOREIGN_KEY && cvErr.TableName == "orders" && cvErr.ColumnName == "user_id")
+	err, _ = db.ExecuteSQL("UPDATE orders SET user_id = 5 WHERE id = 10;")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	_, results1 := db.ExecuteSQL("SELECT user_id FROM orders WHERE id = 10;")
+	testingpkg.SimpleAssert(t, results1[0][0].(int32) == 1)
+
+	// RESTRICT: user 1 is referenced by payments. cascaded deletion is rollbacked
+	err, _ = db.ExecuteSQL("DELETE FROM users WHERE id = 1;")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.TableName == "payments")
+	_, results2 := db.ExecuteSQL("SELECT * FROM orders WHERE user_id = 1;")
+	testingpkg.SimpleAssert(t, len(results2) == 1)
+	_, results3 := db.ExecuteSQL("SELECT * FROM users WHERE id = 1;")
+	testingpkg.SimpleAssert(t, len(results3) == 1)
+
+	// CASCADE and SET NULL
+	err, _ = db.ExecuteSQL("DELETE FROM users WHERE id = 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results4 := db.ExecuteSQL("SELECT * FROM orders;")
+	testingpkg.SimpleAssert(t, len(results4) == 3)
+	_, results5 := db.ExecuteSQL("SELECT user_id FROM reviews WHERE id = 20;")
+	testingpkg.SimpleAssert(t, results5[0][0] == nil)
+
+	// ON UPDATE CASCADE
+	err, _ = db.ExecuteSQL("UPDATE users SET id = 33 WHERE id = 3;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results6 := db.ExecuteSQL("SELECT user_id FROM orders WHERE id = 13;")
+	testingpkg.SimpleAssert(t, results6[0][0].(int32) == 33)
+	// ON UPDATE RESTRICT (default) of payments and reviews
+	err, _ = db.ExecuteSQL("UPDATE users SET id = 11 WHERE id = 1;")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+
+	// referenced column can't be dropped or renamed
+	err, _ = db.ExecuteSQL("ALTER TABLE users DROP COLUMN id;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("ALTER TABLE users RENAME COLUMN id TO user_id;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("ALTER TABLE users RENAME TO members;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	db.Shutdown()
+
+	// relaunch and check that constraints are persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ = db2.ExecuteSQL("CREATE TABLE shipments(id INT, order_id INT REFERENCES orders(id) ON DELETE CASCADE);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db2.ExecuteSQL("INSERT INTO shipments VALUES (40, 13);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db2.ExecuteSQL("INSERT INTO orders VALUES (15, 4);")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	err, _ = db2.ExecuteSQL("INSERT INTO orders VALUES (15, 33);")
+	testingpkg.SimpleAssert(t, err == nil)
+	// cascaded deletion goes through multiple tables
+	err, _ = db2.ExecuteSQL("DELETE FROM members WHERE id = 33;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results7 := db2.ExecuteSQL("SELECT * FROM orders;")
+	testingpkg.SimpleAssert(t, len(results7) == 2)
+	_, results8 := db2.ExecuteSQL("SELECT * FROM shipments;")
+	testingpkg.SimpleAssert(t, len(results8) == 0)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestCheckConstraintAndGeneratedColumn(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE items(id INT PRIMARY KEY, price INT, qty INT CHECK (qty < 100), total INT AS (price * qty) STORED, CHECK (price > 0 AND price < 1000));")
+	testingpkg.SimpleAssert(t, err == nil)
+	// type of generated column must match the expression
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_gen(price INT, label VARCHAR(256) AS (price + 1) STORED);")
+	testingpkg.SimpleAssert(t, err != nil)
+	// CHECK constraint must be boolean expression
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_check(price INT CHECK (price + 1));")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// generated column is omitted from column list
+	err, _ = db.ExecuteSQL("INSERT INTO items VALUES (1, 100, 3), (2, 250, 2);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, price) VALUES (3, 10);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, price, qty, total) VALUES (4, 10, 1, 10);")
+	testingpkg.SimpleAssert(t, err != nil)
+	_, results1 := db.ExecuteSQL("SELECT total FROM items WHERE id = 1;")
+	testingpkg.SimpleAssert(t, results1[0][0].(int32) == 300)
+	// NULL propagates and doesn't violate CHECK constraint
+	_, results2 := db.ExecuteSQL("SELECT total FROM items WHERE id = 3;")
+	testingpkg.SimpleAssert(t, results2[0][0] == nil)
+
+	var cvErr *samehada_errors.ConstraintViolationError
+	err, _ = db.ExecuteSQL("INSERT INTO items VALUES (5, 100, 100);")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.Kind == samehada_errors.CONSTRAINT_CHECK && cvErr.TableName == "items" && cvErr.ColumnName == "qty")
+	err, _ = db.ExecuteSQL("INSERT INTO items VALUES (5, 1000, 1);")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	testingpkg.SimpleAssert(t, cvErr.ColumnName == "price")
+
+	// expression which absorbs NULL is evaluated even when the column is NULL
+	err, _ = db.ExecuteSQL("CREATE TABLE stocks(id INT, qty INT CHECK (COALESCE(qty, -1) >= 0));")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO stocks(id, qty) VALUES (1, NULL);")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr) && cvErr.Kind == samehada_errors.CONSTRAINT_CHECK && cvErr.ColumnName == "qty")
+	err, _ = db.ExecuteSQL("INSERT INTO stocks(id, qty) VALUES (1, 0);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("UPDATE stocks SET qty = NULL WHERE id = 1;")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+
+	// generated column is recomputed on UPDATE and can't be updated directly
+	err, _ = db.ExecuteSQL("UPDATE items SET qty = 5 WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results3 := db.ExecuteSQL("SELECT total FROM items WHERE id = 1;")
+	testingpkg.SimpleAssert(t, results3[0][0].(int32) == 500)
+	err, _ = db.ExecuteSQL("UPDATE items SET total = 1 WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("UPDATE items SET qty = 500 WHERE id = 2;")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	_, results4 := db.ExecuteSQL("SELECT qty FROM items WHERE id = 2;")
+	testingpkg.SimpleAssert(t, results4[0][0].(int32) == 2)
+
+	// column referenced by expression can't be dropped or renamed
+	err, _ = db.ExecuteSQL("ALTER TABLE items DROP COLUMN price;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("ALTER TABLE items RENAME COLUMN qty TO quantity;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	db.Shutdown()
+
+	// relaunch and check that expressions are persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ = db2.ExecuteSQL("INSERT INTO items VALUES (6, 20, 4);")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results5 := db2.ExecuteSQL("SELECT total FROM items WHERE id = 6;")
+	testingpkg.SimpleAssert(t, results5[0][0].(int32) == 80)
+	err, _ = db2.ExecuteSQL("UPDATE items SET price = 0 WHERE id = 6;")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+	// added generated column is computed for existing tuples
+	err, _ = db2.ExecuteSQL("ALTER TABLE items ADD COLUMN doubled INT AS (price * 2) STORED;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results6 := db2.ExecuteSQL("SELECT doubled, total FROM items WHERE id = 2;")
+	testingpkg.SimpleAssert(t, results6[0][0].(int32) == 500)
+	testingpkg.SimpleAssert(t, results6[0][1].(int32) == 500)
+	err, _ = db2.ExecuteSQL("INSERT INTO items VALUES (7, 1000, 1);")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr))
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestAutoIncrementAndSequence(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE users(id INT AUTO_INCREMENT PRIMARY KEY, name VARCHAR(256));")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE logs(id SERIAL, ticket INT, msg VARCHAR(256));")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_auto_inc(name VARCHAR(256) AUTO_INCREMENT);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE SEQUENCE ticket_seq START WITH 100 INCREMENT BY 10;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE SEQUENCE ticket_seq;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	err, _ = db.ExecuteSQL("INSERT INTO users(name) VALUES ('alice'), ('bob');")
+	testingpkg.SimpleAssert(t, err == nil)
+	// NULL means generated value
+	err, _ = db.ExecuteSQL("INSERT INTO users VALUES (NULL, 'carol');")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results1 := db.ExecuteSQL("SELECT name FROM users WHERE id = 3;")
+	testingpkg.SimpleAssert(t, results1[0][0].(string) == "carol")
+	// specified value advances the counter
+	err, _ = db.ExecuteSQL("INSERT INTO users VALUES (10, 'dave');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO users(name) VALUES ('eve');")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results2 := db.ExecuteSQL("SELECT name FROM users WHERE id = 11;")
+	testingpkg.SimpleAssert(t, results2[0][0].(string) == "eve")
+	// generated value is not reused even if the transaction is aborted
+	err, _ = db.ExecuteSQL("INSERT INTO users VALUES (NULL, 'frank'), (11, 'duplicated');")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO users(name) VALUES ('grace');")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results3 := db.ExecuteSQL("SELECT name FROM users WHERE id = 13;")
+	testingpkg.SimpleAssert(t, results3[0][0].(string) == "grace")
+
+	// NEXTVAL and CURRVAL of standalone sequence
+	err, _ = db.ExecuteSQL("INSERT INTO logs(ticket, msg) VALUES (CURRVAL(ticket_seq), 'not called yet');")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO logs(ticket, msg) VALUES (NEXTVAL(ticket_seq), 'first'), (CURRVAL(ticket_seq), 'same');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO logs(ticket, msg) VALUES (NEXTVAL(ticket_seq), 'second');")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results4 := db.ExecuteSQL("SELECT msg FROM logs WHERE ticket = 100;")
+	testingpkg.SimpleAssert(t, len(results4) == 2)
+	_, results5 := db.ExecuteSQL("SELECT msg FROM logs WHERE ticket = 110;")
+	testingpkg.SimpleAssert(t, results5[0][0].(string) == "second")
+	err, _ = db.ExecuteSQL("INSERT INTO logs(ticket, msg) VALUES (NEXTVAL(no_such_seq), 'x');")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// counters are 64-bit and AUTO_INCREMENT column keeps its declared type
+	err, _ = db.ExecuteSQL("CREATE TABLE big_ids(id BIGINT AUTO_INCREMENT, v INT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO big_ids VALUES (3000000000, 1);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO big_ids(v) VALUES (2);")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results9 := db.ExecuteSQL("SELECT id FROM big_ids WHERE v = 2;")
+	testingpkg.SimpleAssert(t, results9[0][0].(int64) == 3000000001)
+	err, _ = db.ExecuteSQL("CREATE TABLE tiny_ids(id TINYINT AUTO_INCREMENT, v INT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO tiny_ids VALUES (127, 1);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO tiny_ids(v) VALUES (2);")
+	testingpkg.SimpleAssert(t, err != nil)
+	// exhausted sequence returns error instead of wrapping around
+	err, _ = db.ExecuteSQL("CREATE TABLE seq_vals(val BIGINT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE SEQUENCE last_seq START WITH 9223372036854775806;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO seq_vals VALUES (NEXTVAL(last_seq)), (NEXTVAL(last_seq));")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO seq_vals VALUES (NEXTVAL(last_seq));")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO seq_vals VALUES (CURRVAL(last_seq));")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results10 := db.ExecuteSQL("SELECT val FROM seq_vals WHERE val = 9223372036854775807;")
+	testingpkg.SimpleAssert(t, len(results10) == 2)
+
+	// counters are recovered from log without flushing pages
+	db.CrashForTesting()
+
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ = db2.ExecuteSQL("INSERT INTO users(name) VALUES ('heidi');")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results6 := db2.ExecuteSQL("SELECT name FROM users WHERE id = 14;")
+	testingpkg.SimpleAssert(t, len(results6) == 1 && results6[0][0].(string) == "heidi")
+	err, _ = db2.ExecuteSQL("INSERT INTO logs(ticket, msg) VALUES (NEXTVAL(ticket_seq), 'third');")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results7 := db2.ExecuteSQL("SELECT msg FROM logs WHERE ticket = 120;")
+	testingpkg.SimpleAssert(t, len(results7) == 1 && results7[0][0].(string) == "third")
+	db2.Shutdown()
+
+	db3 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ = db3.ExecuteSQL("INSERT INTO logs(msg) VALUES ('auto');")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results8 := db3.ExecuteSQL("SELECT id FROM logs WHERE msg = 'auto';")
+	// ids 1-4 are used by tuples inserted before
+	testingpkg.SimpleAssert(t, results8[0][0].(int64) == 5)
+
+	common.TempSuppressOnMemStorage = false
+	db3.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestSequenceFunctionInExpression(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE SEQUENCE sq START WITH 10;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	// SELECT without FROM and quoted sequence name
+	err, results1 := db.ExecuteSQL("SELECT NEXTVAL('sq');")
+	testingpkg.SimpleAssert(t, err == nil && len(results1) == 1 && results1[0][0].(int64) == 10)
+	err, results2 := db.ExecuteSQL("SELECT CURRVAL(sq);")
+	testingpkg.SimpleAssert(t, err == nil && len(results2) == 1 && results2[0][0].(int64) == 10)
+	err, _ = db.ExecuteSQL("SELECT NEXTVAL('no_such_seq');")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	err, _ = db.ExecuteSQL("CREATE TABLE items(id BIGINT, name VARCHAR(256));")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name) VALUES (NEXTVAL('sq'), 'apple'), (NEXTVAL('sq'), 'melon'), (NEXTVAL('sq'), 'grape');")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	// WHERE
+	err, results3 := db.ExecuteSQL("SELECT name FROM items WHERE id = CURRVAL('sq');")
+	testingpkg.SimpleAssert(t, err == nil && len(results3) == 1 && results3[0][0].(string) == "grape")
+
+	// UPDATE SET is evaluated for each tuple
+	err, _ = db.ExecuteSQL("UPDATE items SET id = NEXTVAL(sq) WHERE id < 20;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results4 := db.ExecuteSQL("SELECT id FROM items ORDER BY id;")
+	testingpkg.SimpleAssert(t, err == nil && len(results4) == 3)
+	testingpkg.SimpleAssert(t, results4[0][0].(int64) == 14 && results4[1][0].(int64) == 15 && results4[2][0].(int64) == 16)
+	err, _ = db.ExecuteSQL("UPDATE items SET name = NEXTVAL(sq);")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// DEFAULT
+	err, _ = db.ExecuteSQL("CREATE TABLE orders(id INT DEFAULT NEXTVAL('sq'), qty INT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_default(id VARCHAR(16) DEFAULT NEXTVAL('sq'));")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_default(id INT DEFAULT NEXTVAL('no_such_seq'));")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_default(id INT DEFAULT CURRVAL('sq'));")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO orders(qty) VALUES (1), (2);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO orders(id, qty) VALUES (100, 3);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results5 := db.ExecuteSQL("SELECT id, qty FROM orders ORDER BY qty;")
+	testingpkg.SimpleAssert(t, err == nil && len(results5) == 3)
+	testingpkg.SimpleAssert(t, results5[0][0].(int32) == 17 && results5[1][0].(int32) == 18 && results5[2][0].(int32) == 100)
+
+	// NEXTVAL can't be used in CHECK constraint
+	err, _ = db.ExecuteSQL("CREATE TABLE invalid_check(id INT CHECK (id > NEXTVAL(sq)));")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestViewAndMaterializedView(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE items(id INT, name VARCHAR(256), price INT);")
+	db.ExecuteSQL("CREATE TABLE stocks(item_id INT, qty INT);")
+	db.ExecuteSQL("INSERT INTO items(id, name, price) VALUES (1, 'apple', 50), (2, 'melon', 300), (3, 'grape', 150);")
+	db.ExecuteSQL("INSERT INTO stocks(item_id, qty) VALUES (1, 10), (2, 0), (3, 5);")
+
+	err, _ := db.ExecuteSQL("CREATE VIEW expensive AS SELECT name, price FROM items WHERE price > 100;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE VIEW expensive AS SELECT name FROM items;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE VIEW invalid AS SELECT no_such_col FROM items;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE VIEW item_stocks AS SELECT items.name, stocks.qty FROM items JOIN stocks ON items.id = stocks.item_id;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	_, results1 := db.ExecuteSQL("SELECT * FROM expensive;")
+	testingpkg.SimpleAssert(t, len(results1) == 2)
+	_, results2 := db.ExecuteSQL("SELECT name FROM expensive WHERE price < 200;")
+	testingpkg.SimpleAssert(t, len(results2) == 1 && results2[0][0].(string) == "grape")
+	_, results3 := db.ExecuteSQL("SELECT items.name FROM item_stocks WHERE stocks.qty = 0;")
+	testingpkg.SimpleAssert(t, len(results3) == 1 && results3[0][0].(string) == "melon")
+	// query of view is inlined when it is joined
+	db.ExecuteSQL("CREATE VIEW in_stock AS SELECT item_id, qty FROM stocks WHERE qty > 0;")
+	err, resultsJ1 := db.ExecuteSQL("SELECT items.name, in_stock.qty FROM items JOIN in_stock ON items.id = in_stock.item_id WHERE in_stock.qty < 10;")
+	testingpkg.SimpleAssert(t, err == nil && len(resultsJ1) == 1)
+	testingpkg.SimpleAssert(t, resultsJ1[0][0].(string) == "grape" && resultsJ1[0][1].(int32) == 5)
+	err, resultsJ2 := db.ExecuteSQL("SELECT in_stock.item_id, items.name FROM in_stock JOIN items ON in_stock.item_id = items.id;")
+	testingpkg.SimpleAssert(t, err == nil && len(resultsJ2) == 2)
+
+	// view reflects current data of the tables
+	db.ExecuteSQL("UPDATE items SET price = 500 WHERE id = 1;")
+	_, results4 := db.ExecuteSQL("SELECT * FROM expensive;")
+	testingpkg.SimpleAssert(t, len(results4) == 3)
+
+	err, _ = db.ExecuteSQL("CREATE MATERIALIZED VIEW cheap AS SELECT id, name FROM items WHERE price < 200;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results5 := db.ExecuteSQL("SELECT name FROM cheap;")
+	testingpkg.SimpleAssert(t, len(results5) == 1 && results5[0][0].(string) == "grape")
+	err, _ = db.ExecuteSQL("INSERT INTO cheap(id, name) VALUES (9, 'x');")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("DELETE FROM cheap WHERE id = 3;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// materialized view is updated only by REFRESH
+	db.ExecuteSQL("UPDATE items SET price = 100 WHERE id = 2;")
+	_, results6 := db.ExecuteSQL("SELECT name FROM cheap;")
+	testingpkg.SimpleAssert(t, len(results6) == 1)
+	err, _ = db.ExecuteSQL("REFRESH MATERIALIZED VIEW cheap;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results7 := db.ExecuteSQL("SELECT name FROM cheap WHERE id = 2;")
+	testingpkg.SimpleAssert(t, len(results7) == 1 && results7[0][0].(string) == "melon")
+	_, results8 := db.ExecuteSQL("SELECT name FROM cheap;")
+	testingpkg.SimpleAssert(t, len(results8) == 2)
+
+	// index can be created on materialized view. it is maintained by REFRESH
+	err, _ = db.ExecuteSQL("CREATE UNIQUE INDEX cheap_id ON cheap (id);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE INDEX cheap_id2 ON cheap (id);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE INDEX cheap_name ON cheap (name);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE INDEX expensive_name ON expensive (name);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE INDEX cheap_price ON cheap (price);")
+	testingpkg.SimpleAssert(t, err != nil)
+	db.ExecuteSQL("UPDATE items SET price = 120 WHERE id = 1;")
+	err, _ = db.ExecuteSQL("REFRESH MATERIALIZED VIEW cheap;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, resultsI1 := db.ExecuteSQL("SELECT name FROM cheap WHERE id = 1;")
+	testingpkg.SimpleAssert(t, len(resultsI1) == 1 && resultsI1[0][0].(string) == "apple")
+	_, resultsI2 := db.ExecuteSQL("SELECT id FROM cheap WHERE name = 'melon';")
+	testingpkg.SimpleAssert(t, len(resultsI2) == 1 && resultsI2[0][0].(int32) == 2)
+	db.ExecuteSQL("UPDATE items SET price = 500 WHERE id = 1;")
+	err, _ = db.ExecuteSQL("REFRESH MATERIALIZED VIEW cheap;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, resultsI3 := db.ExecuteSQL("SELECT name FROM cheap WHERE id = 1;")
+	testingpkg.SimpleAssert(t, len(resultsI3) == 0)
+	err, _ = db.ExecuteSQL("REFRESH MATERIALIZED VIEW expensive;")
+	testingpkg.SimpleAssert(t, err != nil)
+	db.Shutdown()
+
+	// definitions of views are persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	_, results9 := db2.ExecuteSQL("SELECT name FROM expensive WHERE price = 500;")
+	testingpkg.SimpleAssert(t, len(results9) == 1 && results9[0][0].(string) == "apple")
+	_, results10 := db2.ExecuteSQL("SELECT name FROM cheap;")
+	testingpkg.SimpleAssert(t, len(results10) == 2)
+	_, results11 := db2.ExecuteSQL("SELECT name FROM cheap WHERE id = 3;")
+	testingpkg.SimpleAssert(t, len(results11) == 1 && results11[0][0].(string) == "grape")
+	err, _ = db2.ExecuteSQL("REFRESH MATERIALIZED VIEW cheap;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestInsertSelect(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE items(id INT, name VARCHAR(256), price INT);")
+	db.ExecuteSQL("CREATE TABLE archive(id INT PRIMARY KEY, name VARCHAR(256), memo VARCHAR(256) DEFAULT 'archived');")
+	db.ExecuteSQL("INSERT INTO items(id, name, price) VALUES (1, 'apple', 50), (2, 'melon', 300), (3, 'grape', 150);")
+
+	err, _ := db.ExecuteSQL("INSERT INTO archive(id, name) SELECT id, name FROM items WHERE price > 100;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results1 := db.ExecuteSQL("SELECT name, memo FROM archive WHERE id = 3;")
+	testingpkg.SimpleAssert(t, len(results1) == 1 && results1[0][0].(string) == "grape" && results1[0][1].(string) == "archived")
+	_, results2 := db.ExecuteSQL("SELECT * FROM archive;")
+	testingpkg.SimpleAssert(t, len(results2) == 2)
+
+	// whole statement fails when a selected tuple violates constraint
+	err, _ = db.ExecuteSQL("INSERT INTO archive(id, name) SELECT id, name FROM items;")
+	testingpkg.SimpleAssert(t, err != nil)
+	_, results3 := db.ExecuteSQL("SELECT * FROM archive;")
+	testingpkg.SimpleAssert(t, len(results3) == 2)
+	err, _ = db.ExecuteSQL("INSERT INTO archive(id, name) SELECT name, id FROM items;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO archive(id) SELECT id, name FROM items;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// tuples inserted by the statement are not read by itself
+	err, _ = db.ExecuteSQL("INSERT INTO items SELECT * FROM items;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results4 := db.ExecuteSQL("SELECT * FROM items;")
+	testingpkg.SimpleAssert(t, len(results4) == 6)
+
+	// bulk insertion with many tuples
+	db.ExecuteSQL("CREATE TABLE numbers(val INT UNIQUE);")
+	sqlStr := "INSERT INTO numbers(val) VALUES "
+	rowNum := common.BulkInsertBatchSize + 10
+	for ii := 0; ii < rowNum; ii++ {
+		if ii > 0 {
+			sqlStr += ", "
+		}
+		sqlStr += "(" + strconv.Itoa(ii) + ")"
+	}
+	err, _ = db.ExecuteSQL(sqlStr + ";")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results5 := db.ExecuteSQL("SELECT * FROM numbers;")
+	testingpkg.SimpleAssert(t, len(results5) == rowNum)
+	_, results6 := db.ExecuteSQL("SELECT * FROM numbers WHERE val = " + strconv.Itoa(rowNum-1) + ";")
+	testingpkg.SimpleAssert(t, len(results6) == 1)
+	// duplication in inserted tuples is also detected
+	db.ExecuteSQL("CREATE TABLE numbers2(val INT UNIQUE);")
+	err, _ = db.ExecuteSQL("INSERT INTO numbers2 SELECT * FROM numbers;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO numbers2 SELECT * FROM numbers WHERE val > 100;")
+	testingpkg.SimpleAssert(t, err != nil)
+	db.ExecuteSQL("CREATE TABLE numbers3(val INT UNIQUE);")
+	err, _ = db.ExecuteSQL(strings.Replace(sqlStr, "numbers", "numbers3", 1) + ", (0);")
+	testingpkg.SimpleAssert(t, err != nil)
+	_, results7 := db.ExecuteSQL("SELECT * FROM numbers3;")
+	testingpkg.SimpleAssert(t, len(results7) == 0)
+
+	// tuples of the child are inserted batch by batch
+	db.ExecuteSQL("CREATE TABLE numbers4(val INT UNIQUE);")
+	err, affected := db.ExecuteSQLRetAffectedRows("INSERT INTO numbers4 SELECT * FROM numbers;")
+	testingpkg.SimpleAssert(t, err == nil && affected == int64(rowNum))
+	err, results8 := db.ExecuteSQL("INSERT INTO numbers3 SELECT * FROM numbers RETURNING val;")
+	testingpkg.SimpleAssert(t, err == nil && len(results8) == rowNum)
+	testingpkg.SimpleAssert(t, results8[rowNum-1][0].(int32) == int32(rowNum-1))
+	// violation in later batch rolls back tuples of former batches
+	db.ExecuteSQL("CREATE TABLE numbers5(val INT UNIQUE);")
+	db.ExecuteSQL("INSERT INTO numbers5(val) VALUES (" + strconv.Itoa(rowNum-1) + ");")
+	err, _ = db.ExecuteSQL("INSERT INTO numbers5 SELECT * FROM numbers;")
+	testingpkg.SimpleAssert(t, err != nil)
+	_, results9 := db.ExecuteSQL("SELECT * FROM numbers5;")
+	testingpkg.SimpleAssert(t, len(results9) == 1)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestUpsert(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE stocks(id INT PRIMARY KEY, name VARCHAR(256) UNIQUE, qty INT);")
+	db.ExecuteSQL("INSERT INTO stocks(id, name, qty) VALUES (1, 'apple', 10), (2, 'melon', 20);")
+
+	err, _ := db.ExecuteSQL("INSERT INTO stocks(id, name, qty) VALUES (1, 'apple', 15), (3, 'grape', 30) ON DUPLICATE KEY UPDATE qty = VALUES(qty);")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results1 := db.ExecuteSQL("SELECT qty FROM stocks WHERE id = 1;")
+	testingpkg.SimpleAssert(t, len(results1) == 1 && results1[0][0].(int32) == 15)
+	_, results2 := db.ExecuteSQL("SELECT qty FROM stocks WHERE id = 3;")
+	testingpkg.SimpleAssert(t, len(results2) == 1 && results2[0][0].(int32) == 30)
+
+	// conflict on any unique column is detected. tuple inserted by same statement can be updated
+	err, _ = db.ExecuteSQL("INSERT INTO stocks(id, name, qty) VALUES (9, 'melon', 0), (4, 'peach', 40), (4, 'peach', 45) ON DUPLICATE KEY UPDATE qty = VALUES(qty);")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results3 := db.ExecuteSQL("SELECT qty FROM stocks WHERE id = 4;")
+	testingpkg.SimpleAssert(t, len(results3) == 1 && results3[0][0].(int32) == 45)
+	_, results4 := db.ExecuteSQL("SELECT qty FROM stocks WHERE id = 2;")
+	testingpkg.SimpleAssert(t, results4[0][0].(int32) == 0)
+	_, results5 := db.ExecuteSQL("SELECT * FROM stocks;")
+	testingpkg.SimpleAssert(t, len(results5) == 4)
+	err, _ = db.ExecuteSQL("INSERT INTO stocks(id, name, qty) VALUES (3, 'x', 0) ON DUPLICATE KEY UPDATE qty = 35, name = 'muscat';")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results9 := db.ExecuteSQL("SELECT name, qty FROM stocks WHERE id = 3;")
+	testingpkg.SimpleAssert(t, results9[0][0].(string) == "muscat" && results9[0][1].(int32) == 35)
+	// update which causes other conflict fails
+	err, _ = db.ExecuteSQL("INSERT INTO stocks(id, name, qty) VALUES (1, 'x', 0) ON DUPLICATE KEY UPDATE name = 'muscat';")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO stocks(id, name, qty) VALUES (1, 'x', 0) ON DUPLICATE KEY UPDATE no_such_col = 1;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// conflicting tuples are skipped
+	err, _ = db.ExecuteSQL("INSERT INTO stocks(id, name, qty) VALUES (1, 'apple', 100), (5, 'lemon', 50) ON CONFLICT (id) DO NOTHING;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results6 := db.ExecuteSQL("SELECT qty FROM stocks WHERE id = 1;")
+	testingpkg.SimpleAssert(t, results6[0][0].(int32) == 15)
+	_, results7 := db.ExecuteSQL("SELECT * FROM stocks;")
+	testingpkg.SimpleAssert(t, len(results7) == 5)
+	err, _ = db.ExecuteSQL("INSERT IGNORE INTO stocks SELECT * FROM stocks;")
+	testingpkg.SimpleAssert(t, err == nil)
+	_, results8 := db.ExecuteSQL("SELECT * FROM stocks;")
+	testingpkg.SimpleAssert(t, len(results8) == 5)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestReturningAndAffectedRows(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE items(id INT AUTO_INCREMENT PRIMARY KEY, name VARCHAR(256), price INT);")
+
+	err, results1 := db.ExecuteSQL("INSERT INTO items(name, price) VALUES ('pen', 100), ('book', 500) RETURNING id, name;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results1) == 2)
+	testingpkg.SimpleAssert(t, results1[0][0].(int32) == 1 && results1[0][1].(string) == "pen")
+	testingpkg.SimpleAssert(t, results1[1][0].(int32) == 2 && results1[1][1].(string) == "book")
+
+	err, results2 := db.ExecuteSQL("UPDATE items SET price = 200 WHERE name = 'pen' RETURNING name, price * 2 AS dbl;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results2) == 1)
+	testingpkg.SimpleAssert(t, results2[0][0].(string) == "pen" && results2[0][1].(int32) == 400)
+
+	err, results3 := db.ExecuteSQL("DELETE FROM items WHERE price > 300 RETURNING *;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results3) == 1 && len(results3[0]) == 3)
+	testingpkg.SimpleAssert(t, results3[0][0].(int32) == 2 && results3[0][1].(string) == "book" && results3[0][2].(int32) == 500)
+
+	err, _ = db.ExecuteSQL("UPDATE items SET price = 1 RETURNING no_such_col;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// plain DML returns count of affected rows
+	err, affected1 := db.ExecuteSQLRetAffectedRows("INSERT INTO items(name, price) VALUES ('a', 1), ('b', 2), ('c', 3);")
+	testingpkg.SimpleAssert(t, err == nil && affected1 == 3)
+	err, affected2 := db.ExecuteSQLRetAffectedRows("UPDATE items SET price = 10 WHERE price < 5;")
+	testingpkg.SimpleAssert(t, err == nil && affected2 == 3)
+	err, affected3 := db.ExecuteSQLRetAffectedRows("DELETE FROM items WHERE price = 99;")
+	testingpkg.SimpleAssert(t, err == nil && affected3 == 0)
+	err, affected4 := db.ExecuteSQLRetAffectedRows("DELETE FROM items WHERE price = 10;")
+	testingpkg.SimpleAssert(t, err == nil && affected4 == 3)
+	err, affected5 := db.ExecuteSQLRetAffectedRows("SELECT * FROM items;")
+	testingpkg.SimpleAssert(t, err == nil && affected5 == 0)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestCommonTableExpression(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE categories(id INT PRIMARY KEY, name VARCHAR(256), parent_id INT);")
+	db.ExecuteSQL("INSERT INTO categories(id, name, parent_id) VALUES (1, 'food', 0), (2, 'fruit', 1), (3, 'apple', 2), (4, 'melon', 2), (5, 'drink', 0), (6, 'fuji', 3);")
+	db.ExecuteSQL("CREATE TABLE stocks(category_id INT, qty INT);")
+	db.ExecuteSQL("INSERT INTO stocks(category_id, qty) VALUES (3, 10), (4, 0), (6, 5);")
+
+	// non-recursive CTE is inlined
+	err, results1 := db.ExecuteSQL("WITH fruits AS (SELECT id, name FROM categories WHERE parent_id = 2) SELECT name FROM fruits WHERE id > 3;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results1) == 1 && results1[0][0].(string) == "melon")
+	// CTE can reference CTEs defined before it and it can be joined
+	err, results2 := db.ExecuteSQL("WITH fruits AS (SELECT id, name FROM categories WHERE parent_id = 2), in_stock(cid, q) AS (SELECT category_id, qty FROM stocks WHERE qty > 0) " +
+		"SELECT fruits.name, in_stock.q FROM fruits JOIN in_stock ON fruits.id = in_stock.cid;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results2) == 1 && results2[0][0].(string) == "apple" && results2[0][1].(int32) == 10)
+	// CTE referenced twice is materialized
+	err, results3 := db.ExecuteSQL("WITH c AS (SELECT id, parent_id FROM categories WHERE parent_id > 0) SELECT * FROM c JOIN c ON c.id = c.parent_id;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results3) == 3)
+
+	// all descendants of food
+	err, results4 := db.ExecuteSQL("WITH RECURSIVE sub(id, name) AS (SELECT id, name FROM categories WHERE id = 1 " +
+		"UNION ALL SELECT categories.id, categories.name FROM categories JOIN sub ON categories.parent_id = sub.id) SELECT name FROM sub;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results4) == 5)
+	names := make(map[string]bool)
+	for _, row := range results4 {
+		names[row[0].(string)] = true
+	}
+	testingpkg.SimpleAssert(t, names["food"] && names["fruit"] && names["apple"] && names["melon"] && names["fuji"] && !names["drink"])
+	err, results5 := db.ExecuteSQL("WITH RECURSIVE sub(id, name) AS (SELECT id, name FROM categories WHERE id = 2 " +
+		"UNION SELECT categories.id, categories.name FROM categories JOIN sub ON categories.parent_id = sub.id) SELECT name FROM sub WHERE id > 3;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results5) == 2)
+
+	// cycle is stopped by UNION and exceeds the limit with UNION ALL
+	db.ExecuteSQL("UPDATE categories SET parent_id = 6 WHERE id = 1;")
+	err, results6 := db.ExecuteSQL("WITH RECURSIVE sub(id) AS (SELECT id FROM categories WHERE id = 1 " +
+		"UNION SELECT categories.id FROM categories JOIN sub ON categories.parent_id = sub.id) SELECT * FROM sub;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results6) == 5)
+	err, _ = db.ExecuteSQL("WITH RECURSIVE sub(id) AS (SELECT id FROM categories WHERE id = 1 " +
+		"UNION ALL SELECT categories.id FROM categories JOIN sub ON categories.parent_id = sub.id) SELECT * FROM sub;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	err, _ = db.ExecuteSQL("WITH RECURSIVE sub(id) AS (SELECT id FROM categories WHERE id = 1 " +
+		"UNION ALL SELECT categories.name FROM categories JOIN sub ON categories.parent_id = sub.id) SELECT * FROM sub;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("WITH c(a, b) AS (SELECT id FROM categories) SELECT * FROM c;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("WITH c AS (SELECT id FROM categories) SELECT no_such_col FROM c;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestSetOperations(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE a(id INT, name VARCHAR(256));")
+	db.ExecuteSQL("INSERT INTO a(id, name) VALUES (1, 'x'), (2, 'y'), (2, 'y'), (3, 'z');")
+	db.ExecuteSQL("CREATE TABLE b(id INT, name VARCHAR(256));")
+	db.ExecuteSQL("INSERT INTO b(id, name) VALUES (2, 'y'), (3, 'z'), (3, 'z'), (4, 'w');")
+
+	err, results1 := db.ExecuteSQL("SELECT id, name FROM a UNION SELECT id, name FROM b;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results1) == 4)
+	err, results2 := db.ExecuteSQL("SELECT id, name FROM a UNION ALL SELECT id, name FROM b;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results2) == 8)
+	err, results3 := db.ExecuteSQL("SELECT id FROM a INTERSECT SELECT id FROM b;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results3) == 2)
+	err, results4 := db.ExecuteSQL("SELECT id FROM b INTERSECT ALL SELECT id FROM b WHERE id > 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results4) == 3)
+	err, results5 := db.ExecuteSQL("SELECT id FROM a EXCEPT SELECT id FROM b;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results5) == 1 && results5[0][0].(int32) == 1)
+	err, results6 := db.ExecuteSQL("SELECT id FROM a EXCEPT ALL SELECT id FROM b;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results6) == 2)
+
+	// ORDER BY and LIMIT are applied to combined result
+	err, results7 := db.ExecuteSQL("SELECT id FROM a UNION SELECT id FROM b ORDER BY id DESC LIMIT 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results7) == 2 && results7[0][0].(int32) == 4 && results7[1][0].(int32) == 3)
+	// INTERSECT binds tighter than UNION
+	err, results8 := db.ExecuteSQL("SELECT id FROM a UNION SELECT id FROM b INTERSECT SELECT id FROM a WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results8) == 3)
+	// CTE can be referenced from each SELECT statement
+	err, results9 := db.ExecuteSQL("WITH c AS (SELECT id FROM a WHERE id < 3) SELECT id FROM c EXCEPT SELECT id FROM b;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results9) == 1 && results9[0][0].(int32) == 1)
+
+	err, _ = db.ExecuteSQL("SELECT id, name FROM a UNION SELECT id FROM b;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT id FROM a UNION SELECT name FROM b;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestWindowFunctions(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE scores(name VARCHAR(256), team VARCHAR(256), score INT);")
+	db.ExecuteSQL("INSERT INTO scores(name, team, score) VALUES ('a', 'red', 10), ('b', 'red', 30), ('c', 'red', 30), ('d', 'blue', 20), ('e', 'blue', 5);")
+
+	// rows are returned in order of the window
+	err, results1 := db.ExecuteSQL("SELECT name, ROW_NUMBER() OVER (ORDER BY score DESC) AS rn, RANK() OVER (ORDER BY score DESC) AS rnk, " +
+		"DENSE_RANK() OVER (ORDER BY score DESC) AS drnk FROM scores;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results1) == 5)
+	expected1 := [][]interface{}{{"b", int32(1), int32(1), int32(1)}, {"c", int32(2), int32(1), int32(1)}, {"d", int32(3), int32(3), int32(2)},
+		{"a", int32(4), int32(4), int32(3)}, {"e", int32(5), int32(5), int32(4)}}
+	for ii, row := range results1 {
+		for jj := range row {
+			testingpkg.SimpleAssert(t, row[jj] == expected1[ii][jj])
+		}
+	}
+
+	err, results2 := db.ExecuteSQL("SELECT name, RANK() OVER (PARTITION BY team ORDER BY score DESC) AS rnk, " +
+		"FIRST_VALUE(name) OVER (PARTITION BY team ORDER BY score DESC) AS top, COUNT(*) OVER (PARTITION BY team) AS cnt FROM scores;")
+	testingpkg.SimpleAssert(t, err == nil)
+	expected2 := map[string][]interface{}{"a": {int32(3), "b", int32(3)}, "b": {int32(1), "b", int32(3)}, "c": {int32(1), "b", int32(3)},
+		"d": {int32(1), "d", int32(2)}, "e": {int32(2), "d", int32(2)}}
+	testingpkg.SimpleAssert(t, len(results2) == 5)
+	for _, row := range results2 {
+		for jj, val := range expected2[row[0].(string)] {
+			testingpkg.SimpleAssert(t, row[jj+1] == val)
+		}
+	}
+
+	// running aggregates. default frame includes peers and ROWS frame does not
+	err, results3 := db.ExecuteSQL("SELECT name, LAG(score) OVER (ORDER BY score) AS prev, LEAD(score, 1, 0) OVER (ORDER BY score) AS next, " +
+		"SUM(score) OVER (ORDER BY score) AS running, SUM(score) OVER (ORDER BY score ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS running_rows, " +
+		"SUM(score) OVER (ORDER BY score ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING) AS moving FROM scores;")
+	testingpkg.SimpleAssert(t, err == nil)
+	expected3 := [][]interface{}{{"e", nil, int32(10), int32(5), int32(5), int32(15)}, {"a", int32(5), int32(20), int32(15), int32(15), int32(35)},
+		{"d", int32(10), int32(30), int32(35), int32(35), int32(60)}, {"b", int32(20), int32(30), int32(95), int32(65), int32(80)},
+		{"c", int32(30), int32(0), int32(95), int32(95), int32(60)}}
+	testingpkg.SimpleAssert(t, len(results3) == 5)
+	for ii, row := range results3 {
+		for jj := range row {
+			testingpkg.SimpleAssert(t, row[jj] == expected3[ii][jj])
+		}
+	}
+
+	err, results4 := db.ExecuteSQL("SELECT team, AVG(score) OVER (PARTITION BY team) AS avg_score FROM scores WHERE name != 'c';")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results4) == 4)
+	for _, row := range results4 {
+		if row[0].(string) == "red" {
+			testingpkg.SimpleAssert(t, row[1].(float32) == 20)
+		} else {
+			testingpkg.SimpleAssert(t, row[1].(float32) == 12.5)
+		}
+	}
+
+	// ORDER BY and LIMIT are applied to the result
+	err, results5 := db.ExecuteSQL("SELECT name, RANK() OVER (ORDER BY score DESC) AS rnk FROM scores ORDER BY name LIMIT 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results5) == 2)
+	testingpkg.SimpleAssert(t, results5[0][0].(string) == "a" && results5[0][1].(int32) == 4)
+	testingpkg.SimpleAssert(t, results5[1][0].(string) == "b" && results5[1][1].(int32) == 1)
+
+	err, _ = db.ExecuteSQL("SELECT name, NTILE(2) OVER (ORDER BY score) FROM scores;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT name, SUM(name) OVER (ORDER BY score) FROM scores;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT name, ROW_NUMBER() OVER (ORDER BY no_such_col) FROM scores;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestScalarFunctions(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	db.ExecuteSQL("CREATE TABLE items(id INT, name VARCHAR(256), nickname VARCHAR(256), price FLOAT);")
+	db.ExecuteSQL("INSERT INTO items(id, name, nickname, price) VALUES (1, 'apple', 'red', 1.25), (2, ' Banana ', NULL, 2.5), (3, 'cherry', 'cherry', 3.75);")
+
+	err, results1 := db.ExecuteSQL("SELECT id, UPPER(name) AS u, LENGTH(name) AS len, SUBSTR(name, 2, 3) AS sub, CONCAT(name, '-', id) AS c, " +
+		"TRIM(name) AS tr, REPLACE(name, 'a', 'o') AS rep FROM items ORDER BY id;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results1) == 3)
+	expected1 := [][]interface{}{{int32(1), "APPLE", int32(5), "ppl", "apple-1", "apple", "opple"},
+		{int32(2), " BANANA ", int32(8), "Ban", " Banana -2", "Banana", " Bonono "},
+		{int32(3), "CHERRY", int32(6), "her", "cherry-3", "cherry", "cherry"}}
+	for ii, row := range results1 {
+		for jj := range row {
+			testingpkg.SimpleAssert(t, row[jj] == expected1[ii][jj])
+		}
+	}
+
+	err, results2 := db.ExecuteSQL("SELECT id, ABS(0 - price) AS a, ROUND(0 - price) AS r, FLOOR(price) AS f, CEIL(0 - price) AS c, MOD(id, 2) AS m, id * 10 + 1 AS calc " +
+		"FROM items ORDER BY id;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results2) == 3)
+	expected2 := [][]interface{}{{int32(1), float32(1.25), float32(-1), float32(1), float32(-1), int32(1), int32(11)},
+		{int32(2), float32(2.5), float32(-3), float32(2), float32(-2), int32(0), int32(21)},
+		{int32(3), float32(3.75), float32(-4), float32(3), float32(-3), int32(1), int32(31)}}
+	for ii, row := range results2 {
+		for jj := range row {
+			testingpkg.SimpleAssert(t, row[jj] == expected2[ii][jj])
+		}
+	}
+
+	err, results3 := db.ExecuteSQL("SELECT id, CASE WHEN price > 3 THEN 'high' WHEN price > 2 THEN 'middle' ELSE 'low' END AS grade, " +
+		"COALESCE(nickname, name) AS nick, NULLIF(nickname, 'cherry') AS nn, CAST(id AS CHAR) AS s, CAST(price AS SIGNED) AS i FROM items ORDER BY id;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results3) == 3)
+	expected3 := [][]interface{}{{int32(1), "low", "red", "red", "1", int32(1)},
+		{int32(2), "middle", " Banana ", nil, "2", int32(3)},
+		{int32(3), "high", "cherry", nil, "3", int32(4)}}
+	for ii, row := range results3 {
+		for jj := range row {
+			testingpkg.SimpleAssert(t, row[jj] == expected3[ii][jj])
+		}
+	}
+
+	// functions in WHERE clause of SELECT, UPDATE and DELETE
+	err, results4 := db.ExecuteSQL("SELECT id FROM items WHERE UPPER(TRIM(name)) = 'BANANA';")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results4) == 1 && results4[0][0].(int32) == 2)
+	err, results5 := db.ExecuteSQL("SELECT id FROM items WHERE nickname IS NULL;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results5) == 1 && results5[0][0].(int32) == 2)
+	err, _ = db.ExecuteSQL("UPDATE items SET nickname = 'long' WHERE LENGTH(name) > 5;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("DELETE FROM items WHERE MOD(id, 2) = 0;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results6 := db.ExecuteSQL("SELECT id, nickname FROM items;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results6) == 2)
+	for _, row := range results6 {
+		if row[0].(int32) == 1 {
+			testingpkg.SimpleAssert(t, row[1].(string) == "red")
+		} else {
+			testingpkg.SimpleAssert(t, row[0].(int32) == 3 && row[1].(string) == "long")
+		}
+	}
+
+	err, _ = db.ExecuteSQL("SELECT NO_SUCH_FUNC(name) FROM items;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT UPPER(id) FROM items;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT id FROM items WHERE LENGTH(name);")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+// sum of squares of values. NULL is ignored
+type sumOfSquares struct {
+	sum float32
+}
+
+func (a *sumOfSquares) Init() { a.sum = 0 }
+
+func (a *sumOfSquares) Step(args ...types.Value) {
+	if !args[0].IsNull() {
+		a.sum += args[0].ToFloat() * args[0].ToFloat()
+	}
+}
+
+func (a *sumOfSquares) Merge(other expression.AggregateFunction) { a.sum += other.(*sumOfSquares).sum }
+
+func (a *sumOfSquares) Final() types.Value { return types.NewFloat(a.sum) }
+
+func TestUserDefinedFunctions(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err := db.RegisterFunction("score_band", []types.TypeID{types.Integer}, types.Varchar, func(args ...types.Value) types.Value {
+		if args[0].IsNull() {
+			return types.NewNull()
+		}
+		if args[0].ToInteger() >= 20 {
+			return types.NewVarchar("high")
+		}
+		return types.NewVarchar("low")
+	})
+	testingpkg.SimpleAssert(t, err == nil)
+	err = db.RegisterFunction("double_it", []types.TypeID{types.Float}, types.Float, func(args ...types.Value) types.Value {
+		return types.NewFloat(args[0].ToFloat() * 2)
+	})
+	testingpkg.SimpleAssert(t, err == nil)
+	err = db.RegisterAggregateFunction("sum_sq", []types.TypeID{types.Float}, types.Float, func() expression.AggregateFunction { return new(sumOfSquares) })
+	testingpkg.SimpleAssert(t, err == nil)
+	err = db.RegisterFunction("sum_sq", []types.TypeID{types.Float}, types.Float, func(args ...types.Value) types.Value { return args[0] })
+	testingpkg.SimpleAssert(t, err != nil)
+
+	db.ExecuteSQL("CREATE TABLE scores(name VARCHAR(256), team VARCHAR(256), score INT);")
+	db.ExecuteSQL("INSERT INTO scores(name, team, score) VALUES ('a', 'red', 10), ('b', 'red', 30), ('c', 'red', 20), ('d', 'blue', 5), ('e', 'blue', 1);")
+
+	// scalar UDF in SELECT and WHERE
+	err, results1 := db.ExecuteSQL("SELECT name, score_band(score) AS band, double_it(score) AS d FROM scores WHERE double_it(score) > 15 ORDER BY name;")
+	testingpkg.SimpleAssert(t, err == nil)
+	expected1 := [][]interface{}{{"a", "low", float32(20)}, {"b", "high", float32(60)}, {"c", "high", float32(40)}}
+	testingpkg.SimpleAssert(t, len(results1) == len(expected1))
+	for ii, row := range results1 {
+		for jj := range row {
+			testingpkg.SimpleAssert(t, row[jj] == expected1[ii][jj])
+		}
+	}
+
+	// built-in and user defined aggregate functions with GROUP BY and HAVING
+	err, results2 := db.ExecuteSQL("SELECT team, COUNT(*) AS cnt, SUM(score), MAX(score) AS mx, sum_sq(score) AS sq FROM scores GROUP BY team ORDER BY team;")
+	testingpkg.SimpleAssert(t, err == nil)
+	expected2 := [][]interface{}{{"blue", int32(2), int32(6), int32(5), float32(26)}, {"red", int32(3), int32(60), int32(30), float32(1400)}}
+	testingpkg.SimpleAssert(t, len(results2) == len(expected2))
+	for ii, row := range results2 {
+		for jj := range row {
+			testingpkg.SimpleAssert(t, row[jj] == expected2[ii][jj])
+		}
+	}
+
+	// UDF in GROUP BY and HAVING
+	err, results3 := db.ExecuteSQL("SELECT score_band(score) AS band, MIN(score) AS mn FROM scores GROUP BY score_band(score) HAVING double_it(sum_sq(score)) > 1000;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results3) == 1)
+	testingpkg.SimpleAssert(t, results3[0][0].(string) == "high" && results3[0][1].(int32) == 20)
+
+	// aggregation without GROUP BY returns a row for empty input
+	err, results4 := db.ExecuteSQL("SELECT COUNT(*), SUM(score), sum_sq(score) FROM scores WHERE score > 100;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results4) == 1)
+	testingpkg.SimpleAssert(t, results4[0][0].(int32) == 0 && results4[0][1] == nil && results4[0][2].(float32) == 0)
+
+	err, _ = db.ExecuteSQL("SELECT name, COUNT(*) FROM scores GROUP BY team;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT score_band(name) FROM scores;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT double_it(score, 1) FROM scores;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestIntegerTypes(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE events(id BIGINT PRIMARY KEY, at BIGINT, kind TINYINT, cnt SMALLINT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (1, 1700000000000, 1, 1000), (2, 1700000000500, 2, 3), (3, 1600000000000, 1, 20000);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (1, 0, 0, 0);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (4, 0, 128, 0);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (4, 0, 0, 40000);")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	err, results1 := db.ExecuteSQL("SELECT id, at, kind, cnt FROM events WHERE at > 1650000000000 ORDER BY at;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results1) == 2)
+	testingpkg.SimpleAssert(t, results1[0][0].(int64) == 1 && results1[0][1].(int64) == 1700000000000)
+	testingpkg.SimpleAssert(t, results1[0][2].(int8) == 1 && results1[0][3].(int16) == 1000)
+	testingpkg.SimpleAssert(t, results1[1][1].(int64) == 1700000000500 && results1[1][3].(int16) == 3)
+
+	err, results2 := db.ExecuteSQL("SELECT kind, SUM(at), MAX(cnt) FROM events GROUP BY kind ORDER BY kind;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results2) == 2)
+	testingpkg.SimpleAssert(t, results2[0][0].(int8) == 1 && results2[0][1].(int64) == 3300000000000 && results2[0][2].(int16) == 20000)
+
+	err, results3 := db.ExecuteSQL("SELECT id, at - 1700000000000 AS diff FROM events WHERE kind = 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results3) == 1 && results3[0][1].(int64) == 500)
+
+	// negative values at the limits of each type
+	err, _ = db.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (-9223372036854775808, 9223372036854775807, -128, -32768), (-5, 0 - 1700000000000, -1, -(20));")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (-9223372036854775809, 0, 0, 0);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (5, 0, -129, 0);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (5, 0, 0, -32769);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, results5 := db.ExecuteSQL("SELECT id, at, kind, cnt FROM events WHERE id < 0 ORDER BY id;")
+	testingpkg.SimpleAssert(t, err == nil && len(results5) == 2)
+	testingpkg.SimpleAssert(t, results5[0][0].(int64) == math.MinInt64 && results5[0][1].(int64) == math.MaxInt64)
+	testingpkg.SimpleAssert(t, results5[0][2].(int8) == math.MinInt8 && results5[0][3].(int16) == math.MinInt16)
+	testingpkg.SimpleAssert(t, results5[1][0].(int64) == -5 && results5[1][1].(int64) == -1700000000000)
+	testingpkg.SimpleAssert(t, results5[1][2].(int8) == -1 && results5[1][3].(int16) == -20)
+	err, results6 := db.ExecuteSQL("SELECT at FROM events WHERE id = -9223372036854775808;")
+	testingpkg.SimpleAssert(t, err == nil && len(results6) == 1 && results6[0][0].(int64) == math.MaxInt64)
+	// ON DUPLICATE KEY UPDATE with negative value
+	err, _ = db.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (-5, 0, 0, 0) ON DUPLICATE KEY UPDATE at = -9223372036854775808;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results7 := db.ExecuteSQL("SELECT at FROM events WHERE id = -5;")
+	testingpkg.SimpleAssert(t, err == nil && len(results7) == 1 && results7[0][0].(int64) == math.MinInt64)
+	err, _ = db.ExecuteSQL("UPDATE events SET cnt = -32768 WHERE id = -5;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results8 := db.ExecuteSQL("SELECT cnt FROM events WHERE id = -5;")
+	testingpkg.SimpleAssert(t, err == nil && len(results8) == 1 && results8[0][0].(int16) == math.MinInt16)
+	err, _ = db.ExecuteSQL("DELETE FROM events WHERE id < 0;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	db.ExecuteSQL("UPDATE events SET at = 5000000000 WHERE id = 3;")
+	db.ExecuteSQL("DELETE FROM events WHERE id = 2;")
+	db.Shutdown()
+
+	// relaunch and check that column types are persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, results4 := db2.ExecuteSQL("SELECT id, at FROM events ORDER BY id;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results4) == 2)
+	testingpkg.SimpleAssert(t, results4[1][0].(int64) == 3 && results4[1][1].(int64) == 5000000000)
+	err, _ = db2.ExecuteSQL("INSERT INTO events(id, at, kind, cnt) VALUES (3, 0, 0, 0);")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestDecimalType(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE payments(code DECIMAL(8, 3) PRIMARY KEY, amount DECIMAL(12, 2), rate FLOAT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO payments(code, amount, rate) VALUES (1.001, 0.1, 0.5), (1.002, 0.2, 1.5), (2.5, 1234567890.12, 2);")
+	testingpkg.SimpleAssert(t, err == nil)
+	// duplicated key of hash index
+	err, _ = db.ExecuteSQL("INSERT INTO payments(code, amount, rate) VALUES (1.0010, 1, 1);")
+	testingpkg.SimpleAssert(t, err != nil)
+	// precision overflow
+	err, _ = db.ExecuteSQL("INSERT INTO payments(code, amount, rate) VALUES (3, 12345678901.5, 1);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE bad(d DECIMAL(40, 2));")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// values are rounded to scale of the column
+	err, _ = db.ExecuteSQL("INSERT INTO payments(code, amount, rate) VALUES (3, 0.005, 1);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results1 := db.ExecuteSQL("SELECT code, amount FROM payments WHERE code = 3;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results1) == 1 && results1[0][0].(string) == "3.000" && results1[0][1].(string) == "0.01")
+
+	// 0.1 + 0.2 is 0.3 exactly
+	err, results2 := db.ExecuteSQL("SELECT SUM(amount), AVG(amount), COUNT(amount) FROM payments WHERE code < 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results2) == 1 && results2[0][0].(string) == "0.30" && results2[0][1].(string) == "0.150000")
+	err, results3 := db.ExecuteSQL("SELECT code, amount * 2 AS a, amount + code AS b, amount * rate AS c, ROUND(amount, 1) AS r FROM payments WHERE amount = 0.2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results3) == 1)
+	testingpkg.SimpleAssert(t, results3[0][0].(string) == "1.002" && results3[0][1].(string) == "0.40" && results3[0][2].(string) == "1.202")
+	testingpkg.SimpleAssert(t, results3[0][3].(float32) == 0.3 && results3[0][4].(string) == "0.2")
+	err, results4 := db.ExecuteSQL("SELECT CAST(amount AS DECIMAL(5, 1)) AS c, CAST('12.345' AS DECIMAL(6, 2)) AS s FROM payments WHERE code = 2.5;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results4) == 1 && results4[0][0] == nil && results4[0][1].(string) == "12.35")
+
+	err, _ = db.ExecuteSQL("UPDATE payments SET amount = 0.214 WHERE code = 1.002;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	// negative values. rounding is half away from zero
+	err, _ = db.ExecuteSQL("INSERT INTO payments(code, amount, rate) VALUES (-1.25, -0.125, -0.5), (-2, -9999999999.99, 2);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO payments(code, amount, rate) VALUES (-3, -10000000000, 1);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, results6 := db.ExecuteSQL("SELECT code, amount, rate FROM payments WHERE code = -1.250;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results6) == 1 && results6[0][0].(string) == "-1.250" && results6[0][1].(string) == "-0.13" && results6[0][2].(float32) == -0.5)
+	err, results12 := db.ExecuteSQL("SELECT code FROM payments ORDER BY code;")
+	testingpkg.SimpleAssert(t, err == nil && len(results12) == 6)
+	testingpkg.SimpleAssert(t, results12[0][0].(string) == "-2.000" && results12[1][0].(string) == "-1.250" && results12[2][0].(string) == "1.001" && results12[5][0].(string) == "3.000")
+	err, results13 := db.ExecuteSQL("SELECT code, amount FROM payments WHERE rate < 10 ORDER BY amount DESC LIMIT 2 OFFSET 1;")
+	testingpkg.SimpleAssert(t, err == nil && len(results13) == 2)
+	testingpkg.SimpleAssert(t, results13[0][1].(string) == "0.21" && results13[1][1].(string) == "0.10")
+	err, results7 := db.ExecuteSQL("SELECT code FROM payments WHERE code < -1.5;")
+	testingpkg.SimpleAssert(t, err == nil && len(results7) == 1 && results7[0][0].(string) == "-2.000")
+	err, results8 := db.ExecuteSQL("SELECT SUM(amount), AVG(amount), MIN(amount), MAX(code) FROM payments WHERE code < 0;")
+	testingpkg.SimpleAssert(t, err == nil && len(results8) == 1)
+	testingpkg.SimpleAssert(t, results8[0][0].(string) == "-10000000000.12" && results8[0][1].(string) == "-5000000000.060000")
+	testingpkg.SimpleAssert(t, results8[0][2].(string) == "-9999999999.99" && results8[0][3].(string) == "-1.250")
+	err, results9 := db.ExecuteSQL("SELECT amount - 1 AS a, -amount AS b, amount * rate AS c FROM payments WHERE code = -1.25;")
+	testingpkg.SimpleAssert(t, err == nil && len(results9) == 1)
+	testingpkg.SimpleAssert(t, results9[0][0].(string) == "-1.13" && results9[0][1].(string) == "0.13" && results9[0][2].(float32) == 0.065)
+	err, _ = db.ExecuteSQL("UPDATE payments SET amount = -0.005 WHERE code = -1.25;")
+	testingpkg.SimpleAssert(t, err == nil)
+	db.Shutdown()
+
+	// relaunch and check that precision and scale are persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, results5 := db2.ExecuteSQL("SELECT code, amount FROM payments WHERE code = 1.002;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(results5) == 1 && results5[0][1].(string) == "0.21")
+	err, _ = db2.ExecuteSQL("INSERT INTO payments(code, amount, rate) VALUES (2.5, 0, 0);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db2.ExecuteSQL("INSERT INTO payments(code, amount, rate) VALUES (4, 12345678901.5, 0);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, results10 := db2.ExecuteSQL("SELECT amount FROM payments WHERE code = -1.25;")
+	testingpkg.SimpleAssert(t, err == nil && len(results10) == 1 && results10[0][0].(string) == "-0.01")
+
+	// precision and scale are kept after the table is rewritten by ALTER TABLE and CREATE INDEX
+	err, _ = db2.ExecuteSQL("ALTER TABLE payments RENAME COLUMN amount TO amt;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db2.ExecuteSQL("CREATE INDEX payments_rate ON payments (rate);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db2.ExecuteSQL("INSERT INTO payments(code, amt, rate) VALUES (-4, -0.005, 1);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db2.ExecuteSQL("INSERT INTO payments(code, amt, rate) VALUES (-5, -12345678901.5, 1);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, results11 := db2.ExecuteSQL("SELECT code, amt FROM payments WHERE code = -4;")
+	testingpkg.SimpleAssert(t, err == nil && len(results11) == 1 && results11[0][0].(string) == "-4.000" && results11[0][1].(string) == "-0.01")
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestTimestampAndDateTypes(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE todos(id INT PRIMARY KEY, title VARCHAR(32), due DATE, created_at TIMESTAMP);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO todos(id, title, due, created_at) VALUES (1, 'write', '2024-01-31', '2024-01-10 09:30:00'), (2, 'read', DATE '2024-02-15', TIMESTAMP '2024-01-20 18:00:00.25');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO todos(id, title, due, created_at) VALUES (3, 'sleep', '2023-12-24', NOW()), (4, 'nothing', NULL, NULL);")
+	testingpkg.SimpleAssert(t, err == nil)
+	// invalid date string
+	err, _ = db.ExecuteSQL("INSERT INTO todos(id, title, due, created_at) VALUES (5, 'bad', '2024-13-01', NULL);")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// values are returned as time.Time
+	err, results1 := db.ExecuteSQL("SELECT id, due, created_at FROM todos WHERE id = 2;")
+	testingpkg.SimpleAssert(t, err == nil && len(results1) == 1)
+	testingpkg.SimpleAssert(t, results1[0][1].(time.Time).Equal(time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)))
+	testingpkg.SimpleAssert(t, results1[0][2].(time.Time).Equal(time.Date(2024, 1, 20, 18, 0, 0, 250000000, time.UTC)))
+
+	// string literal is compared as date
+	err, results2 := db.ExecuteSQL("SELECT id FROM todos WHERE due < '2024-02-01';")
+	testingpkg.SimpleAssert(t, err == nil && len(results2) == 2)
+	testingpkg.SimpleAssert(t, results2[0][0].(int32)+results2[1][0].(int32) == 1+3)
+	err, results3 := db.ExecuteSQL("SELECT id FROM todos WHERE due >= DATE '2024-01-01' AND created_at < TIMESTAMP '2024-02-01 00:00:00';")
+	testingpkg.SimpleAssert(t, err == nil && len(results3) == 2)
+	testingpkg.SimpleAssert(t, results3[0][0].(int32)+results3[1][0].(int32) == 1+2)
+
+	// date/time functions and interval arithmetic
+	err, results4 := db.ExecuteSQL("SELECT DATE_TRUNC('month', due) AS m, EXTRACT(YEAR FROM due) AS y, due + INTERVAL 1 MONTH AS next, created_at - INTERVAL 2 HOUR AS before, DATEDIFF(due, created_at) AS d FROM todos WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil && len(results4) == 1)
+	testingpkg.SimpleAssert(t, results4[0][0].(time.Time).Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	testingpkg.SimpleAssert(t, results4[0][1].(int32) == 2024)
+	testingpkg.SimpleAssert(t, results4[0][2].(time.Time).Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)))
+	testingpkg.SimpleAssert(t, results4[0][3].(time.Time).Equal(time.Date(2024, 1, 10, 7, 30, 0, 0, time.UTC)))
+	testingpkg.SimpleAssert(t, results4[0][4].(int32) == 21)
+	err, results5 := db.ExecuteSQL("SELECT id FROM todos WHERE created_at > NOW() - INTERVAL 1 DAY;")
+	testingpkg.SimpleAssert(t, err == nil && len(results5) == 1 && results5[0][0].(int32) == 3)
+	err, results6 := db.ExecuteSQL("SELECT MIN(due), MAX(due) FROM todos;")
+	testingpkg.SimpleAssert(t, err == nil && len(results6) == 1)
+	testingpkg.SimpleAssert(t, results6[0][0].(time.Time).Equal(time.Date(2023, 12, 24, 0, 0, 0, 0, time.UTC)))
+	testingpkg.SimpleAssert(t, results6[0][1].(time.Time).Equal(time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)))
+	err, _ = db.ExecuteSQL("SELECT EXTRACT(YEAR FROM title) FROM todos;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	err, _ = db.ExecuteSQL("UPDATE todos SET due = '2024-03-01' WHERE id = 4;")
+	testingpkg.SimpleAssert(t, err == nil)
+	db.Shutdown()
+
+	// relaunch and check that date/time columns are persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, results7 := db2.ExecuteSQL("SELECT id, due, created_at FROM todos WHERE due = '2024-03-01';")
+	testingpkg.SimpleAssert(t, err == nil && len(results7) == 1)
+	testingpkg.SimpleAssert(t, results7[0][0].(int32) == 4 && results7[0][2] == nil)
+	err, _ = db2.ExecuteSQL("INSERT INTO todos(id, title, due, created_at) VALUES (6, 'bad', 'tomorrow', NULL);")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestBooleanType(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE todos(id INT PRIMARY KEY, title VARCHAR(32), done BOOLEAN DEFAULT FALSE, pinned BOOL UNIQUE);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO todos(id, title, done, pinned) VALUES (1, 'write', TRUE, TRUE), (2, 'read', false, NULL), (3, 'sleep', NULL, FALSE);")
+	testingpkg.SimpleAssert(t, err == nil)
+	// integer and string are converted
+	err, _ = db.ExecuteSQL("INSERT INTO todos(id, title, done) VALUES (4, 'walk', 1), (5, 'run', 'false');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO todos(id, title) VALUES (6, 'eat');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO todos(id, title, done) VALUES (7, 'bad', 'maybe');")
+	testingpkg.SimpleAssert(t, err != nil)
+	// UNIQUE constraint is checked with hash index on boolean column
+	err, _ = db.ExecuteSQL("INSERT INTO todos(id, title, pinned) VALUES (8, 'dup', TRUE);")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// values are returned as bool
+	err, results1 := db.ExecuteSQL("SELECT id, done, pinned FROM todos WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil && len(results1) == 1)
+	testingpkg.SimpleAssert(t, results1[0][1].(bool) == true && results1[0][2].(bool) == true)
+	err, results2 := db.ExecuteSQL("SELECT id, done FROM todos WHERE id = 6;")
+	testingpkg.SimpleAssert(t, err == nil && len(results2) == 1 && results2[0][1].(bool) == false)
+
+	// boolean column as predicate. NULL matches none of them
+	err, results3 := db.ExecuteSQL("SELECT id FROM todos WHERE done;")
+	testingpkg.SimpleAssert(t, err == nil && len(results3) == 2)
+	err, results4 := db.ExecuteSQL("SELECT id FROM todos WHERE NOT done;")
+	testingpkg.SimpleAssert(t, err == nil && len(results4) == 3)
+	err, results5 := db.ExecuteSQL("SELECT id FROM todos WHERE done = TRUE;")
+	testingpkg.SimpleAssert(t, err == nil && len(results5) == 2)
+	err, results6 := db.ExecuteSQL("SELECT id FROM todos WHERE done = 0;")
+	testingpkg.SimpleAssert(t, err == nil && len(results6) == 3)
+	err, results7 := db.ExecuteSQL("SELECT id FROM todos WHERE done IS NOT TRUE;")
+	testingpkg.SimpleAssert(t, err == nil && len(results7) == 4)
+	err, results8 := db.ExecuteSQL("SELECT id FROM todos WHERE done IS NULL;")
+	testingpkg.SimpleAssert(t, err == nil && len(results8) == 1 && results8[0][0].(int32) == 3)
+	err, results9 := db.ExecuteSQL("SELECT id FROM todos WHERE done OR id = 3;")
+	testingpkg.SimpleAssert(t, err == nil && len(results9) == 3)
+	err, results10 := db.ExecuteSQL("SELECT done, COUNT(id) FROM todos GROUP BY done;")
+	testingpkg.SimpleAssert(t, err == nil && len(results10) == 3)
+	err, _ = db.ExecuteSQL("SELECT id FROM todos WHERE title IS TRUE;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// point scan with skip list index on a table which has boolean columns
+	err, _ = db.ExecuteSQL("CREATE INDEX idone ON todos(done);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results13 := db.ExecuteSQL("SELECT id FROM todos WHERE done = TRUE;")
+	testingpkg.SimpleAssert(t, err == nil && len(results13) == 2)
+	err, _ = db.ExecuteSQL("CREATE TABLE flags(id BIGINT, flag BOOLEAN);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE INDEX iid ON flags(id);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO flags(id, flag) VALUES (1, TRUE), (2, FALSE), (3, NULL);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results14 := db.ExecuteSQL("SELECT flag FROM flags WHERE id = 2;")
+	testingpkg.SimpleAssert(t, err == nil && len(results14) == 1 && results14[0][0].(bool) == false)
+
+	err, _ = db.ExecuteSQL("UPDATE todos SET done = TRUE WHERE id = 3;")
+	testingpkg.SimpleAssert(t, err == nil)
+	db.Shutdown()
+
+	// relaunch and check that boolean column and its default value are persisted
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	err, results11 := db2.ExecuteSQL("SELECT id FROM todos WHERE done;")
+	testingpkg.SimpleAssert(t, err == nil && len(results11) == 3)
+	err, _ = db2.ExecuteSQL("INSERT INTO todos(id, title) VALUES (9, 'cook');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results12 := db2.ExecuteSQL("SELECT done FROM todos WHERE id = 9;")
+	testingpkg.SimpleAssert(t, err == nil && len(results12) == 1 && results12[0][0].(bool) == false)
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestTextAndBlobTypes(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	// values larger than a page are stored in overflow pages
+	longBody := strings.Repeat("SamehadaDB ", 2000)
+	longTitle := strings.Repeat("t", 5000)
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE docs(id INT PRIMARY KEY, title VARCHAR(256), body TEXT, data BLOB);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO docs(id, title, body, data) VALUES (1, 'first', '" + longBody + "', 'raw'), (2, 'second', 'short', NULL);")
+	testingpkg.SimpleAssert(t, err == nil)
+	// VARCHAR value which doesn't fit in a page
+	err, _ = db.ExecuteSQL("INSERT INTO docs(id, title, body) VALUES (3, '" + longTitle + "', 'third');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO docs(id, title) VALUES (4, '" + strings.Repeat("x", 70000) + "');")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	err, results1 := db.ExecuteSQL("SELECT body, data FROM docs WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil && len(results1) == 1)
+	testingpkg.SimpleAssert(t, results1[0][0].(string) == longBody && string(results1[0][1].([]byte)) == "raw")
+	err, results2 := db.ExecuteSQL("SELECT title FROM docs WHERE id = 3;")
+	testingpkg.SimpleAssert(t, err == nil && len(results2) == 1 && results2[0][0].(string) == longTitle)
+	// TEXT is compared and processed as string
+	err, results3 := db.ExecuteSQL("SELECT id FROM docs WHERE body = 'short';")
+	testingpkg.SimpleAssert(t, err == nil && len(results3) == 1 && results3[0][0].(int32) == 2)
+	err, results4 := db.ExecuteSQL("SELECT LENGTH(body) FROM docs WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil && results4[0][0].(int32) == int32(len(longBody)))
+
+	// update of other column keeps the large value and the large value can be replaced
+	err, _ = db.ExecuteSQL("UPDATE docs SET title = 'renamed' WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("UPDATE docs SET body = '" + longTitle + "' WHERE id = 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("DELETE FROM docs WHERE id = 3;")
+	testingpkg.SimpleAssert(t, err == nil)
+	// large value inserted by aborted transaction is not left
+	err, _ = db.ExecuteSQL("INSERT INTO docs(id, title, body) VALUES (5, 'aborted', '" + longBody + "'), (1, 'duplicated', 'x');")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	checkDocs := func(db *samehada.SamehadaDB) {
+		err, results := db.ExecuteSQL("SELECT id, title, body FROM docs;")
+		testingpkg.SimpleAssert(t, err == nil && len(results) == 2)
+		for _, row := range results {
+			switch row[0].(int32) {
+			case 1:
+				testingpkg.SimpleAssert(t, row[1].(string) == "renamed" && row[2].(string) == longBody)
+			case 2:
+				testingpkg.SimpleAssert(t, row[1].(string) == "second" && row[2].(string) == longTitle)
+			default:
+				t.Errorf("unexpected row: %v", row[0])
+			}
+		}
+	}
+	checkDocs(db)
+
+	// overflow pages are recovered from log without flushing pages
+	db.CrashForTesting()
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	checkDocs(db2)
+	db2.Shutdown()
+
+	db3 := samehada.NewSamehadaDB(t.Name(), 200)
+	checkDocs(db3)
+
+	common.TempSuppressOnMemStorage = false
+	db3.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestImplicitTypeCoercion(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE measures(id INT PRIMARY KEY, val FLOAT, cnt TINYINT, amount DECIMAL(6, 2), label VARCHAR(32), at DATE);")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	// numeric values are promoted and string literals are parsed as type of the column
+	err, _ = db.ExecuteSQL("INSERT INTO measures(id, val, cnt, amount, label, at) VALUES (1, 1, 10, 3, 'one', '2024-01-01');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO measures(id, val, cnt, amount, label, at) VALUES ('2', '2.5', 20.4, '1.25', 'two', '2024-02-01');")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, results1 := db.ExecuteSQL("SELECT val, cnt, amount FROM measures WHERE id = 2;")
+	testingpkg.SimpleAssert(t, err == nil && len(results1) == 1)
+	testingpkg.SimpleAssert(t, results1[0][0].(float32) == 2.5 && results1[0][1].(int8) == 20 && results1[0][2].(string) == "1.25")
+
+	// invalid and overflowed values are rejected
+	err, _ = db.ExecuteSQL("INSERT INTO measures(id, val) VALUES (3, 'abc');")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO measures(id, cnt) VALUES (3, 300);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("INSERT INTO measures(id, label) VALUES (3, 1);")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("UPDATE measures SET cnt = '1000' WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("UPDATE measures SET val = 4 WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	// literals of predicates are converted to type of the column
+	err, results2 := db.ExecuteSQL("SELECT id FROM measures WHERE val = 4;")
+	testingpkg.SimpleAssert(t, err == nil && len(results2) == 1 && results2[0][0].(int32) == 1)
+	err, results3 := db.ExecuteSQL("SELECT id FROM measures WHERE id = '2';")
+	testingpkg.SimpleAssert(t, err == nil && len(results3) == 1 && results3[0][0].(int32) == 2)
+	err, results4 := db.ExecuteSQL("SELECT id FROM measures WHERE cnt > 10.5 AND amount < '2';")
+	testingpkg.SimpleAssert(t, err == nil && len(results4) == 1 && results4[0][0].(int32) == 2)
+	err, results5 := db.ExecuteSQL("SELECT id FROM measures WHERE id = 1.5;")
+	testingpkg.SimpleAssert(t, err == nil && len(results5) == 0)
+	err, results6 := db.ExecuteSQL("SELECT id FROM measures WHERE LENGTH(label) = 3 AND cnt = '20';")
+	testingpkg.SimpleAssert(t, err == nil && len(results6) == 1 && results6[0][0].(int32) == 2)
+	err, _ = db.ExecuteSQL("SELECT id FROM measures WHERE id = 'abc';")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT id FROM measures WHERE at = 'abc';")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT id FROM measures WHERE label = 1;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT id FROM measures WHERE LENGTH(label) = 3 AND cnt = 'x';")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// CAST of constant reports overflow and format errors
+	err, results7 := db.ExecuteSQL("SELECT CAST('12' AS SIGNED) AS i, CAST(cnt AS CHAR) AS s FROM measures WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil && len(results7) == 1 && results7[0][0].(int32) == 12 && results7[0][1].(string) == "10")
+	err, _ = db.ExecuteSQL("SELECT CAST('abc' AS SIGNED) AS i FROM measures;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT CAST('99999999999' AS SIGNED) AS i FROM measures;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT CAST(1234.5 AS DECIMAL(3, 1)) AS d FROM measures;")
+	testingpkg.SimpleAssert(t, err != nil)
+	err, _ = db.ExecuteSQL("SELECT CAST('2024-13-01' AS DATE) AS d FROM measures;")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestJSONType(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE users(id INT PRIMARY KEY, doc JSON, name VARCHAR(64) AS (doc->>'$.name'), INDEX idx_name (name));")
+	testingpkg.SimpleAssert(t, err == nil)
+	// index on expression should be made with generated column
+	err, _ = db.ExecuteSQL("CREATE TABLE users2(id INT PRIMARY KEY, doc JSON, INDEX idx_name ((doc->>'$.name')));")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	err, _ = db.ExecuteSQL(`INSERT INTO users(id, doc) VALUES (1, '{"name": "alice", "age": 30, "tags": ["a", "b"]}'), ` +
+		`(2, '{"name": "bob", "age": 25, "address": {"city": "Tokyo"}}'), (3, '{"name": "alice", "age": 41}'), (4, '[1, 2]');`)
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL(`INSERT INTO users(id, doc) VALUES (5, '{"name": }');`)
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// document is stored as compact text
+	err, results1 := db.ExecuteSQL("SELECT doc FROM users WHERE id = 2;")
+	testingpkg.SimpleAssert(t, err == nil && len(results1) == 1)
+	testingpkg.SimpleAssert(t, results1[0][0].(string) == `{"name":"bob","age":25,"address":{"city":"Tokyo"}}`)
+
+	// -> returns JSON and ->> returns unquoted string
+	err, results2 := db.ExecuteSQL("SELECT doc->'$.name' AS n, doc->>'$.name' AS u, JSON_EXTRACT(doc, '$.tags[1]') AS tag, doc->>'$.address.city' AS city FROM users WHERE id = 1;")
+	testingpkg.SimpleAssert(t, err == nil && len(results2) == 1)
+	testingpkg.SimpleAssert(t, results2[0][0].(string) == `"alice"` && results2[0][1].(string) == "alice" && results2[0][2].(string) == `"b"`)
+	testingpkg.SimpleAssert(t, results2[0][3] == nil)
+	err, results3 := db.ExecuteSQL("SELECT id FROM users WHERE doc->>'$.address.city' = 'Tokyo';")
+	testingpkg.SimpleAssert(t, err == nil && len(results3) == 1 && results3[0][0].(int32) == 2)
+	err, results4 := db.ExecuteSQL("SELECT id FROM users WHERE doc->'$.age' > 28 ORDER BY id;")
+	testingpkg.SimpleAssert(t, err == nil && len(results4) == 2 && results4[0][0].(int32) == 1 && results4[1][0].(int32) == 3)
+	err, _ = db.ExecuteSQL("SELECT id FROM users WHERE doc->>'$[*]' = 'a';")
+	testingpkg.SimpleAssert(t, err != nil)
+
+	// lookups with the index on the generated column
+	checkNames := func(db *samehada.SamehadaDB, name string, expected []int32) {
+		for _, sql := range []string{"SELECT id FROM users WHERE name = '" + name + "';", "SELECT id FROM users WHERE doc->>'$.name' = '" + name + "';"} {
+			err, results := db.ExecuteSQL(sql)
+			testingpkg.SimpleAssert(t, err == nil && len(results) == len(expected))
+			found := make(map[int32]bool)
+			for _, row := range results {
+				found[row[0].(int32)] = true
+			}
+			for _, id := range expected {
+				testingpkg.SimpleAssert(t, found[id])
+			}
+		}
+	}
+	checkNames(db, "alice", []int32{1, 3})
+	checkNames(db, "bob", []int32{2})
+
+	// index entries are maintained by UPDATE and DELETE
+	err, _ = db.ExecuteSQL(`UPDATE users SET doc = '{"name": "carol"}' WHERE id = 3;`)
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("DELETE FROM users WHERE id = 2;")
+	testingpkg.SimpleAssert(t, err == nil)
+	checkNames(db, "alice", []int32{1})
+	checkNames(db, "carol", []int32{3})
+	checkNames(db, "bob", []int32{})
+
+	db.Shutdown()
+	db2 := samehada.NewSamehadaDB(t.Name(), 200)
+	checkNames(db2, "alice", []int32{1})
+	checkNames(db2, "carol", []int32{3})
+	err, results5 := db2.ExecuteSQL("SELECT doc->'$[1]' AS v FROM users WHERE id = 4;")
+	testingpkg.SimpleAssert(t, err == nil && len(results5) == 1 && results5[0][0].(string) == "2")
+
+	common.TempSuppressOnMemStorage = false
+	db2.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestStructuredErrors(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE items(id INT PRIMARY KEY, name VARCHAR(64) NOT NULL, price INT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name, price) VALUES (1, 'apple', 100);")
+	testingpkg.SimpleAssert(t, err == nil)
+
+	var parseErr *samehada_errors.ParseError
+	for _, sql := range []string{"SELEC * FROM items;", "DROP TABLE items;", ""} {
+		err, _ = db.ExecuteSQL(sql)
+		testingpkg.SimpleAssert(t, errors.As(err, &parseErr) && parseErr.SQL == sql)
+	}
+	// not supported syntax is reported as ParseError without terminating the process
+	for _, sql := range []string{
+		"CREATE TABLE u(id INT DEFAULT NOW());",
+		"ALTER TABLE items ENGINE=x;",
+		"CREATE TABLE u(id INT, item_id INT, FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE SET DEFAULT);",
+		"INSERT INTO items(id, name, price) VALUES (2, UPPER('banana'), 200);",
+		"SELECT * FROM items WHERE id IN (1, 2);",
+		"SELECT * FROM items WHERE price = 0." + strings.Repeat("1", 40) + ";",
+		"SELECT * FROM items ORDER BY 1;",
+	} {
+		err, _ = db.ExecuteSQL(sql)
+		testingpkg.SimpleAssert(t, errors.As(err, &parseErr) && parseErr.SQL == sql)
+		rows, err := db.Query(sql)
+		testingpkg.SimpleAssert(t, rows == nil && errors.As(err, &parseErr) && parseErr.SQL == sql)
+	}
+
+	var tableErr *samehada_errors.UnknownTableError
+	err, _ = db.ExecuteSQL("SELECT * FROM nothing;")
+	testingpkg.SimpleAssert(t, errors.As(err, &tableErr) && tableErr.TableName == "nothing")
+	err, _ = db.ExecuteSQL("INSERT INTO nothing(id) VALUES (1);")
+	testingpkg.SimpleAssert(t, errors.As(err, &tableErr))
+
+	var columnErr *samehada_errors.UnknownColumnError
+	err, _ = db.ExecuteSQL("SELECT weight FROM items;")
+	testingpkg.SimpleAssert(t, errors.As(err, &columnErr) && columnErr.ColumnName == "weight")
+	err, _ = db.ExecuteSQL("SELECT id FROM items WHERE weight + 1 > 2;")
+	testingpkg.SimpleAssert(t, errors.As(err, &columnErr) && columnErr.ColumnName == "weight")
+	err, _ = db.ExecuteSQL("UPDATE items SET weight = 1 WHERE id = 1;")
+	testingpkg.SimpleAssert(t, errors.As(err, &columnErr) && columnErr.TableName == "items")
+
+	var typeErr *samehada_errors.TypeMismatchError
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, name, price) VALUES (2, 'banana', 'cheap');")
+	testingpkg.SimpleAssert(t, errors.As(err, &typeErr))
+	err, _ = db.ExecuteSQL("SELECT id FROM items WHERE name = 1;")
+	testingpkg.SimpleAssert(t, errors.As(err, &typeErr))
+	err, _ = db.ExecuteSQL("SELECT id FROM items WHERE price + 1 = 'abc';")
+	testingpkg.SimpleAssert(t, errors.As(err, &typeErr))
+
+	var cvErr *samehada_errors.ConstraintViolationError
+	err, _ = db.ExecuteSQL("INSERT INTO items(id, price) VALUES (2, 200);")
+	testingpkg.SimpleAssert(t, errors.As(err, &cvErr) && cvErr.Kind == samehada_errors.CONSTRAINT_NOT_NULL)
+
+	// failed statements don't leave any change and the DB can be used continuously
+	err, results := db.ExecuteSQL("SELECT id, name FROM items;")
+	testingpkg.SimpleAssert(t, err == nil && len(results) == 1 && results[0][1].(string) == "apple")
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestQueryCursor(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE items(id INT, name VARCHAR(64), price FLOAT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	for ii := 0; ii < 300; ii++ {
+		err, _ = db.ExecuteSQL(fmt.Sprintf("INSERT INTO items(id, name, price) VALUES (%d, 'item%d', %d.5);", ii, ii, ii))
+		testingpkg.SimpleAssert(t, err == nil)
+	}
+
+	rows, err := db.Query("SELECT id, name, price FROM items WHERE id >= 100;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, len(rows.Columns()) == 3)
+	cnt := 0
+	for rows.Next() {
+		var id int64
+		var name string
+		var price float64
+		testingpkg.SimpleAssert(t, rows.Scan(&id, &name, &price) == nil)
+		testingpkg.SimpleAssert(t, id == int64(100+cnt) && name == fmt.Sprintf("item%d", id) && price == float64(id)+0.5)
+		cnt++
+	}
+	testingpkg.SimpleAssert(t, rows.Err() == nil && cnt == 200)
+	testingpkg.SimpleAssert(t, rows.Close() == nil)
+
+	// stop reading halfway. the transaction is finished by Close
+	rows, err = db.Query("SELECT id, name FROM items;")
+	testingpkg.SimpleAssert(t, err == nil)
+	testingpkg.SimpleAssert(t, rows.Next())
+	var id int32
+	var name interface{}
+	var wrongTyped bool
+	testingpkg.SimpleAssert(t, rows.Scan(&id) != nil)
+	testingpkg.SimpleAssert(t, rows.Scan(&wrongTyped, &name) != nil)
+	testingpkg.SimpleAssert(t, rows.Scan(&id, &name) == nil && name.(string) == fmt.Sprintf("item%d", id))
+	testingpkg.SimpleAssert(t, rows.Close() == nil)
+	testingpkg.SimpleAssert(t, !rows.Next())
+
+	// DML is executed completely even if no row is read
+	rows, err = db.Query("UPDATE items SET name = 'updated' WHERE id < 10;")
+	testingpkg.SimpleAssert(t, err == nil && !rows.Next())
+	testingpkg.SimpleAssert(t, rows.RowsAffected() == 10)
+	testingpkg.SimpleAssert(t, rows.Close() == nil)
+	err, results := db.ExecuteSQL("SELECT COUNT(*) FROM items WHERE name = 'updated';")
+	testingpkg.SimpleAssert(t, err == nil && results[0][0].(int32) == 10)
+
+	// rows of RETURNING clause can be read after DML is executed
+	rows, err = db.Query("UPDATE items SET name = 'returned' WHERE id >= 290 RETURNING id, name;")
+	testingpkg.SimpleAssert(t, err == nil && rows.RowsAffected() == 10)
+	testingpkg.SimpleAssert(t, len(rows.Columns()) == 2 && rows.Columns()[1] == "name")
+	cnt = 0
+	for rows.Next() {
+		var retName string
+		testingpkg.SimpleAssert(t, rows.Scan(&id, &retName) == nil)
+		testingpkg.SimpleAssert(t, id >= 290 && retName == "returned")
+		cnt++
+	}
+	testingpkg.SimpleAssert(t, rows.Err() == nil && cnt == 10)
+	testingpkg.SimpleAssert(t, rows.Close() == nil)
+	rows, err = db.Query("DELETE FROM items WHERE id >= 295 RETURNING id;")
+	testingpkg.SimpleAssert(t, err == nil && rows.Next())
+	testingpkg.SimpleAssert(t, rows.Close() == nil && !rows.Next())
+	err, results = db.ExecuteSQL("SELECT COUNT(*) FROM items;")
+	testingpkg.SimpleAssert(t, err == nil && results[0][0].(int32) == 295)
+
+	rows, err = db.Query("SELECT id FROM nothing;")
+	var tableErr *samehada_errors.UnknownTableError
+	testingpkg.SimpleAssert(t, rows == nil && errors.As(err, &tableErr))
+
+	rows, err = db.Query("SELECT COUNT(*), SUM(id) FROM items;")
+	testingpkg.SimpleAssert(t, err == nil && rows.Next())
+	var count, sum interface{}
+	testingpkg.SimpleAssert(t, rows.Scan(&count, &sum) == nil && count.(int32) == 295)
+	testingpkg.SimpleAssert(t, !rows.Next() && rows.Err() == nil)
+	testingpkg.SimpleAssert(t, rows.Close() == nil)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
+
+func TestQueryCancellation(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	// clear all state of DB
+	if !common.EnableOnMemStorage || common.TempSuppressOnMemStorage == true {
+		os.Remove(t.Name() + ".db")
+		os.Remove(t.Name() + ".log")
+	}
+
+	db := samehada.NewSamehadaDB(t.Name(), 200)
+	err, _ := db.ExecuteSQL("CREATE TABLE items(id INT, grp INT, price INT);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE TABLE categories(id INT, name VARCHAR(32));")
+	testingpkg.SimpleAssert(t, err == nil)
+	for ii := 0; ii < 200; ii++ {
+		err, _ = db.ExecuteSQL(fmt.Sprintf("INSERT INTO items(id, grp, price) VALUES (%d, %d, 100);", ii, ii%10))
+		testingpkg.SimpleAssert(t, err == nil)
+	}
+	for ii := 0; ii < 10; ii++ {
+		err, _ = db.ExecuteSQL(fmt.Sprintf("INSERT INTO categories(id, name) VALUES (%d, 'group%d');", ii, ii))
+		testingpkg.SimpleAssert(t, err == nil)
+	}
+
+	// cancel_at(id) cancels the statement when it is evaluated on the row which has id 100
+	var cancel context.CancelFunc
+	err = db.RegisterFunction("cancel_at", []types.TypeID{types.Integer}, types.Boolean, func(args ...types.Value) types.Value {
+		if args[0].ToInteger() == 100 {
+			cancel()
+		}
+		return types.NewBoolean(true)
+	})
+	testingpkg.SimpleAssert(t, err == nil)
+	var canceledErr *samehada_errors.QueryCanceledError
+
+	for _, sql := range []string{
+		"SELECT id FROM items WHERE cancel_at(id);",
+		"SELECT grp, SUM(price) FROM items WHERE cancel_at(id) GROUP BY grp;",
+		"SELECT items.id, categories.name FROM items JOIN categories ON items.grp = categories.id WHERE cancel_at(items.id);",
+		"UPDATE items SET price = 200 WHERE cancel_at(id);",
+	} {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		err, results := db.ExecuteSQLContext(ctx, sql)
+		cancel()
+		testingpkg.SimpleAssert(t, errors.As(err, &canceledErr) && errors.Is(err, context.Canceled) && len(results) == 0)
+	}
+	// the transaction is aborted and changes made before cancellation are rollbacked
+	err, results := db.ExecuteSQL("SELECT COUNT(*) FROM items WHERE price = 100;")
+	testingpkg.SimpleAssert(t, err == nil && results[0][0].(int32) == 200)
+
+	// cancellation while rows are read
+	ctx, cancelQuery := context.WithCancel(context.Background())
+	rows, err := db.QueryContext(ctx, "SELECT items.id, categories.name FROM items JOIN categories ON items.grp = categories.id;")
+	testingpkg.SimpleAssert(t, err == nil && rows.Next())
+	cancelQuery()
+	testingpkg.SimpleAssert(t, !rows.Next() && errors.As(rows.Err(), &canceledErr))
+	testingpkg.SimpleAssert(t, errors.As(rows.Close(), &canceledErr))
+
+	// cancellation while rows of index scans, window functions, CTEs and set operations are read
+	err, _ = db.ExecuteSQL("CREATE INDEX igrp ON items(grp);")
+	testingpkg.SimpleAssert(t, err == nil)
+	err, _ = db.ExecuteSQL("CREATE UNIQUE INDEX iid ON items(id);")
+	testingpkg.SimpleAssert(t, err == nil)
+	for _, sql := range []string{
+		"SELECT id FROM items WHERE grp = 3;",
+		"SELECT id FROM items WHERE id >= 10;",
+		"SELECT id, ROW_NUMBER() OVER (ORDER BY id) FROM items;",
+		"WITH cheap AS (SELECT id FROM items WHERE price = 100) SELECT id FROM cheap;",
+		"WITH RECURSIVE nums(n) AS (SELECT id FROM items WHERE id = 0 UNION ALL SELECT n + 1 FROM nums WHERE n < 100) SELECT n FROM nums;",
+		"SELECT id FROM items UNION SELECT id FROM categories;",
+		"SELECT grp FROM items EXCEPT SELECT id FROM categories WHERE id < 5;",
+	} {
+		ctx, cancelQuery := context.WithCancel(context.Background())
+		rows, err := db.QueryContext(ctx, sql)
+		testingpkg.SimpleAssert(t, err == nil && rows.Next())
+		cancelQuery()
+		testingpkg.SimpleAssert(t, !rows.Next() && errors.As(rows.Err(), &canceledErr))
+		rows.Close()
+	}
+
+	// already canceled context
+	err, _ = db.ExecuteSQLContext(ctx, "SELECT id FROM items;")
+	testingpkg.SimpleAssert(t, errors.As(err, &canceledErr))
+
+	// statement timeout
+	err = db.RegisterFunction("delay_row", []types.TypeID{types.Integer}, types.Boolean, func(args ...types.Value) types.Value {
+		time.Sleep(time.Millisecond)
+		return types.NewBoolean(true)
+	})
+	testingpkg.SimpleAssert(t, err == nil)
+	db.SetStatementTimeout(20 * time.Millisecond)
+	err, _ = db.ExecuteSQL("SELECT id FROM items WHERE delay_row(id);")
+	testingpkg.SimpleAssert(t, errors.As(err, &canceledErr) && errors.Is(err, context.DeadlineExceeded))
+	db.SetStatementTimeout(0)
+	err, results = db.ExecuteSQL("SELECT COUNT(*) FROM items WHERE delay_row(id);")
+	testingpkg.SimpleAssert(t, err == nil && results[0][0].(int32) == 200)
+
+	common.TempSuppressOnMemStorage = false
+	db.Shutdown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
@@ -0,0 +1,112 @@
+package samehada
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ryogrid/SamehadaDB/execution/expression"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+// RegisterFunction makes fn callable from SQL as a scalar function named name.
+// arguments are converted to argTypes before fn is called (integer and Decimal are accepted for Float argument)
+// and NULL is passed as NULL of the argument type. value returned from fn is converted to retType.
+// registered functions are shared among SamehadaDB objects in the process
+func (sdb *SamehadaDB) RegisterFunction(name string, argTypes []types.TypeID, retType types.TypeID, fn func(...types.Value) types.Value) error {
+	if name == "" || fn == nil {
+		return errors.New("name and function must be specified.")
+	}
+	if expression.GetAggregateFunction(name) != nil {
+		return errors.New("aggregate function " + name + " is already registered.")
+	}
+	expression.RegisterScalarFunction(&expression.ScalarFunction{
+		Name:       name,
+		ReturnType: userFuncReturnType(name, argTypes, retType),
+		Eval: func(args []types.Value, _ types.TypeID) types.Value {
+			ret, ok := expression.CastValue(fn(convUserFuncArgs(args, argTypes)...), retType)
+			if !ok {
+				return types.NewNullOfType(retType)
+			}
+			return ret
+		},
+	})
+	return nil
+}
+
+// RegisterAggregateFunction makes an aggregate function named name callable from SQL.
+// newFunc is called for each group and each input tuple (see expression.AggregateFunction).
+// arguments passed to Step are converted like RegisterFunction and value returned from Final
+// is converted to retType
+func (sdb *SamehadaDB) RegisterAggregateFunction(name string, argTypes []types.TypeID, retType types.TypeID, newFunc func() expression.AggregateFunction) error {
+	if name == "" || newFunc == nil {
+		return errors.New("name and function must be specified.")
+	}
+	if expression.GetScalarFunction(name) != nil {
+		return errors.New("scalar function " + name + " is already registered.")
+	}
+	expression.RegisterAggregateFunction(&expression.UserAggregateFunction{
+		Name:       name,
+		ReturnType: userFuncReturnType(name, argTypes, retType),
+		New: func() expression.AggregateFunction {
+			return &userAggregateAdapter{newFunc(), argTypes, retType}
+		},
+	})
+	return nil
+}
+
+func userFuncReturnType(name string, argTypes []types.TypeID, retType types.TypeID) func([]types.TypeID) (types.TypeID, error) {
+	return func(passedTypes []types.TypeID) (types.TypeID, error) {
+		if len(passedTypes) != len(argTypes) {
+			return types.Invalid, fmt.Errorf("%s takes %d arguments.", name, len(argTypes))
+		}
+		for ii, passedType := range passedTypes {
+			if passedType != types.Invalid && passedType != argTypes[ii] && !isWidenedArg(passedType, argTypes[ii]) {
+				return types.Invalid, fmt.Errorf("argument %d of %s must be %s.", ii+1, name, argTypes[ii].String())
+			}
+		}
+		return retType, nil
+	}
+}
+
+// integer argument can be passed as Float, Decimal or wider integer type. Decimal argument can be passed as Float
+func isWidenedArg(passedType types.TypeID, argType types.TypeID) bool {
+	if passedType == types.Decimal {
+		return argType == types.Float
+	}
+	if !passedType.IsIntegerFamily() {
+		return false
+	}
+	return argType == types.Float || argType == types.Decimal || (argType.IsIntegerFamily() && types.WiderIntegerType(passedType, argType) == argType)
+}
+
+func convUserFuncArgs(args []types.Value, argTypes []types.TypeID) []types.Value {
+	ret := make([]types.Value, 0, len(args))
+	for ii, arg := range args {
+		// types of arguments are checked at planning
+		converted, _ := expression.CastValue(arg, argTypes[ii])
+		ret = append(ret, converted)
+	}
+	return ret
+}
+
+// userAggregateAdapter converts arguments and result of user defined aggregate function
+type userAggregateAdapter struct {
+	expression.AggregateFunction
+	argTypes []types.TypeID
+	retType  types.TypeID
+}
+
+func (a *userAggregateAdapter) Step(args ...types.Value) {
+	a.AggregateFunction.Step(convUserFuncArgs(args, a.argTypes)...)
+}
+
+func (a *userAggregateAdapter) Merge(other expression.AggregateFunction) {
+	a.AggregateFunction.Merge(other.(*userAggregateAdapter).AggregateFunction)
+}
+
+func (a *userAggregateAdapter) Final() types.Value {
+	ret, ok := expression.CastValue(a.AggregateFunction.Final(), a.retType)
+	if !ok {
+		return types.NewNullOfType(a.retType)
+	}
+	return ret
+}
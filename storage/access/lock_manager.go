@@ -285,6 +285,10 @@ func (lock_manager *LockManager) Unlock(txn *Transaction, rid_list []page.RID) b
 				// fmt.Println("remove txnid from shared_lock_table entry")
 				// fmt.Println(txn.GetTransactionId())
 				lock_manager.shared_lock_table[locked_rid] = removeTxnID(arr, txn.GetTransactionId())
+				if len(lock_manager.shared_lock_table[locked_rid]) == 0 {
+					// empty entry should not be left because it blocks exclusive locking of the RID
+					delete(lock_manager.shared_lock_table, locked_rid)
+				}
 			}
 		}
 	}
@@ -0,0 +1,167 @@
+package access
+
+import (
+	"unsafe"
+
+	"github.com/ryogrid/SamehadaDB/common"
+	"github.com/ryogrid/SamehadaDB/recovery"
+	"github.com/ryogrid/SamehadaDB/storage/buffer"
+	"github.com/ryogrid/SamehadaDB/storage/page"
+	"github.com/ryogrid/SamehadaDB/types"
+)
+
+const offsetOverflowNextPageId = uint32(8)
+const offsetOverflowDataSize = uint32(12)
+const sizeOverflowPageHeader = uint32(16)
+
+// OverflowPageCapacity is max size of data which is stored in one overflow page
+const OverflowPageCapacity = common.PageSize - sizeOverflowPageHeader
+
+// tuple whose size is larger than this is stored in overflow pages
+const overflowThreshold = common.PageSize / 2
+
+// stub of tuple stored in overflow pages
+//
+//	-----------------------------------------------------------
+//	| Marker 0xFF (1)| TupleSize (4)| FirstOverflowPageId (4) |
+//	-----------------------------------------------------------
+//
+// first byte of ordinary tuple is NULL flag of first column or offset of varied-sized field.
+// the latter is never 0xFF when size of the tuple is same as stub
+const overflowStubMarker = byte(0xFF)
+const sizeOverflowStub = uint32(9)
+
+// Overflow page format:
+//
+//	-------------------------------------------------------------------------------
+//	| PageId (4)| LSN (4)| NextPageId (4)| DataSize (4)| ... PART OF TUPLE DATA ... |
+//	-------------------------------------------------------------------------------
+//
+// data of large tuple is split into chunks and stored in a chain of overflow pages.
+// TablePage holds the stub which points to the first page of the chain
+type OverflowPage struct {
+	page.Page
+}
+
+// CastPageAsOverflowPage casts the abstract Page struct into OverflowPage
+func CastPageAsOverflowPage(page *page.Page) *OverflowPage {
+	if page == nil {
+		return nil
+	}
+
+	return (*OverflowPage)(unsafe.Pointer(page))
+}
+
+// SetContent writes next page id and a chunk of tuple data to the page
+func (op *OverflowPage) SetContent(pageId types.PageID, nextPageId types.PageID, data []byte) {
+	op.Copy(0, pageId.Serialize())
+	op.Copy(offsetOverflowNextPageId, nextPageId.Serialize())
+	op.Copy(offsetOverflowDataSize, types.UInt32(len(data)).Serialize())
+	op.Copy(sizeOverflowPageHeader, data)
+}
+
+func (op *OverflowPage) GetNextPageId() types.PageID {
+	return types.NewPageIDFromBytes(op.Data()[offsetOverflowNextPageId:])
+}
+
+func (op *OverflowPage) GetContent() []byte {
+	size := uint32(types.NewUInt32FromBytes(op.Data()[offsetOverflowDataSize:]))
+	ret := make([]byte, size)
+	copy(ret, op.Data()[sizeOverflowPageHeader:sizeOverflowPageHeader+size])
+	return ret
+}
+
+func newOverflowStub(tupleSize uint32, firstPageId types.PageID) []byte {
+	ret := make([]byte, 0, sizeOverflowStub)
+	ret = append(ret, overflowStubMarker)
+	ret = append(ret, types.UInt32(tupleSize).Serialize()...)
+	return append(ret, firstPageId.Serialize()...)
+}
+
+// parseOverflowStub returns size of original tuple and first overflow page id when data is stub
+func parseOverflowStub(data []byte) (uint32, types.PageID, bool) {
+	if uint32(len(data)) != sizeOverflowStub || data[0] != overflowStubMarker {
+		return 0, types.InvalidPageID, false
+	}
+	return uint32(types.NewUInt32FromBytes(data[1:])), types.NewPageIDFromBytes(data[5:]), true
+}
+
+// writeOverflowPages stores data to a new chain of overflow pages and returns the first page id.
+// chunks are written from the tail so that next page id is decided when each page is logged
+func writeOverflowPages(bpm *buffer.BufferPoolManager, log_manager *recovery.LogManager, data []byte, txn *Transaction) types.PageID {
+	nextPageId := types.InvalidPageID
+	chunkCnt := (uint32(len(data)) + OverflowPageCapacity - 1) / OverflowPageCapacity
+	for ii := int(chunkCnt) - 1; ii >= 0; ii-- {
+		start := uint32(ii) * OverflowPageCapacity
+		end := start + OverflowPageCapacity
+		if end > uint32(len(data)) {
+			end = uint32(len(data))
+		}
+		p := bpm.NewPage()
+		op := CastPageAsOverflowPage(p)
+		op.WLatch()
+		op.SetContent(p.ID(), nextPageId, data[start:end])
+		if log_manager.IsEnabledLogging() {
+			log_record := recovery.NewLogRecordOverflowPage(txn.GetTransactionId(), txn.GetPrevLSN(), recovery.OVERFLOWPAGE, p.ID(), nextPageId, data[start:end])
+			lsn := log_manager.AppendLogRecord(log_record)
+			op.SetLSN(lsn)
+			txn.SetPrevLSN(lsn)
+		}
+		op.WUnlatch()
+		// flush page for recovery process works...
+		bpm.FlushPage(p.ID())
+		bpm.UnpinPage(p.ID(), true)
+		nextPageId = p.ID()
+	}
+	return nextPageId
+}
+
+// readOverflowPages returns data stored in the chain which starts from firstPageId.
+// error is returned when a page of the chain can't be fetched
+func readOverflowPages(bpm *buffer.BufferPoolManager, firstPageId types.PageID, size uint32) ([]byte, error) {
+	ret := make([]byte, 0, size)
+	for pageId := firstPageId; pageId.IsValid(); {
+		pg, err := fetchPage(bpm, pageId)
+		if err != nil {
+			return nil, err
+		}
+		op := CastPageAsOverflowPage(pg)
+		op.RLatch()
+		ret = append(ret, op.GetContent()...)
+		nextPageId := op.GetNextPageId()
+		op.RUnlatch()
+		bpm.UnpinPage(pageId, false)
+		pageId = nextPageId
+	}
+	return ret, nil
+}
+
+// freeOverflowPages deallocates pages of the chain which starts from firstPageId.
+// it should be called after the stub referencing the chain is removed or replaced
+func freeOverflowPages(bpm *buffer.BufferPoolManager, firstPageId types.PageID) {
+	for pageId := firstPageId; pageId.IsValid(); {
+		op := CastPageAsOverflowPage(bpm.FetchPage(pageId))
+		if op == nil {
+			return
+		}
+		nextPageId := op.GetNextPageId()
+		bpm.UnpinPage(pageId, false)
+		bpm.DeletePage(pageId)
+		pageId = nextPageId
+	}
+}
+
+// getOverflowPageIdAt returns first overflow page id when tuple at slot_num is stub.
+// deleted flag is ignored because this is used when deleted tuple is removed
+func (tp *TablePage) getOverflowPageIdAt(slot_num uint32) types.PageID {
+	if slot_num >= tp.GetTupleCount() {
+		return types.InvalidPageID
+	}
+	tuple_size := UnsetDeletedFlag(tp.GetTupleSize(slot_num))
+	if tuple_size != sizeOverflowStub {
+		return types.InvalidPageID
+	}
+	tuple_offset := tp.GetTupleOffsetAtSlot(slot_num)
+	_, firstPageId, _ := parseOverflowStub(tp.Data()[tuple_offset : tuple_offset+tuple_size])
+	return firstPageId
+}
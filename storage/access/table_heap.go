@@ -6,12 +6,15 @@ package access
 import (
 	"fmt"
 	"github.com/ryogrid/SamehadaDB/common"
+	"github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/recovery"
 	"github.com/ryogrid/SamehadaDB/storage/buffer"
 	"github.com/ryogrid/SamehadaDB/storage/page"
 	"github.com/ryogrid/SamehadaDB/storage/table/schema"
 	"github.com/ryogrid/SamehadaDB/storage/tuple"
 	"github.com/ryogrid/SamehadaDB/types"
+	"strconv"
+	"sync"
 )
 
 // TableHeap represents a physical table on disk.
@@ -21,6 +24,9 @@ type TableHeap struct {
 	firstPageId  types.PageID
 	log_manager  *recovery.LogManager
 	lock_manager *LockManager
+	// serializes check of UNIQUE constraints and insertion of index entries between transactions.
+	// this is held by the heap because TableMetadata is replaced by ALTER TABLE and CREATE INDEX
+	uniqueCheckMutex *sync.Mutex
 }
 
 // NewTableHeap creates a table heap without a  (open table)
@@ -34,12 +40,22 @@ func NewTableHeap(bpm *buffer.BufferPoolManager, log_manager *recovery.LogManage
 	// flush page for recovery process works...
 	bpm.FlushPage(p.ID())
 	bpm.UnpinPage(p.ID(), true)
-	return &TableHeap{bpm, p.ID(), log_manager, lock_manager}
+	return &TableHeap{bpm, p.ID(), log_manager, lock_manager, new(sync.Mutex)}
 }
 
 // InitTableHeap ...
 func InitTableHeap(bpm *buffer.BufferPoolManager, pageId types.PageID, log_manager *recovery.LogManager, lock_manager *LockManager) *TableHeap {
-	return &TableHeap{bpm, pageId, log_manager, lock_manager}
+	return &TableHeap{bpm, pageId, log_manager, lock_manager, new(sync.Mutex)}
+}
+
+// LockUniqueCheck should be called before check of UNIQUE constraints and
+// UnlockUniqueCheck should be called after index entries of checked tuple are inserted
+func (t *TableHeap) LockUniqueCheck() {
+	t.uniqueCheckMutex.Lock()
+}
+
+func (t *TableHeap) UnlockUniqueCheck() {
+	t.uniqueCheckMutex.Unlock()
 }
 
 // GetFirstPageId returns firstPageId
@@ -54,24 +70,31 @@ func (t *TableHeap) GetFirstPageId() types.PageID {
 // If the tuple is too large (>= page_size):
 // 1. It tries to insert in the next page
 // 2. If there is no next page, it creates a new page and insert in it
+// data of tuple larger than overflowThreshold is stored in overflow pages and only the stub is inserted
 func (t *TableHeap) InsertTuple(tuple_ *tuple.Tuple, txn *Transaction, oid uint32) (rid *page.RID, err error) {
 	if common.EnableDebug {
 		common.ShPrintf(common.RDB_OP_FUNC_CALL, "TableHeap::InsertTuple called. txn.txn_id:%v tuple_:%v\n", txn.txn_id, *tuple_)
 	}
-	currentPage := CastPageAsTablePage(t.bpm.FetchPage(t.firstPageId))
+	storedTuple := t.toStoredTuple(tuple_, txn)
+	currentPage := t.fetchTablePage(t.firstPageId, txn)
+	if currentPage == nil {
+		t.freeUnstoredOverflowPages(tuple_, storedTuple)
+		return nil, txn.GetAbortCause()
+	}
 
 	// Insert into the first page with enough space. If no such page exists, create a new page and insert into that.
 	// INVARIANT: currentPage is WLatched if you leave the loop normally.
 
 	for {
 		currentPage.WLatch()
-		rid, err = currentPage.InsertTuple(tuple_, t.log_manager, t.lock_manager, txn)
+		rid, err = currentPage.InsertTuple(storedTuple, t.log_manager, t.lock_manager, txn)
 		if err == nil || err == ErrEmptyTuple {
 			currentPage.WUnlatch()
 			break
 		}
 		if rid == nil && err != nil && err != ErrEmptyTuple && err != ErrNotEnoughSpace {
 			currentPage.WUnlatch()
+			t.freeUnstoredOverflowPages(tuple_, storedTuple)
 			return nil, err
 		}
 
@@ -79,7 +102,11 @@ func (t *TableHeap) InsertTuple(tuple_ *tuple.Tuple, txn *Transaction, oid uint3
 		if nextPageId.IsValid() {
 			t.bpm.UnpinPage(currentPage.GetTablePageId(), false)
 			currentPage.WUnlatch()
-			currentPage = CastPageAsTablePage(t.bpm.FetchPage(nextPageId))
+			currentPage = t.fetchTablePage(nextPageId, txn)
+			if currentPage == nil {
+				t.freeUnstoredOverflowPages(tuple_, storedTuple)
+				return nil, txn.GetAbortCause()
+			}
 			//currentPage.WLatch()
 		} else {
 			p := t.bpm.NewPage()
@@ -99,22 +126,113 @@ func (t *TableHeap) InsertTuple(tuple_ *tuple.Tuple, txn *Transaction, oid uint3
 	//currentPage.WUnlatch()
 
 	t.bpm.UnpinPage(currentPage.GetTablePageId(), true)
+	tuple_.SetRID(rid)
 	// Update the transaction's write set.
 	txn.AddIntoWriteSet(NewWriteRecord(*rid, INSERT, new(tuple.Tuple), t, oid))
 	return rid, nil
 }
 
+// InsertTuples inserts tuples into the table in order and returns RIDs of them
+// PAY ATTENTION: index entries are not inserted
+//
+// unlike InsertTuple, page chain is traversed only once and each page is filled
+// with tuples as many as possible before moving to next page.
+// so it is suitable for loading many tuples
+func (t *TableHeap) InsertTuples(tuples []*tuple.Tuple, txn *Transaction, oid uint32) ([]page.RID, error) {
+	if common.EnableDebug {
+		common.ShPrintf(common.RDB_OP_FUNC_CALL, "TableHeap::InsertTuples called. txn.txn_id:%v len(tuples):%v\n", txn.txn_id, len(tuples))
+	}
+	rids := make([]page.RID, 0, len(tuples))
+	storedTuples := make([]*tuple.Tuple, 0, len(tuples))
+	for _, tuple_ := range tuples {
+		storedTuples = append(storedTuples, t.toStoredTuple(tuple_, txn))
+	}
+	currentPage := t.fetchTablePage(t.firstPageId, txn)
+	if currentPage == nil {
+		for idx := range tuples {
+			t.freeUnstoredOverflowPages(tuples[idx], storedTuples[idx])
+		}
+		return nil, txn.GetAbortCause()
+	}
+	currentPage.WLatch()
+	for idx := 0; idx < len(tuples); {
+		rid, err := currentPage.InsertTuple(storedTuples[idx], t.log_manager, t.lock_manager, txn)
+		if err == nil {
+			tuples[idx].SetRID(rid)
+			rids = append(rids, *rid)
+			txn.AddIntoWriteSet(NewWriteRecord(*rid, INSERT, new(tuple.Tuple), t, oid))
+			idx++
+			continue
+		}
+		if err != ErrNotEnoughSpace {
+			currentPage.WUnlatch()
+			t.bpm.UnpinPage(currentPage.GetTablePageId(), true)
+			for ; idx < len(tuples); idx++ {
+				t.freeUnstoredOverflowPages(tuples[idx], storedTuples[idx])
+			}
+			return nil, err
+		}
+
+		nextPageId := currentPage.GetNextPageId()
+		if nextPageId.IsValid() {
+			currentPage.WUnlatch()
+			t.bpm.UnpinPage(currentPage.GetTablePageId(), true)
+			currentPage = t.fetchTablePage(nextPageId, txn)
+			if currentPage == nil {
+				// tuples inserted already are removed when the transaction is aborted
+				for ; idx < len(tuples); idx++ {
+					t.freeUnstoredOverflowPages(tuples[idx], storedTuples[idx])
+				}
+				return nil, txn.GetAbortCause()
+			}
+		} else {
+			p := t.bpm.NewPage()
+			currentPage.SetNextPageId(p.ID())
+			newPage := CastPageAsTablePage(p)
+			newPage.Init(p.ID(), currentPage.GetTablePageId(), t.log_manager, t.lock_manager, txn)
+			t.bpm.FlushPage(newPage.GetPageId())
+			currentPage.WUnlatch()
+			t.bpm.UnpinPage(currentPage.GetTablePageId(), true)
+			currentPage = newPage
+		}
+		currentPage.WLatch()
+	}
+	currentPage.WUnlatch()
+	t.bpm.UnpinPage(currentPage.GetTablePageId(), true)
+	return rids, nil
+}
+
 // if specified nil to update_col_idxs and schema_, all data of existed tuple is replaced one of new_tuple
 // if specified not nil, new_tuple also should have all columns defined in schema. but not update target value can be dummy value
 func (t *TableHeap) UpdateTuple(tuple_ *tuple.Tuple, update_col_idxs []int, schema_ *schema.Schema, oid uint32, rid page.RID, txn *Transaction) (bool, *page.RID) {
 	if common.EnableDebug {
 		common.ShPrintf(common.RDB_OP_FUNC_CALL, "TableHeap::UpadteTuple called. txn.txn_id:%v update_col_idxs:%v rid:%v\n", txn.txn_id, update_col_idxs, rid)
 	}
+	if update_col_idxs != nil && schema_ != nil {
+		// columns are merged here because old tuple may be stored in overflow pages
+		old_tuple := t.GetTuple(&rid, txn)
+		if old_tuple == nil {
+			return false, nil
+		}
+		update_tuple_values := make([]types.Value, 0)
+		matched_cnt := int(0)
+		for idx, _ := range schema_.GetColumns() {
+			if matched_cnt < len(update_col_idxs) && idx == update_col_idxs[matched_cnt] {
+				update_tuple_values = append(update_tuple_values, tuple_.GetValue(schema_, uint32(idx)))
+				matched_cnt++
+			} else {
+				update_tuple_values = append(update_tuple_values, old_tuple.GetValue(schema_, uint32(idx)))
+			}
+		}
+		tuple_ = tuple.NewTupleFromSchema(update_tuple_values, schema_)
+	}
+	storedTuple := t.toStoredTuple(tuple_, txn)
+
 	// Find the page which contains the tuple.
-	page_ := CastPageAsTablePage(t.bpm.FetchPage(rid.GetPageId()))
+	page_ := t.fetchTablePage(rid.GetPageId(), txn)
 	// If the page could not be found, then abort the transaction.
 	if page_ == nil {
-		txn.SetState(ABORTED)
+		t.freeUnstoredOverflowPages(tuple_, storedTuple)
 		return false, nil
 	}
 	// Update the tuple; but first save the old value for rollbacks.
@@ -122,7 +240,7 @@ func (t *TableHeap) UpdateTuple(tuple_ *tuple.Tuple, update_col_idxs []int, sche
 	old_tuple.SetRID(new(page.RID))
 
 	page_.WLatch()
-	is_updated, err, need_follow_tuple := page_.UpdateTuple(tuple_, update_col_idxs, schema_, old_tuple, &rid, txn, t.lock_manager, t.log_manager)
+	is_updated, err, need_follow_tuple := page_.UpdateTuple(storedTuple, nil, nil, old_tuple, &rid, txn, t.lock_manager, t.log_manager)
 	page_.WUnlatch()
 	t.bpm.UnpinPage(page_.GetTablePageId(), is_updated)
 
@@ -135,6 +253,7 @@ func (t *TableHeap) UpdateTuple(tuple_ *tuple.Tuple, update_col_idxs []int, sche
 		if !is_deleted {
 			fmt.Println("TableHeap::UpdateTuple(): MarkDelete failed")
 			txn.SetState(ABORTED)
+			t.freeUnstoredOverflowPages(tuple_, storedTuple)
 			return false, nil
 		}
 
@@ -143,6 +262,7 @@ func (t *TableHeap) UpdateTuple(tuple_ *tuple.Tuple, update_col_idxs []int, sche
 		if err != nil {
 			fmt.Println("TableHeap::UpdateTuple(): InsertTuple failed")
 			txn.SetState(ABORTED)
+			t.freeUnstoredOverflowPages(tuple_, storedTuple)
 			return false, nil
 		}
 
@@ -150,6 +270,9 @@ func (t *TableHeap) UpdateTuple(tuple_ *tuple.Tuple, update_col_idxs []int, sche
 		// change return flag to success
 		is_updated = true
 	}
+	if !is_updated {
+		t.freeUnstoredOverflowPages(tuple_, storedTuple)
+	}
 
 	// TODO: (SDB) for debugging. this code should be removed after finish of debugging
 	// last condition is for when rollback case
@@ -158,8 +281,10 @@ func (t *TableHeap) UpdateTuple(tuple_ *tuple.Tuple, update_col_idxs []int, sche
 		"illegal internal state!")
 
 	// Update the transaction's write set.
-	// when txn is ABORTED state case, data is not updated. so adding a write set entry is not needed
-	if is_updated && txn.GetState() != ABORTED {
+	// when txn is ABORTED state case, data is not updated. so adding a write set entry is not needed.
+	// when the tuple is moved, DELETE and INSERT records are already added. old overflow pages are
+	// freed by the DELETE record only
+	if is_updated && txn.GetState() != ABORTED && new_rid == nil {
 		txn.AddIntoWriteSet(NewWriteRecord(rid, UPDATE, old_tuple, t, oid))
 	}
 
@@ -176,10 +301,9 @@ func (t *TableHeap) MarkDelete(rid *page.RID, oid uint32, txn *Transaction) bool
 		common.ShPrintf(common.RDB_OP_FUNC_CALL, "TableHeap::MarkDelete called. txn.txn_id:%v rid:%v\n", txn.txn_id, *rid)
 	}
 	// Find the page which contains the tuple.
-	page_ := CastPageAsTablePage(t.bpm.FetchPage(rid.GetPageId()))
+	page_ := t.fetchTablePage(rid.GetPageId(), txn)
 	// If the page could not be found, then abort the transaction.
 	if page_ == nil {
-		txn.SetState(ABORTED)
 		return false
 	}
 	// Otherwise, mark the tuple as deleted.
@@ -195,33 +319,46 @@ func (t *TableHeap) MarkDelete(rid *page.RID, oid uint32, txn *Transaction) bool
 	return is_marked
 }
 
-func (t *TableHeap) ApplyDelete(rid *page.RID, txn *Transaction) {
+// ApplyDelete removes the tuple at rid from the page.
+// error is returned when the page can't be fetched
+func (t *TableHeap) ApplyDelete(rid *page.RID, txn *Transaction) error {
 	if common.EnableDebug {
 		common.ShPrintf(common.RDB_OP_FUNC_CALL, "TableHeap::ApplyDelete called. txn.txn_id:%v rid:%v\n", txn.txn_id, *rid)
 	}
 	// Find the page which contains the tuple.
-	page_ := CastPageAsTablePage(t.bpm.FetchPage(rid.GetPageId()))
-	common.SH_Assert(page_ != nil, "Couldn't find a page containing that RID.")
+	page_, err := t.fetchTablePageWithError(rid.GetPageId())
+	if err != nil {
+		return err
+	}
 	// Delete the tuple from the page.
 	page_.WLatch()
+	overflowPageId := page_.getOverflowPageIdAt(rid.GetSlotNum())
 	page_.ApplyDelete(rid, txn, t.log_manager)
 	//t.lock_manager.WUnlock(txn, []page.RID{*rid})
 	page_.WUnlatch()
 	t.bpm.UnpinPage(page_.GetTablePageId(), true)
+	// overflow pages are not referenced after the stub is removed
+	freeOverflowPages(t.bpm, overflowPageId)
+	return nil
 }
 
-func (t *TableHeap) RollbackDelete(rid *page.RID, txn *Transaction) {
+// RollbackDelete unsets deleted flag of the tuple at rid.
+// error is returned when the page can't be fetched
+func (t *TableHeap) RollbackDelete(rid *page.RID, txn *Transaction) error {
 	if common.EnableDebug {
 		common.ShPrintf(common.RDB_OP_FUNC_CALL, "TableHeap::RollBackDelete called. txn.txn_id:%v rid:%v\n", txn.txn_id, *rid)
 	}
 	// Find the page which contains the tuple.
-	page_ := CastPageAsTablePage(t.bpm.FetchPage(rid.GetPageId()))
-	common.SH_Assert(page_ != nil, "Couldn't find a page containing that RID.")
+	page_, err := t.fetchTablePageWithError(rid.GetPageId())
+	if err != nil {
+		return err
+	}
 	// Rollback the delete.
 	page_.WLatch()
 	page_.RollbackDelete(rid, txn, t.log_manager)
 	page_.WUnlatch()
 	t.bpm.UnpinPage(page_.GetTablePageId(), true)
+	return nil
 }
 
 // GetTuple reads a tuple from the table
@@ -233,12 +370,48 @@ func (t *TableHeap) GetTuple(rid *page.RID, txn *Transaction) *tuple.Tuple {
 		txn.SetState(ABORTED)
 		return nil
 	}
-	page := CastPageAsTablePage(t.bpm.FetchPage(rid.GetPageId()))
+	page := t.fetchTablePage(rid.GetPageId(), txn)
+	if page == nil {
+		return nil
+	}
 	defer t.bpm.UnpinPage(page.ID(), false)
 	page.RLatch()
 	ret := page.GetTuple(rid, t.log_manager, t.lock_manager, txn)
 	page.RUnlatch()
-	return ret
+	return t.fromStoredTuple(ret, txn)
+}
+
+// GetTuplesOnPage reads tuples stored on the page of this heap and returns them with id of the next page.
+// tuples marked as deleted are skipped. this is used for processing whole table page by page
+// because the iterator stops at tuple which is deleted by the caller's transaction
+func (t *TableHeap) GetTuplesOnPage(pageId types.PageID, txn *Transaction) ([]*tuple.Tuple, types.PageID, error) {
+	page_, err := t.fetchTablePageWithError(pageId)
+	if err != nil {
+		return nil, types.InvalidPageID, err
+	}
+	rids := make([]page.RID, 0)
+	page_.RLatch()
+	for slot := uint32(0); slot < page_.GetTupleCount(); slot++ {
+		if !IsDeleted(page_.GetTupleSize(slot)) {
+			rids = append(rids, page.RID{PageId: pageId, SlotNum: slot})
+		}
+	}
+	nextPageId := page_.GetNextPageId()
+	page_.RUnlatch()
+	t.bpm.UnpinPage(pageId, false)
+
+	ret := make([]*tuple.Tuple, 0, len(rids))
+	for ii := range rids {
+		tuple_ := t.GetTuple(&rids[ii], txn)
+		if tuple_ == nil {
+			if cause := txn.GetAbortCause(); cause != nil {
+				return nil, types.InvalidPageID, cause
+			}
+			return nil, types.InvalidPageID, errors.Error("tuple on page " + strconv.Itoa(int(pageId)) + " can't be read.")
+		}
+		ret = append(ret, tuple_)
+	}
+	return ret, nextPageId, nil
 }
 
 // GetFirstTuple reads the first tuple from the table
@@ -246,7 +419,10 @@ func (t *TableHeap) GetFirstTuple(txn *Transaction) *tuple.Tuple {
 	var rid *page.RID = nil
 	pageId := t.firstPageId
 	for pageId.IsValid() {
-		page := CastPageAsTablePage(t.bpm.FetchPage(pageId))
+		page := t.fetchTablePage(pageId, txn)
+		if page == nil {
+			return nil
+		}
 		page.RLatch()
 		rid = page.GetTupleFirstRID()
 		t.bpm.UnpinPage(pageId, false)
@@ -263,6 +439,39 @@ func (t *TableHeap) GetFirstTuple(txn *Transaction) *tuple.Tuple {
 	return t.GetTuple(rid, txn)
 }
 
+// fetchTablePage returns the table page. when the page can't be fetched, txn is aborted and nil is returned.
+// error of reading the page is recorded as cause of the abort
+func (t *TableHeap) fetchTablePage(pageId types.PageID, txn *Transaction) *TablePage {
+	page_, err := t.fetchTablePageWithError(pageId)
+	if err != nil {
+		txn.SetAbortCause(err)
+		return nil
+	}
+	return page_
+}
+
+// fetchTablePageWithError is same as fetchTablePage except that error is returned instead of aborting txn
+func (t *TableHeap) fetchTablePageWithError(pageId types.PageID) (*TablePage, error) {
+	pg, err := fetchPage(t.bpm, pageId)
+	if err != nil {
+		return nil, err
+	}
+	return CastPageAsTablePage(pg), nil
+}
+
+// fetchPage fetches the page. *samehada_errors.IOError is returned when read of the page fails
+// and error is also returned when no frame of buffer pool is available
+func fetchPage(bpm *buffer.BufferPoolManager, pageId types.PageID) (*page.Page, error) {
+	pg, err := bpm.FetchPageWithError(pageId)
+	if pg == nil {
+		if err == nil {
+			err = errors.Error("page " + strconv.Itoa(int(pageId)) + " can't be fetched. buffer pool is full.")
+		}
+		return nil, err
+	}
+	return pg, nil
+}
+
 // Iterator returns a iterator for this table heap
 func (t *TableHeap) Iterator(txn *Transaction) *TableHeapIterator {
 	if common.EnableDebug {
@@ -271,6 +480,56 @@ func (t *TableHeap) Iterator(txn *Transaction) *TableHeapIterator {
 	return NewTableHeapIterator(t, t.lock_manager, txn)
 }
 
+// toStoredTuple returns tuple which is stored to table page.
+// when tuple_ is larger than overflowThreshold, its data is written to overflow pages and the stub is returned
+func (t *TableHeap) toStoredTuple(tuple_ *tuple.Tuple, txn *Transaction) *tuple.Tuple {
+	if tuple_.Size() <= overflowThreshold {
+		return tuple_
+	}
+	firstPageId := writeOverflowPages(t.bpm, t.log_manager, tuple_.Data()[:tuple_.Size()], txn)
+	return tuple.NewTuple(tuple_.GetRID(), sizeOverflowStub, newOverflowStub(tuple_.Size(), firstPageId))
+}
+
+// fromStoredTuple returns original tuple when stored is stub. otherwise stored is returned as is.
+// when overflow pages can't be read, txn is aborted and nil is returned
+func (t *TableHeap) fromStoredTuple(stored *tuple.Tuple, txn *Transaction) *tuple.Tuple {
+	if stored == nil {
+		return nil
+	}
+	size, firstPageId, ok := parseOverflowStub(stored.Data()[:stored.Size()])
+	if !ok {
+		return stored
+	}
+	data, err := readOverflowPages(t.bpm, firstPageId, size)
+	if err != nil {
+		txn.SetAbortCause(err)
+		return nil
+	}
+	return tuple.NewTuple(stored.GetRID(), size, data)
+}
+
+// freeUnstoredOverflowPages frees overflow pages written by toStoredTuple when storing of the stub failed
+func (t *TableHeap) freeUnstoredOverflowPages(tuple_ *tuple.Tuple, storedTuple *tuple.Tuple) {
+	if storedTuple == tuple_ {
+		return
+	}
+	_, firstPageId, _ := parseOverflowStub(storedTuple.Data())
+	freeOverflowPages(t.bpm, firstPageId)
+}
+
+// getOverflowPageId returns first overflow page id when tuple at rid is stored in overflow pages
+func (t *TableHeap) getOverflowPageId(rid *page.RID) (types.PageID, error) {
+	page_, err := t.fetchTablePageWithError(rid.GetPageId())
+	if err != nil {
+		return types.InvalidPageID, err
+	}
+	page_.RLatch()
+	ret := page_.getOverflowPageIdAt(rid.GetSlotNum())
+	page_.RUnlatch()
+	t.bpm.UnpinPage(rid.GetPageId(), false)
+	return ret, nil
+}
+
 func (t *TableHeap) GetBufferPoolManager() *buffer.BufferPoolManager {
 	return t.bpm
 }
@@ -39,14 +39,25 @@ func (it *TableHeapIterator) End() bool {
 // or it can be in the next page
 func (it *TableHeapIterator) Next() *tuple.Tuple {
 	bpm := it.tableHeap.bpm
-	currentPage := CastPageAsTablePage(bpm.FetchPage(it.tuple.GetRID().GetPageId()))
+	currentPage := it.tableHeap.fetchTablePage(it.tuple.GetRID().GetPageId(), it.txn)
+	if currentPage == nil {
+		// transaction is aborted
+		it.tuple = nil
+		return nil
+	}
 	currentPage.RLatch()
 
 	nextTupleRID := currentPage.GetNextTupleRID(it.tuple.GetRID(), false)
 	if nextTupleRID == nil {
 		// VARIANT: currentPage is always RLatched after loop
 		for currentPage.GetNextPageId().IsValid() {
-			nextPage := CastPageAsTablePage(bpm.FetchPage(currentPage.GetNextPageId()))
+			nextPage := it.tableHeap.fetchTablePage(currentPage.GetNextPageId(), it.txn)
+			if nextPage == nil {
+				currentPage.RUnlatch()
+				bpm.UnpinPage(currentPage.GetTablePageId(), false)
+				it.tuple = nil
+				return nil
+			}
 			currentPage.RUnlatch()
 			bpm.UnpinPage(currentPage.GetTablePageId(), false)
 			currentPage = nextPage
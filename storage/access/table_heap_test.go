@@ -6,8 +6,10 @@ package access
 import (
 	"fmt"
 	"github.com/ryogrid/SamehadaDB/common"
+	"github.com/ryogrid/SamehadaDB/storage/index"
 	"github.com/ryogrid/SamehadaDB/storage/index/index_constants"
 	"math"
+	"strings"
 	"testing"
 
 	"github.com/ryogrid/SamehadaDB/recovery"
@@ -171,3 +173,126 @@ func TestTableHeapFourCol(t *testing.T) {
 
 	txn_mgr.Commit(txn)
 }
+
+func TestTableHeapOverflowPages(t *testing.T) {
+	dm := disk.NewDiskManagerTest()
+	defer dm.ShutDown()
+	log_manager := recovery.NewLogManager(&dm)
+	bpm := buffer.NewBufferPoolManager(10, dm, log_manager)
+	lock_manager := NewLockManager(STRICT, SS2PL_MODE)
+	txn_mgr := NewTransactionManager(lock_manager, log_manager)
+	txn := txn_mgr.Begin(nil)
+
+	th := NewTableHeap(bpm, log_manager, lock_manager, txn)
+
+	columnA := column.NewColumn("a", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	columnB := column.NewColumn("b", types.Text, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	schema_ := schema.NewSchema([]*column.Column{columnA, columnB})
+
+	// tuple which is larger than a page is stored in a chain of overflow pages
+	largeStr := strings.Repeat("0123456789", 1000)
+	bodies := []string{"short", largeStr, strings.Repeat("abc", 5000)}
+	rids := make([]*page.RID, 0)
+	for i, body := range bodies {
+		row := []types.Value{types.NewInteger(int32(i)), types.NewText(body)}
+		rid, err := th.InsertTuple(tuple.NewTupleFromSchema(row, schema_), txn, math.MaxUint32)
+		testingpkg.Ok(t, err)
+		rids = append(rids, rid)
+	}
+	// rest of the first page can be used because only stubs are stored in it
+	testingpkg.Equals(t, rids[0].GetPageId(), rids[2].GetPageId())
+
+	for i, rid := range rids {
+		tuple_ := th.GetTuple(rid, txn)
+		testingpkg.Equals(t, int32(i), tuple_.GetValue(schema_, 0).ToInteger())
+		testingpkg.Equals(t, bodies[i], tuple_.GetValue(schema_, 1).ToVarchar())
+	}
+
+	it := th.Iterator(txn)
+	tuple_cnt := 0
+	for tuple_ := it.Current(); !it.End(); tuple_ = it.Next() {
+		testingpkg.Equals(t, bodies[tuple_cnt], tuple_.GetValue(schema_, 1).ToVarchar())
+		tuple_cnt++
+	}
+	testingpkg.Equals(t, 3, tuple_cnt)
+
+	// update of other column keeps large value and large value can be replaced with small one
+	updateRow := []types.Value{types.NewInteger(10), types.NewText("")}
+	is_updated, _ := th.UpdateTuple(tuple.NewTupleFromSchema(updateRow, schema_), []int{0}, schema_, math.MaxUint32, *rids[1], txn)
+	testingpkg.Assert(t, is_updated, "update of the tuple failed.")
+	updated := th.GetTuple(rids[1], txn)
+	testingpkg.Equals(t, int32(10), updated.GetValue(schema_, 0).ToInteger())
+	testingpkg.Equals(t, largeStr, updated.GetValue(schema_, 1).ToVarchar())
+
+	updateRow = []types.Value{types.NewInteger(20), types.NewText("small")}
+	is_updated, _ = th.UpdateTuple(tuple.NewTupleFromSchema(updateRow, schema_), nil, nil, math.MaxUint32, *rids[2], txn)
+	testingpkg.Assert(t, is_updated, "update of the tuple failed.")
+	testingpkg.Equals(t, "small", th.GetTuple(rids[2], txn).GetValue(schema_, 1).ToVarchar())
+
+	txn_mgr.Commit(txn)
+}
+
+// rollback of tuples without indexes doesn't need catalog
+type noIndexCatalog struct{}
+
+func (c *noIndexCatalog) GetRollbackNeededIndexes(indexMap map[uint32][]index.Index, oid uint32) []index.Index {
+	return nil
+}
+
+func (c *noIndexCatalog) GetColValFromTupleForRollback(tuple_ *tuple.Tuple, colIdx uint32, oid uint32) *types.Value {
+	return nil
+}
+
+func TestTableHeapMovedTupleWithOverflowPages(t *testing.T) {
+	dm := disk.NewDiskManagerTest()
+	defer dm.ShutDown()
+	log_manager := recovery.NewLogManager(&dm)
+	bpm := buffer.NewBufferPoolManager(10, dm, log_manager)
+	lock_manager := NewLockManager(STRICT, SS2PL_MODE)
+	txn_mgr := NewTransactionManager(lock_manager, log_manager)
+	txn := txn_mgr.Begin(nil)
+
+	th := NewTableHeap(bpm, log_manager, lock_manager, txn)
+
+	columnA := column.NewColumn("a", types.Integer, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	columnB := column.NewColumn("b", types.Text, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	schema_ := schema.NewSchema([]*column.Column{columnA, columnB})
+
+	largeStr := strings.Repeat("0123456789", 1000)
+	rid, err := th.InsertTuple(tuple.NewTupleFromSchema([]types.Value{types.NewInteger(0), types.NewText(largeStr)}, schema_), txn, math.MaxUint32)
+	testingpkg.Ok(t, err)
+	// fill the first page so that the tuple is moved when it grows
+	for i := 1; ; i++ {
+		filler, err := th.InsertTuple(tuple.NewTupleFromSchema([]types.Value{types.NewInteger(int32(i)), types.NewText(strings.Repeat("f", 100))}, schema_), txn, math.MaxUint32)
+		testingpkg.Ok(t, err)
+		if filler.GetPageId() != rid.GetPageId() {
+			break
+		}
+	}
+	txn_mgr.Commit(txn)
+
+	// value which is stored inline doesn't fit to the first page
+	inlineStr := strings.Repeat("i", 1500)
+	newRow := []types.Value{types.NewInteger(0), types.NewText(inlineStr)}
+	for _, isCommit := range []bool{false, true} {
+		txn = txn_mgr.Begin(nil)
+		is_updated, new_rid := th.UpdateTuple(tuple.NewTupleFromSchema(newRow, schema_), nil, nil, math.MaxUint32, *rid, txn)
+		testingpkg.Assert(t, is_updated && new_rid != nil, "the tuple should be moved.")
+		// old overflow pages are freed only by the DELETE record
+		for _, record := range txn.GetWriteSet() {
+			testingpkg.Assert(t, record.wtype != UPDATE, "UPDATE record should not be added for moved tuple.")
+		}
+		if !isCommit {
+			txn_mgr.Abort(&noIndexCatalog{}, txn)
+			txn = txn_mgr.Begin(nil)
+			testingpkg.Equals(t, largeStr, th.GetTuple(rid, txn).GetValue(schema_, 1).ToVarchar())
+			txn_mgr.Commit(txn)
+			continue
+		}
+		txn_mgr.Commit(txn)
+		txn = txn_mgr.Begin(nil)
+		testingpkg.Assert(t, th.GetTuple(rid, txn) == nil, "old tuple should be deleted.")
+		testingpkg.Equals(t, inlineStr, th.GetTuple(new_rid, txn).GetValue(schema_, 1).ToVarchar())
+		txn_mgr.Commit(txn)
+	}
+}
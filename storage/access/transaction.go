@@ -37,6 +37,8 @@ const (
 	INSERT WType = iota
 	DELETE
 	UPDATE
+	// change of in-memory data (ex: catalog). it is finished or undone by registered functions
+	CALLBACK
 )
 
 /**
@@ -50,6 +52,9 @@ type WriteRecord struct {
 	/** The table heap specifies which table this write record is for. */
 	table *TableHeap
 	oid   uint32 // for rollback of index data
+	// called at commit and abort respectively when wtype is CALLBACK. nil is allowed
+	onCommit func()
+	onAbort  func()
 }
 
 func NewWriteRecord(rid page.RID, wtype WType, tuple *tuple.Tuple, table *TableHeap, oid uint32) *WriteRecord {
@@ -90,6 +95,10 @@ type Transaction struct {
 	shared_lock_set []page.RID
 	// /** LockManager: the set of exclusive-locked tuples held by this access. */
 	exclusive_lock_set []page.RID
+
+	// error which caused abort of this transaction (ex: read failure of a page).
+	// it is nil when the transaction is aborted without error (ex: lock conflict)
+	abort_cause error
 }
 
 func NewTransaction(txn_id types.TxnID) *Transaction {
@@ -103,6 +112,7 @@ func NewTransaction(txn_id types.TxnID) *Transaction {
 		// unordered_set<PageID>
 		make([]page.RID, 0),
 		make([]page.RID, 0),
+		nil,
 	}
 }
 
@@ -121,6 +131,13 @@ func (txn *Transaction) AddIntoWriteSet(write_record *WriteRecord) {
 	txn.write_set = append(txn.write_set, write_record)
 }
 
+// AddCallbackIntoWriteSet registers functions which finish or undo in-memory change of this transaction.
+// they are called in same order with rollback of other write records, so onAbort sees state at the time of registration
+func (txn *Transaction) AddCallbackIntoWriteSet(onCommit func(), onAbort func()) {
+	record := &WriteRecord{wtype: CALLBACK, onCommit: onCommit, onAbort: onAbort}
+	txn.write_set = append(txn.write_set, record)
+}
+
 // /** @return the set of resources under a shared lock */
 func (txn *Transaction) GetSharedLockSet() []page.RID {
 	ret := txn.shared_lock_set
@@ -168,6 +185,14 @@ func (txn *Transaction) GetState() TransactionState { return txn.state }
  */
 func (txn *Transaction) SetState(state TransactionState) { txn.state = state }
 
+// SetAbortCause sets state to ABORTED and records err as the cause of abort
+func (txn *Transaction) SetAbortCause(err error) {
+	txn.state = ABORTED
+	txn.abort_cause = err
+}
+
+func (txn *Transaction) GetAbortCause() error { return txn.abort_cause }
+
 /** @return the previous LSN */
 func (txn *Transaction) GetPrevLSN() types.LSN { return txn.prev_lsn }
 
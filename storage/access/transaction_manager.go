@@ -33,6 +33,18 @@ func NewTransactionManager(lock_manager *LockManager, log_manager *recovery.LogM
 func (transaction_manager *TransactionManager) Begin(txn *Transaction) *Transaction {
 	// Acquire the global transaction latch in shared mode.
 	transaction_manager.global_txn_latch.RLock()
+	return transaction_manager.begin(txn)
+}
+
+// BeginSystemTxn begins a transaction which is committed independently of the transaction
+// running on the calling thread (ex: advance of sequence counter).
+// the global transaction latch is not acquired because the running transaction already holds it.
+// the returned transaction must be finished with CommitSystemTxn or AbortSystemTxn
+func (transaction_manager *TransactionManager) BeginSystemTxn() *Transaction {
+	return transaction_manager.begin(nil)
+}
+
+func (transaction_manager *TransactionManager) begin(txn *Transaction) *Transaction {
 	var txn_ret *Transaction = txn
 
 	if txn_ret == nil {
@@ -56,7 +68,21 @@ func (transaction_manager *TransactionManager) Begin(txn *Transaction) *Transact
 	return txn_ret
 }
 
-func (transaction_manager *TransactionManager) Commit(txn *Transaction) {
+// Commit commits txn. the returned error is the first error which occurred on removing
+// tuples deleted by txn. txn is committed even in that case, and such tuples are left
+// as marked deleted (they are not visible)
+func (transaction_manager *TransactionManager) Commit(txn *Transaction) error {
+	err := transaction_manager.commit(txn)
+	// Release the global transaction latch.
+	transaction_manager.global_txn_latch.RUnlock()
+	return err
+}
+
+func (transaction_manager *TransactionManager) CommitSystemTxn(txn *Transaction) error {
+	return transaction_manager.commit(txn)
+}
+
+func (transaction_manager *TransactionManager) commit(txn *Transaction) (retErr error) {
 	if common.EnableDebug {
 		common.ShPrintf(common.RDB_OP_FUNC_CALL, "TransactionManager::Commit called. txn.txn_id:%v\n", txn.txn_id)
 	}
@@ -79,11 +105,18 @@ func (transaction_manager *TransactionManager) Commit(txn *Transaction) {
 		rid := item.rid
 		if item.wtype == DELETE {
 			// Note that this also releases the lock when holding the page latch.
-			pageID := rid.GetPageId()
-			tpage := CastPageAsTablePage(table.bpm.FetchPage(pageID))
-			tpage.WLatch()
-			tpage.ApplyDelete(&item.rid, txn, transaction_manager.log_manager)
-			tpage.WUnlatch()
+			if err := table.ApplyDelete(&rid, txn); err != nil && retErr == nil {
+				retErr = err
+			}
+		} else if item.wtype == UPDATE {
+			// overflow pages of old data are not referenced after commit
+			if _, overflowPageId, ok := parseOverflowStub(item.tuple.Data()); ok {
+				freeOverflowPages(table.bpm, overflowPageId)
+			}
+		} else if item.wtype == CALLBACK {
+			if item.onCommit != nil {
+				item.onCommit()
+			}
 		}
 		write_set = write_set[:len(write_set)-1]
 	}
@@ -100,11 +133,20 @@ func (transaction_manager *TransactionManager) Commit(txn *Transaction) {
 	transaction_manager.mutex.Lock()
 	transaction_manager.releaseLocks(txn)
 	transaction_manager.mutex.Unlock()
+	return retErr
+}
+
+func (transaction_manager *TransactionManager) Abort(catalog_ catalog_interface.CatalogInterface, txn *Transaction) {
+	transaction_manager.abort(catalog_, txn)
 	// Release the global transaction latch.
 	transaction_manager.global_txn_latch.RUnlock()
 }
 
-func (transaction_manager *TransactionManager) Abort(catalog_ catalog_interface.CatalogInterface, txn *Transaction) {
+func (transaction_manager *TransactionManager) AbortSystemTxn(catalog_ catalog_interface.CatalogInterface, txn *Transaction) {
+	transaction_manager.abort(catalog_, txn)
+}
+
+func (transaction_manager *TransactionManager) abort(catalog_ catalog_interface.CatalogInterface, txn *Transaction) {
 	if common.EnableDebug {
 		common.ShPrintf(common.RDB_OP_FUNC_CALL, "TransactionManager::Abort called. txn.txn_id:%v\n", txn.txn_id)
 	}
@@ -128,7 +170,12 @@ func (transaction_manager *TransactionManager) Abort(catalog_ catalog_interface.
 		table := item.table
 		if item.wtype == DELETE {
 			// rollback record data
-			table.RollbackDelete(&item.rid, txn)
+			if err := table.RollbackDelete(&item.rid, txn); err != nil {
+				// the tuple is left as marked deleted. index entry is not restored
+				recordAbortError(txn, err)
+				write_set = write_set[:len(write_set)-1]
+				continue
+			}
 			// rollback index data
 			indexes := catalog_.GetRollbackNeededIndexes(indexMap, item.oid)
 			tuple_ := item.table.GetTuple(&item.rid, txn)
@@ -140,27 +187,33 @@ func (transaction_manager *TransactionManager) Abort(catalog_ catalog_interface.
 		} else if item.wtype == INSERT {
 			insertedTuple := item.table.GetTuple(&item.rid, txn)
 			// rollback record data
-			rid := item.rid
 			// Note that this also releases the lock when holding the page latch.
-			pageID := rid.GetPageId()
-			tpage := CastPageAsTablePage(table.bpm.FetchPage(pageID))
-			tpage.WLatch()
-			tpage.ApplyDelete(&item.rid, txn, transaction_manager.log_manager)
-			tpage.WUnlatch()
+			if err := table.ApplyDelete(&item.rid, txn); err != nil {
+				recordAbortError(txn, err)
+			}
 			// rollback index data
 			indexes := catalog_.GetRollbackNeededIndexes(indexMap, item.oid)
 			for _, index_ := range indexes {
-				if index_ != nil {
+				if index_ != nil && insertedTuple != nil {
 					index_.DeleteEntry(insertedTuple, item.rid, txn)
 				}
 			}
 		} else if item.wtype == UPDATE {
 			beforRollbackTuple_ := item.table.GetTuple(&item.rid, txn)
+			overflowPageId, err := table.getOverflowPageId(&item.rid)
+			if err != nil {
+				// the page can't be read. update by txn is left as it is
+				recordAbortError(txn, err)
+				write_set = write_set[:len(write_set)-1]
+				continue
+			}
 			// rollback record data
 			is_updated, _ := table.UpdateTuple(item.tuple, nil, nil, item.oid, item.rid, txn)
 			if !is_updated {
 				panic("UpdateTuple at rollback failed!")
 			}
+			// overflow pages of data written by the transaction are not referenced after rollback
+			freeOverflowPages(table.bpm, overflowPageId)
 			// rollback index data
 			indexes := catalog_.GetRollbackNeededIndexes(indexMap, item.oid)
 			tuple_ := item.table.GetTuple(&item.rid, txn)
@@ -177,6 +230,12 @@ func (transaction_manager *TransactionManager) Abort(catalog_ catalog_interface.
 					}
 				}
 			}
+		} else if item.wtype == CALLBACK {
+			if item.onAbort != nil {
+				item.onAbort()
+			}
+			// indexes of tables may be replaced by undo of catalog change
+			indexMap = make(map[uint32][]index.Index, 0)
 		}
 		write_set = write_set[:len(write_set)-1]
 	}
@@ -192,8 +251,6 @@ func (transaction_manager *TransactionManager) Abort(catalog_ catalog_interface.
 	transaction_manager.mutex.Lock()
 	transaction_manager.releaseLocks(txn)
 	transaction_manager.mutex.Unlock()
-	// Release the global transaction latch.
-	transaction_manager.global_txn_latch.RUnlock()
 }
 
 func (transaction_manager *TransactionManager) BlockAllTransactions() {
@@ -213,3 +270,10 @@ func (transaction_manager *TransactionManager) releaseLocks(txn *Transaction) {
 	// 	transaction_manager.lock_manager.WUnlock(txn, &locked_rid)
 	// }
 }
+
+// recordAbortError records err which occurred on rollback as cause of abort when the cause is not set
+func recordAbortError(txn *Transaction, err error) {
+	if txn.GetAbortCause() == nil {
+		txn.SetAbortCause(err)
+	}
+}
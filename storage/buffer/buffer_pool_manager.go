@@ -6,9 +6,11 @@ package buffer
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 
 	"github.com/ryogrid/SamehadaDB/common"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/recovery"
 	"github.com/ryogrid/SamehadaDB/storage/disk"
 	"github.com/ryogrid/SamehadaDB/storage/page"
@@ -27,7 +29,16 @@ type BufferPoolManager struct {
 }
 
 // FetchPage fetches the requested page from the buffer pool.
+// nil is returned when no frame is available or read of the page fails.
+// use FetchPageWithError to get the cause of read failure
 func (b *BufferPoolManager) FetchPage(pageID types.PageID) *page.Page {
+	pg, _ := b.FetchPageWithError(pageID)
+	return pg
+}
+
+// FetchPageWithError is same as FetchPage except that *samehada_errors.IOError is returned
+// when read of the page from db file fails
+func (b *BufferPoolManager) FetchPageWithError(pageID types.PageID) (*page.Page, error) {
 	// if it is on buffer pool return it
 	//b.mutex.WLock()
 	b.mutex.Lock()
@@ -39,7 +50,7 @@ func (b *BufferPoolManager) FetchPage(pageID types.PageID) *page.Page {
 		if common.EnableDebug {
 			common.ShPrintf(common.DEBUG_INFO, "FetchPage: PageId=%d PinCount=%d\n", pg.GetPageId(), pg.PinCount())
 		}
-		return pg
+		return pg, nil
 	}
 
 	//b.mutex.WUnlock()
@@ -48,7 +59,7 @@ func (b *BufferPoolManager) FetchPage(pageID types.PageID) *page.Page {
 	//b.mutex.WLock()
 	if frameID == nil {
 		b.mutex.Unlock()
-		return nil
+		return nil, nil
 	}
 
 	if !isFromFreeList {
@@ -78,9 +89,11 @@ func (b *BufferPoolManager) FetchPage(pageID types.PageID) *page.Page {
 	data := make([]byte, common.PageSize)
 	err := b.diskManager.ReadPage(pageID, data)
 	if err != nil {
-		fmt.Println(err)
-		panic("ReadPage returned error!")
-		//return nil
+		// the frame is empty because the page in it was evicted above
+		b.pages[*frameID] = nil
+		b.freeList = append(b.freeList, *frameID)
+		b.mutex.Unlock()
+		return nil, samehada_errors.NewIOError("read of page "+strconv.Itoa(int(pageID)), err)
 	}
 	var pageData [common.PageSize]byte
 	copy(pageData[:], data)
@@ -92,7 +105,7 @@ func (b *BufferPoolManager) FetchPage(pageID types.PageID) *page.Page {
 	if common.EnableDebug {
 		common.ShPrintf(common.DEBUG_INFO, "FetchPage: PageId=%d PinCount=%d\n", pg.GetPageId(), pg.PinCount())
 	}
-	return pg
+	return pg, nil
 }
 
 // UnpinPage unpins the target page from the buffer pool.
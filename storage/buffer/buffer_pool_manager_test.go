@@ -5,9 +5,11 @@ package buffer
 
 import (
 	"crypto/rand"
+	"errors"
 	"testing"
 
 	"github.com/ryogrid/SamehadaDB/common"
+	samehada_errors "github.com/ryogrid/SamehadaDB/errors"
 	"github.com/ryogrid/SamehadaDB/recovery"
 	"github.com/ryogrid/SamehadaDB/storage/disk"
 	"github.com/ryogrid/SamehadaDB/storage/page"
@@ -130,3 +132,36 @@ func TestSample(t *testing.T) {
 	dm.ShutDown()
 	common.TempSuppressOnMemStorageMutex.Unlock()
 }
+
+func TestFetchPageReadError(t *testing.T) {
+	common.TempSuppressOnMemStorageMutex.Lock()
+	common.TempSuppressOnMemStorage = true
+
+	poolSize := uint32(2)
+
+	dm := disk.NewDiskManagerTest()
+	bpm := NewBufferPoolManager(poolSize, dm, recovery.NewLogManager(&dm))
+
+	page0 := bpm.NewPage()
+	page0.Copy(0, []byte("Hello"))
+	testingpkg.Ok(t, bpm.UnpinPage(page0.ID(), true))
+	bpm.FlushPage(page0.ID())
+
+	// Scenario: Read of a page which is not in db file fails with IOError and the frame is not lost.
+	for i := uint32(0); i < poolSize+1; i++ {
+		pg, err := bpm.FetchPageWithError(types.PageID(100))
+		var ioErr *samehada_errors.IOError
+		testingpkg.Assert(t, pg == nil && errors.As(err, &ioErr), "IOError should be returned")
+		testingpkg.Equals(t, (*page.Page)(nil), bpm.FetchPage(types.PageID(100)))
+	}
+
+	// Scenario: The buffer pool can be used after the failures.
+	page0 = bpm.FetchPage(types.PageID(0))
+	testingpkg.Equals(t, [common.PageSize]byte{'H', 'e', 'l', 'l', 'o'}, *page0.Data())
+	testingpkg.Ok(t, bpm.UnpinPage(types.PageID(0), false))
+	testingpkg.Equals(t, types.PageID(1), bpm.NewPage().ID())
+
+	common.TempSuppressOnMemStorage = false
+	dm.ShutDown()
+	common.TempSuppressOnMemStorageMutex.Unlock()
+}
@@ -127,8 +127,7 @@ func (d *DiskManagerImpl) ReadPage(pageID types.PageID, pageData []byte) error {
 
 	fileInfo, err := d.db.Stat()
 	if err != nil {
-		fmt.Println(err)
-		return errors.New("file info error")
+		return errors.New("file info error: " + err.Error())
 	}
 
 	if offset > fileInfo.Size() {
@@ -139,7 +138,7 @@ func (d *DiskManagerImpl) ReadPage(pageID types.PageID, pageData []byte) error {
 
 	bytesRead, err := d.db.Read(pageData)
 	if err != nil {
-		return errors.New("I/O error while reading")
+		return errors.New("I/O error while reading: " + err.Error())
 	}
 
 	if bytesRead < common.PageSize {
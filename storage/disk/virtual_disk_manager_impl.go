@@ -2,7 +2,6 @@ package disk
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 	"sync"
 
@@ -79,10 +78,9 @@ func (d *VirtualDiskManagerImpl) ReadPage(pageID types.PageID, pageData []byte)
 
 	_, err := d.db.ReadAt(pageData, offset)
 	if err != nil {
-		fmt.Println(err)
-		panic("file read error!")
+		return errors.New("I/O error while reading: " + err.Error())
 	}
-	return err
+	return nil
 }
 
 // AllocatePage allocates a new page
@@ -97,12 +97,18 @@ type Index interface {
 	// Point Modification
 	///////////////////////////////////////////////////////////////////
 	// designed for secondary indexes.
+	// when read of a page of the index fails, the transaction passed as last arg is aborted
+	// with *samehada_errors.IOError as the cause (ScanKey returns no RID in that case)
 	InsertEntry(*tuple.Tuple, page.RID, interface{})
+	// insert index entries of many tuples at once. keys and rids should have same length
+	BulkInsertEntries([]*tuple.Tuple, []page.RID, interface{})
 	// delete the index entry linked to given tuple
 	DeleteEntry(*tuple.Tuple, page.RID, interface{})
 	ScanKey(*tuple.Tuple, interface{}) []page.RID
 	// pass start key and end key. nil is also ok.
 	GetRangeScanIterator(*tuple.Tuple, *tuple.Tuple, interface{}) IndexRangeScanIterator
+	// free all pages used by the index. the index must not be used after calling this
+	FreeAllPages()
 
 	/*
 	      // Get a string representation for debugging
@@ -115,3 +121,19 @@ type Index interface {
 	      }
 	*/
 }
+
+// abortCauseSetter is implemented by *access.Transaction.
+// access package can't be imported here because it depends on this package
+type abortCauseSetter interface {
+	SetAbortCause(err error)
+}
+
+// abortTxn aborts transaction with err as the cause.
+// when transaction is not passed, err is raised with panic
+func abortTxn(transaction interface{}, err error) {
+	if txn, ok := transaction.(abortCauseSetter); ok {
+		txn.SetAbortCause(err)
+		return
+	}
+	panic(err)
+}
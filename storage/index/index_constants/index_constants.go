@@ -6,4 +6,6 @@ const (
 	INDEX_KIND_INVAID IndexKind = iota
 	INDEX_KIND_SKIP_LIST
 	INDEX_KIND_HASH
+	// SkipList index whose keys can be duplicated. it is used only for point scan
+	INDEX_KIND_NON_UNIQUE_SKIP_LIST
 )
@@ -46,21 +46,35 @@ func (htidx *LinearProbeHashTableIndex) InsertEntry(key *tuple.Tuple, rid page.R
 	tupleSchema_ := htidx.GetTupleSchema()
 	keyDataInBytes := key.GetValueInBytes(tupleSchema_, htidx.col_idx)
 
-	htidx.container.Insert(keyDataInBytes, samehada_util.PackRIDtoUint32(&rid))
+	if err := htidx.container.Insert(keyDataInBytes, samehada_util.PackRIDtoUint32(&rid)); err != nil && err != hash.ErrDuplicatedValue {
+		abortTxn(transaction, err)
+	}
+}
+
+func (htidx *LinearProbeHashTableIndex) BulkInsertEntries(keys []*tuple.Tuple, rids []page.RID, transaction interface{}) {
+	for idx, key := range keys {
+		htidx.InsertEntry(key, rids[idx], transaction)
+	}
 }
 
 func (htidx *LinearProbeHashTableIndex) DeleteEntry(key *tuple.Tuple, rid page.RID, transaction interface{}) {
 	tupleSchema_ := htidx.GetTupleSchema()
 	keyDataInBytes := key.GetValueInBytes(tupleSchema_, htidx.col_idx)
 
-	htidx.container.Remove(keyDataInBytes, samehada_util.PackRIDtoUint32(&rid))
+	if err := htidx.container.Remove(keyDataInBytes, samehada_util.PackRIDtoUint32(&rid)); err != nil {
+		abortTxn(transaction, err)
+	}
 }
 
 func (htidx *LinearProbeHashTableIndex) ScanKey(key *tuple.Tuple, transaction interface{}) []page.RID {
 	tupleSchema_ := htidx.GetTupleSchema()
 	keyDataInBytes := key.GetValueInBytes(tupleSchema_, htidx.col_idx)
 
-	packed_values := htidx.container.GetValue(keyDataInBytes)
+	packed_values, err := htidx.container.GetValue(keyDataInBytes)
+	if err != nil {
+		abortTxn(transaction, err)
+		return nil
+	}
 	var ret_arr []page.RID
 	for _, packed_val := range packed_values {
 		ret_arr = append(ret_arr, samehada_util.UnpackUint32toRID(packed_val))
@@ -72,6 +86,10 @@ func (htidx *LinearProbeHashTableIndex) GetRangeScanIterator(startkey *tuple.Tup
 	return nil
 }
 
+func (htidx *LinearProbeHashTableIndex) FreeAllPages() {
+	htidx.container.FreeAllPages()
+}
+
 func (htidx *LinearProbeHashTableIndex) GetHeaderPageId() types.PageID {
 	return htidx.container.GetHeaderPageId()
 }
@@ -1,6 +1,7 @@
 package index
 
 import (
+	"encoding/binary"
 	"github.com/ryogrid/SamehadaDB/container/skip_list"
 	"github.com/ryogrid/SamehadaDB/samehada/samehada_util"
 	"github.com/ryogrid/SamehadaDB/storage/buffer"
@@ -9,6 +10,7 @@ import (
 	"github.com/ryogrid/SamehadaDB/storage/tuple"
 	"github.com/ryogrid/SamehadaDB/types"
 	"math"
+	"sort"
 )
 
 type SkipListIndex struct {
@@ -16,6 +18,8 @@ type SkipListIndex struct {
 	metadata  *IndexMetadata
 	// idx of target column on table
 	col_idx uint32
+	// when true, RID is appended to key of entries so that same key can be stored many times
+	isNonUnique bool
 }
 
 func NewSkipListIndex(metadata *IndexMetadata, buffer_pool_manager *buffer.BufferPoolManager, col_idx uint32) *SkipListIndex {
@@ -26,18 +30,75 @@ func NewSkipListIndex(metadata *IndexMetadata, buffer_pool_manager *buffer.Buffe
 	return ret
 }
 
+// NewNonUniqueSkipListIndex returns SkipList index which allows duplicated keys.
+// keys of entries are Varchar values which consist of serialized key and RID
+func NewNonUniqueSkipListIndex(metadata *IndexMetadata, buffer_pool_manager *buffer.BufferPoolManager, col_idx uint32) *SkipListIndex {
+	ret := new(SkipListIndex)
+	ret.metadata = metadata
+	ret.container = *skip_list.NewSkipList(buffer_pool_manager, types.Varchar)
+	ret.col_idx = col_idx
+	ret.isNonUnique = true
+	return ret
+}
+
+// entryKey returns key of the entry which is stored to the container
+func (slidx *SkipListIndex) entryKey(keyVal types.Value, packedRID uint32) types.Value {
+	if !slidx.isNonUnique {
+		return keyVal
+	}
+	return nonUniqueEntryKey(keyVal, packedRID)
+}
+
+// nonUniqueEntryKey returns serialized keyVal followed by packedRID in big endian.
+// entries which have same keyVal are placed in a row because the serialized keys have same length
+func nonUniqueEntryKey(keyVal types.Value, packedRID uint32) types.Value {
+	ridBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(ridBytes, packedRID)
+	return types.NewVarchar(string(append(keyVal.Serialize(), ridBytes...)))
+}
+
 func (slidx *SkipListIndex) InsertEntry(key *tuple.Tuple, rid page.RID, transaction interface{}) {
 	tupleSchema_ := slidx.GetTupleSchema()
-	keyVal := key.GetValue(tupleSchema_, slidx.col_idx)
+	packedRID := samehada_util.PackRIDtoUint32(&rid)
+	keyVal := slidx.entryKey(key.GetValue(tupleSchema_, slidx.col_idx), packedRID)
 
-	slidx.container.Insert(&keyVal, samehada_util.PackRIDtoUint32(&rid))
+	if err := slidx.container.Insert(&keyVal, packedRID); err != nil {
+		abortTxn(transaction, err)
+	}
+}
+
+// entries are inserted in key order. it keeps accesses to pages of the skip list local
+func (slidx *SkipListIndex) BulkInsertEntries(keys []*tuple.Tuple, rids []page.RID, transaction interface{}) {
+	tupleSchema_ := slidx.GetTupleSchema()
+	keyVals := make([]types.Value, len(keys))
+	order := make([]int, len(keys))
+	for idx, key := range keys {
+		keyVals[idx] = slidx.entryKey(key.GetValue(tupleSchema_, slidx.col_idx), samehada_util.PackRIDtoUint32(&rids[idx]))
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(ii, jj int) bool {
+		left, right := keyVals[order[ii]], keyVals[order[jj]]
+		if left.IsNull() || right.IsNull() {
+			return left.IsNull() && !right.IsNull()
+		}
+		return left.CompareLessThan(right)
+	})
+	for _, idx := range order {
+		if err := slidx.container.Insert(&keyVals[idx], samehada_util.PackRIDtoUint32(&rids[idx])); err != nil {
+			abortTxn(transaction, err)
+			return
+		}
+	}
 }
 
 func (slidx *SkipListIndex) DeleteEntry(key *tuple.Tuple, rid page.RID, transaction interface{}) {
 	tupleSchema_ := slidx.GetTupleSchema()
-	keyVal := key.GetValue(tupleSchema_, slidx.col_idx)
+	packedRID := samehada_util.PackRIDtoUint32(&rid)
+	keyVal := slidx.entryKey(key.GetValue(tupleSchema_, slidx.col_idx), packedRID)
 
-	slidx.container.Remove(&keyVal, samehada_util.PackRIDtoUint32(&rid))
+	if _, err := slidx.container.Remove(&keyVal, packedRID); err != nil {
+		abortTxn(transaction, err)
+	}
 }
 
 func (slidx *SkipListIndex) ScanKey(key *tuple.Tuple, transaction interface{}) []page.RID {
@@ -45,7 +106,29 @@ func (slidx *SkipListIndex) ScanKey(key *tuple.Tuple, transaction interface{}) [
 	keyVal := key.GetValue(tupleSchema_, slidx.col_idx)
 
 	ret_arr := make([]page.RID, 0)
-	packed_value := slidx.container.GetValue(&keyVal)
+	if slidx.isNonUnique {
+		// all entries of keyVal are between the keys which have minimum and maximum RID
+		startKey := nonUniqueEntryKey(keyVal, 0)
+		endKey := nonUniqueEntryKey(keyVal, math.MaxUint32)
+		itr := slidx.container.Iterator(&startKey, &endKey)
+		for {
+			done, err, _, rid := itr.Next()
+			if err != nil {
+				abortTxn(transaction, err)
+				return make([]page.RID, 0)
+			}
+			if done {
+				break
+			}
+			ret_arr = append(ret_arr, *rid)
+		}
+		return ret_arr
+	}
+	packed_value, err := slidx.container.GetValue(&keyVal)
+	if err != nil {
+		abortTxn(transaction, err)
+		return ret_arr
+	}
 	if packed_value != math.MaxUint32 {
 		// when packed_vale == math.MaxUint32 => true, keyVal is not found on index
 		ret_arr = append(ret_arr, samehada_util.UnpackUint32toRID(packed_value))
@@ -57,6 +140,9 @@ func (slidx *SkipListIndex) ScanKey(key *tuple.Tuple, transaction interface{}) [
 // and iterates specified key range.
 // when start_key arg is nil , start point is head of entry list. when end_key, end point is tail of the list
 func (slidx *SkipListIndex) GetRangeScanIterator(start_key *tuple.Tuple, end_key *tuple.Tuple, transaction interface{}) IndexRangeScanIterator {
+	if slidx.isNonUnique {
+		panic("range scan is not supported on non-unique SkipList index.")
+	}
 	tupleSchema_ := slidx.GetTupleSchema()
 	var start_val *types.Value = nil
 	if start_key != nil {
@@ -86,6 +172,10 @@ func (slidx *SkipListIndex) GetTupleSchema() *schema.Schema {
 
 func (slidx *SkipListIndex) GetKeyAttrs() []uint32 { return slidx.metadata.GetKeyAttrs() }
 
+func (slidx *SkipListIndex) FreeAllPages() {
+	slidx.container.FreeAllPages()
+}
+
 func (slidx *SkipListIndex) GetHeaderPageId() types.PageID {
 	return slidx.container.GetHeaderPageId()
 }
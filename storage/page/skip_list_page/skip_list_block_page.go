@@ -261,8 +261,9 @@ func (node *SkipListBlockPage) getSplitIdxForNotFixed() (splitIdx_ int32, isNeed
 
 // Attempts to insert a key and value into an index in the baccess
 // return value is whether newNode is created or not
+// when err is not nil, read of a node failed and lock and pin of this node is already released
 func (node *SkipListBlockPage) Insert(key *types.Value, value uint32, bpm *buffer.BufferPoolManager, corners []SkipListCornerInfo,
-	level int32) (isNeedRetry_ bool) {
+	level int32) (isNeedRetry_ bool, err error) {
 	if common.EnableDebug {
 		common.ShPrintf(common.DEBUG_INFO, "Insert of SkipListBlockPage called! : key=%v\n", key.ToIFValue())
 	}
@@ -299,7 +300,7 @@ func (node *SkipListBlockPage) Insert(key *types.Value, value uint32, bpm *buffe
 		if common.EnableDebug {
 			common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Insert: finish (replace). key=%v\n", key.ToIFValue())
 		}
-		return false
+		return false, nil
 	} else { // !found
 		//fmt.Printf("not found at Insert of SkipListBlockPage. foundIdx=%d\n", foundIdx)
 		if node.getFreeSpaceRemaining() < node.GetSpecifiedSLPNeedSpace(&SkipListPair{*key, value}) {
@@ -325,14 +326,14 @@ func (node *SkipListBlockPage) Insert(key *types.Value, value uint32, bpm *buffe
 				corners[0] = SkipListCornerInfo{node.GetPageId(), node.GetLSN()}
 
 				node.WUnlatch()
-				isSuccess, lockedAndPinnedNodes = validateNoChangeAndGetLock(bpm, corners[:level], nil)
+				isSuccess, lockedAndPinnedNodes, err = validateNoChangeAndGetLock(bpm, corners[:level], nil)
 				if !isSuccess {
 					bpm.UnpinPage(node.GetPageId(), false)
 					// already released lock of this node
 					if common.EnableDebug {
 						common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Insert: finish (validation NG). key=%v\n", key.ToIFValue())
 					}
-					return true
+					return err == nil, err
 				} else {
 					//node.DecPinCount()
 					bpm.DecPinOfPage(node)
@@ -364,7 +365,7 @@ func (node *SkipListBlockPage) Insert(key *types.Value, value uint32, bpm *buffe
 					if common.EnableDebug {
 						common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Insert: finish (split & insert to new node). key=%v\n", key.ToIFValue())
 					}
-					return false
+					return false, nil
 				} else {
 					// insert to this node
 					// foundIdx is index of nearlest smaller key entry
@@ -391,7 +392,7 @@ func (node *SkipListBlockPage) Insert(key *types.Value, value uint32, bpm *buffe
 						common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Insert: finish (new ently only split). key=%v\n", key.ToIFValue())
 					}
 
-					return false
+					return false, nil
 				}
 			} else {
 				// new entry only inserted new node
@@ -400,7 +401,7 @@ func (node *SkipListBlockPage) Insert(key *types.Value, value uint32, bpm *buffe
 				corners[0] = SkipListCornerInfo{node.GetPageId(), node.GetLSN()}
 
 				node.WUnlatch()
-				isSuccess, lockedAndPinnedNodes = validateNoChangeAndGetLock(bpm, corners[:level], nil)
+				isSuccess, lockedAndPinnedNodes, err = validateNoChangeAndGetLock(bpm, corners[:level], nil)
 				//bpm.UnpinPage(node.GetPageId(), false)
 				//node.DecPinCount()
 				bpm.DecPinOfPage(node)
@@ -409,7 +410,7 @@ func (node *SkipListBlockPage) Insert(key *types.Value, value uint32, bpm *buffe
 					if common.EnableDebug {
 						common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Insert: finish (validation NG). key=%v\n", key.ToIFValue())
 					}
-					return true
+					return err == nil, err
 				}
 
 				//corners[0] = SkipListCornerInfo{node.GetPageId(), node.GetLSN()}
@@ -428,7 +429,7 @@ func (node *SkipListBlockPage) Insert(key *types.Value, value uint32, bpm *buffe
 				if common.EnableDebug {
 					common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Insert: finish (new node without split). key=%v\n", key.ToIFValue())
 				}
-				return false
+				return false, nil
 			}
 		} else {
 			// no split
@@ -444,7 +445,7 @@ func (node *SkipListBlockPage) Insert(key *types.Value, value uint32, bpm *buffe
 			if common.EnableDebug {
 				common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Insert: finish (no split). key=%v\n", key.ToIFValue())
 			}
-			return false
+			return false, nil
 		}
 	}
 }
@@ -504,18 +505,20 @@ func (node *SkipListBlockPage) RemoveInner(idx int) {
 // => nodes on lockedAndPinnedNodes (= checkNodes) are locked and pinned
 // isSuccess == false
 // => lockedAndPinnedNodes is nil and nodes on lockedAndPinnedNodes (= checkNodes) are unlocked and unpinned
-func validateNoChangeAndGetLock(bpm *buffer.BufferPoolManager, checkNodes []SkipListCornerInfo, additonalCheckNode *SkipListCornerInfo) (isSuccess bool, lockedAndPinnedNodes []*SkipListBlockPage) {
+// err != nil
+// => read of a node failed. isSuccess is false and retry should not be done
+func validateNoChangeAndGetLock(bpm *buffer.BufferPoolManager, checkNodes []SkipListCornerInfo, additonalCheckNode *SkipListCornerInfo) (isSuccess bool, lockedAndPinnedNodes []*SkipListBlockPage, err error) {
 	common.ShPrintf(common.DEBUG_INFO, "validateNoChangeAndGetLock: start. len(checkNodes)=%d\n", len(checkNodes))
 	checkLen := len(checkNodes)
 	validatedNodes := make([]*SkipListBlockPage, 0)
 	prevPageId := types.InvalidPageID
 	for ii := checkLen - 1; ii >= 0; ii-- {
-		node := FetchAndCastToBlockPage(bpm, checkNodes[ii].PageId)
+		node, err := FetchAndCastToBlockPageWithError(bpm, checkNodes[ii].PageId)
 		isPassed := false
 		if node == nil {
 			common.ShPrintf(common.DEBUG_INFO, "validateNoChangeAndGetLock: validation failed. go retry.\n")
 			unlockAndUnpinNodes(bpm, validatedNodes, false)
-			return false, nil
+			return false, nil, err
 		}
 
 		// check whether update counter is not changed
@@ -540,7 +543,7 @@ func validateNoChangeAndGetLock(bpm *buffer.BufferPoolManager, checkNodes []Skip
 		if node.GetLSN() != checkNodes[ii].UpdateCounter {
 			common.ShPrintf(common.DEBUG_INFO, "validateNoChangeAndGetLock: validation is NG: go retry. len(validatedNodes)=%d\n", len(validatedNodes))
 			unlockAndUnpinNodes(bpm, validatedNodes, false)
-			return false, nil
+			return false, nil, nil
 		}
 
 		prevPageId = checkNodes[ii].PageId
@@ -548,11 +551,11 @@ func validateNoChangeAndGetLock(bpm *buffer.BufferPoolManager, checkNodes []Skip
 
 	// additionalCheckNode is remove node at Remove method currently
 	if additonalCheckNode != nil {
-		node := FetchAndCastToBlockPage(bpm, additonalCheckNode.PageId)
+		node, err := FetchAndCastToBlockPageWithError(bpm, additonalCheckNode.PageId)
 		if node == nil {
 			common.ShPrintf(common.DEBUG_INFO, "validateNoChangeAndGetLock: additionalCheckNode validation failed. go retry.\n")
 			unlockAndUnpinNodes(bpm, validatedNodes, false)
-			return false, nil
+			return false, nil, err
 		}
 		node.WLatch()
 		if node.GetLSN() != additonalCheckNode.UpdateCounter {
@@ -563,7 +566,7 @@ func validateNoChangeAndGetLock(bpm *buffer.BufferPoolManager, checkNodes []Skip
 			bpm.UnpinPage(node.GetPageId(), true)
 			node.WUnlatch()
 			unlockAndUnpinNodes(bpm, validatedNodes, false)
-			return false, nil
+			return false, nil, nil
 		}
 		//bpm.UnpinPage(node.GetPageId(), true)
 		validatedNodes = append(validatedNodes, node)
@@ -571,7 +574,7 @@ func validateNoChangeAndGetLock(bpm *buffer.BufferPoolManager, checkNodes []Skip
 
 	common.ShPrintf(common.DEBUG_INFO, "validateNoChangeAndGetLock: finish. len(validatedNodes)=%d\n", len(validatedNodes))
 	// validation is passed
-	return true, validatedNodes
+	return true, validatedNodes, nil
 }
 
 func unlockAndUnpinNodes(bpm *buffer.BufferPoolManager, checkedNodes []*SkipListBlockPage, isDirty bool) {
@@ -584,7 +587,8 @@ func unlockAndUnpinNodes(bpm *buffer.BufferPoolManager, checkedNodes []*SkipList
 	common.ShPrintf(common.DEBUG_INFO, "unlockAndUnpinNodes: finished. len(checkNodes)=%d\n", len(checkedNodes))
 }
 
-func (node *SkipListBlockPage) Remove(bpm *buffer.BufferPoolManager, key *types.Value, predOfCorners []SkipListCornerInfo, corners []SkipListCornerInfo) (isNodeShouldBeDeleted bool, isDeleted bool, isNeedRetry bool) {
+// when err is not nil, read of a node failed and lock and pin of this node is already released
+func (node *SkipListBlockPage) Remove(bpm *buffer.BufferPoolManager, key *types.Value, predOfCorners []SkipListCornerInfo, corners []SkipListCornerInfo) (isNodeShouldBeDeleted bool, isDeleted bool, isNeedRetry bool, err error) {
 	if common.EnableDebug {
 		common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Remove: start. key=%v\n", key.ToIFValue())
 	}
@@ -604,7 +608,7 @@ func (node *SkipListBlockPage) Remove(bpm *buffer.BufferPoolManager, key *types.
 		// check of thid node is also needed
 		additionalCheckNode := &SkipListCornerInfo{node.GetPageId(), node.GetLSN()}
 		node.WUnlatch()
-		isSuccess, lockedAndPinnedNodes := validateNoChangeAndGetLock(bpm, checkNodes, additionalCheckNode)
+		isSuccess, lockedAndPinnedNodes, err := validateNoChangeAndGetLock(bpm, checkNodes, additionalCheckNode)
 		//bpm.UnpinPage(node.GetPageId(), true)
 		//node.DecPinCount()
 		bpm.DecPinOfPage(node)
@@ -614,7 +618,7 @@ func (node *SkipListBlockPage) Remove(bpm *buffer.BufferPoolManager, key *types.
 			//// because WUnlatch is already called once before validateNoChangeAndGetLock func call, but  pin is not released
 			//bpm.UnpinPage(node.GetPageId(), true)
 
-			return false, false, true
+			return false, false, err == nil, err
 		}
 
 		// removing this node from all level of chain
@@ -645,7 +649,7 @@ func (node *SkipListBlockPage) Remove(bpm *buffer.BufferPoolManager, key *types.
 		//// because WUnlatch is already called once before validateNoChangeAndGetLock func call, but  pin is not released ???
 		//bpm.UnpinPage(node.GetPageId(), true)
 
-		return true, true, false
+		return true, true, false, nil
 	} else if found {
 		if !node.GetEntry(int(foundIdx), key.ValueType()).Key.CompareEquals(*key) {
 			panic("removing wrong entry!")
@@ -659,7 +663,7 @@ func (node *SkipListBlockPage) Remove(bpm *buffer.BufferPoolManager, key *types.
 		if common.EnableDebug {
 			common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Remove: finished (found). key=%v\n", key.ToIFValue())
 		}
-		return false, true, false
+		return false, true, false, nil
 	} else { // found == false
 		bpm.UnpinPage(node.GetPageId(), true)
 		node.WUnlatch()
@@ -667,7 +671,7 @@ func (node *SkipListBlockPage) Remove(bpm *buffer.BufferPoolManager, key *types.
 		if common.EnableDebug {
 			common.ShPrintf(common.DEBUG_INFO, "SkipListBlockPage::Remove: finished (not found). key=%v\n", key.ToIFValue())
 		}
-		return false, false, false
+		return false, false, false, nil
 	}
 }
 
@@ -958,13 +962,24 @@ func (node *SkipListBlockPage) getFreeSpaceRemaining() uint32 {
 //
 //	caller must call UnpinPage with appropriate diaty page to the got page when page using ends
 func FetchAndCastToBlockPage(bpm *buffer.BufferPoolManager, pageId types.PageID) *SkipListBlockPage {
-	bPage := bpm.FetchPage(pageId)
+	bPage, _ := FetchAndCastToBlockPageWithError(bpm, pageId)
+	return bPage
+}
+
+// FetchAndCastToBlockPageWithError is same as FetchAndCastToBlockPage except that
+// *samehada_errors.IOError is returned when read of the page from db file fails
+//
+// Attention:
+//
+//	caller must call UnpinPage with appropriate diaty page to the got page when page using ends
+func FetchAndCastToBlockPageWithError(bpm *buffer.BufferPoolManager, pageId types.PageID) (*SkipListBlockPage, error) {
+	bPage, err := bpm.FetchPageWithError(pageId)
 	if bPage == nil {
-		// target page is physically removed (deallocated)
-		return nil
+		// target page is physically removed (deallocated) or read of the page failed
+		return nil, err
 	}
 	if common.EnableDebug {
 		common.ShPrintf(common.DEBUG_INFO, "FetchAndCastToBlockPage: PageId=%d PinCount=%d\n", bPage.GetPageId(), bPage.PinCount())
 	}
-	return (*SkipListBlockPage)(unsafe.Pointer(bPage))
+	return (*SkipListBlockPage)(unsafe.Pointer(bPage)), nil
 }
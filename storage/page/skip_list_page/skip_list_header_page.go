@@ -7,6 +7,8 @@ import (
 	"github.com/ryogrid/SamehadaDB/storage/page"
 	"github.com/ryogrid/SamehadaDB/types"
 	"math"
+	"math/big"
+	"time"
 	"unsafe"
 )
 
@@ -50,6 +52,21 @@ func NewSkipListStartBlockPage(bpm *buffer.BufferPoolManager, keyType types.Type
 	switch keyType {
 	case types.Integer:
 		startNode = NewSkipListBlockPage(bpm, MAX_FOWARD_LIST_LEN, SkipListPair{types.NewInteger(math.MinInt32), 0})
+	case types.Tinyint, types.Smallint, types.BigInt:
+		v := types.NewIntegerOfType(keyType, 0)
+		v.SetInfMin()
+		startNode = NewSkipListBlockPage(bpm, MAX_FOWARD_LIST_LEN, SkipListPair{v, 0})
+	case types.Decimal:
+		v := types.NewDecimal(big.NewInt(0), 0)
+		v.SetInfMin()
+		startNode = NewSkipListBlockPage(bpm, MAX_FOWARD_LIST_LEN, SkipListPair{v, 0})
+	case types.Timestamp, types.Date:
+		v := types.NewTimestamp(time.Unix(0, 0))
+		if keyType == types.Date {
+			v = types.NewDate(time.Unix(0, 0))
+		}
+		v.SetInfMin()
+		startNode = NewSkipListBlockPage(bpm, MAX_FOWARD_LIST_LEN, SkipListPair{v, 0})
 	case types.Float:
 		startNode = NewSkipListBlockPage(bpm, MAX_FOWARD_LIST_LEN, SkipListPair{types.NewFloat(math.SmallestNonzeroFloat32), 0})
 	case types.Varchar:
@@ -66,6 +83,21 @@ func NewSkipListStartBlockPage(bpm *buffer.BufferPoolManager, keyType types.Type
 		pl := SkipListPair{types.NewInteger(0), 0}
 		pl.Key = *pl.Key.SetInfMax()
 		sentinelNode = NewSkipListBlockPage(bpm, MAX_FOWARD_LIST_LEN, pl)
+	case types.Tinyint, types.Smallint, types.BigInt:
+		pl := SkipListPair{types.NewIntegerOfType(keyType, 0), 0}
+		pl.Key = *pl.Key.SetInfMax()
+		sentinelNode = NewSkipListBlockPage(bpm, MAX_FOWARD_LIST_LEN, pl)
+	case types.Decimal:
+		pl := SkipListPair{types.NewDecimal(big.NewInt(0), 0), 0}
+		pl.Key = *pl.Key.SetInfMax()
+		sentinelNode = NewSkipListBlockPage(bpm, MAX_FOWARD_LIST_LEN, pl)
+	case types.Timestamp, types.Date:
+		pl := SkipListPair{types.NewTimestamp(time.Unix(0, 0)), 0}
+		if keyType == types.Date {
+			pl.Key = types.NewDate(time.Unix(0, 0))
+		}
+		pl.Key = *pl.Key.SetInfMax()
+		sentinelNode = NewSkipListBlockPage(bpm, MAX_FOWARD_LIST_LEN, pl)
 	case types.Float:
 		pl := SkipListPair{types.NewFloat(0), 0}
 		pl.Key = *pl.Key.SetInfMax()
@@ -18,22 +18,39 @@ type Column struct {
 	indexKind         index_constants.IndexKind
 	indexHeaderPageId types.PageID
 	isLeft            bool // when temporal schema, this is used for join
+	isPrimaryKey      bool // PRIMARY KEY constraint (implies isUnique)
+	isUnique          bool // UNIQUE constraint. checked with index of the column
+	isNotNull         bool
+	defaultValue      *types.Value // nil if the column has no DEFAULT value
+	foreignKey        *ForeignKey  // nil if the column does not reference other table
+	generatedExprStr  string       // SQL text of generation expression. empty if the column is not generated column
+	checkExprStr      string       // SQL text of CHECK constraint. empty if the column has no CHECK constraint
+	autoIncrementSeq  string       // name of sequence which generates values. empty if the column is not AUTO_INCREMENT
+	defaultSeq        string       // name of sequence whose NEXTVAL is DEFAULT value. empty if DEFAULT is not NEXTVAL
+	decimalPrecision  int32        // max number of digits of DECIMAL column. 0 on other types
+	decimalScale      int32        // number of digits after the point of DECIMAL column
+	// compiled CHECK constraint. should be pointer of subtype of expression.Expression
+	checkExpr interface{}
 	// should be pointer of subtype of expression.Expression
-	// this member is used and needed at temporarily created table (schema) on query execution
+	// this member is used and needed at temporarily created table (schema) on query execution.
+	// on schema of table, this is compiled generation expression of generated column
 	expr_ interface{}
 }
 
 // expr argument should be pointer of subtype of expression.Expression
 func NewColumn(name string, columnType types.TypeID, hasIndex bool, indexKind index_constants.IndexKind, indexHeaderPageID types.PageID, expr interface{}) *Column {
-	if columnType != types.Varchar {
-		return &Column{name, columnType, columnType.Size(), 0, 0, hasIndex, indexKind, indexHeaderPageID, true, expr}
+	if columnType == types.Decimal {
+		return &Column{name, columnType, columnType.Size(), 0, 0, hasIndex, indexKind, indexHeaderPageID, true, false, false, false, nil, nil, "", "", "", "", types.DecimalDefaultPrecision, types.DecimalDefaultScale, nil, expr}
+	}
+	if !columnType.IsString() {
+		return &Column{name, columnType, columnType.Size(), 0, 0, hasIndex, indexKind, indexHeaderPageID, true, false, false, false, nil, nil, "", "", "", "", 0, 0, nil, expr}
 	}
 
-	return &Column{name, types.Varchar, 4, 255, 0, hasIndex, indexKind, indexHeaderPageID, true, expr}
+	return &Column{name, columnType, 4, 255, 0, hasIndex, indexKind, indexHeaderPageID, true, false, false, false, nil, nil, "", "", "", "", 0, 0, nil, expr}
 }
 
 func (c *Column) IsInlined() bool {
-	return c.columnType != types.Varchar
+	return !c.columnType.IsString()
 }
 
 func (c *Column) GetType() types.TypeID {
@@ -100,6 +117,118 @@ func (c *Column) SetIsLeft(isLeft bool) {
 	c.isLeft = isLeft
 }
 
+func (c *Column) IsPrimaryKey() bool {
+	return c.isPrimaryKey
+}
+
+func (c *Column) SetIsPrimaryKey(isPrimaryKey bool) {
+	c.isPrimaryKey = isPrimaryKey
+}
+
+func (c *Column) IsUnique() bool {
+	return c.isUnique
+}
+
+func (c *Column) SetIsUnique(isUnique bool) {
+	c.isUnique = isUnique
+}
+
+func (c *Column) IsNotNull() bool {
+	return c.isNotNull
+}
+
+func (c *Column) SetIsNotNull(isNotNull bool) {
+	c.isNotNull = isNotNull
+}
+
+// returns nil if the column has no DEFAULT value
+func (c *Column) DefaultValue() *types.Value {
+	return c.defaultValue
+}
+
+func (c *Column) SetDefaultValue(val *types.Value) {
+	c.defaultValue = val
+}
+
+// returns nil if the column has no FOREIGN KEY constraint
+func (c *Column) ForeignKey() *ForeignKey {
+	return c.foreignKey
+}
+
+func (c *Column) SetForeignKey(fk *ForeignKey) {
+	c.foreignKey = fk
+}
+
+func (c *Column) IsGenerated() bool {
+	return c.generatedExprStr != ""
+}
+
+func (c *Column) GeneratedExprStr() string {
+	return c.generatedExprStr
+}
+
+// compiled expression should be set with SetExpr
+func (c *Column) SetGeneratedExprStr(exprStr string) {
+	c.generatedExprStr = exprStr
+}
+
+func (c *Column) IsAutoIncrement() bool {
+	return c.autoIncrementSeq != ""
+}
+
+func (c *Column) AutoIncrementSeqName() string {
+	return c.autoIncrementSeq
+}
+
+func (c *Column) SetAutoIncrementSeqName(seqName string) {
+	c.autoIncrementSeq = seqName
+}
+
+// HasDefaultSeq returns true when DEFAULT value of the column is NEXTVAL of a sequence
+func (c *Column) HasDefaultSeq() bool {
+	return c.defaultSeq != ""
+}
+
+func (c *Column) DefaultSeqName() string {
+	return c.defaultSeq
+}
+
+func (c *Column) SetDefaultSeqName(seqName string) {
+	c.defaultSeq = seqName
+}
+
+// DecimalPrecision and DecimalScale are meaningful only when the column type is Decimal
+func (c *Column) DecimalPrecision() int32 {
+	return c.decimalPrecision
+}
+
+func (c *Column) DecimalScale() int32 {
+	return c.decimalScale
+}
+
+func (c *Column) SetDecimalPrecisionAndScale(precision int32, scale int32) {
+	c.decimalPrecision = precision
+	c.decimalScale = scale
+}
+
+func (c *Column) CheckExprStr() string {
+	return c.checkExprStr
+}
+
+// compiled expression should be set with SetCheckExpr
+func (c *Column) SetCheckExprStr(exprStr string) {
+	c.checkExprStr = exprStr
+}
+
+// returned value should be used with type validation at expression.Expression
+func (c *Column) GetCheckExpr() interface{} {
+	return c.checkExpr
+}
+
+func (c *Column) SetCheckExpr(expr interface{}) {
+	c.checkExpr = expr
+}
+
 // returned value should be used with type validation at expression.Expression
 func (c *Column) GetExpr() interface{} {
 	return c.expr_
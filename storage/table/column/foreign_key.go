@@ -0,0 +1,37 @@
+package column
+
+// ReferentialAction is the action taken on referencing (child) tuples
+// when referenced (parent) tuple is deleted or its key is updated
+type ReferentialAction int32
+
+const (
+	FK_ACTION_RESTRICT ReferentialAction = iota // NO ACTION is treated as RESTRICT
+	FK_ACTION_CASCADE
+	FK_ACTION_SET_NULL
+)
+
+func (a ReferentialAction) String() string {
+	switch a {
+	case FK_ACTION_RESTRICT:
+		return "RESTRICT"
+	case FK_ACTION_CASCADE:
+		return "CASCADE"
+	case FK_ACTION_SET_NULL:
+		return "SET NULL"
+	default:
+		return "unknown"
+	}
+}
+
+// ForeignKey is FOREIGN KEY constraint defined on a column.
+// referenced table is identified with OID for keeping the reference on RENAME TABLE
+type ForeignKey struct {
+	RefTableOID   uint32
+	RefColumnName string
+	OnDelete      ReferentialAction
+	OnUpdate      ReferentialAction
+}
+
+func NewForeignKey(refTableOID uint32, refColumnName string, onDelete ReferentialAction, onUpdate ReferentialAction) *ForeignKey {
+	return &ForeignKey{refTableOID, refColumnName, onDelete, onUpdate}
+}
@@ -75,6 +75,12 @@ func GenTupleForIndexSearch(schema_ *schema.Schema, colIndex uint32, keyVal *typ
 			} else {
 				values = append(values, types.NewInteger(0))
 			}
+		case types.Tinyint, types.Smallint, types.BigInt, types.Decimal, types.Timestamp, types.Date:
+			if idx == int(colIndex) {
+				values = append(values, *keyVal)
+			} else {
+				values = append(values, types.NewNullOfType(columnObj.GetType()))
+			}
 		case types.Float:
 			if idx == int(colIndex) {
 				values = append(values, *keyVal)
@@ -87,6 +93,14 @@ func GenTupleForIndexSearch(schema_ *schema.Schema, colIndex uint32, keyVal *typ
 			} else {
 				values = append(values, types.NewVarchar(""))
 			}
+		case types.Boolean, types.Text, types.Blob, types.JSON:
+			if idx == int(colIndex) {
+				values = append(values, *keyVal)
+			} else {
+				values = append(values, types.NewNullOfType(columnObj.GetType()))
+			}
+		default:
+			panic("illegal type column found in schema")
 		}
 	}
 	return NewTupleFromSchema(values, schema_)
@@ -134,6 +148,11 @@ func (t *Tuple) GetValueInBytes(schema *schema.Schema, colIndex uint32) []byte {
 		binary.Write(retBuf, binary.LittleEndian, *isNull)
 		binary.Write(retBuf, binary.LittleEndian, *v)
 		return retBuf.Bytes()
+	case types.Tinyint, types.Smallint, types.BigInt, types.Decimal, types.Timestamp, types.Date:
+		// NULL flag and the value are fixed length
+		retArr := make([]byte, column.GetType().Size())
+		copy(retArr, t.data[offset:offset+column.GetType().Size()])
+		return retArr
 	case types.Boolean:
 		buf := bytes.NewBuffer(t.data[offset:])
 		isNull := new(bool)
@@ -161,6 +180,13 @@ func (t *Tuple) GetValueInBytes(schema *schema.Schema, colIndex uint32) []byte {
 		retArr = append(retArr, t.data[offset+(1+2):offset+(uint32(*length)+(1+2))]...)
 		return retArr
 		//return data[2:(*length + 2)]
+	case types.Text, types.Blob, types.JSON:
+		// NULL flag, length (uint32) and the bytes
+		length := new(uint32)
+		binary.Read(bytes.NewBuffer(t.data[offset+1:]), binary.LittleEndian, length)
+		retArr := make([]byte, 1+4+*length)
+		copy(retArr, t.data[offset:offset+1+4+*length])
+		return retArr
 	default:
 		panic("illegal type column found in schema")
 	}
@@ -43,3 +43,60 @@ func TestTuple(t *testing.T) {
 	// added info of isNull(bool, 1byte) * 5 to 96(hos no info of isNull)
 	testingpkg.Equals(t, uint32(101), tuple.Size())
 }
+
+func TestTupleIntegerTypes(t *testing.T) {
+	columnA := column.NewColumn("a", types.Tinyint, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	columnB := column.NewColumn("b", types.Smallint, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	columnC := column.NewColumn("c", types.BigInt, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	columnD := column.NewColumn("d", types.BigInt, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+
+	schema := schema.NewSchema([]*column.Column{columnA, columnB, columnC, columnD})
+
+	row := make([]types.Value, 0)
+	expA, expB, expC := int8(-128), int16(32767), int64(1700000000000)
+	row = append(row, types.NewTinyint(expA))
+	row = append(row, types.NewSmallint(expB))
+	row = append(row, types.NewBigInt(expC))
+	row = append(row, types.NewNullOfType(types.BigInt))
+	tuple := NewTupleFromSchema(row, schema)
+
+	testingpkg.Equals(t, expA, tuple.GetValue(schema, 0).ToTinyint())
+	testingpkg.Equals(t, expB, tuple.GetValue(schema, 1).ToSmallint())
+	testingpkg.Equals(t, expC, tuple.GetValue(schema, 2).ToBigInt())
+	testingpkg.SimpleAssert(t, tuple.GetValue(schema, 3).IsNull())
+	testingpkg.Equals(t, types.NewBigInt(expC).Serialize(), tuple.GetValueInBytes(schema, 2))
+
+	// values of different width are compared as int64
+	testingpkg.Equals(t, true, types.NewBigInt(expC).CompareGreaterThan(types.NewInteger(1)))
+	testingpkg.Equals(t, true, types.NewTinyint(127).CompareLessThan(types.NewInteger(200)))
+
+	// NULL flag (1byte) * 4 + 1 + 2 + 8 + 8
+	testingpkg.Equals(t, uint32(23), tuple.Size())
+}
+
+func TestTupleDecimal(t *testing.T) {
+	columnA := column.NewColumn("a", types.Decimal, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	columnB := column.NewColumn("b", types.Decimal, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+	columnC := column.NewColumn("c", types.Decimal, false, index_constants.INDEX_KIND_INVAID, types.PageID(-1), nil)
+
+	schema := schema.NewSchema([]*column.Column{columnA, columnB, columnC})
+
+	valA, _ := types.NewDecimalFromString("-12345678901234567890.123456789")
+	valB, _ := types.NewDecimalFromString("0.05")
+	row := []types.Value{valA, valB, types.NewNullOfType(types.Decimal)}
+	tuple := NewTupleFromSchema(row, schema)
+
+	testingpkg.Equals(t, "-12345678901234567890.123456789", tuple.GetValue(schema, 0).ToString())
+	testingpkg.Equals(t, "0.05", tuple.GetValue(schema, 1).ToString())
+	testingpkg.SimpleAssert(t, tuple.GetValue(schema, 2).IsNull())
+	testingpkg.Equals(t, valB.Serialize(), tuple.GetValueInBytes(schema, 1))
+
+	// values which have different scale are compared exactly
+	valC, _ := types.NewDecimalFromString("0.050")
+	testingpkg.SimpleAssert(t, valB.CompareEquals(valC))
+	testingpkg.SimpleAssert(t, valA.CompareLessThan(types.NewInteger(0)))
+	testingpkg.SimpleAssert(t, valB.CompareGreaterThan(types.NewInteger(0)))
+
+	// NULL flag (1byte) * 3 + (scale (1byte) + unscaled value (16bytes)) * 3
+	testingpkg.Equals(t, uint32(54), tuple.Size())
+}
@@ -18,6 +18,10 @@ const (
 	Varchar
 	Timestamp
 	Null
+	Date
+	Text
+	Blob
+	JSON
 )
 
 //func (t TypeID) Size() uint32 {
@@ -32,12 +36,88 @@ const (
 
 func (t TypeID) Size() uint32 {
 	switch t {
+	case Tinyint:
+		return 1 + 1
+	case Smallint:
+		return 1 + 2
 	case Integer:
 		return 1 + 4
+	case BigInt:
+		return 1 + 8
+	case Decimal:
+		return 1 + 1 + decimalUnscaledBytes
 	case Float:
 		return 1 + 4
 	case Boolean:
 		return 1 + 1
+	case Timestamp:
+		return 1 + 8
+	case Date:
+		return 1 + 4
 	}
 	return 0
 }
+
+// IsIntegerFamily returns true when t is one of Tinyint, Smallint, Integer and BigInt
+func (t TypeID) IsIntegerFamily() bool {
+	return t == Tinyint || t == Smallint || t == Integer || t == BigInt
+}
+
+// IsNumeric returns true when t is integer type, Decimal or Float
+func (t TypeID) IsNumeric() bool {
+	return t.IsIntegerFamily() || t == Decimal || t == Float
+}
+
+// IsString returns true when t is Varchar, Text, Blob or JSON. these are compared as string.
+// JSON value is held as its text
+func (t TypeID) IsString() bool {
+	return t == Varchar || t == Text || t == Blob || t == JSON
+}
+
+// IsTemporal returns true when t is Timestamp or Date
+func (t TypeID) IsTemporal() bool {
+	return t == Timestamp || t == Date
+}
+
+// WiderIntegerType returns wider one of two integer types
+func WiderIntegerType(a TypeID, b TypeID) TypeID {
+	if b.IsIntegerFamily() && b > a {
+		return b
+	}
+	return a
+}
+
+func (t TypeID) String() string {
+	switch t {
+	case Boolean:
+		return "BOOLEAN"
+	case Tinyint:
+		return "TINYINT"
+	case Smallint:
+		return "SMALLINT"
+	case Integer:
+		return "INTEGER"
+	case BigInt:
+		return "BIGINT"
+	case Decimal:
+		return "DECIMAL"
+	case Float:
+		return "FLOAT"
+	case Varchar:
+		return "VARCHAR"
+	case Timestamp:
+		return "TIMESTAMP"
+	case Date:
+		return "DATE"
+	case Text:
+		return "TEXT"
+	case Blob:
+		return "BLOB"
+	case JSON:
+		return "JSON"
+	case Null:
+		return "NULL"
+	default:
+		return "INVALID"
+	}
+}
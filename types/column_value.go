@@ -8,7 +8,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
+	"time"
 )
 
 // A value is an class that represents a view over SQL data stored in
@@ -21,43 +23,99 @@ type Value struct {
 	boolean   *bool
 	varchar   *string
 	float     *float32
+	tinyint   *int8
+	smallint  *int16
+	bigint    *int64
+	decimal   *decimalVal
+	timestamp *int64 // microseconds since Unix epoch
+	date      *int32 // days since Unix epoch
 }
 
 func NewInteger(value int32) Value {
 	tmpBool := false
-	return Value{Integer, &tmpBool, &value, nil, nil, nil}
+	return Value{valueType: Integer, isNull: &tmpBool, integer: &value}
+}
+
+func NewTinyint(value int8) Value {
+	tmpBool := false
+	return Value{valueType: Tinyint, isNull: &tmpBool, tinyint: &value}
+}
+
+func NewSmallint(value int16) Value {
+	tmpBool := false
+	return Value{valueType: Smallint, isNull: &tmpBool, smallint: &value}
+}
+
+func NewBigInt(value int64) Value {
+	tmpBool := false
+	return Value{valueType: BigInt, isNull: &tmpBool, bigint: &value}
 }
 
 func NewFloat(value float32) Value {
 	tmpBool := false
-	return Value{Float, &tmpBool, nil, nil, nil, &value}
+	return Value{valueType: Float, isNull: &tmpBool, float: &value}
 }
 
 func NewBoolean(value bool) Value {
 	tmpBool := false
-	return Value{Boolean, &tmpBool, nil, &value, nil, nil}
+	return Value{valueType: Boolean, isNull: &tmpBool, boolean: &value}
 }
 
 func NewVarchar(value string) Value {
 	tmpBool := false
-	return Value{Varchar, &tmpBool, nil, nil, &value, nil}
+	return Value{valueType: Varchar, isNull: &tmpBool, varchar: &value}
 }
 
-func NewValue(value interface{}) Value {
+// NewText returns TEXT value. TEXT is string which can be longer than the limit of Varchar
+func NewText(value string) Value {
+	tmpBool := false
+	return Value{valueType: Text, isNull: &tmpBool, varchar: &value}
+}
+
+// NewBlob returns BLOB value. bytes are held as string internally
+func NewBlob(value []byte) Value {
 	tmpBool := false
-	switch value.(type) {
+	str := string(value)
+	return Value{valueType: Blob, isNull: &tmpBool, varchar: &str}
+}
+
+// NewIntegerOfType returns value of integer type valueType (Tinyint, Smallint, Integer or BigInt).
+// value is truncated to width of the type
+func NewIntegerOfType(valueType TypeID, value int64) Value {
+	switch valueType {
+	case Tinyint:
+		return NewTinyint(int8(value))
+	case Smallint:
+		return NewSmallint(int16(value))
+	case Integer:
+		return NewInteger(int32(value))
+	case BigInt:
+		return NewBigInt(value)
+	default:
+		panic("not integer type passed")
+	}
+}
+
+func NewValue(value interface{}) Value {
+	switch val := value.(type) {
+	case int8:
+		return NewTinyint(val)
+	case int16:
+		return NewSmallint(val)
 	case int32:
-		val := value.(int32)
-		return Value{Integer, &tmpBool, &val, nil, nil, nil}
+		return NewInteger(val)
+	case int64:
+		return NewBigInt(val)
 	case float32:
-		val := value.(float32)
-		return Value{Float, &tmpBool, nil, nil, nil, &val}
+		return NewFloat(val)
 	case bool:
-		val := value.(bool)
-		return Value{Boolean, &tmpBool, nil, &val, nil, nil}
+		return NewBoolean(val)
 	case string:
-		val := value.(string)
-		return Value{Varchar, &tmpBool, nil, nil, &val, nil}
+		return NewVarchar(val)
+	case []byte:
+		return NewBlob(val)
+	case time.Time:
+		return NewTimestamp(val)
 	default:
 		panic("not supported type passed")
 	}
@@ -67,7 +125,37 @@ func NewValue(value interface{}) Value {
 func NewNull() Value {
 	tmpTrue := true
 	tmpVal := int32(0)
-	return Value{Integer, &tmpTrue, &tmpVal, nil, nil, nil}
+	return Value{valueType: Integer, isNull: &tmpTrue, integer: &tmpVal}
+}
+
+// NULL value which has specified type. it should be used when the value is stored to a column
+func NewNullOfType(valueType TypeID) Value {
+	var ret Value
+	switch valueType {
+	case Tinyint, Smallint, Integer, BigInt:
+		ret = NewIntegerOfType(valueType, 0)
+	case Decimal:
+		ret = NewDecimal(big.NewInt(0), 0)
+	case Timestamp:
+		ret = NewTimestamp(time.Unix(0, 0))
+	case Date:
+		ret = NewDate(time.Unix(0, 0))
+	case Float:
+		ret = NewFloat(0)
+	case Varchar:
+		ret = NewVarchar("")
+	case Text:
+		ret = NewText("")
+	case Blob:
+		ret = NewBlob([]byte{})
+	case JSON:
+		ret = NewJSON("null")
+	case Boolean:
+		ret = NewBoolean(false)
+	default:
+		panic("not supported type passed")
+	}
+	return *ret.SetNull()
 }
 
 // NewValueFromBytes is used for deserialization
@@ -84,6 +172,70 @@ func NewValueFromBytes(data []byte, valueType TypeID) (ret *Value) {
 			vInteger.SetNull()
 		}
 		ret = &vInteger
+	case Tinyint:
+		buf := bytes.NewBuffer(data)
+		isNull := new(bool)
+		binary.Read(buf, binary.LittleEndian, isNull)
+		v := new(int8)
+		binary.Read(buf, binary.LittleEndian, v)
+		vTinyint := NewTinyint(*v)
+		if *isNull {
+			vTinyint.SetNull()
+		}
+		ret = &vTinyint
+	case Smallint:
+		buf := bytes.NewBuffer(data)
+		isNull := new(bool)
+		binary.Read(buf, binary.LittleEndian, isNull)
+		v := new(int16)
+		binary.Read(buf, binary.LittleEndian, v)
+		vSmallint := NewSmallint(*v)
+		if *isNull {
+			vSmallint.SetNull()
+		}
+		ret = &vSmallint
+	case BigInt:
+		buf := bytes.NewBuffer(data)
+		isNull := new(bool)
+		binary.Read(buf, binary.LittleEndian, isNull)
+		v := new(int64)
+		binary.Read(buf, binary.LittleEndian, v)
+		vBigInt := NewBigInt(*v)
+		if *isNull {
+			vBigInt.SetNull()
+		}
+		ret = &vBigInt
+	case Timestamp:
+		buf := bytes.NewBuffer(data)
+		isNull := new(bool)
+		binary.Read(buf, binary.LittleEndian, isNull)
+		v := new(int64)
+		binary.Read(buf, binary.LittleEndian, v)
+		vTimestamp := Value{valueType: Timestamp, isNull: isNull, timestamp: v}
+		if *isNull {
+			vTimestamp.SetNull()
+		}
+		ret = &vTimestamp
+	case Date:
+		buf := bytes.NewBuffer(data)
+		isNull := new(bool)
+		binary.Read(buf, binary.LittleEndian, isNull)
+		v := new(int32)
+		binary.Read(buf, binary.LittleEndian, v)
+		vDate := Value{valueType: Date, isNull: isNull, date: v}
+		if *isNull {
+			vDate.SetNull()
+		}
+		ret = &vDate
+	case Decimal:
+		buf := bytes.NewBuffer(data)
+		isNull := new(bool)
+		binary.Read(buf, binary.LittleEndian, isNull)
+		vDecimal := Value{valueType: Decimal, isNull: isNull, decimal: deserializeDecimalVal(data[1:])}
+		if *isNull {
+			vDecimal.SetNull()
+		}
+		ret = &vDecimal
 	case Float:
 		buf := bytes.NewBuffer(data)
 		isNull := new(bool)
@@ -109,6 +261,19 @@ func NewValueFromBytes(data []byte, valueType TypeID) (ret *Value) {
 			varchar.SetNull()
 		}
 		ret = &varchar
+	case Text, Blob, JSON:
+		// length of TEXT, BLOB and JSON is uint32
+		buf := bytes.NewBuffer(data)
+		isNull := new(bool)
+		binary.Read(buf, binary.LittleEndian, isNull)
+		length := new(uint32)
+		binary.Read(buf, binary.LittleEndian, length)
+		str := string(data[1+4 : *length+(1+4)])
+		vLob := Value{valueType: valueType, isNull: isNull, varchar: &str}
+		if *isNull {
+			vLob.SetNull()
+		}
+		ret = &vLob
 	case Boolean:
 		buf := bytes.NewBuffer(data)
 		isNull := new(bool)
@@ -133,16 +298,25 @@ func (v Value) CompareEquals(right Value) bool {
 	} else if v.IsNull() || right.IsNull() {
 		return false
 	}
+	if isMixedTypes(v, right) {
+		return compareMixedTypes(v, right) == 0
+	}
 	if v.IsInfMax() == true && right.IsInfMax() == true {
 		return true
 	}
 
 	switch v.valueType {
-	case Integer:
-		return *v.integer == *right.integer
+	case Tinyint, Smallint, Integer, BigInt:
+		return v.ToInt64() == right.ToInt64()
+	case Decimal:
+		return compareDecimalVals(v.decimal, right.decimal) == 0
+	case Timestamp:
+		return *v.timestamp == *right.timestamp
+	case Date:
+		return *v.date == *right.date
 	case Float:
 		return *v.float == *right.float
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		return *v.varchar == *right.varchar
 	case Boolean:
 		return *v.boolean == *right.boolean
@@ -156,6 +330,9 @@ func (v Value) CompareNotEquals(right Value) bool {
 	} else if v.IsNull() || right.IsNull() {
 		return true
 	}
+	if isMixedTypes(v, right) {
+		return compareMixedTypes(v, right) != 0
+	}
 	if v.IsInfMax() && right.IsInfMax() {
 		return false
 	} else if v.IsInfMax() || right.IsInfMax() {
@@ -163,11 +340,17 @@ func (v Value) CompareNotEquals(right Value) bool {
 	}
 
 	switch v.valueType {
-	case Integer:
-		return *v.integer != *right.integer
+	case Tinyint, Smallint, Integer, BigInt:
+		return v.ToInt64() != right.ToInt64()
+	case Decimal:
+		return compareDecimalVals(v.decimal, right.decimal) != 0
+	case Timestamp:
+		return *v.timestamp != *right.timestamp
+	case Date:
+		return *v.date != *right.date
 	case Float:
 		return *v.float != *right.float
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		return *v.varchar != *right.varchar
 	case Boolean:
 		return *v.boolean != *right.boolean
@@ -179,6 +362,9 @@ func (v Value) CompareGreaterThan(right Value) bool {
 	if v.IsNull() {
 		return false
 	}
+	if isMixedTypes(v, right) {
+		return compareMixedTypes(v, right) > 0
+	}
 	if v.IsInfMax() && right.IsInfMax() {
 		return false
 	} else if v.IsInfMax() {
@@ -195,11 +381,17 @@ func (v Value) CompareGreaterThan(right Value) bool {
 	}
 
 	switch v.valueType {
-	case Integer:
-		return *v.integer > *right.integer
+	case Tinyint, Smallint, Integer, BigInt:
+		return v.ToInt64() > right.ToInt64()
+	case Decimal:
+		return compareDecimalVals(v.decimal, right.decimal) > 0
+	case Timestamp:
+		return *v.timestamp > *right.timestamp
+	case Date:
+		return *v.date > *right.date
 	case Float:
 		return *v.float > *right.float
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		return *v.varchar > *right.varchar
 	case Boolean:
 		return *v.boolean == true && *right.boolean == false
@@ -213,6 +405,9 @@ func (v Value) CompareGreaterThanOrEqual(right Value) bool {
 	} else if v.IsNull() || right.IsNull() {
 		return false
 	}
+	if isMixedTypes(v, right) {
+		return compareMixedTypes(v, right) >= 0
+	}
 	if v.IsInfMax() && right.IsInfMax() {
 		return true
 	} else if v.IsInfMax() {
@@ -229,11 +424,17 @@ func (v Value) CompareGreaterThanOrEqual(right Value) bool {
 	}
 
 	switch v.valueType {
-	case Integer:
-		return *v.integer >= *right.integer
+	case Tinyint, Smallint, Integer, BigInt:
+		return v.ToInt64() >= right.ToInt64()
+	case Decimal:
+		return compareDecimalVals(v.decimal, right.decimal) >= 0
+	case Timestamp:
+		return *v.timestamp >= *right.timestamp
+	case Date:
+		return *v.date >= *right.date
 	case Float:
 		return *v.float >= *right.float
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		return *v.varchar >= *right.varchar
 	case Boolean:
 		return *v.boolean == *right.boolean || (*v.boolean == true && *right.boolean == false)
@@ -245,6 +446,9 @@ func (v Value) CompareLessThan(right Value) bool {
 	if v.IsNull() {
 		return false
 	}
+	if isMixedTypes(v, right) {
+		return compareMixedTypes(v, right) < 0
+	}
 	if v.IsInfMax() && right.IsInfMax() {
 		return false
 	} else if v.IsInfMax() {
@@ -261,11 +465,17 @@ func (v Value) CompareLessThan(right Value) bool {
 	}
 
 	switch v.valueType {
-	case Integer:
-		return *v.integer < *right.integer
+	case Tinyint, Smallint, Integer, BigInt:
+		return v.ToInt64() < right.ToInt64()
+	case Decimal:
+		return compareDecimalVals(v.decimal, right.decimal) < 0
+	case Timestamp:
+		return *v.timestamp < *right.timestamp
+	case Date:
+		return *v.date < *right.date
 	case Float:
 		return *v.float < *right.float
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		return *v.varchar < *right.varchar
 	case Boolean:
 		return *v.boolean == false && *right.boolean == true
@@ -279,6 +489,9 @@ func (v Value) CompareLessThanOrEqual(right Value) bool {
 	} else if v.IsNull() || right.IsNull() {
 		return false
 	}
+	if isMixedTypes(v, right) {
+		return compareMixedTypes(v, right) <= 0
+	}
 	if v.IsInfMax() && right.IsInfMax() {
 		return true
 	} else if v.IsInfMax() {
@@ -295,11 +508,17 @@ func (v Value) CompareLessThanOrEqual(right Value) bool {
 	}
 
 	switch v.valueType {
-	case Integer:
-		return *v.integer <= *right.integer
+	case Tinyint, Smallint, Integer, BigInt:
+		return v.ToInt64() <= right.ToInt64()
+	case Decimal:
+		return compareDecimalVals(v.decimal, right.decimal) <= 0
+	case Timestamp:
+		return *v.timestamp <= *right.timestamp
+	case Date:
+		return *v.date <= *right.date
 	case Float:
 		return *v.float <= *right.float
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		return *v.varchar <= *right.varchar
 	case Boolean:
 		return *v.boolean == *right.boolean || (*v.boolean == false && *right.boolean == true)
@@ -315,6 +534,35 @@ func (v Value) Serialize() []byte {
 		binary.Write(buf, binary.LittleEndian, *v.isNull)
 		binary.Write(buf, binary.LittleEndian, v.ToInteger())
 		return buf.Bytes()
+	case Tinyint:
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.LittleEndian, *v.isNull)
+		binary.Write(buf, binary.LittleEndian, v.ToTinyint())
+		return buf.Bytes()
+	case Smallint:
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.LittleEndian, *v.isNull)
+		binary.Write(buf, binary.LittleEndian, v.ToSmallint())
+		return buf.Bytes()
+	case BigInt:
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.LittleEndian, *v.isNull)
+		binary.Write(buf, binary.LittleEndian, v.ToBigInt())
+		return buf.Bytes()
+	case Timestamp:
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.LittleEndian, *v.isNull)
+		binary.Write(buf, binary.LittleEndian, *v.timestamp)
+		return buf.Bytes()
+	case Date:
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.LittleEndian, *v.isNull)
+		binary.Write(buf, binary.LittleEndian, *v.date)
+		return buf.Bytes()
+	case Decimal:
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.LittleEndian, *v.isNull)
+		return append(buf.Bytes(), v.decimal.serialize()...)
 	case Float:
 		buf := new(bytes.Buffer)
 		binary.Write(buf, binary.LittleEndian, *v.isNull)
@@ -326,6 +574,12 @@ func (v Value) Serialize() []byte {
 		binary.Write(buf, binary.LittleEndian, uint16(len(v.ToVarchar())))
 		isNullAndLength := buf.Bytes()
 		return append(isNullAndLength, []byte(v.ToVarchar())...)
+	case Text, Blob, JSON:
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.LittleEndian, *v.isNull)
+		binary.Write(buf, binary.LittleEndian, uint32(len(v.ToVarchar())))
+		isNullAndLength := buf.Bytes()
+		return append(isNullAndLength, []byte(v.ToVarchar())...)
 	case Boolean:
 		buf := new(bytes.Buffer)
 		binary.Write(buf, binary.LittleEndian, *v.isNull)
@@ -339,12 +593,14 @@ func (v Value) Serialize() []byte {
 func (v Value) Size() uint32 {
 	// all type occupies the whether NULL or not + 1 byte for the info storage
 	switch v.valueType {
-	case Integer:
+	case Tinyint, Smallint, Integer, BigInt, Decimal, Timestamp, Date:
 		return v.valueType.Size()
 	case Float:
 		return v.valueType.Size()
 	case Varchar:
 		return uint32(len(*v.varchar)) + 1 + 2 // varchar occupies the size of the string + 2 bytes for length storage
+	case Text, Blob, JSON:
+		return uint32(len(*v.varchar)) + 1 + 4 // length of TEXT, BLOB and JSON is stored in 4 bytes
 	case Boolean:
 		return v.valueType.Size()
 	}
@@ -354,11 +610,15 @@ func (v Value) Size() uint32 {
 func (v Value) ToString() string {
 	// all type occupies the whether NULL or not + 1 byte for the info storage
 	switch v.valueType {
-	case Integer:
-		return strconv.Itoa(int(*v.integer))
+	case Tinyint, Smallint, Integer, BigInt:
+		return strconv.FormatInt(v.ToInt64(), 10)
+	case Decimal:
+		return v.decimal.String()
+	case Timestamp, Date:
+		return v.formatTemporal()
 	case Float:
 		return strconv.FormatFloat(float64(*v.float), 'f', -1, 64)
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		return *v.varchar
 	case Boolean:
 		if *v.boolean {
@@ -382,6 +642,41 @@ func (v Value) ToInteger() int32 {
 	return *v.integer
 }
 
+// if you use this to get column value
+// NULL value check is needed in general
+func (v Value) ToTinyint() int8 {
+	return *v.tinyint
+}
+
+// if you use this to get column value
+// NULL value check is needed in general
+func (v Value) ToSmallint() int16 {
+	return *v.smallint
+}
+
+// if you use this to get column value
+// NULL value check is needed in general
+func (v Value) ToBigInt() int64 {
+	return *v.bigint
+}
+
+// ToInt64 returns value of integer types (Tinyint, Smallint, Integer and BigInt) as int64.
+// it is used when integer values which have different width are compared or calculated
+func (v Value) ToInt64() int64 {
+	switch v.valueType {
+	case Tinyint:
+		return int64(*v.tinyint)
+	case Smallint:
+		return int64(*v.smallint)
+	case Integer:
+		return int64(*v.integer)
+	case BigInt:
+		return *v.bigint
+	default:
+		panic("ToInt64 is implemented to integer types only.")
+	}
+}
+
 // if you use this to get column value
 // NULL value check is needed in general
 func (v Value) ToFloat() float32 {
@@ -394,14 +689,32 @@ func (v Value) ToVarchar() string {
 	return *v.varchar
 }
 
+// if you use this to get column value
+// NULL value check is needed in general
+func (v Value) ToBlob() []byte {
+	return []byte(*v.varchar)
+}
+
 func (v Value) ToIFValue() interface{} {
 	switch v.valueType {
+	case Tinyint:
+		return *v.tinyint
+	case Smallint:
+		return *v.smallint
 	case Integer:
 		return *v.integer
+	case BigInt:
+		return *v.bigint
+	case Decimal:
+		return v.decimal.String()
+	case Timestamp, Date:
+		return v.ToTime()
 	case Boolean:
 		return *v.boolean
-	case Varchar:
+	case Varchar, Text, JSON:
 		return *v.varchar
+	case Blob:
+		return v.ToBlob()
 	case Float:
 		return *v.float
 	default:
@@ -421,10 +734,28 @@ func (v Value) SetNull() *Value {
 	case Integer:
 		*v.integer = 0
 		return &v
+	case Tinyint:
+		*v.tinyint = 0
+		return &v
+	case Smallint:
+		*v.smallint = 0
+		return &v
+	case BigInt:
+		*v.bigint = 0
+		return &v
+	case Decimal:
+		*v.decimal = decimalVal{big.NewInt(0), 0}
+		return &v
+	case Timestamp:
+		*v.timestamp = 0
+		return &v
+	case Date:
+		*v.date = 0
+		return &v
 	case Float:
 		*v.float = 0
 		return &v
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		*v.varchar = ""
 		return &v
 	case Boolean:
@@ -443,10 +774,28 @@ func (v Value) SetInfMax() *Value {
 	case Integer:
 		*v.integer = math.MaxInt32
 		return &v
+	case Tinyint:
+		*v.tinyint = math.MaxInt8
+		return &v
+	case Smallint:
+		*v.smallint = math.MaxInt16
+		return &v
+	case BigInt:
+		*v.bigint = math.MaxInt64
+		return &v
+	case Decimal:
+		*v.decimal = decimalVal{new(big.Int).Set(decimalInfUnscaled), 0}
+		return &v
+	case Timestamp:
+		*v.timestamp = math.MaxInt64
+		return &v
+	case Date:
+		*v.date = math.MaxInt32
+		return &v
 	case Float:
 		*v.float = math.MaxFloat32
 		return &v
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		*v.varchar = "SamehadaDBInfMaxValue"
 		return &v
 	case Boolean:
@@ -461,10 +810,28 @@ func (v Value) SetInfMin() *Value {
 	case Integer:
 		*v.integer = math.MinInt32
 		return &v
+	case Tinyint:
+		*v.tinyint = math.MinInt8
+		return &v
+	case Smallint:
+		*v.smallint = math.MinInt16
+		return &v
+	case BigInt:
+		*v.bigint = math.MinInt64
+		return &v
+	case Decimal:
+		*v.decimal = decimalVal{new(big.Int).Neg(decimalInfUnscaled), 0}
+		return &v
+	case Timestamp:
+		*v.timestamp = math.MinInt64
+		return &v
+	case Date:
+		*v.date = math.MinInt32
+		return &v
 	case Float:
 		*v.float = math.SmallestNonzeroFloat32
 		return &v
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		*v.varchar = "SamehadaDBInfMinValue"
 		return &v
 	case Boolean:
@@ -478,9 +845,21 @@ func (v Value) IsInfMax() bool {
 	switch v.valueType {
 	case Integer:
 		return *v.integer == math.MaxInt32
+	case Tinyint:
+		return *v.tinyint == math.MaxInt8
+	case Smallint:
+		return *v.smallint == math.MaxInt16
+	case BigInt:
+		return *v.bigint == math.MaxInt64
+	case Decimal:
+		return v.decimal.unscaled.Cmp(decimalInfUnscaled) == 0
+	case Timestamp:
+		return *v.timestamp == math.MaxInt64
+	case Date:
+		return *v.date == math.MaxInt32
 	case Float:
 		return *v.float == math.MaxFloat32
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		return *v.varchar == "SamehadaDBInfMaxValue"
 	case Boolean:
 		return *v.boolean == true
@@ -492,9 +871,21 @@ func (v Value) IsInfMin() bool {
 	switch v.valueType {
 	case Integer:
 		return *v.integer == math.MinInt32
+	case Tinyint:
+		return *v.tinyint == math.MinInt8
+	case Smallint:
+		return *v.smallint == math.MinInt16
+	case BigInt:
+		return *v.bigint == math.MinInt64
+	case Decimal:
+		return new(big.Int).Neg(v.decimal.unscaled).Cmp(decimalInfUnscaled) == 0
+	case Timestamp:
+		return *v.timestamp == math.MinInt64
+	case Date:
+		return *v.date == math.MinInt32
 	case Float:
 		return *v.float == math.SmallestNonzeroFloat32
-	case Varchar:
+	case Varchar, Text, Blob, JSON:
 		return *v.varchar == "SamehadaDBInfMinValue"
 	case Boolean:
 		return *v.boolean == false
@@ -508,14 +899,22 @@ func (v Value) Add(other *Value) *Value {
 	}
 
 	switch v.valueType {
-	case Integer:
-		ret := NewInteger(*v.integer + *other.integer)
+	case Tinyint, Smallint, Integer, BigInt:
+		// result has type of wider side
+		ret := NewIntegerOfType(WiderIntegerType(v.valueType, other.valueType), v.ToInt64()+other.ToInt64())
+		return &ret
+	case Decimal:
+		// SUM of Decimal doesn't overflow in practice. overflowed result is NULL
+		ret, ok := AddDecimal(v, *other)
+		if !ok {
+			ret = NewNullOfType(Decimal)
+		}
 		return &ret
 	case Float:
 		ret := NewFloat(*v.float + *other.float)
 		return &ret
 	default:
-		panic("Add is implemented to Integer and Float only.")
+		panic("Add is implemented to numeric types only.")
 	}
 }
 
@@ -525,12 +924,27 @@ func (v Value) Max(other *Value) *Value {
 	}
 
 	switch v.valueType {
-	case Integer:
-		if *v.integer >= *other.integer {
-			ret := NewInteger(*v.integer)
+	case Tinyint, Smallint, Integer, BigInt:
+		retType := WiderIntegerType(v.valueType, other.valueType)
+		if v.ToInt64() >= other.ToInt64() {
+			ret := NewIntegerOfType(retType, v.ToInt64())
 			return &ret
 		} else {
-			ret := NewInteger(other.ToInteger())
+			ret := NewIntegerOfType(retType, other.ToInt64())
+			return &ret
+		}
+	case Decimal:
+		if compareDecimalVals(v.decimal, other.toDecimalVal()) >= 0 {
+			return &v
+		} else {
+			ret := other.ToDecimal()
+			return &ret
+		}
+	case Timestamp, Date:
+		if compareMixedTypes(v, *other) >= 0 {
+			return &v
+		} else {
+			ret := *other
 			return &ret
 		}
 	case Float:
@@ -542,7 +956,7 @@ func (v Value) Max(other *Value) *Value {
 			return &ret
 		}
 	default:
-		panic("Max is implemented to Integer and Float only.")
+		panic("Max is implemented to numeric and date/time types only.")
 	}
 }
 
@@ -552,12 +966,27 @@ func (v Value) Min(other *Value) *Value {
 	}
 
 	switch v.valueType {
-	case Integer:
-		if *v.integer <= *other.integer {
-			ret := NewInteger(*v.integer)
+	case Tinyint, Smallint, Integer, BigInt:
+		retType := WiderIntegerType(v.valueType, other.valueType)
+		if v.ToInt64() <= other.ToInt64() {
+			ret := NewIntegerOfType(retType, v.ToInt64())
+			return &ret
+		} else {
+			ret := NewIntegerOfType(retType, other.ToInt64())
+			return &ret
+		}
+	case Decimal:
+		if compareDecimalVals(v.decimal, other.toDecimalVal()) <= 0 {
+			return &v
+		} else {
+			ret := other.ToDecimal()
 			return &ret
+		}
+	case Timestamp, Date:
+		if compareMixedTypes(v, *other) <= 0 {
+			return &v
 		} else {
-			ret := NewInteger(other.ToInteger())
+			ret := *other
 			return &ret
 		}
 	case Float:
@@ -569,6 +998,61 @@ func (v Value) Min(other *Value) *Value {
 			return &ret
 		}
 	default:
-		panic("Max is implemented to Integer and Float only.")
+		panic("Min is implemented to numeric and date/time types only.")
+	}
+}
+
+// numeric values which have different type (and Timestamp and Date) are compared with compareMixedTypes.
+// checks of InfMax and InfMin are skipped because the bounds differ between the types
+func isMixedTypes(left Value, right Value) bool {
+	if left.valueType == right.valueType {
+		return false
+	}
+	return (left.valueType.IsNumeric() && right.valueType.IsNumeric()) || (left.valueType.IsTemporal() && right.valueType.IsTemporal())
+}
+
+// compareMixedTypes returns -1, 0 or 1. numeric values are compared as float64 when either side is Float.
+// otherwise they are compared exactly as Decimal or int64. Date is compared as midnight of the day
+func compareMixedTypes(left Value, right Value) int {
+	switch {
+	case left.valueType.IsTemporal():
+		l, r := left.toMicros(), right.toMicros()
+		if l < r {
+			return -1
+		} else if l > r {
+			return 1
+		}
+		return 0
+	case left.valueType == Float || right.valueType == Float:
+		l, r := left.toFloat64(), right.toFloat64()
+		if l < r {
+			return -1
+		} else if l > r {
+			return 1
+		}
+		return 0
+	case left.valueType == Decimal || right.valueType == Decimal:
+		return compareDecimalVals(left.toDecimalVal(), right.toDecimalVal())
+	default:
+		l, r := left.ToInt64(), right.ToInt64()
+		if l < r {
+			return -1
+		} else if l > r {
+			return 1
+		}
+		return 0
+	}
+}
+
+func (v Value) toFloat64() float64 {
+	switch v.valueType {
+	case Float:
+		return float64(*v.float)
+	case Decimal:
+		// rounded to float32 as same as conversion to Float. so decimal literal like 1.1 equals to Float value 1.1
+		ret, _ := v.ToBigRat().Float32()
+		return float64(ret)
+	default:
+		return float64(v.ToInt64())
 	}
 }
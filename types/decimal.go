@@ -0,0 +1,318 @@
+package types
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	DecimalMaxPrecision     = 38
+	DecimalDefaultPrecision = 10
+	DecimalDefaultScale     = 0
+	// scale of multiplication result is rounded to this value at most
+	DecimalMaxScale = 30
+	// scale of division result is scale of dividend + DecimalDivScaleIncrement
+	DecimalDivScaleIncrement = 4
+	// unscaled value is stored as 128bit two's complement
+	decimalUnscaledBytes = 16
+)
+
+var bigTen = big.NewInt(10)
+
+// 2^128. used for conversion between big.Int and two's complement
+var decimalModulus = new(big.Int).Lsh(big.NewInt(1), 8*decimalUnscaledBytes)
+
+// 10^38 is out of range of DECIMAL(38, 0). so it can be used as InfMax (and its negation as InfMin)
+var decimalInfUnscaled = new(big.Int).Exp(bigTen, big.NewInt(DecimalMaxPrecision), nil)
+
+// decimalVal is exact fixed-point number whose value is unscaled * 10^(-scale)
+type decimalVal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// unscaled is copied
+func NewDecimal(unscaled *big.Int, scale int32) Value {
+	tmpBool := false
+	return Value{valueType: Decimal, isNull: &tmpBool, decimal: &decimalVal{new(big.Int).Set(unscaled), scale}}
+}
+
+// NewDecimalFromString parses string like "-123.45". scale of returned value is the number of digits after the point
+func NewDecimalFromString(str string) (Value, error) {
+	str = strings.TrimSpace(str)
+	negative := false
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+	intPart, fracPart := str, ""
+	if idx := strings.IndexByte(str, '.'); idx >= 0 {
+		intPart, fracPart = str[:idx], str[idx+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return Value{}, errors.New("invalid decimal: " + str)
+	}
+	for _, c := range intPart + fracPart {
+		if c < '0' || c > '9' {
+			return Value{}, errors.New("invalid decimal: " + str)
+		}
+	}
+	if len(fracPart) > DecimalMaxPrecision {
+		return Value{}, errors.New("too many digits after decimal point: " + str)
+	}
+	unscaled, _ := new(big.Int).SetString("0"+intPart+fracPart, 10)
+	if negative {
+		unscaled.Neg(unscaled)
+	}
+	return NewDecimal(unscaled, int32(len(fracPart))), nil
+}
+
+// ValidateDecimalPrecisionAndScale checks precision and scale of DECIMAL(p, s)
+func ValidateDecimalPrecisionAndScale(precision int32, scale int32) error {
+	if precision < 1 || precision > DecimalMaxPrecision {
+		return errors.New("precision of DECIMAL must be between 1 and " + strconv.Itoa(DecimalMaxPrecision) + ".")
+	}
+	if scale < 0 || scale > precision || scale > DecimalMaxScale {
+		return errors.New("scale of DECIMAL must be between 0 and min(precision, " + strconv.Itoa(DecimalMaxScale) + ").")
+	}
+	return nil
+}
+
+// toDecimalVal converts integer, Float and Decimal value to decimalVal
+func (v Value) toDecimalVal() *decimalVal {
+	switch v.valueType {
+	case Decimal:
+		return v.decimal
+	case Tinyint, Smallint, Integer, BigInt:
+		return &decimalVal{big.NewInt(v.ToInt64()), 0}
+	case Float:
+		// shortest representation which is converted back to same float32
+		ret, err := NewDecimalFromString(strconv.FormatFloat(float64(*v.float), 'f', -1, 32))
+		if err != nil {
+			return &decimalVal{big.NewInt(0), 0}
+		}
+		return ret.decimal
+	default:
+		panic("not numeric value passed")
+	}
+}
+
+// returns unscaled value of d converted to scale. rounding is half away from zero
+func (d *decimalVal) rescaled(scale int32) *big.Int {
+	if scale >= d.scale {
+		return new(big.Int).Mul(d.unscaled, pow10(scale-d.scale))
+	}
+	return divRoundHalfAway(d.unscaled, pow10(d.scale-scale))
+}
+
+func divRoundHalfAway(x *big.Int, y *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(x, y, new(big.Int))
+	// |rem| * 2 >= |y| means the remainder is half or more
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(new(big.Int).Abs(y)) >= 0 {
+		if x.Sign()*y.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+func compareDecimalVals(left *decimalVal, right *decimalVal) int {
+	scale := left.scale
+	if right.scale > scale {
+		scale = right.scale
+	}
+	return left.rescaled(scale).Cmp(right.rescaled(scale))
+}
+
+func (d *decimalVal) String() string {
+	digits := new(big.Int).Abs(d.unscaled).String()
+	sign := ""
+	if d.unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= int(d.scale) {
+		digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// serialized as scale (1byte) and unscaled value (128bit two's complement, big endian)
+func (d *decimalVal) serialize() []byte {
+	unscaled := new(big.Int).Set(d.unscaled)
+	if unscaled.Sign() < 0 {
+		unscaled.Add(unscaled, decimalModulus)
+	}
+	ret := make([]byte, 1+decimalUnscaledBytes)
+	ret[0] = byte(int8(d.scale))
+	unscaled.FillBytes(ret[1:])
+	return ret
+}
+
+func deserializeDecimalVal(data []byte) *decimalVal {
+	unscaled := new(big.Int).SetBytes(data[1 : 1+decimalUnscaledBytes])
+	if data[1]&0x80 != 0 {
+		unscaled.Sub(unscaled, decimalModulus)
+	}
+	return &decimalVal{unscaled, int32(int8(data[0]))}
+}
+
+// decimal value whose digits exceed DecimalMaxPrecision can't be stored
+func (d *decimalVal) isOverflowed() bool {
+	return new(big.Int).Abs(d.unscaled).Cmp(decimalInfUnscaled) >= 0
+}
+
+func decimalResult(unscaled *big.Int, scale int32) (Value, bool) {
+	if scale > DecimalMaxScale {
+		unscaled = divRoundHalfAway(unscaled, pow10(scale-DecimalMaxScale))
+		scale = DecimalMaxScale
+	}
+	ret := NewDecimal(unscaled, scale)
+	if ret.decimal.isOverflowed() {
+		return Value{}, false
+	}
+	return ret, true
+}
+
+// ToBigRat returns Decimal value as big.Rat. integer and Float value are also converted
+func (v Value) ToBigRat() *big.Rat {
+	d := v.toDecimalVal()
+	return new(big.Rat).SetFrac(d.unscaled, pow10(d.scale))
+}
+
+// ToDecimal converts integer, Float and Decimal value to Decimal value.
+// scale of converted Float value is the number of digits of its shortest representation
+func (v Value) ToDecimal() Value {
+	d := v.toDecimalVal()
+	return NewDecimal(d.unscaled, d.scale)
+}
+
+// DecimalScale returns the number of digits after the point of Decimal value
+func (v Value) DecimalScale() int32 {
+	return v.decimal.scale
+}
+
+// DecimalDigits returns the number of digits of Decimal value (includes digits after the point)
+func (v Value) DecimalDigits() int32 {
+	abs := new(big.Int).Abs(v.decimal.unscaled)
+	if abs.Sign() == 0 {
+		return 1
+	}
+	return int32(len(abs.String()))
+}
+
+// RescaleDecimal converts numeric value to Decimal value which has the scale.
+// rounding is half away from zero
+func (v Value) RescaleDecimal(scale int32) Value {
+	return NewDecimal(v.toDecimalVal().rescaled(scale), scale)
+}
+
+// FitsDecimal returns true when numeric value can be stored to DECIMAL(precision, scale) column
+func (v Value) FitsDecimal(precision int32, scale int32) bool {
+	return v.RescaleDecimal(scale).DecimalDigits() <= precision
+}
+
+// AddDecimal, SubDecimal, MulDecimal, DivDecimal and ModDecimal calculate numeric values as Decimal.
+// false is returned when the result overflows or divisor is zero
+
+func AddDecimal(left Value, right Value) (Value, bool) {
+	l, r := left.toDecimalVal(), right.toDecimalVal()
+	scale := l.scale
+	if r.scale > scale {
+		scale = r.scale
+	}
+	return decimalResult(new(big.Int).Add(l.rescaled(scale), r.rescaled(scale)), scale)
+}
+
+func SubDecimal(left Value, right Value) (Value, bool) {
+	l, r := left.toDecimalVal(), right.toDecimalVal()
+	scale := l.scale
+	if r.scale > scale {
+		scale = r.scale
+	}
+	return decimalResult(new(big.Int).Sub(l.rescaled(scale), r.rescaled(scale)), scale)
+}
+
+func MulDecimal(left Value, right Value) (Value, bool) {
+	l, r := left.toDecimalVal(), right.toDecimalVal()
+	return decimalResult(new(big.Int).Mul(l.unscaled, r.unscaled), l.scale+r.scale)
+}
+
+func DivDecimal(left Value, right Value) (Value, bool) {
+	l, r := left.toDecimalVal(), right.toDecimalVal()
+	if r.unscaled.Sign() == 0 {
+		return Value{}, false
+	}
+	scale := l.scale + DecimalDivScaleIncrement
+	if scale > DecimalMaxScale {
+		scale = DecimalMaxScale
+	}
+	// l / r = (l.unscaled * 10^(scale - l.scale + r.scale) / r.unscaled) * 10^(-scale)
+	dividend := new(big.Int).Mul(l.unscaled, pow10(scale-l.scale+r.scale))
+	return decimalResult(divRoundHalfAway(dividend, r.unscaled), scale)
+}
+
+// result has sign of left
+func ModDecimal(left Value, right Value) (Value, bool) {
+	l, r := left.toDecimalVal(), right.toDecimalVal()
+	if r.unscaled.Sign() == 0 {
+		return Value{}, false
+	}
+	scale := l.scale
+	if r.scale > scale {
+		scale = r.scale
+	}
+	return decimalResult(new(big.Int).Rem(l.rescaled(scale), r.rescaled(scale)), scale)
+}
+
+// RoundDecimal rounds Decimal value to digits after the point. halves are rounded away from zero.
+// negative digits rounds digits before the point
+func RoundDecimal(val Value, digits int32) Value {
+	d := val.toDecimalVal()
+	if digits >= d.scale {
+		return val
+	}
+	if digits >= 0 {
+		return NewDecimal(d.rescaled(digits), digits)
+	}
+	rounded := divRoundHalfAway(d.unscaled, pow10(d.scale-digits))
+	return NewDecimal(rounded.Mul(rounded, pow10(-digits)), 0)
+}
+
+// FloorDecimal and CeilDecimal return Decimal value whose scale is zero
+func FloorDecimal(val Value) Value {
+	d := val.toDecimalVal()
+	if d.scale <= 0 {
+		return val
+	}
+	// big.Int.Div is Euclidean division. it is floor because divisor is positive
+	return NewDecimal(new(big.Int).Div(d.unscaled, pow10(d.scale)), 0)
+}
+
+func CeilDecimal(val Value) Value {
+	d := val.toDecimalVal()
+	if d.scale <= 0 {
+		return val
+	}
+	floor := new(big.Int).Div(d.unscaled, pow10(d.scale))
+	if new(big.Int).Mul(floor, pow10(d.scale)).Cmp(d.unscaled) != 0 {
+		floor.Add(floor, big.NewInt(1))
+	}
+	return NewDecimal(floor, 0)
+}
+
+func AbsDecimal(val Value) Value {
+	d := val.toDecimalVal()
+	return NewDecimal(new(big.Int).Abs(d.unscaled), d.scale)
+}
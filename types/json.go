@@ -0,0 +1,154 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// JSON value is held as compact text of the document. documents are validated when they are
+// converted from string, so JSON value always has valid text
+
+// NewJSON returns JSON value of text. text must be valid JSON. use ParseJSON to validate it
+func NewJSON(text string) Value {
+	tmpBool := false
+	return Value{valueType: JSON, isNull: &tmpBool, varchar: &text}
+}
+
+// ParseJSON validates str as JSON document and returns JSON value which holds compact text of it
+func ParseJSON(str string) (Value, error) {
+	buf := new(bytes.Buffer)
+	if err := json.Compact(buf, []byte(str)); err != nil {
+		return Value{}, errors.New("invalid JSON text: " + err.Error())
+	}
+	return NewJSON(buf.String()), nil
+}
+
+// jsonPathLeg is a step of JSON path. it is member access when key is not nil and array access otherwise
+type jsonPathLeg struct {
+	key   *string
+	index int
+}
+
+// parseJSONPath parses path like "$.a.b[0]" or `$."key with space"`. wildcards are not supported
+func parseJSONPath(path string) ([]jsonPathLeg, error) {
+	invalidErr := errors.New("invalid JSON path: " + path)
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, invalidErr
+	}
+	legs := make([]jsonPathLeg, 0)
+	for rest := path[1:]; len(rest) > 0; {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			if strings.HasPrefix(rest, "\"") {
+				// quoted key is unquoted as JSON string
+				end := 1
+				for end < len(rest) && (rest[end] != '"' || rest[end-1] == '\\') {
+					end++
+				}
+				if end >= len(rest) {
+					return nil, invalidErr
+				}
+				key, err := strconv.Unquote(rest[:end+1])
+				if err != nil {
+					return nil, invalidErr
+				}
+				legs = append(legs, jsonPathLeg{key: &key})
+				rest = rest[end+1:]
+			} else {
+				end := 0
+				for end < len(rest) && rest[end] != '.' && rest[end] != '[' {
+					end++
+				}
+				key := rest[:end]
+				if key == "" || key == "*" {
+					return nil, invalidErr
+				}
+				legs = append(legs, jsonPathLeg{key: &key})
+				rest = rest[end:]
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, invalidErr
+			}
+			index, err := strconv.Atoi(strings.TrimSpace(rest[1:end]))
+			if err != nil || index < 0 {
+				return nil, invalidErr
+			}
+			legs = append(legs, jsonPathLeg{index: index})
+			rest = rest[end+1:]
+		default:
+			return nil, invalidErr
+		}
+	}
+	return legs, nil
+}
+
+// ValidateJSONPath returns error when path can't be passed to JSONExtract
+func ValidateJSONPath(path string) error {
+	_, err := parseJSONPath(path)
+	return err
+}
+
+// JSONExtract returns the element of val (JSON or string) which is specified by path.
+// NULL of JSON is returned when the element does not exist
+func JSONExtract(val Value, path string) (Value, error) {
+	legs, err := parseJSONPath(path)
+	if err != nil {
+		return Value{}, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(val.ToVarchar()))
+	// numbers are kept as they are written
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return Value{}, errors.New("invalid JSON text: " + err.Error())
+	}
+	for _, leg := range legs {
+		if leg.key != nil {
+			obj, ok := doc.(map[string]interface{})
+			if !ok {
+				return NewNullOfType(JSON), nil
+			}
+			if doc, ok = obj[*leg.key]; !ok {
+				return NewNullOfType(JSON), nil
+			}
+		} else {
+			arr, ok := doc.([]interface{})
+			if !ok || leg.index >= len(arr) {
+				return NewNullOfType(JSON), nil
+			}
+			doc = arr[leg.index]
+		}
+	}
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return Value{}, err
+	}
+	return NewJSON(strings.TrimSuffix(buf.String(), "\n")), nil
+}
+
+// JSONUnquote returns string of val as Varchar. when val is JSON string, quotes are removed
+// and escaped characters are unescaped. other JSON values are returned as their text
+func JSONUnquote(val Value) Value {
+	text := val.ToVarchar()
+	if strings.HasPrefix(text, "\"") {
+		var str string
+		if err := json.Unmarshal([]byte(text), &str); err == nil {
+			return NewVarchar(str)
+		}
+	}
+	return NewVarchar(text)
+}
+
+// IsValidJSON returns true when str is valid JSON document
+func IsValidJSON(str string) bool {
+	return json.Valid([]byte(str))
+}
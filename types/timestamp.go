@@ -0,0 +1,251 @@
+package types
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Timestamp value is microseconds since Unix epoch (UTC) and Date value is days since Unix epoch.
+// time zone is not stored. time.Time passed from outside is converted to UTC
+
+const (
+	microsPerSecond = int64(1000 * 1000)
+	microsPerDay    = 24 * 60 * 60 * microsPerSecond
+
+	timestampFormat = "2006-01-02 15:04:05.999999"
+	dateFormat      = "2006-01-02"
+)
+
+// formats accepted by ParseTimestamp. date only string is midnight of the day
+var timestampParseFormats = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02 15:04",
+	dateFormat,
+}
+
+func NewTimestamp(t time.Time) Value {
+	micros := t.UnixMicro()
+	tmpBool := false
+	return Value{valueType: Timestamp, isNull: &tmpBool, timestamp: &micros}
+}
+
+// NewDate returns the date of t. time of the day is truncated
+func NewDate(t time.Time) Value {
+	days := int32(floorDiv(t.UnixMicro(), microsPerDay))
+	tmpBool := false
+	return Value{valueType: Date, isNull: &tmpBool, date: &days}
+}
+
+// ParseTimestamp parses string like "2024-01-02 15:04:05" or "2024-01-02T15:04:05Z".
+// string without time zone is treated as UTC
+func ParseTimestamp(str string) (Value, error) {
+	t, err := parseTime(str)
+	if err != nil {
+		return Value{}, err
+	}
+	return NewTimestamp(t), nil
+}
+
+// ParseDate parses string like "2024-01-02". time part is allowed and truncated
+func ParseDate(str string) (Value, error) {
+	t, err := parseTime(str)
+	if err != nil {
+		return Value{}, err
+	}
+	return NewDate(t), nil
+}
+
+func parseTime(str string) (time.Time, error) {
+	str = strings.TrimSpace(str)
+	for _, format := range timestampParseFormats {
+		if t, err := time.Parse(format, str); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date/time: " + str)
+}
+
+// floorDiv is division which rounds toward negative infinity. b must be positive
+func floorDiv(a int64, b int64) int64 {
+	if a < 0 && a%b != 0 {
+		return a/b - 1
+	}
+	return a / b
+}
+
+// ToTime returns Timestamp or Date value as time.Time in UTC
+func (v Value) ToTime() time.Time {
+	return time.UnixMicro(v.toMicros()).UTC()
+}
+
+// toMicros returns microseconds since Unix epoch. Date value is midnight of the day
+func (v Value) toMicros() int64 {
+	switch v.valueType {
+	case Timestamp:
+		return *v.timestamp
+	case Date:
+		return int64(*v.date) * microsPerDay
+	default:
+		panic("not date/time value passed")
+	}
+}
+
+// newTemporalOfType returns Timestamp or Date value of t
+func newTemporalOfType(valueType TypeID, t time.Time) Value {
+	if valueType == Date {
+		return NewDate(t)
+	}
+	return NewTimestamp(t)
+}
+
+func (v Value) formatTemporal() string {
+	if v.valueType == Date {
+		return v.ToTime().Format(dateFormat)
+	}
+	return v.ToTime().Format(timestampFormat)
+}
+
+// IsSupportedTimeUnit returns true when unit (lower case) can be passed to AddInterval, TruncTime and ExtractTimeField
+func IsSupportedTimeUnit(unit string) bool {
+	switch unit {
+	case "microsecond", "second", "minute", "hour", "day", "week", "month", "quarter", "year":
+		return true
+	}
+	return false
+}
+
+// AddInterval adds n units to Timestamp or Date value. result has type of val.
+// when month is added to the end of month, result is the end of month (ex: 2024-01-31 + 1 month = 2024-02-29).
+// sub-day units added to Date value are truncated with the time of the day
+func AddInterval(val Value, n int64, unit string) (Value, error) {
+	t := val.ToTime()
+	switch unit {
+	case "microsecond":
+		return newTemporalOfType(val.valueType, t.Add(time.Duration(n)*time.Microsecond)), nil
+	case "second":
+		return newTemporalOfType(val.valueType, t.Add(time.Duration(n)*time.Second)), nil
+	case "minute":
+		return newTemporalOfType(val.valueType, t.Add(time.Duration(n)*time.Minute)), nil
+	case "hour":
+		return newTemporalOfType(val.valueType, t.Add(time.Duration(n)*time.Hour)), nil
+	case "day":
+		return newTemporalOfType(val.valueType, t.AddDate(0, 0, int(n))), nil
+	case "week":
+		return newTemporalOfType(val.valueType, t.AddDate(0, 0, int(n)*7)), nil
+	case "month":
+		return newTemporalOfType(val.valueType, addMonths(t, int(n))), nil
+	case "quarter":
+		return newTemporalOfType(val.valueType, addMonths(t, int(n)*3)), nil
+	case "year":
+		return newTemporalOfType(val.valueType, addMonths(t, int(n)*12)), nil
+	}
+	return Value{}, errors.New("time unit " + unit + " is not supported.")
+}
+
+// time.AddDate normalizes overflowed day (2024-01-31 + 1 month = 2024-03-02). day is clamped to the end of month instead
+func addMonths(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	moved := firstOfMonth.AddDate(0, months, 0)
+	lastDay := moved.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return moved.AddDate(0, 0, day-1)
+}
+
+// TruncTime truncates Timestamp or Date value to the unit. week starts on Monday
+func TruncTime(val Value, unit string) (Value, error) {
+	t := val.ToTime()
+	var ret time.Time
+	switch unit {
+	case "microsecond":
+		ret = t
+	case "second":
+		ret = t.Truncate(time.Second)
+	case "minute":
+		ret = t.Truncate(time.Minute)
+	case "hour":
+		ret = t.Truncate(time.Hour)
+	case "day":
+		ret = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case "week":
+		daysFromMonday := (int(t.Weekday()) + 6) % 7
+		ret = time.Date(t.Year(), t.Month(), t.Day()-daysFromMonday, 0, 0, 0, 0, time.UTC)
+	case "month":
+		ret = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "quarter":
+		ret = time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case "year":
+		ret = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return Value{}, errors.New("time unit " + unit + " is not supported.")
+	}
+	return newTemporalOfType(val.valueType, ret), nil
+}
+
+// ExtractTimeField returns the field of Timestamp or Date value. week is ISO 8601 week number
+func ExtractTimeField(val Value, unit string) (int64, error) {
+	t := val.ToTime()
+	switch unit {
+	case "microsecond":
+		return int64(t.Nanosecond() / 1000), nil
+	case "second":
+		return int64(t.Second()), nil
+	case "minute":
+		return int64(t.Minute()), nil
+	case "hour":
+		return int64(t.Hour()), nil
+	case "day":
+		return int64(t.Day()), nil
+	case "week":
+		_, week := t.ISOWeek()
+		return int64(week), nil
+	case "month":
+		return int64(t.Month()), nil
+	case "quarter":
+		return int64(t.Month()-1)/3 + 1, nil
+	case "year":
+		return int64(t.Year()), nil
+	}
+	return 0, errors.New("time unit " + unit + " is not supported.")
+}
+
+// TimeDiff returns the number of whole units from start to end. month, quarter and year are calendar based
+func TimeDiff(start Value, end Value, unit string) (int64, error) {
+	diff := end.toMicros() - start.toMicros()
+	switch unit {
+	case "microsecond":
+		return diff, nil
+	case "second":
+		return diff / microsPerSecond, nil
+	case "minute":
+		return diff / (60 * microsPerSecond), nil
+	case "hour":
+		return diff / (60 * 60 * microsPerSecond), nil
+	case "day":
+		return diff / microsPerDay, nil
+	case "week":
+		return diff / (7 * microsPerDay), nil
+	case "month", "quarter", "year":
+		s, e := start.ToTime(), end.ToTime()
+		months := int64(e.Year()-s.Year())*12 + int64(e.Month()-s.Month())
+		// the last month is not counted when it is not completed
+		if months > 0 && addMonths(s, int(months)).After(e) {
+			months--
+		} else if months < 0 && addMonths(s, int(months)).Before(e) {
+			months++
+		}
+		switch unit {
+		case "quarter":
+			return months / 3, nil
+		case "year":
+			return months / 12, nil
+		}
+		return months, nil
+	}
+	return 0, errors.New("time unit " + unit + " is not supported.")
+}